@@ -111,6 +111,8 @@ func initCommands(
 		ProviderDevOverrides: providerDevOverrides,
 		UnmanagedProviders:   unmanagedProviders,
 
+		OCIRepositoryStore: ociRepositoryStore(config.OCICredentialsPolicy),
+
 		AllowExperimentalFeatures: experimentsAreAllowed(),
 	}
 
@@ -205,6 +207,12 @@ func initCommands(
 			}, nil
 		},
 
+		"import generate": func() (cli.Command, error) {
+			return &command.ImportGenerateCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"init": func() (cli.Command, error) {
 			return &command.InitCommand{
 				Meta: meta,
@@ -247,6 +255,12 @@ func initCommands(
 			}, nil
 		},
 
+		"plan diff": func() (cli.Command, error) {
+			return &command.PlanDiffCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"providers": func() (cli.Command, error) {
 			return &command.ProvidersCommand{
 				Meta: meta,
@@ -436,6 +450,12 @@ func initCommands(
 			return &command.StateCommand{}, nil
 		},
 
+		"state history": func() (cli.Command, error) {
+			return &command.StateHistoryCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state list": func() (cli.Command, error) {
 			return &command.StateListCommand{
 				Meta: meta,
@@ -498,6 +518,12 @@ func initCommands(
 			}, nil
 		},
 
+		"state rollback": func() (cli.Command, error) {
+			return &command.StateRollbackCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state show": func() (cli.Command, error) {
 			return &command.StateShowCommand{
 				Meta: meta,
@@ -0,0 +1,75 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	orasremote "oras.land/oras-go/v2/registry/remote"
+	orasauth "oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/command/cliconfig/ociauthconfig"
+	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/internal/ociregistry"
+	"github.com/opentofu/opentofu/version"
+)
+
+// ociRepositoryStore returns a function that returns a client for the given
+// OCI repository, which authenticates using the credentials that the OCI
+// credentials policy selects for that repository. Both the provider
+// installer and the module installer use it.
+//
+// The policy is built at most once, on the first call of the returned
+// function, so that we don't need to discover credentials unless we
+// actually interact with an OCI registry.
+func ociRepositoryStore(getOCICredsPolicy ociCredsPolicyBuilder) ociregistry.RepositoryStoreFunc {
+	var once sync.Once
+	var policy ociauthconfig.CredentialsConfigs
+	var policyErr error
+
+	return func(ctx context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryStore, error) {
+		once.Do(func() {
+			policy, policyErr = getOCICredsPolicy(ctx)
+		})
+		if policyErr != nil {
+			return nil, fmt.Errorf("failed to find OCI registry credentials: %w", policyErr)
+		}
+
+		repo, err := orasremote.NewRepository(registryDomain + "/" + repositoryName)
+		if err != nil {
+			return nil, err
+		}
+		repo.Client = &orasauth.Client{
+			Client: httpclient.New(),
+			Header: http.Header{
+				"User-Agent": {httpclient.OpenTofuUserAgent(version.String())},
+			},
+			Cache: orasauth.NewCache(),
+			Credential: func(ctx context.Context, _ string) (orasauth.Credential, error) {
+				source, err := policy.CredentialsSourceForRepository(ctx, registryDomain, repositoryName)
+				if ociauthconfig.IsCredentialsNotFoundError(err) {
+					log.Printf("[TRACE] No credentials for OCI repository %s/%s", registryDomain, repositoryName)
+					return orasauth.EmptyCredential, nil
+				}
+				if err != nil {
+					return orasauth.EmptyCredential, err
+				}
+				creds, err := source.Credentials(ctx, cliconfig.OCICredentialsLookupEnvironment())
+				if ociauthconfig.IsCredentialsNotFoundError(err) {
+					return orasauth.EmptyCredential, nil
+				}
+				if err != nil {
+					return orasauth.EmptyCredential, err
+				}
+				return creds.ToORASCredential(), nil
+			},
+		}
+		return repo, nil
+	}
+}
@@ -203,7 +203,7 @@ func implicitProviderSource(services *disco.Disco) getproviders.Source {
 	return getproviders.MultiSource(searchRules)
 }
 
-func providerSourceForCLIConfigLocation(loc cliconfig.ProviderInstallationLocation, services *disco.Disco, _ ociCredsPolicyBuilder) (getproviders.Source, tfdiags.Diagnostics) {
+func providerSourceForCLIConfigLocation(loc cliconfig.ProviderInstallationLocation, services *disco.Disco, getOCICredsPolicy ociCredsPolicyBuilder) (getproviders.Source, tfdiags.Diagnostics) {
 	if loc == cliconfig.ProviderInstallationDirect {
 		return getproviders.NewMemoizeSource(
 			getproviders.NewRegistrySource(services),
@@ -237,11 +237,11 @@ func providerSourceForCLIConfigLocation(loc cliconfig.ProviderInstallationLocati
 		}
 		return getproviders.NewHTTPMirrorSource(url, services.CredentialsSource()), nil
 
-	// TODO: Once we implement an OCI-Distribution-based mirror source in a
-	// future commit, we'll use the ociCredsPolicyBuilder callback as part of
-	// initializing it so that it can find any credentials it needs to do its work.
-	// For now this is just a stub to illustrate where future work should
-	// continue, to help split this OCI integration work across multiple changes.
+	case cliconfig.ProviderInstallationOCIMirror:
+		return getproviders.NewOCIRegistryMirrorSource(
+			loc.RepositoryForProvider,
+			ociRepositoryStore(getOCICredsPolicy),
+		), nil
 
 	default:
 		// We should not get here because the set of cases above should
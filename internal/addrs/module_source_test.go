@@ -296,6 +296,29 @@ func TestParseModuleSource(t *testing.T) {
 				Package: ModulePackage("https://example.com/module?archive=tar&checksum=blah"),
 			},
 		},
+
+		"OCI repository, tag": {
+			input: "oci://example.com/modules/vpc:1.0.0",
+			want: ModuleSourceRemote{
+				Package: ModulePackage("oci://example.com/modules/vpc:1.0.0"),
+			},
+		},
+		"OCI repository, digest and subdirectory": {
+			input: "oci://example.com:5000/modules/vpc@sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7//child",
+			want: ModuleSourceRemote{
+				Package: ModulePackage("oci://example.com:5000/modules/vpc@sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7"),
+				Subdir:  "child",
+			},
+		},
+		"OCI repository without tag or digest": {
+			input:   "oci://example.com/modules/vpc",
+			wantErr: `invalid OCI module source "oci://example.com/modules/vpc": must specify a tag or a digest`,
+		},
+		"OCI repository with invalid name": {
+			input:   "oci://example.com/Modules/vpc:1.0.0",
+			wantErr: `invalid OCI module source "oci://example.com/Modules/vpc:1.0.0": invalid reference: invalid repository "Modules/vpc"`,
+		},
+
 		"absolute filesystem path": {
 			// Although a local directory isn't really "remote", we do
 			// treat it as such because we still need to do all of the same
@@ -140,10 +140,27 @@ func (b *Local) opApply(
 			return
 		}
 
+		policyResults, moreDiags := b.evaluatePolicies(op, lr.Config, plan, schemas)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			// A plan that failed a mandatory policy is rendered the same way
+			// as a plan that OpenTofu Core failed to complete.
+			plan.Errored = true
+			op.View.Plan(plan, schemas)
+			if policyResults != nil {
+				op.View.PolicyResults(policyResults)
+			}
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
 		trivialPlan := !plan.CanApply()
 		hasUI := op.UIOut != nil && op.UIIn != nil
 		mustConfirm := hasUI && !op.AutoApprove && !trivialPlan
 		op.View.Plan(plan, schemas)
+		if policyResults != nil {
+			op.View.PolicyResults(policyResults)
+		}
 
 		if testHookStopPlanApply != nil {
 			testHookStopPlanApply()
@@ -256,6 +273,18 @@ func (b *Local) opApply(
 				op.View.PlannedChange(change)
 			}
 		}
+
+		// The policies may have changed since the plan was created, so we
+		// evaluate them again before applying a saved plan.
+		policyResults, moreDiags := b.evaluatePolicies(op, lr.Config, plan, schemas)
+		diags = diags.Append(moreDiags)
+		if policyResults != nil {
+			op.View.PolicyResults(policyResults)
+		}
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
 	}
 
 	// Set up our hook for continuous state updates
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sort"
 
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/genconfig"
@@ -142,6 +143,27 @@ func (b *Local) opPlan(
 		return
 	}
 
+	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
+	// Policies are evaluated before the plan is saved, so that a plan which
+	// fails a mandatory policy is saved as errored and cannot be applied.
+	policyResults, moreDiags := b.evaluatePolicies(op, lr.Config, plan, schemas)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		plan.Errored = true
+	}
+
+	// Generated configuration refers to the other resources in the plan by
+	// their IDs, rather than repeating them as literal values.
+	if genconfig.ShouldWriteConfig(op.GenerateConfigOut) {
+		replaceGeneratedConfigReferences(plan)
+	}
+
 	// Record whether this plan includes any side-effects that could be applied.
 	runningOp.PlanEmpty = !plan.CanApply()
 
@@ -194,15 +216,6 @@ func (b *Local) opPlan(
 		}
 	}
 
-	// Render the plan, if we produced one.
-	// (This might potentially be a partial plan with Errored set to true)
-	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
-	diags = diags.Append(moreDiags)
-	if moreDiags.HasErrors() {
-		op.ReportResult(runningOp, diags)
-		return
-	}
-
 	// Write out any generated config, before we render the plan.
 	wroteConfig, moreDiags := maybeWriteGeneratedConfig(plan, op.GenerateConfigOut)
 	diags = diags.Append(moreDiags)
@@ -211,7 +224,12 @@ func (b *Local) opPlan(
 		return
 	}
 
+	// Render the plan, if we produced one.
+	// (This might potentially be a partial plan with Errored set to true)
 	op.View.Plan(plan, schemas)
+	if policyResults != nil {
+		op.View.PolicyResults(policyResults)
+	}
 
 	// If we've accumulated any diagnostics along the way then we'll show them
 	// here just before we show the summary and next steps. This can potentially
@@ -229,6 +247,33 @@ func (b *Local) opPlan(
 	}
 }
 
+// replaceGeneratedConfigReferences rewrites the configuration generated for
+// imported resources, replacing the values that match the ID of another
+// resource instance in the plan with references to it.
+//
+// The resources are rewritten in address order, so that the references left
+// out to avoid dependency cycles are the same from one plan to the next.
+func replaceGeneratedConfigReferences(plan *plans.Plan) {
+	var changes []*plans.ResourceInstanceChangeSrc
+	for _, c := range plan.Changes.Resources {
+		if c.GeneratedConfig != "" {
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Addr.Less(changes[j].Addr)
+	})
+
+	refs := genconfig.NewReferences(plan)
+	for _, c := range changes {
+		var count int
+		c.GeneratedConfig, count = refs.Replace(c.Addr, c.GeneratedConfig)
+		if count > 0 {
+			log.Printf("[DEBUG] backend/local: replaced %d values with references in the generated config for %s", count, c.Addr)
+		}
+	}
+}
+
 func maybeWriteGeneratedConfig(plan *plans.Plan, out string) (wroteConfig bool, diags tfdiags.Diagnostics) {
 	if genconfig.ShouldWriteConfig(out) {
 		diags := genconfig.ValidateTargetFile(out)
@@ -7,6 +7,7 @@ package local
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,6 +26,7 @@ import (
 	"github.com/opentofu/opentofu/internal/initwd"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/policy"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/terminal"
@@ -60,6 +62,71 @@ func TestLocal_planBasic(t *testing.T) {
 	}
 }
 
+func TestLocal_planPolicies(t *testing.T) {
+	tcs := map[string]struct {
+		level      string
+		wantResult backend.OperationResult
+		wantOutput string
+	}{
+		"advisory": {
+			level:      "advisory",
+			wantResult: backend.OperationSuccess,
+			wantOutput: "1 passed, 1 advisory failed, 0 mandatory failed",
+		},
+		"mandatory": {
+			level:      "mandatory",
+			wantResult: backend.OperationFailure,
+			wantOutput: "1 passed, 0 advisory failed, 1 mandatory failed",
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			b := TestLocal(t)
+			TestLocalProvider(t, b, "test", planFixtureSchema())
+
+			op, configCleanup, done := testOperationPlan(t, "./testdata/plan")
+			defer configCleanup()
+			op.PlanRefresh = true
+
+			policies, diags := policy.ParseFile("test.tfpolicy.hcl", []byte(fmt.Sprintf(`
+policy "creates" {
+  condition     = length(plan.resource_changes) > 0
+  error_message = "Nothing to do."
+}
+
+policy "no_foo" {
+  enforcement_level = %q
+  condition         = !anytrue([for rc in plan.resource_changes : rc.name == "foo"])
+  error_message     = "Resources must not be named foo."
+}
+`, tc.level)))
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			op.Policies = policies
+
+			run, err := b.Operation(context.Background(), op)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			<-run.Done()
+			if run.Result != tc.wantResult {
+				t.Fatalf("wrong result %d; want %d", run.Result, tc.wantResult)
+			}
+
+			output := done(t)
+			if got := output.Stdout(); !strings.Contains(got, tc.wantOutput) || !strings.Contains(got, "Resources must not be named foo.") {
+				t.Errorf("unexpected output:\n%s", got)
+			}
+			if tc.wantResult == backend.OperationFailure {
+				if got, want := output.Stderr(), "A mandatory policy failed, so this plan cannot be applied."; !strings.Contains(got, want) {
+					t.Errorf("wrong error output\ngot:  %s\nwant: %s", got, want)
+				}
+			}
+		})
+	}
+}
+
 func TestLocal_planInAutomation(t *testing.T) {
 	b := TestLocal(t)
 	TestLocalProvider(t, b, "test", planFixtureSchema())
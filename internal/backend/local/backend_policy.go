@@ -0,0 +1,65 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/policy"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// evaluatePolicies evaluates the policies of the operation against a plan.
+// It returns nil results if the operation has no policies or the plan is
+// incomplete.
+//
+// The returned diagnostics include an error if a mandatory policy failed or
+// if any policy could not be evaluated, in which case the plan must not be
+// applied.
+func (b *Local) evaluatePolicies(op *backend.Operation, config *configs.Config, plan *plans.Plan, schemas *tofu.Schemas) (*policy.Results, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if op.Policies.Empty() || plan.Errored {
+		return nil, diags
+	}
+
+	log.Printf("[INFO] backend/local: evaluating %d policies", len(op.Policies.Policies))
+	input, err := policy.NewInput(config, plan, schemas)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to evaluate policies",
+			fmt.Sprintf("The plan could not be prepared for evaluating policies: %s.", err),
+		))
+		return nil, diags
+	}
+
+	baseDir := op.ConfigDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	results, moreDiags := op.Policies.Evaluate(input, baseDir)
+	diags = diags.Append(moreDiags)
+
+	switch failed := results.Failed(policy.Mandatory); failed {
+	case 0:
+	case 1:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Mandatory policy failed",
+			"A mandatory policy failed, so this plan cannot be applied.",
+		))
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Mandatory policies failed",
+			fmt.Sprintf("%d mandatory policies failed, so this plan cannot be applied.", failed),
+		))
+	}
+	return results, diags
+}
@@ -0,0 +1,183 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package etcdv3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/legacy/helper/schema"
+)
+
+const (
+	// defaultChunkSize is the default maximum size of a single value written
+	// to etcd. etcd rejects requests larger than 1.5 MiB by default, so this
+	// leaves room for the key and the request overhead.
+	defaultChunkSize = 1024 * 1024
+
+	// defaultLockTTL is the default time to live, in seconds, of the lease
+	// attached to a state lock. The lease is kept alive for as long as the
+	// lock is held, so this is only the time after which a lock held by a
+	// crashed process is released.
+	defaultLockTTL = 60
+)
+
+// New creates a new backend for etcd v3 remote state.
+func New(enc encryption.StateEncryption) backend.Backend {
+	s := &schema.Backend{
+		Schema: map[string]*schema.Schema{
+			"endpoints": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				MinItems:    1,
+				Required:    true,
+				Description: "Endpoints for the etcd cluster.",
+			},
+
+			"username": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Username used to connect to the etcd cluster.",
+				DefaultFunc: schema.EnvDefaultFunc("ETCDV3_USERNAME", ""),
+			},
+
+			"password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Password used to connect to the etcd cluster.",
+				DefaultFunc: schema.EnvDefaultFunc("ETCDV3_PASSWORD", ""),
+			},
+
+			"prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "An optional prefix to be added to keys when storing state in etcd.",
+				Default:     "",
+			},
+
+			"lock": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Whether to lock state access.",
+				Default:     true,
+			},
+
+			"lock_ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Time to live, in seconds, of the lease attached to a state lock.",
+				Default:     defaultLockTTL,
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if v.(int) < 5 {
+						return nil, []error{fmt.Errorf("%q must be at least 5 seconds", k)}
+					}
+					return nil, nil
+				},
+			},
+
+			"chunk_size": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Maximum size in bytes of a single value written to etcd; larger states are split into chunks.",
+				Default:     defaultChunkSize,
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if v.(int) < 1024 {
+						return nil, []error{fmt.Errorf("%q must be at least 1024 bytes", k)}
+					}
+					return nil, nil
+				},
+			},
+
+			"cacert_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path to a PEM-encoded CA bundle with which to verify certificates of TLS-enabled etcd servers.",
+				DefaultFunc: schema.EnvDefaultFunc("ETCDV3_CACERT", ""),
+			},
+
+			"cert_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path to a PEM-encoded certificate to provide to etcd for secure client identification.",
+				DefaultFunc: schema.EnvDefaultFunc("ETCDV3_CERT", ""),
+			},
+
+			"key_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path to a PEM-encoded key to provide to etcd for secure client identification.",
+				DefaultFunc: schema.EnvDefaultFunc("ETCDV3_KEY", ""),
+			},
+		},
+	}
+
+	result := &Backend{Backend: s, encryption: enc}
+	result.Backend.ConfigureFunc = result.configure
+	return result
+}
+
+type Backend struct {
+	*schema.Backend
+	encryption encryption.StateEncryption
+
+	// The fields below are set from configure
+	client    *etcdv3.Client
+	prefix    string
+	lock      bool
+	lockTTL   int64
+	chunkSize int
+}
+
+func (b *Backend) configure(ctx context.Context) error {
+	// Grab the resource data
+	data := schema.FromContextBackendConfig(ctx)
+
+	b.prefix = data.Get("prefix").(string)
+	b.lock = data.Get("lock").(bool)
+	b.lockTTL = int64(data.Get("lock_ttl").(int))
+	b.chunkSize = data.Get("chunk_size").(int)
+
+	config := etcdv3.Config{
+		DialTimeout: 5 * time.Second,
+		Username:    data.Get("username").(string),
+		Password:    data.Get("password").(string),
+		// Chunks are written in single requests, so the client must accept
+		// to send at least one chunk with its key.
+		MaxCallSendMsgSize: b.chunkSize + 64*1024,
+	}
+
+	for _, endpoint := range data.Get("endpoints").([]interface{}) {
+		config.Endpoints = append(config.Endpoints, endpoint.(string))
+	}
+
+	tlsInfo := transport.TLSInfo{
+		TrustedCAFile: data.Get("cacert_path").(string),
+		CertFile:      data.Get("cert_path").(string),
+		KeyFile:       data.Get("key_path").(string),
+	}
+	if tlsInfo.TrustedCAFile != "" || tlsInfo.CertFile != "" || tlsInfo.KeyFile != "" {
+		if (tlsInfo.CertFile == "") != (tlsInfo.KeyFile == "") {
+			return fmt.Errorf("cert_path and key_path must be set together to use TLS client authentication")
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS for etcd: %w", err)
+		}
+		config.TLS = tlsConfig
+	}
+
+	client, err := etcdv3.New(config)
+	if err != nil {
+		return fmt.Errorf("failed to create etcd client: %w", err)
+	}
+	b.client = client
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package etcdv3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	etcdv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// Each workspace is stored under its own key prefix, "<prefix><name>/":
+//
+//   - "<prefix><name>/state" holds the state itself, or a link to its chunks
+//     if the state is larger than the configured chunk size.
+//   - "<prefix><name>/state/<hash>/<n>" hold the chunks of a large state.
+//   - "<prefix><name>/lock" holds the lock info while the state is locked.
+const (
+	stateKeySuffix = "/state"
+	lockKeySuffix  = "/lock"
+)
+
+func (b *Backend) Workspaces() ([]string, error) {
+	res, err := b.client.Get(context.TODO(), b.prefix, etcdv3.WithPrefix(), etcdv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{backend.DefaultStateName}
+	for _, kv := range res.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), b.prefix)
+
+		// Workspace names can't contain slashes, so this only matches the
+		// state keys themselves and not the chunks or locks.
+		name, ok := strings.CutSuffix(key, stateKeySuffix)
+		if !ok || name == "" || strings.Contains(name, "/") || name == backend.DefaultStateName {
+			continue
+		}
+		result = append(result, name)
+	}
+	sort.Strings(result[1:])
+
+	return result, nil
+}
+
+func (b *Backend) DeleteWorkspace(name string, _ bool) error {
+	if name == backend.DefaultStateName || name == "" {
+		return fmt.Errorf("can't delete default state")
+	}
+
+	// Delete everything under the workspace prefix: the state, its chunks
+	// and any lock left behind.
+	_, err := b.client.Delete(context.TODO(), b.workspacePrefix(name), etcdv3.WithPrefix())
+	return err
+}
+
+func (b *Backend) StateMgr(name string) (statemgr.Full, error) {
+	// Build the state client
+	var stateMgr = remote.NewState(
+		&RemoteClient{
+			Client:    b.client,
+			Key:       b.prefix + name + stateKeySuffix,
+			LockKey:   b.prefix + name + lockKeySuffix,
+			LockTTL:   b.lockTTL,
+			ChunkSize: b.chunkSize,
+		},
+		b.encryption,
+	)
+
+	if !b.lock {
+		stateMgr.DisableLocks()
+	}
+
+	// Check to see if this state already exists.
+	// If the state doesn't exist, we have to assume this
+	// is a normal create operation, and take the lock at that point.
+	existing, err := b.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	exists := false
+	for _, s := range existing {
+		if s == name {
+			exists = true
+			break
+		}
+	}
+
+	// Grab a lock, we use this to write an empty state if one doesn't
+	// exist already. We have to write an empty state as a sentinel value
+	// so Workspaces() knows it exists.
+	if !exists {
+		lockInfo := statemgr.NewLockInfo()
+		lockInfo.Operation = "init"
+		lockId, err := stateMgr.Lock(lockInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock state in etcd: %w", err)
+		}
+
+		// Local helper function so we can call it multiple places
+		lockUnlock := func(parent error) error {
+			if err := stateMgr.Unlock(lockId); err != nil {
+				return fmt.Errorf("error unlocking etcd state: %w", err)
+			}
+			return parent
+		}
+
+		if err := stateMgr.RefreshState(); err != nil {
+			err = lockUnlock(err)
+			return nil, err
+		}
+
+		if v := stateMgr.State(); v == nil {
+			if err := stateMgr.WriteState(states.NewState()); err != nil {
+				err = lockUnlock(err)
+				return nil, err
+			}
+			if err := stateMgr.PersistState(nil); err != nil {
+				err = lockUnlock(err)
+				return nil, err
+			}
+		}
+
+		// Unlock, the state should now be initialized
+		if err := lockUnlock(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return stateMgr, nil
+}
+
+// workspacePrefix returns the prefix of all of the keys of a workspace.
+func (b *Backend) workspacePrefix(name string) string {
+	return b.prefix + name + "/"
+}
@@ -0,0 +1,129 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package etcdv3
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	etcdv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+)
+
+// testACC skips the test unless acceptance tests are enabled, and returns
+// the endpoints of the etcd cluster to test against, which are taken from
+// TF_ETCDV3_ENDPOINTS as a comma-separated list. "make test-etcdv3" runs
+// the tests against a local cluster in Docker.
+func testACC(t *testing.T) []interface{} {
+	t.Helper()
+
+	if os.Getenv("TF_ACC") == "" && os.Getenv("TF_ETCDV3_TEST") == "" {
+		t.Skip("etcdv3 backend tests require setting TF_ACC or TF_ETCDV3_TEST")
+	}
+
+	var endpoints []interface{}
+	for _, endpoint := range strings.Split(os.Getenv("TF_ETCDV3_ENDPOINTS"), ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if len(endpoints) == 0 {
+		endpoints = []interface{}{"http://127.0.0.1:2379"}
+	}
+	return endpoints
+}
+
+// testPrefix returns a key prefix that no other test run uses, because the
+// cluster keeps the keys of earlier runs.
+func testPrefix(t *testing.T) string {
+	return fmt.Sprintf("tf-unit/%s/%d/", t.Name(), time.Now().UnixNano())
+}
+
+func testBackendConfig(t *testing.T, config map[string]interface{}) *Backend {
+	t.Helper()
+
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), backend.TestWrapConfig(config)).(*Backend)
+	t.Cleanup(func() {
+		_, _ = b.client.Delete(context.Background(), b.prefix, etcdv3.WithPrefix())
+		b.client.Close()
+	})
+	return b
+}
+
+func TestBackend_impl(t *testing.T) {
+	var _ backend.Backend = new(Backend)
+}
+
+func TestBackendConfig(t *testing.T) {
+	prefix := testPrefix(t)
+	b := testBackendConfig(t, map[string]interface{}{
+		"endpoints": testACC(t),
+		"prefix":    prefix,
+	})
+
+	if b.prefix != prefix {
+		t.Fatalf("wrong prefix %q", b.prefix)
+	}
+	if !b.lock {
+		t.Fatal("locking should be enabled by default")
+	}
+	if b.lockTTL != 60 {
+		t.Fatalf("wrong lock_ttl %d", b.lockTTL)
+	}
+
+	_, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackendConfig_invalid(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"lock_ttl": {
+			"endpoints": []interface{}{"http://127.0.0.1:2379"},
+			"lock_ttl":  1,
+		},
+		"chunk_size": {
+			"endpoints":  []interface{}{"http://127.0.0.1:2379"},
+			"chunk_size": 10,
+		},
+	}
+
+	for name, config := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, errs := backend.TestBackendConfigWarningsAndErrors(t, New(encryption.StateEncryptionDisabled()), backend.TestWrapConfig(config))
+			if len(errs) == 0 {
+				t.Fatalf("expected an error for invalid %s", name)
+			}
+		})
+	}
+}
+
+func TestBackendStates(t *testing.T) {
+	b := testBackendConfig(t, map[string]interface{}{
+		"endpoints": testACC(t),
+		"prefix":    testPrefix(t),
+	})
+
+	backend.TestBackendStates(t, b)
+}
+
+func TestBackendLocks(t *testing.T) {
+	config := map[string]interface{}{
+		"endpoints": testACC(t),
+		"prefix":    testPrefix(t),
+	}
+
+	b1 := testBackendConfig(t, config)
+	b2 := testBackendConfig(t, config)
+
+	backend.TestBackendStateLocks(t, b1, b2)
+	backend.TestBackendStateForceUnlock(t, b1, b2)
+}
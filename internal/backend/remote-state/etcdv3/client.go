@@ -0,0 +1,362 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package etcdv3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+
+	uuid "github.com/hashicorp/go-uuid"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// RemoteClient is a remote client that stores data in etcd.
+type RemoteClient struct {
+	Client  *etcdv3.Client
+	Key     string
+	LockKey string
+
+	// LockTTL is the time to live, in seconds, of the lease attached to the
+	// lock key. The lease is kept alive while the lock is held.
+	LockTTL int64
+
+	// ChunkSize is the maximum size of a single value written to etcd.
+	// States larger than this are split into chunks.
+	ChunkSize int
+
+	mu              sync.Mutex
+	info            *statemgr.LockInfo
+	leaseID         etcdv3.LeaseID
+	cancelKeepAlive context.CancelFunc
+}
+
+// chunkLink is stored at the state key in place of the state when the state
+// is split into chunks.
+type chunkLink struct {
+	Hash   string   `json:"current-hash"`
+	Chunks []string `json:"chunks"`
+}
+
+func (c *RemoteClient) Get() (*remote.Payload, error) {
+	ctx := context.TODO()
+
+	res, err := c.Client.Get(ctx, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	if res.Count == 0 {
+		return nil, nil
+	}
+
+	data := res.Kvs[0].Value
+	if link := parseChunkLink(data); link != nil {
+		// The chunks are read at the same revision as the link, so that
+		// a concurrent write can't give us a mix of two states.
+		chunks := make([][]byte, 0, len(link.Chunks))
+		for _, key := range link.Chunks {
+			chunkRes, err := c.Client.Get(ctx, key, etcdv3.WithRev(res.Header.Revision))
+			if err != nil {
+				return nil, err
+			}
+			if chunkRes.Count == 0 {
+				return nil, fmt.Errorf("state chunk %q is missing", key)
+			}
+			chunks = append(chunks, chunkRes.Kvs[0].Value)
+		}
+
+		data, err = joinChunks(c.Key, link, chunks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	md5 := md5.Sum(data)
+	return &remote.Payload{
+		Data: data,
+		MD5:  md5[:],
+	}, nil
+}
+
+func (c *RemoteClient) Put(data []byte) error {
+	ctx := context.TODO()
+
+	// We need the previous link, if any, to remove its chunks once the new
+	// state has been written.
+	res, err := c.Client.Get(ctx, c.Key)
+	if err != nil {
+		return err
+	}
+	var oldLink *chunkLink
+	if res.Count > 0 {
+		oldLink = parseChunkLink(res.Kvs[0].Value)
+	}
+
+	value, chunks, err := splitState(c.Key, data, c.ChunkSize)
+	if err != nil {
+		return err
+	}
+	// A single request can't be larger than the etcd limit, so each chunk is
+	// written separately. The state only changes when the value is written
+	// below.
+	for _, chunk := range chunks {
+		if _, err := c.Client.Put(ctx, chunk.Key, string(chunk.Data)); err != nil {
+			return err
+		}
+	}
+
+	ops := []etcdv3.Op{etcdv3.OpPut(c.Key, string(value))}
+	if prefix := staleChunksPrefix(c.Key, oldLink, parseChunkLink(value)); prefix != "" {
+		ops = append(ops, etcdv3.OpDelete(prefix, etcdv3.WithPrefix()))
+	}
+	_, err = c.Client.Txn(ctx).Then(ops...).Commit()
+	return err
+}
+
+func (c *RemoteClient) Delete() error {
+	_, err := c.Client.Txn(context.TODO()).Then(
+		etcdv3.OpDelete(c.Key),
+		etcdv3.OpDelete(c.Key+"/", etcdv3.WithPrefix()),
+	).Commit()
+	return err
+}
+
+func (c *RemoteClient) Lock(info *statemgr.LockInfo) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx := context.TODO()
+
+	if info.ID == "" {
+		lockID, err := uuid.GenerateUUID()
+		if err != nil {
+			return "", err
+		}
+		info.ID = lockID
+	}
+	info.Path = c.Key
+
+	// The lock key is attached to a lease, so that it is released
+	// automatically if this process dies without unlocking the state.
+	lease, err := c.Client.Grant(ctx, c.LockTTL)
+	if err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+
+	res, err := c.Client.Txn(ctx).
+		If(etcdv3.Compare(etcdv3.CreateRevision(c.LockKey), "=", 0)).
+		Then(etcdv3.OpPut(c.LockKey, string(info.Marshal()), etcdv3.WithLease(lease.ID))).
+		Else(etcdv3.OpGet(c.LockKey)).
+		Commit()
+	if err != nil {
+		c.revokeLease(lease.ID)
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	if !res.Succeeded {
+		c.revokeLease(lease.ID)
+
+		lockErr := &statemgr.LockError{Err: errors.New("state locked")}
+		if kvs := res.Responses[0].GetResponseRange().Kvs; len(kvs) > 0 {
+			existing := &statemgr.LockInfo{}
+			if err := json.Unmarshal(kvs[0].Value, existing); err != nil {
+				lockErr.Err = fmt.Errorf("state locked, but the lock info is invalid: %w", err)
+			} else {
+				lockErr.Info = existing
+			}
+		}
+		return "", lockErr
+	}
+
+	keepAliveCtx, cancel := context.WithCancel(context.Background())
+	keepAlive, err := c.Client.KeepAlive(keepAliveCtx, lease.ID)
+	if err != nil {
+		cancel()
+		c.revokeLease(lease.ID)
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	go func() {
+		// The responses must be consumed for the keepalive to continue.
+		for range keepAlive {
+		}
+		if keepAliveCtx.Err() == nil {
+			log.Printf("[WARN] etcdv3: lost the lease of the lock on %q", c.Key)
+		}
+	}()
+
+	c.info = info
+	c.leaseID = lease.ID
+	c.cancelKeepAlive = cancel
+
+	return info.ID, nil
+}
+
+func (c *RemoteClient) Unlock(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx := context.TODO()
+
+	res, err := c.Client.Get(ctx, c.LockKey)
+	if err != nil {
+		return &statemgr.LockError{Info: c.info, Err: err}
+	}
+	if res.Count == 0 {
+		c.releaseLease()
+		return &statemgr.LockError{Info: c.info, Err: errors.New("state not locked")}
+	}
+
+	kv := res.Kvs[0]
+	existing := &statemgr.LockInfo{}
+	if err := json.Unmarshal(kv.Value, existing); err != nil {
+		return &statemgr.LockError{Info: c.info, Err: fmt.Errorf("invalid lock info: %w", err)}
+	}
+	if existing.ID != id {
+		return &statemgr.LockError{Info: existing, Err: errors.New("lock ID does not match existing lock")}
+	}
+
+	// Deleting the key only if it's unchanged ensures we don't release a
+	// lock that was taken by someone else in the meantime.
+	txnRes, err := c.Client.Txn(ctx).
+		If(etcdv3.Compare(etcdv3.ModRevision(c.LockKey), "=", kv.ModRevision)).
+		Then(etcdv3.OpDelete(c.LockKey)).
+		Commit()
+	if err != nil {
+		return &statemgr.LockError{Info: existing, Err: err}
+	}
+	if !txnRes.Succeeded {
+		return &statemgr.LockError{Info: existing, Err: errors.New("lock was modified while unlocking")}
+	}
+
+	// The lease may belong to another process if this is a forced unlock,
+	// in which case revoking it also stops that process' keepalive.
+	c.revokeLease(etcdv3.LeaseID(kv.Lease))
+	if etcdv3.LeaseID(kv.Lease) == c.leaseID {
+		c.releaseLease()
+	}
+
+	return nil
+}
+
+// releaseLease stops keeping alive the lease of the lock held by this client.
+func (c *RemoteClient) releaseLease() {
+	if c.cancelKeepAlive != nil {
+		c.cancelKeepAlive()
+	}
+	c.info = nil
+	c.leaseID = etcdv3.NoLease
+	c.cancelKeepAlive = nil
+}
+
+// revokeLease revokes the given lease, deleting all of the keys attached to
+// it. Failures are only logged since the lease expires on its own anyway.
+func (c *RemoteClient) revokeLease(id etcdv3.LeaseID) {
+	if id == etcdv3.NoLease {
+		return
+	}
+	_, err := c.Client.Revoke(context.TODO(), id)
+	if err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		log.Printf("[WARN] etcdv3: failed to revoke lease %x: %s", id, err)
+	}
+}
+
+// stateChunk is a part of a state that is too large to store in a single
+// value.
+type stateChunk struct {
+	Key  string
+	Data []byte
+}
+
+// stateHash returns the checksum used to identify the chunks of a state.
+func stateHash(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitState returns the value to store at the given state key. States larger
+// than limit are split into chunks stored under a prefix specific to the
+// state, and the returned value is a chunk link to them.
+func splitState(stateKey string, data []byte, limit int) ([]byte, []stateChunk, error) {
+	if len(data) <= limit {
+		return data, nil, nil
+	}
+
+	hash := stateHash(data)
+	link := &chunkLink{Hash: hash}
+	var chunks []stateChunk
+	for i, chunk := range split(data, limit) {
+		key := chunkPrefix(stateKey, hash) + strconv.Itoa(i)
+		chunks = append(chunks, stateChunk{Key: key, Data: chunk})
+		link.Chunks = append(link.Chunks, key)
+	}
+
+	value, err := json.Marshal(link)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, chunks, nil
+}
+
+// joinChunks returns the state stored in the given chunks, which must be in
+// the order of the chunk link.
+func joinChunks(stateKey string, link *chunkLink, chunks [][]byte) ([]byte, error) {
+	if len(chunks) != len(link.Chunks) {
+		return nil, fmt.Errorf("the state in %q has %d chunks, but %d were read", stateKey, len(link.Chunks), len(chunks))
+	}
+	var data []byte
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+	if stateHash(data) != link.Hash {
+		return nil, fmt.Errorf("the state in %q doesn't match its checksum", stateKey)
+	}
+	return data, nil
+}
+
+// staleChunksPrefix returns the prefix of the chunks of the previous state
+// that are no longer needed once the new state is written, or an empty string
+// if there are none. newLink is nil if the new state isn't split into chunks.
+func staleChunksPrefix(stateKey string, oldLink, newLink *chunkLink) string {
+	if oldLink == nil || (newLink != nil && oldLink.Hash == newLink.Hash) {
+		return ""
+	}
+	return chunkPrefix(stateKey, oldLink.Hash)
+}
+
+// chunkPrefix returns the prefix of the keys of the chunks of the state with
+// the given hash.
+func chunkPrefix(stateKey, hash string) string {
+	return stateKey + "/" + hash + "/"
+}
+
+// parseChunkLink returns the chunk link stored in the given value, or nil if
+// the value is a state rather than a link.
+func parseChunkLink(data []byte) *chunkLink {
+	var link chunkLink
+	if err := json.Unmarshal(data, &link); err != nil || link.Hash == "" {
+		return nil
+	}
+	return &link
+}
+
+func split(payload []byte, limit int) [][]byte {
+	chunks := make([][]byte, 0, len(payload)/limit+1)
+	for len(payload) > limit {
+		chunks = append(chunks, payload[:limit])
+		payload = payload[limit:]
+	}
+	if len(payload) > 0 {
+		chunks = append(chunks, payload)
+	}
+	return chunks
+}
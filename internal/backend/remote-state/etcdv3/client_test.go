@@ -0,0 +1,280 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package etcdv3
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+func TestRemoteClient_impl(t *testing.T) {
+	var _ remote.Client = new(RemoteClient)
+	var _ remote.ClientLocker = new(RemoteClient)
+}
+
+func TestRemoteClient(t *testing.T) {
+	b := testBackendConfig(t, map[string]interface{}{
+		"endpoints": testACC(t),
+		"prefix":    testPrefix(t),
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClient(t, s.(*remote.State).Client)
+}
+
+func TestRemoteLocks(t *testing.T) {
+	config := map[string]interface{}{
+		"endpoints": testACC(t),
+		"prefix":    testPrefix(t),
+	}
+
+	s1, err := testBackendConfig(t, config).StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := testBackendConfig(t, config).StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestRemoteLocks(t, s1.(*remote.State).Client, s2.(*remote.State).Client)
+}
+
+func TestRemoteClient_lockLease(t *testing.T) {
+	b := testBackendConfig(t, map[string]interface{}{
+		"endpoints": testACC(t),
+		"prefix":    testPrefix(t),
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := s.(*remote.State).Client.(*RemoteClient)
+
+	lockID, err := client.Lock(statemgr.NewLockInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := b.client.Get(context.Background(), client.LockKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Count != 1 || res.Kvs[0].Lease == 0 {
+		t.Fatal("expected the lock key to be attached to a lease")
+	}
+
+	if err := client.Unlock(lockID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unlocking revokes the lease.
+	ttl, err := b.client.TimeToLive(context.Background(), etcdv3.LeaseID(res.Kvs[0].Lease))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl.TTL != -1 {
+		t.Fatalf("expected the lease to be revoked, but its TTL is %d", ttl.TTL)
+	}
+}
+
+func TestRemoteClient_chunks(t *testing.T) {
+	b := testBackendConfig(t, map[string]interface{}{
+		"endpoints":  testACC(t),
+		"prefix":     testPrefix(t),
+		"chunk_size": 1024,
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := s.(*remote.State).Client.(*RemoteClient)
+
+	chunkCount := func() int64 {
+		t.Helper()
+		res, err := b.client.Get(context.Background(), client.Key+"/", etcdv3.WithPrefix(), etcdv3.WithCountOnly())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Count
+	}
+
+	for _, size := range []int{4000, 2500, 100, 3000} {
+		data := bytes.Repeat([]byte{'a' + byte(size%26)}, size)
+		if err := client.Put(data); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := client.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p.Data, data) {
+			t.Fatalf("wrong data read back for a state of %d bytes", size)
+		}
+
+		// Only the chunks of the current state are kept.
+		want := int64((size + 1023) / 1024)
+		if size <= 1024 {
+			want = 0
+		}
+		if got := chunkCount(); got != want {
+			t.Fatalf("wrong number of chunks for a state of %d bytes: got %d, want %d", size, got, want)
+		}
+	}
+
+	if err := client.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if got := chunkCount(); got != 0 {
+		t.Fatalf("expected no chunks after delete, got %d", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		data  string
+		limit int
+		want  []string
+	}{
+		{"", 4, []string{}},
+		{"abc", 4, []string{"abc"}},
+		{"abcd", 4, []string{"abcd"}},
+		{"abcde", 4, []string{"abcd", "e"}},
+		{"abcdefgh", 4, []string{"abcd", "efgh"}},
+		{"abcdefghi", 3, []string{"abc", "def", "ghi"}},
+	}
+	for _, test := range tests {
+		got := make([]string, 0)
+		for _, chunk := range split([]byte(test.data), test.limit) {
+			got = append(got, string(chunk))
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("wrong chunks for %q with limit %d:\n%s", test.data, test.limit, diff)
+		}
+	}
+}
+
+func TestSplitState(t *testing.T) {
+	small := []byte(`{"version":4}`)
+	value, chunks, err := splitState("tfstate", small, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(value, small) || len(chunks) != 0 {
+		t.Fatalf("expected a small state to be stored as is, got %q and %d chunks", value, len(chunks))
+	}
+	if link := parseChunkLink(value); link != nil {
+		t.Fatalf("expected a small state not to be read as a chunk link, got %#v", link)
+	}
+
+	large := bytes.Repeat([]byte("0123456789"), 250)
+	value, chunks, err = splitState("tfstate", large, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := parseChunkLink(value)
+	if link == nil {
+		t.Fatalf("expected a chunk link, got %q", value)
+	}
+	hash := stateHash(large)
+	wantKeys := []string{"tfstate/" + hash + "/0", "tfstate/" + hash + "/1", "tfstate/" + hash + "/2"}
+	if diff := cmp.Diff(wantKeys, link.Chunks); diff != "" {
+		t.Fatalf("wrong chunk keys:\n%s", diff)
+	}
+
+	data := make([][]byte, 0, len(chunks))
+	for i, chunk := range chunks {
+		if chunk.Key != link.Chunks[i] {
+			t.Fatalf("chunk %d has key %q, but the link has %q", i, chunk.Key, link.Chunks[i])
+		}
+		if len(chunk.Data) > 1024 {
+			t.Fatalf("chunk %d is %d bytes, larger than the limit", i, len(chunk.Data))
+		}
+		data = append(data, chunk.Data)
+	}
+	got, err := joinChunks("tfstate", link, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, large) {
+		t.Fatal("the joined chunks don't match the state")
+	}
+
+	// Chunks that don't match the checksum, or are missing, are rejected.
+	tampered := append([][]byte{}, data...)
+	tampered[1] = bytes.Repeat([]byte("x"), len(data[1]))
+	if _, err := joinChunks("tfstate", link, tampered); err == nil {
+		t.Fatal("expected an error for chunks that don't match the checksum")
+	}
+	if _, err := joinChunks("tfstate", link, data[:2]); err == nil {
+		t.Fatal("expected an error for a missing chunk")
+	}
+}
+
+func TestParseChunkLink(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want *chunkLink
+	}{
+		"state": {
+			`{"version":4,"serial":1,"resources":[]}`,
+			nil,
+		},
+		"not json": {
+			`not json`,
+			nil,
+		},
+		"empty hash": {
+			`{"current-hash":"","chunks":["a"]}`,
+			nil,
+		},
+		"link": {
+			`{"current-hash":"abc","chunks":["tfstate/abc/0","tfstate/abc/1"]}`,
+			&chunkLink{Hash: "abc", Chunks: []string{"tfstate/abc/0", "tfstate/abc/1"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, parseChunkLink([]byte(test.data))); diff != "" {
+				t.Errorf("wrong chunk link:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStaleChunksPrefix(t *testing.T) {
+	old := &chunkLink{Hash: "old"}
+	tests := map[string]struct {
+		oldLink, newLink *chunkLink
+		want             string
+	}{
+		"no previous chunks":      {nil, &chunkLink{Hash: "new"}, ""},
+		"unchanged state":         {old, &chunkLink{Hash: "old"}, ""},
+		"changed state":           {old, &chunkLink{Hash: "new"}, "tfstate/old/"},
+		"state no longer chunked": {old, nil, "tfstate/old/"},
+		"neither chunked":         {nil, nil, ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := staleChunksPrefix("tfstate", test.oldLink, test.newLink); got != test.want {
+				t.Errorf("wrong prefix: got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -19,8 +19,10 @@ import (
 )
 
 const (
-	statesTableName = "states"
-	statesIndexName = "states_by_name"
+	statesTableName        = "states"
+	statesIndexName        = "states_by_name"
+	statesHistoryTableName = "states_history"
+	statesHistoryIndexName = "states_history_by_name"
 )
 
 func defaultBoolFunc(k string, dv bool) schema.SchemaDefaultFunc {
@@ -71,6 +73,26 @@ func New(enc encryption.StateEncryption) backend.Backend {
 				Description: "If set to `true`, OpenTofu won't try to create the Postgres index",
 				DefaultFunc: defaultBoolFunc("PG_SKIP_INDEX_CREATION", false),
 			},
+
+			"enable_history": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If set to `true`, OpenTofu will keep previous versions of the state in a history table",
+				DefaultFunc: defaultBoolFunc("PG_ENABLE_HISTORY", false),
+			},
+
+			"history_retention": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Maximum number of state versions to keep per workspace when history is enabled; 0 keeps all versions",
+				DefaultFunc: schema.EnvDefaultFunc("PG_HISTORY_RETENTION", 0),
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if v.(int) < 0 {
+						return nil, []error{fmt.Errorf("%q must not be negative", k)}
+					}
+					return nil, nil
+				},
+			},
 		},
 	}
 
@@ -88,6 +110,9 @@ type Backend struct {
 	configData *schema.ResourceData
 	connStr    string
 	schemaName string
+
+	enableHistory    bool
+	historyRetention int
 }
 
 func (b *Backend) configure(ctx context.Context) error {
@@ -97,6 +122,8 @@ func (b *Backend) configure(ctx context.Context) error {
 
 	b.connStr = data.Get("conn_str").(string)
 	b.schemaName = pq.QuoteIdentifier(data.Get("schema_name").(string))
+	b.enableHistory = data.Get("enable_history").(bool)
+	b.historyRetention = data.Get("history_retention").(int)
 
 	db, err := sql.Open("postgres", b.connStr)
 	if err != nil {
@@ -139,6 +166,21 @@ func (b *Backend) configure(ctx context.Context) error {
 		if _, err := db.Exec(fmt.Sprintf(query, b.schemaName, statesTableName)); err != nil {
 			return err
 		}
+
+		if b.enableHistory {
+			query = `CREATE TABLE IF NOT EXISTS %s.%s (
+				id bigserial PRIMARY KEY,
+				name text NOT NULL,
+				serial bigint NOT NULL,
+				lineage text NOT NULL,
+				data text,
+				lock_info text,
+				created_at timestamp with time zone NOT NULL DEFAULT now()
+				)`
+			if _, err := db.Exec(fmt.Sprintf(query, b.schemaName, statesHistoryTableName)); err != nil {
+				return err
+			}
+		}
 	}
 
 	if !data.Get("skip_index_creation").(bool) {
@@ -146,6 +188,13 @@ func (b *Backend) configure(ctx context.Context) error {
 		if _, err := db.Exec(fmt.Sprintf(query, statesIndexName, b.schemaName, statesTableName)); err != nil {
 			return err
 		}
+
+		if b.enableHistory {
+			query = `CREATE INDEX IF NOT EXISTS %s ON %s.%s (name, id)`
+			if _, err := db.Exec(fmt.Sprintf(query, statesHistoryIndexName, b.schemaName, statesHistoryTableName)); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Assign db after its schema is prepared.
@@ -51,6 +51,14 @@ func (b *Backend) DeleteWorkspace(name string, _ bool) error {
 		return err
 	}
 
+	if b.enableHistory {
+		query = `DELETE FROM %s.%s WHERE name = $1`
+		_, err = b.db.Exec(fmt.Sprintf(query, b.schemaName, statesHistoryTableName), name)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -61,6 +69,9 @@ func (b *Backend) StateMgr(name string) (statemgr.Full, error) {
 			Client:     b.db,
 			Name:       name,
 			SchemaName: b.schemaName,
+
+			History:          b.enableHistory,
+			HistoryRetention: b.historyRetention,
 		},
 		b.encryption,
 	)
@@ -8,8 +8,11 @@ package pg
 import (
 	"crypto/md5"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"strconv"
+	"time"
 
 	uuid "github.com/hashicorp/go-uuid"
 	_ "github.com/lib/pq"
@@ -23,9 +26,17 @@ type RemoteClient struct {
 	Name       string
 	SchemaName string
 
+	// History enables the retention of every written state version in the
+	// history table. HistoryRetention limits the number of retained
+	// versions per workspace; zero means that all versions are kept.
+	History          bool
+	HistoryRetention int
+
 	info *statemgr.LockInfo
 }
 
+var _ remote.ClientVersioner = (*RemoteClient)(nil)
+
 func (c *RemoteClient) Get() (*remote.Payload, error) {
 	query := `SELECT data FROM %s.%s WHERE name = $1`
 	row := c.Client.QueryRow(fmt.Sprintf(query, c.SchemaName, statesTableName), c.Name)
@@ -46,17 +57,68 @@ func (c *RemoteClient) Get() (*remote.Payload, error) {
 	}
 }
 
-func (c *RemoteClient) Put(data []byte) error {
-	query := `INSERT INTO %s.%s (name, data) VALUES ($1, $2)
+func (c *RemoteClient) putQuery() string {
+	return fmt.Sprintf(`INSERT INTO %s.%s (name, data) VALUES ($1, $2)
 		ON CONFLICT (name) DO UPDATE
-		SET data = $2 WHERE %s.name = $1`
-	_, err := c.Client.Exec(fmt.Sprintf(query, c.SchemaName, statesTableName, statesTableName), c.Name, data)
+		SET data = $2 WHERE %s.name = $1`, c.SchemaName, statesTableName, statesTableName)
+}
+
+// Put stores the state without retaining a version of it. remote.State
+// calls PutVersion instead.
+func (c *RemoteClient) Put(data []byte) error {
+	_, err := c.Client.Exec(c.putQuery(), c.Name, data)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+func (c *RemoteClient) PutVersion(data []byte, serial uint64, lineage string) error {
+	if !c.History {
+		return c.Put(data)
+	}
+
+	// With history enabled, the current state and its history entry are
+	// written in one transaction so that they can't get out of sync.
+	tx, err := c.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // this is a no-op once the transaction is committed
+
+	if _, err := tx.Exec(c.putQuery(), c.Name, data); err != nil {
+		return err
+	}
+	if err := c.putHistory(tx, data, serial, lineage); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// putHistory records the given state data as a new version in the history
+// table, and prunes versions beyond the configured retention.
+func (c *RemoteClient) putHistory(tx *sql.Tx, data []byte, serial uint64, lineage string) error {
+	var lockInfo []byte
+	if c.info != nil {
+		lockInfo = c.info.Marshal()
+	}
+
+	query := `INSERT INTO %s.%s (name, serial, lineage, data, lock_info) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(fmt.Sprintf(query, c.SchemaName, statesHistoryTableName), c.Name, serial, lineage, data, lockInfo); err != nil {
+		return err
+	}
+
+	if c.HistoryRetention > 0 {
+		query = `DELETE FROM %s.%s WHERE name = $1 AND id NOT IN (
+			SELECT id FROM %s.%s WHERE name = $1 ORDER BY id DESC LIMIT $2
+			)`
+		if _, err := tx.Exec(fmt.Sprintf(query, c.SchemaName, statesHistoryTableName, c.SchemaName, statesHistoryTableName), c.Name, c.HistoryRetention); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *RemoteClient) Delete() error {
 	query := `DELETE FROM %s.%s WHERE name = $1`
 	_, err := c.Client.Exec(fmt.Sprintf(query, c.SchemaName, statesTableName), c.Name)
@@ -66,6 +128,71 @@ func (c *RemoteClient) Delete() error {
 	return nil
 }
 
+func (c *RemoteClient) Versions() ([]*remote.StateVersion, error) {
+	if !c.History {
+		return nil, remote.ErrHistoryDisabled
+	}
+
+	query := `SELECT id, serial, lineage, lock_info, created_at FROM %s.%s WHERE name = $1 ORDER BY id DESC`
+	rows, err := c.Client.Query(fmt.Sprintf(query, c.SchemaName, statesHistoryTableName), c.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*remote.StateVersion
+	for rows.Next() {
+		var id int64
+		var lockInfo []byte
+		v := &remote.StateVersion{}
+		if err := rows.Scan(&id, &v.Serial, &v.Lineage, &lockInfo, &v.Created); err != nil {
+			return nil, err
+		}
+		v.ID = strconv.FormatInt(id, 10)
+		v.Created = v.Created.In(time.UTC)
+		if len(lockInfo) > 0 {
+			v.LockInfo = &statemgr.LockInfo{}
+			if err := json.Unmarshal(lockInfo, v.LockInfo); err != nil {
+				return nil, fmt.Errorf("invalid lock info for state version %s: %w", v.ID, err)
+			}
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *RemoteClient) GetVersion(id string) (*remote.Payload, error) {
+	if !c.History {
+		return nil, remote.ErrHistoryDisabled
+	}
+
+	versionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state version ID %q", id)
+	}
+
+	query := `SELECT data FROM %s.%s WHERE name = $1 AND id = $2`
+	row := c.Client.QueryRow(fmt.Sprintf(query, c.SchemaName, statesHistoryTableName), c.Name, versionID)
+	var data []byte
+	err = row.Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		md5 := md5.Sum(data)
+		return &remote.Payload{
+			Data: data,
+			MD5:  md5[:],
+		}, nil
+	}
+}
+
 func (c *RemoteClient) Lock(info *statemgr.LockInfo) (string, error) {
 	var err error
 	var lockID string
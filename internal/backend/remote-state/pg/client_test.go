@@ -16,6 +16,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/encryption/enctest"
 	"github.com/opentofu/opentofu/internal/states/remote"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
@@ -23,6 +24,7 @@ import (
 func TestRemoteClient_impl(t *testing.T) {
 	var _ remote.Client = new(RemoteClient)
 	var _ remote.ClientLocker = new(RemoteClient)
+	var _ remote.ClientVersioner = new(RemoteClient)
 }
 
 func TestRemoteClient(t *testing.T) {
@@ -83,6 +85,185 @@ func TestRemoteLocks(t *testing.T) {
 	remote.TestRemoteLocks(t, s1.(*remote.State).Client, s2.(*remote.State).Client)
 }
 
+func TestRemoteClientVersions(t *testing.T) {
+	testACC(t)
+	connStr := getDatabaseUrl()
+	schemaName := fmt.Sprintf("terraform_%s", t.Name())
+	dbCleaner, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dropSchema(t, dbCleaner, schemaName)
+
+	config := backend.TestWrapConfig(map[string]interface{}{
+		"conn_str":       connStr,
+		"schema_name":    schemaName,
+		"enable_history": true,
+	})
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClientVersioner(t, s.(*remote.State).Client.(remote.ClientVersioner))
+}
+
+func TestRemoteClientVersions_fake(t *testing.T) {
+	client, _ := testFakeClient(t, 0)
+	remote.TestClientVersioner(t, client)
+
+	// Versions that were never retained are reported as missing.
+	p, err := client.GetVersion("1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != nil {
+		t.Fatalf("expected no payload for an unknown version, got %q", p.Data)
+	}
+	if _, err := client.GetVersion("latest"); err == nil {
+		t.Fatal("expected an error for an invalid version ID")
+	}
+}
+
+func TestRemoteClientVersions_putAtomic(t *testing.T) {
+	client, d := testFakeClient(t, 0)
+	if err := client.PutVersion([]byte(`{"serial": 1}`), 1, "atomic"); err != nil {
+		t.Fatal(err)
+	}
+
+	// If the version can't be recorded, the state must not change either.
+	d.historyErr = fmt.Errorf("history unavailable")
+	if err := client.PutVersion([]byte(`{"serial": 2}`), 2, "atomic"); err == nil {
+		t.Fatal("expected an error when the version can't be recorded")
+	}
+
+	p, err := client.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || string(p.Data) != `{"serial": 1}` {
+		t.Fatalf("expected the previous state to be kept, got %v", p)
+	}
+	versions, err := client.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].Serial != 1 {
+		t.Fatalf("expected only the first version to be retained, got %d versions", len(versions))
+	}
+}
+
+func TestRemoteClientVersions_rollback(t *testing.T) {
+	// This test runs without a PostgreSQL server, against a fake database
+	// that only serves the queries for the state and its history.
+	client, _ := testFakeClient(t, 0)
+
+	remote.TestClientVersionerRollback(t, client, encryption.StateEncryptionDisabled())
+}
+
+func TestRemoteClientVersions_rollbackEncrypted(t *testing.T) {
+	// The versions of an encrypted state must record its serial and lineage,
+	// which can't be read from the stored data.
+	client, _ := testFakeClient(t, 0)
+
+	remote.TestClientVersionerRollback(t, client, enctest.EncryptionRequired().State())
+}
+
+func TestRemoteClientVersions_retention(t *testing.T) {
+	testACC(t)
+	connStr := getDatabaseUrl()
+	schemaName := fmt.Sprintf("terraform_%s", t.Name())
+	dbCleaner, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dropSchema(t, dbCleaner, schemaName)
+
+	config := backend.TestWrapConfig(map[string]interface{}{
+		"conn_str":          connStr,
+		"schema_name":       schemaName,
+		"enable_history":    true,
+		"history_retention": 2,
+	})
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRetention(t, s.(*remote.State).Client.(remote.ClientVersioner))
+}
+
+func TestRemoteClientVersions_retentionFake(t *testing.T) {
+	client, _ := testFakeClient(t, 2)
+	testRetention(t, client)
+}
+
+// testRetention tests a client that retains 2 versions of the state.
+func testRetention(t *testing.T, client remote.ClientVersioner) {
+	t.Helper()
+
+	for i := 0; i < 3; i++ {
+		if err := client.PutVersion([]byte(fmt.Sprintf(`{"serial": %d, "lineage": "retention"}`, i+10)), uint64(i+10), "retention"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := client.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 retained versions, got %d", len(versions))
+	}
+	if versions[0].Serial != 12 || versions[1].Serial != 11 {
+		t.Fatalf("wrong versions retained: serials %d and %d", versions[0].Serial, versions[1].Serial)
+	}
+}
+
+func TestRemoteClientVersions_disabledFake(t *testing.T) {
+	client, _ := testFakeClient(t, 0)
+	client.History = false
+
+	if err := client.PutVersion([]byte(`{"serial": 1}`), 1, "disabled"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Versions(); err != remote.ErrHistoryDisabled {
+		t.Fatalf("expected history disabled error, got: %v", err)
+	}
+	if _, err := client.GetVersion("1"); err != remote.ErrHistoryDisabled {
+		t.Fatalf("expected history disabled error, got: %v", err)
+	}
+}
+
+func TestRemoteClientVersions_disabled(t *testing.T) {
+	testACC(t)
+	connStr := getDatabaseUrl()
+	schemaName := fmt.Sprintf("terraform_%s", t.Name())
+	dbCleaner, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dropSchema(t, dbCleaner, schemaName)
+
+	config := backend.TestWrapConfig(map[string]interface{}{
+		"conn_str":    connStr,
+		"schema_name": schemaName,
+	})
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), config).(*Backend)
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.(*remote.State).Client.(remote.ClientVersioner).Versions(); err != remote.ErrHistoryDisabled {
+		t.Fatalf("expected history disabled error, got: %v", err)
+	}
+}
+
 // TestConcurrentCreationLocksInDifferentSchemas tests whether backends with different schemas
 // affect each other while taking global workspace creation locks.
 func TestConcurrentCreationLocksInDifferentSchemas(t *testing.T) {
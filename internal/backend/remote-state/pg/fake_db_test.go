@@ -0,0 +1,271 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package pg
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/backend"
+)
+
+// newFakeDB returns a database that serves the queries that RemoteClient
+// makes for its state and state history from memory, so that they can be
+// tested without a PostgreSQL server. It doesn't support locking, and
+// transactions only support being rolled back, not isolation.
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDriver) {
+	t.Helper()
+
+	d := &fakeDriver{states: make(map[string][]byte)}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db, d
+}
+
+// testFakeClient returns a client with history enabled, which retains the
+// given number of versions, that uses a fake database.
+func testFakeClient(t *testing.T, retention int) (*RemoteClient, *fakeDriver) {
+	t.Helper()
+
+	db, d := newFakeDB(t)
+	return &RemoteClient{
+		Client:           db,
+		Name:             backend.DefaultStateName,
+		SchemaName:       "terraform",
+		History:          true,
+		HistoryRetention: retention,
+	}, d
+}
+
+type fakeDriver struct {
+	mu      sync.Mutex
+	states  map[string][]byte
+	history []fakeHistoryRow
+	nextID  int64
+
+	// historyErr, if set, is returned when a version is inserted into the
+	// history table.
+	historyErr error
+}
+
+type fakeHistoryRow struct {
+	id       int64
+	name     string
+	serial   int64
+	lineage  string
+	data     []byte
+	lockInfo []byte
+	created  time.Time
+}
+
+var (
+	_ driver.Driver    = (*fakeDriver)(nil)
+	_ driver.Connector = (*fakeDriver)(nil)
+)
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+func (d *fakeDriver) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+func (d *fakeDriver) Driver() driver.Driver {
+	return d
+}
+
+// exec runs the given statement, and records how to undo it in tx if the
+// statement is part of a transaction.
+func (d *fakeDriver) exec(tx *fakeTx, query string, args []driver.Value) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if tx != nil {
+		states, history := maps.Clone(d.states), slices.Clone(d.history)
+		tx.undo = append(tx.undo, func() {
+			d.states, d.history = states, history
+		})
+	}
+
+	query = strings.TrimSpace(query)
+	history := strings.Contains(query, "."+statesHistoryTableName)
+	switch {
+	case strings.HasPrefix(query, "INSERT INTO") && history:
+		if d.historyErr != nil {
+			return d.historyErr
+		}
+		d.nextID++
+		d.history = append(d.history, fakeHistoryRow{
+			id:       d.nextID,
+			name:     args[0].(string),
+			serial:   args[1].(int64),
+			lineage:  args[2].(string),
+			data:     bytes.Clone(args[3].([]byte)),
+			lockInfo: fakeBytes(args[4]),
+			created:  time.Now(),
+		})
+	case strings.HasPrefix(query, "INSERT INTO"):
+		d.states[args[0].(string)] = bytes.Clone(args[1].([]byte))
+	case strings.HasPrefix(query, "DELETE FROM") && history:
+		name, keep := args[0].(string), args[1].(int64)
+		var count int64
+		var kept []fakeHistoryRow
+		for i := len(d.history) - 1; i >= 0; i-- {
+			row := d.history[i]
+			if row.name == name {
+				if count == keep {
+					continue
+				}
+				count++
+			}
+			kept = append([]fakeHistoryRow{row}, kept...)
+		}
+		d.history = kept
+	case strings.HasPrefix(query, "DELETE FROM"):
+		delete(d.states, args[0].(string))
+	default:
+		return fmt.Errorf("unsupported query: %s", query)
+	}
+	return nil
+}
+
+func (d *fakeDriver) query(query string, args []driver.Value) (driver.Rows, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	query = strings.TrimSpace(query)
+	history := strings.Contains(query, "."+statesHistoryTableName)
+	switch {
+	case strings.HasPrefix(query, "SELECT id, serial, lineage, lock_info, created_at") && history:
+		rows := &fakeRows{columns: []string{"id", "serial", "lineage", "lock_info", "created_at"}}
+		for i := len(d.history) - 1; i >= 0; i-- {
+			if row := d.history[i]; row.name == args[0].(string) {
+				rows.values = append(rows.values, []driver.Value{row.id, row.serial, row.lineage, row.lockInfo, row.created})
+			}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT data") && history:
+		rows := &fakeRows{columns: []string{"data"}}
+		for _, row := range d.history {
+			if row.name == args[0].(string) && row.id == args[1].(int64) {
+				rows.values = append(rows.values, []driver.Value{row.data})
+			}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT data"):
+		rows := &fakeRows{columns: []string{"data"}}
+		if data, ok := d.states[args[0].(string)]; ok {
+			rows.values = append(rows.values, []driver.Value{data})
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unsupported query: %s", query)
+	}
+}
+
+func fakeBytes(v driver.Value) []byte {
+	if b, ok := v.([]byte); ok {
+		return bytes.Clone(b)
+	}
+	return nil
+}
+
+type fakeConn struct {
+	d  *fakeDriver
+	tx *fakeTx
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeTx{conn: c}
+	return c.tx, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+	undo []func()
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.tx = nil
+
+	d := tx.conn.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.conn.d.exec(s.conn.tx, s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.d.query(s.query, args)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
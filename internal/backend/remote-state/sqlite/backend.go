@@ -0,0 +1,160 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/legacy/helper/schema"
+
+	// This driver is written in pure Go, because release builds don't
+	// use cgo.
+	_ "modernc.org/sqlite"
+)
+
+const (
+	statesTableName        = "states"
+	locksTableName         = "locks"
+	statesHistoryTableName = "states_history"
+	statesHistoryIndexName = "states_history_by_name"
+)
+
+func defaultBoolFunc(k string, dv bool) schema.SchemaDefaultFunc {
+	return func() (interface{}, error) {
+		if v := os.Getenv(k); v != "" {
+			return strconv.ParseBool(v)
+		}
+
+		return dv, nil
+	}
+}
+
+// New creates a new backend for SQLite remote state.
+func New(enc encryption.StateEncryption) backend.Backend {
+	s := &schema.Backend{
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Path to the SQLite database file; it is created if it doesn't exist",
+				DefaultFunc: schema.EnvDefaultFunc("TF_SQLITE_PATH", nil),
+			},
+
+			"busy_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Time in milliseconds to wait for the database file to be unlocked by another process",
+				DefaultFunc: schema.EnvDefaultFunc("TF_SQLITE_BUSY_TIMEOUT", 5000),
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if v.(int) < 0 {
+						return nil, []error{fmt.Errorf("%q must not be negative", k)}
+					}
+					return nil, nil
+				},
+			},
+
+			"enable_history": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If set to `true`, OpenTofu will keep previous versions of the state in a history table",
+				DefaultFunc: defaultBoolFunc("TF_SQLITE_ENABLE_HISTORY", false),
+			},
+
+			"history_retention": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "Maximum number of state versions to keep per workspace when history is enabled; 0 keeps all versions",
+				DefaultFunc: schema.EnvDefaultFunc("TF_SQLITE_HISTORY_RETENTION", 0),
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if v.(int) < 0 {
+						return nil, []error{fmt.Errorf("%q must not be negative", k)}
+					}
+					return nil, nil
+				},
+			},
+		},
+	}
+
+	result := &Backend{Backend: s, encryption: enc}
+	result.Backend.ConfigureFunc = result.configure
+	return result
+}
+
+type Backend struct {
+	*schema.Backend
+	encryption encryption.StateEncryption
+
+	// The fields below are set from configure
+	db   *sql.DB
+	path string
+
+	enableHistory    bool
+	historyRetention int
+}
+
+func (b *Backend) configure(ctx context.Context) error {
+	// Grab the resource data
+	data := schema.FromContextBackendConfig(ctx)
+
+	b.path = data.Get("path").(string)
+	b.enableHistory = data.Get("enable_history").(bool)
+	b.historyRetention = data.Get("history_retention").(int)
+
+	// Transactions are started with BEGIN IMMEDIATE so that each of them
+	// takes the database write lock up front, which makes the check-and-set
+	// of lock rows atomic across processes.
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout("+strconv.Itoa(data.Get("busy_timeout").(int))+")")
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite", "file:"+b.path+"?"+params.Encode())
+	if err != nil {
+		return err
+	}
+
+	// Prepare the database tables & indexes.
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS ` + statesTableName + ` (
+			name TEXT NOT NULL PRIMARY KEY,
+			data BLOB
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + locksTableName + ` (
+			name TEXT NOT NULL PRIMARY KEY,
+			id TEXT NOT NULL,
+			info TEXT NOT NULL
+		)`,
+	}
+	if b.enableHistory {
+		queries = append(queries,
+			`CREATE TABLE IF NOT EXISTS `+statesHistoryTableName+` (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				serial INTEGER NOT NULL,
+				lineage TEXT NOT NULL,
+				data BLOB,
+				lock_info TEXT,
+				created_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS `+statesHistoryIndexName+` ON `+statesHistoryTableName+` (name, id)`,
+		)
+	}
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to prepare SQLite database %q: %w", b.path, err)
+		}
+	}
+
+	// Assign db after its schema is prepared.
+	b.db = db
+
+	return nil
+}
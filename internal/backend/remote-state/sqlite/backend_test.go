@@ -0,0 +1,155 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/encryption/enctest"
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+func testBackendConfig(t *testing.T, enc encryption.StateEncryption, config map[string]interface{}) *Backend {
+	t.Helper()
+
+	b := backend.TestBackendConfig(t, New(enc), backend.TestWrapConfig(config)).(*Backend)
+	t.Cleanup(func() {
+		b.db.Close()
+	})
+	return b
+}
+
+func TestBackend_impl(t *testing.T) {
+	var _ backend.Backend = new(Backend)
+}
+
+func TestBackendConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path": path,
+	})
+
+	if b.path != path {
+		t.Fatalf("wrong path %q; want %q", b.path, path)
+	}
+	if b.enableHistory {
+		t.Fatal("history should be disabled by default")
+	}
+
+	_, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackendConfig_invalidRetention(t *testing.T) {
+	config := backend.TestWrapConfig(map[string]interface{}{
+		"path":              filepath.Join(t.TempDir(), "state.db"),
+		"history_retention": -1,
+	})
+	_, _, errs := backend.TestBackendConfigWarningsAndErrors(t, New(encryption.StateEncryptionDisabled()), config)
+	if len(errs) == 0 {
+		t.Fatal("expected an error for negative history_retention")
+	}
+}
+
+func TestBackendStates(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path": filepath.Join(t.TempDir(), "state.db"),
+	})
+
+	backend.TestBackendStates(t, b)
+}
+
+func TestBackendStates_history(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path":           filepath.Join(t.TempDir(), "state.db"),
+		"enable_history": true,
+	})
+
+	backend.TestBackendStates(t, b)
+
+	// Deleting a workspace also removes its history.
+	var count int
+	query := `SELECT count(1) FROM ` + statesHistoryTableName + ` WHERE name NOT IN (SELECT name FROM ` + statesTableName + `)`
+	if err := b.db.QueryRow(query).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no history for deleted workspaces, got %d versions", count)
+	}
+}
+
+func TestBackendStateLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	config := map[string]interface{}{
+		"path": path,
+	}
+	b1 := testBackendConfig(t, encryption.StateEncryptionDisabled(), config)
+	b2 := testBackendConfig(t, encryption.StateEncryptionDisabled(), config)
+
+	backend.TestBackendStateLocks(t, b1, b2)
+	backend.TestBackendStateForceUnlock(t, b1, b2)
+}
+
+func TestBackendEncryption(t *testing.T) {
+	enc := enctest.EncryptionRequired().State()
+	b := testBackendConfig(t, enc, map[string]interface{}{
+		"path":           filepath.Join(t.TempDir(), "state.db"),
+		"enable_history": true,
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := statemgr.TestFullInitialState()
+	if err := s.WriteState(state); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PersistState(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var data []byte
+	if err := b.db.QueryRow(`SELECT data FROM `+statesTableName+` WHERE name = ?`, backend.DefaultStateName).Scan(&data); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"encrypted_data"`)) {
+		t.Fatalf("expected the stored state to be encrypted, got:\n%s", data)
+	}
+
+	// The state must still be readable through a new state manager.
+	s2, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s2.RefreshState(); err != nil {
+		t.Fatal(err)
+	}
+	if !statefile.StatesMarshalEqual(s2.State(), state) {
+		t.Fatal("state read back from the backend does not match the written state")
+	}
+
+	// The version must record the serial and lineage of the state, which
+	// can't be read from the encrypted data.
+	sf := statemgr.Export(s2)
+	versions, err := s2.(*remote.State).Client.(remote.ClientVersioner).Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(versions))
+	}
+	if versions[0].Serial != sf.Serial || versions[0].Lineage != sf.Lineage {
+		t.Fatalf("expected the version to have serial %d and lineage %q, got %d and %q", sf.Serial, sf.Lineage, versions[0].Serial, versions[0].Lineage)
+	}
+}
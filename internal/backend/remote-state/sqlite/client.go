@@ -0,0 +1,274 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"crypto/md5"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	uuid "github.com/hashicorp/go-uuid"
+
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// RemoteClient is a remote client that stores data in a SQLite database
+type RemoteClient struct {
+	Client *sql.DB
+	Name   string
+
+	// History enables the retention of every written state version in the
+	// history table. HistoryRetention limits the number of retained
+	// versions per workspace; zero means that all versions are kept.
+	History          bool
+	HistoryRetention int
+
+	info *statemgr.LockInfo
+}
+
+var (
+	_ remote.ClientLocker    = (*RemoteClient)(nil)
+	_ remote.ClientVersioner = (*RemoteClient)(nil)
+)
+
+const putQuery = `INSERT INTO ` + statesTableName + ` (name, data) VALUES (?, ?)
+	ON CONFLICT (name) DO UPDATE SET data = excluded.data`
+
+func (c *RemoteClient) Get() (*remote.Payload, error) {
+	row := c.Client.QueryRow(`SELECT data FROM `+statesTableName+` WHERE name = ?`, c.Name)
+	var data []byte
+	err := row.Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		// No existing state returns empty.
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		md5 := md5.Sum(data)
+		return &remote.Payload{
+			Data: data,
+			MD5:  md5[:],
+		}, nil
+	}
+}
+
+// Put stores the state without retaining a version of it. remote.State
+// calls PutVersion instead.
+func (c *RemoteClient) Put(data []byte) error {
+	_, err := c.Client.Exec(putQuery, c.Name, data)
+	return err
+}
+
+func (c *RemoteClient) PutVersion(data []byte, serial uint64, lineage string) error {
+	if !c.History {
+		return c.Put(data)
+	}
+
+	tx, err := c.Client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // this is a no-op once the transaction is committed
+
+	if _, err := tx.Exec(putQuery, c.Name, data); err != nil {
+		return err
+	}
+	if err := c.putHistory(tx, data, serial, lineage); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// putHistory records the given state data as a new version in the history
+// table, and prunes versions beyond the configured retention.
+func (c *RemoteClient) putHistory(tx *sql.Tx, data []byte, serial uint64, lineage string) error {
+	var lockInfo []byte
+	if c.info != nil {
+		lockInfo = c.info.Marshal()
+	}
+
+	query := `INSERT INTO ` + statesHistoryTableName + ` (name, serial, lineage, data, lock_info, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, c.Name, serial, lineage, data, lockInfo, time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+
+	if c.HistoryRetention > 0 {
+		query = `DELETE FROM ` + statesHistoryTableName + ` WHERE name = ? AND id NOT IN (
+			SELECT id FROM ` + statesHistoryTableName + ` WHERE name = ? ORDER BY id DESC LIMIT ?
+			)`
+		if _, err := tx.Exec(query, c.Name, c.Name, c.HistoryRetention); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *RemoteClient) Delete() error {
+	_, err := c.Client.Exec(`DELETE FROM `+statesTableName+` WHERE name = ?`, c.Name)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *RemoteClient) Versions() ([]*remote.StateVersion, error) {
+	if !c.History {
+		return nil, remote.ErrHistoryDisabled
+	}
+
+	query := `SELECT id, serial, lineage, lock_info, created_at FROM ` + statesHistoryTableName + ` WHERE name = ? ORDER BY id DESC`
+	rows, err := c.Client.Query(query, c.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*remote.StateVersion
+	for rows.Next() {
+		var id int64
+		var lockInfo []byte
+		var created string
+		v := &remote.StateVersion{}
+		if err := rows.Scan(&id, &v.Serial, &v.Lineage, &lockInfo, &created); err != nil {
+			return nil, err
+		}
+		v.ID = strconv.FormatInt(id, 10)
+		v.Created, err = time.Parse(time.RFC3339Nano, created)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time for state version %s: %w", v.ID, err)
+		}
+		if len(lockInfo) > 0 {
+			v.LockInfo = &statemgr.LockInfo{}
+			if err := json.Unmarshal(lockInfo, v.LockInfo); err != nil {
+				return nil, fmt.Errorf("invalid lock info for state version %s: %w", v.ID, err)
+			}
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *RemoteClient) GetVersion(id string) (*remote.Payload, error) {
+	if !c.History {
+		return nil, remote.ErrHistoryDisabled
+	}
+
+	versionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state version ID %q", id)
+	}
+
+	row := c.Client.QueryRow(`SELECT data FROM `+statesHistoryTableName+` WHERE name = ? AND id = ?`, c.Name, versionID)
+	var data []byte
+	err = row.Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		md5 := md5.Sum(data)
+		return &remote.Payload{
+			Data: data,
+			MD5:  md5[:],
+		}, nil
+	}
+}
+
+func (c *RemoteClient) Lock(info *statemgr.LockInfo) (string, error) {
+	if info.ID == "" {
+		lockID, err := uuid.GenerateUUID()
+		if err != nil {
+			return "", err
+		}
+		info.ID = lockID
+	}
+	info.Path = c.Name
+
+	tx, err := c.Client.Begin()
+	if err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	defer tx.Rollback() //nolint:errcheck // this is a no-op once the transaction is committed
+
+	// The transaction holds the database write lock, so nobody else can
+	// take the state lock between our check and our insert.
+	existing, err := c.getLockInfo(tx)
+	if err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	if existing != nil {
+		return "", &statemgr.LockError{Info: existing, Err: fmt.Errorf("Workspace is already locked: %s", c.Name)}
+	}
+
+	query := `INSERT INTO ` + locksTableName + ` (name, id, info) VALUES (?, ?, ?)`
+	if _, err := tx.Exec(query, c.Name, info.ID, info.Marshal()); err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", &statemgr.LockError{Info: info, Err: err}
+	}
+	c.info = info
+
+	return info.ID, nil
+}
+
+func (c *RemoteClient) Unlock(id string) error {
+	tx, err := c.Client.Begin()
+	if err != nil {
+		return &statemgr.LockError{Info: c.info, Err: err}
+	}
+	defer tx.Rollback() //nolint:errcheck // this is a no-op once the transaction is committed
+
+	existing, err := c.getLockInfo(tx)
+	if err != nil {
+		return &statemgr.LockError{Info: c.info, Err: err}
+	}
+	if existing == nil {
+		return &statemgr.LockError{Info: c.info, Err: fmt.Errorf("Workspace is not locked: %s", c.Name)}
+	}
+	if existing.ID != id {
+		return &statemgr.LockError{Info: existing, Err: fmt.Errorf("lock ID %q does not match existing lock", id)}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM `+locksTableName+` WHERE name = ?`, c.Name); err != nil {
+		return &statemgr.LockError{Info: existing, Err: err}
+	}
+	if err := tx.Commit(); err != nil {
+		return &statemgr.LockError{Info: existing, Err: err}
+	}
+	c.info = nil
+
+	return nil
+}
+
+// getLockInfo returns the lock currently held on the state, or nil if the
+// state is not locked.
+func (c *RemoteClient) getLockInfo(tx *sql.Tx) (*statemgr.LockInfo, error) {
+	row := tx.QueryRow(`SELECT info FROM `+locksTableName+` WHERE name = ?`, c.Name)
+	var data []byte
+	err := row.Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	info := &statemgr.LockInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("invalid lock info for %s: %w", c.Name, err)
+	}
+	return info, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/encryption/enctest"
+	"github.com/opentofu/opentofu/internal/states/remote"
+)
+
+func TestRemoteClient_impl(t *testing.T) {
+	var _ remote.Client = new(RemoteClient)
+	var _ remote.ClientLocker = new(RemoteClient)
+	var _ remote.ClientVersioner = new(RemoteClient)
+}
+
+func TestRemoteClient(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path": filepath.Join(t.TempDir(), "state.db"),
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClient(t, s.(*remote.State).Client)
+}
+
+func TestRemoteLocks(t *testing.T) {
+	config := map[string]interface{}{
+		"path": filepath.Join(t.TempDir(), "state.db"),
+	}
+
+	b1 := testBackendConfig(t, encryption.StateEncryptionDisabled(), config)
+	s1, err := b1.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b2 := testBackendConfig(t, encryption.StateEncryptionDisabled(), config)
+	s2, err := b2.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestRemoteLocks(t, s1.(*remote.State).Client, s2.(*remote.State).Client)
+}
+
+func TestRemoteClientVersions(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path":           filepath.Join(t.TempDir(), "state.db"),
+		"enable_history": true,
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClientVersioner(t, s.(*remote.State).Client.(remote.ClientVersioner))
+}
+
+func TestRemoteClientVersions_rollback(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path":           filepath.Join(t.TempDir(), "state.db"),
+		"enable_history": true,
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClientVersionerRollback(t, s.(*remote.State).Client.(remote.ClientVersioner), encryption.StateEncryptionDisabled())
+}
+
+func TestRemoteClientVersions_rollbackEncrypted(t *testing.T) {
+	enc := enctest.EncryptionRequired().State()
+	b := testBackendConfig(t, enc, map[string]interface{}{
+		"path":           filepath.Join(t.TempDir(), "state.db"),
+		"enable_history": true,
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.TestClientVersionerRollback(t, s.(*remote.State).Client.(remote.ClientVersioner), enc)
+}
+
+func TestRemoteClientVersions_retention(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path":              filepath.Join(t.TempDir(), "state.db"),
+		"enable_history":    true,
+		"history_retention": 2,
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := s.(*remote.State).Client.(remote.ClientVersioner)
+
+	for i := 0; i < 3; i++ {
+		if err := client.PutVersion([]byte(fmt.Sprintf(`{"serial": %d, "lineage": "retention"}`, i+10)), uint64(i+10), "retention"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := client.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 retained versions, got %d", len(versions))
+	}
+	if versions[0].Serial != 12 || versions[1].Serial != 11 {
+		t.Fatalf("wrong versions retained: serials %d and %d", versions[0].Serial, versions[1].Serial)
+	}
+}
+
+func TestRemoteClientVersions_disabled(t *testing.T) {
+	b := testBackendConfig(t, encryption.StateEncryptionDisabled(), map[string]interface{}{
+		"path": filepath.Join(t.TempDir(), "state.db"),
+	})
+
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.(*remote.State).Client.(remote.ClientVersioner).Versions(); err != remote.ErrHistoryDisabled {
+		t.Fatalf("expected history disabled error, got: %v", err)
+	}
+}
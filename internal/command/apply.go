@@ -125,6 +125,13 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	opReq, opDiags := c.OperationRequest(be, view, args.ViewType, planFile, args.Operation, args.AutoApprove, enc)
 	diags = diags.Append(opDiags)
 
+	// Load the local policies to evaluate against the plan
+	if !diags.HasErrors() {
+		var policyDiags tfdiags.Diagnostics
+		opReq.Policies, policyDiags = c.loadPolicies(args.PolicyPaths)
+		diags = diags.Append(policyDiags)
+	}
+
 	// Before we delegate to the backend, we'll print any warning diagnostics
 	// we've accumulated here, since the backend will start fresh with its own
 	// diagnostics.
@@ -385,6 +392,12 @@ Options:
   -parallelism=n         Limit the number of parallel resource operations.
                          Defaults to 10.
 
+  -policy=path           Evaluate the local policies in the given file, or in
+                         the .tfpolicy.hcl files of the given directory,
+                         against the plan before applying it. A failed
+                         mandatory policy prevents the apply. Can be used
+                         multiple times.
+
   -state=path            Path to read and save state (unless state-out
                          is specified). Defaults to "terraform.tfstate".
 
@@ -34,6 +34,10 @@ type Apply struct {
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// PolicyPaths are the files and directories of the local policies to
+	// evaluate against the plan before it is applied.
+	PolicyPaths []string
 }
 
 // ParseApply processes CLI arguments, returning an Apply value and errors.
@@ -51,6 +55,7 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 	cmdFlags.BoolVar(&apply.AutoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.BoolVar(&apply.InputEnabled, "input", true, "input")
 	cmdFlags.BoolVar(&apply.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.Var((*flagStringSlice)(&apply.PolicyPaths), "policy", "policy")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -70,6 +70,22 @@ func TestParseApply_basicValid(t *testing.T) {
 				},
 			},
 		},
+		"policies and plan path": {
+			[]string{"-policy=policies", "saved.tfplan"},
+			&Apply{
+				InputEnabled: true,
+				PlanPath:     "saved.tfplan",
+				ViewType:     ViewHuman,
+				State:        &State{Lock: true},
+				Vars:         &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+				PolicyPaths: []string{"policies"},
+			},
+		},
 		"JSON view disables input": {
 			[]string{"-json", "-auto-approve"},
 			&Apply{
@@ -37,6 +37,10 @@ type Plan struct {
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// PolicyPaths are the files and directories of the local policies to
+	// evaluate against the plan.
+	PolicyPaths []string
 }
 
 // ParsePlan processes CLI arguments, returning a Plan value and errors.
@@ -56,6 +60,7 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 	cmdFlags.StringVar(&plan.OutPath, "out", "", "out")
 	cmdFlags.StringVar(&plan.GenerateConfigPath, "generate-config-out", "", "generate-config-out")
 	cmdFlags.BoolVar(&plan.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.Var((*flagStringSlice)(&plan.PolicyPaths), "policy", "policy")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
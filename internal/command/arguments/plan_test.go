@@ -54,6 +54,21 @@ func TestParsePlan_basicValid(t *testing.T) {
 				},
 			},
 		},
+		"policies": {
+			[]string{"-policy=policies", "-policy=extra.tfpolicy.hcl"},
+			&Plan{
+				InputEnabled: true,
+				ViewType:     ViewHuman,
+				State:        &State{Lock: true},
+				Vars:         &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+				PolicyPaths: []string{"policies", "extra.tfpolicy.hcl"},
+			},
+		},
 		"JSON view disables input": {
 			[]string{"-json"},
 			&Plan{
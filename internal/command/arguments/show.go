@@ -6,6 +6,8 @@
 package arguments
 
 import (
+	"fmt"
+
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -15,7 +17,8 @@ type Show struct {
 	// unspecified, show will display the latest state snapshot.
 	Path string
 
-	// ViewType specifies which output format to use: human, JSON, or "raw".
+	// ViewType specifies which output format to use: human, JSON, markdown
+	// or HTML.
 	ViewType ViewType
 
 	Vars *Vars
@@ -35,8 +38,10 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 	}
 
 	var jsonOutput bool
+	var format string
 	cmdFlags := extendedFlagSet("show", nil, nil, show.Vars)
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.StringVar(&format, "format", "", "format")
 	cmdFlags.BoolVar(&show.ShowSensitive, "show-sensitive", false, "displays sensitive values")
 
 	if err := cmdFlags.Parse(args); err != nil {
@@ -63,9 +68,34 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 	switch {
 	case jsonOutput:
 		show.ViewType = ViewJSON
+	case format == "markdown":
+		show.ViewType = ViewMarkdown
+	case format == "html":
+		show.ViewType = ViewHTML
 	default:
 		show.ViewType = ViewHuman
 	}
 
+	switch {
+	case format != "" && format != "markdown" && format != "html":
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output format",
+			fmt.Sprintf("The -format option must be \"markdown\" or \"html\", not %q.", format),
+		))
+	case format != "" && jsonOutput:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command-line options",
+			"The -format and -json options are mutually exclusive.",
+		))
+	case format != "" && show.ShowSensitive:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command-line options",
+			"The -show-sensitive option can't be used with -format, because markdown and HTML documents always hide sensitive values.",
+		))
+	}
+
 	return show, diags
 }
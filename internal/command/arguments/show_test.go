@@ -39,6 +39,20 @@ func TestParseShow_valid(t *testing.T) {
 				ViewType: ViewJSON,
 			},
 		},
+		"markdown": {
+			[]string{"-format=markdown", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewMarkdown,
+			},
+		},
+		"html": {
+			[]string{"-format", "html", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewHTML,
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -89,6 +103,49 @@ func TestParseShow_invalid(t *testing.T) {
 				),
 			},
 		},
+		"unknown format": {
+			[]string{"-format=yaml", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewHuman,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output format",
+					`The -format option must be "markdown" or "html", not "yaml".`,
+				),
+			},
+		},
+		"format and json": {
+			[]string{"-format=markdown", "-json", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewJSON,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command-line options",
+					"The -format and -json options are mutually exclusive.",
+				),
+			},
+		},
+		"format and show-sensitive": {
+			[]string{"-format=html", "-show-sensitive", "foo"},
+			&Show{
+				Path:          "foo",
+				ViewType:      ViewHTML,
+				ShowSensitive: true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command-line options",
+					"The -show-sensitive option can't be used with -format, because markdown and HTML documents always hide sensitive values.",
+				),
+			},
+		},
 	}
 
 	for name, tc := range testCases {
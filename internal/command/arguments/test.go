@@ -6,6 +6,8 @@
 package arguments
 
 import (
+	"fmt"
+
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -32,13 +34,47 @@ type Test struct {
 	// human-readable format or JSON for each run step depending on the
 	// ViewType.
 	Verbose bool
+
+	// JUnitXMLFile is the path of a file to write a JUnit XML report of the
+	// test results to, in addition to the normal output. If empty, no
+	// report is written.
+	JUnitXMLFile string
+
+	// Parallelism is the maximum number of test files to execute at the same
+	// time. Only test files that replace every provider with a mock provider
+	// are executed concurrently.
+	Parallelism int
+
+	// Coverage tells the test command to record the objects in the
+	// configuration exercised by the run blocks, and print a summary.
+	Coverage bool
+
+	// CoverageOut is the path of a file to write the coverage report to, in
+	// the format given by CoverageFormat. Setting it implies Coverage.
+	CoverageOut    string
+	CoverageFormat string
+
+	// UpdateSnapshots tells the test command to write the plan snapshots of
+	// run blocks instead of comparing the plans against them.
+	UpdateSnapshots bool
+
+	// Watch tells the test command to keep running after the tests complete,
+	// and re-run the test files affected by any changes to the configuration
+	// or the test files.
+	Watch bool
 }
 
+const (
+	CoverageFormatLCOV = "lcov"
+	CoverageFormatJSON = "json"
+)
+
 func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
 	test := Test{
-		Vars: new(Vars),
+		Vars:        new(Vars),
+		Parallelism: 1,
 	}
 
 	var jsonOutput bool
@@ -47,6 +83,13 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 	cmdFlags.StringVar(&test.TestDirectory, "test-directory", configs.DefaultTestDirectory, "test-directory")
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.BoolVar(&test.Verbose, "verbose", false, "verbose")
+	cmdFlags.StringVar(&test.JUnitXMLFile, "junit-xml", "", "junit-xml")
+	cmdFlags.IntVar(&test.Parallelism, "parallelism", 1, "parallelism")
+	cmdFlags.BoolVar(&test.UpdateSnapshots, "update-snapshots", false, "update-snapshots")
+	cmdFlags.BoolVar(&test.Watch, "watch", false, "watch")
+	cmdFlags.BoolVar(&test.Coverage, "coverage", false, "coverage")
+	cmdFlags.StringVar(&test.CoverageOut, "coverage-out", "", "coverage-out")
+	cmdFlags.StringVar(&test.CoverageFormat, "coverage-format", CoverageFormatLCOV, "coverage-format")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -55,6 +98,32 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 			err.Error()))
 	}
 
+	if test.Parallelism < 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid parallelism",
+			"The -parallelism option must be at least 1."))
+	}
+
+	switch test.CoverageFormat {
+	case CoverageFormatLCOV, CoverageFormatJSON:
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid coverage format",
+			fmt.Sprintf("The -coverage-format option must be %q or %q.", CoverageFormatLCOV, CoverageFormatJSON)))
+	}
+	if test.CoverageOut != "" {
+		test.Coverage = true
+	}
+
+	if test.Watch && jsonOutput {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command-line options",
+			"The -watch option can't be used with -json, because the watch status is only shown in the human-readable output."))
+	}
+
 	switch {
 	case jsonOutput:
 		test.ViewType = ViewJSON
@@ -76,60 +76,198 @@ func TestParseTest(t *testing.T) {
 		"defaults": {
 			args: nil,
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
 			},
 			wantDiags: nil,
 		},
 		"with-filters": {
 			args: []string{"-filter=one.tftest.hcl", "-filter=two.tftest.hcl"},
 			want: &Test{
-				Filter:        []string{"one.tftest.hcl", "two.tftest.hcl"},
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:         []string{"one.tftest.hcl", "two.tftest.hcl"},
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
 			},
 			wantDiags: nil,
 		},
 		"json": {
 			args: []string{"-json"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewJSON,
-				Vars:          &Vars{},
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewJSON,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
 			},
 			wantDiags: nil,
 		},
 		"test-directory": {
 			args: []string{"-test-directory=other"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "other",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:         nil,
+				TestDirectory:  "other",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
 			},
 			wantDiags: nil,
 		},
 		"verbose": {
 			args: []string{"-verbose"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Verbose:       true,
-				Vars:          &Vars{},
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Verbose:        true,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
+			},
+		},
+		"junit-xml": {
+			args: []string{"-junit-xml=results.xml"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				JUnitXMLFile:   "results.xml",
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
+			},
+		},
+		"parallelism": {
+			args: []string{"-parallelism=4"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    4,
+				CoverageFormat: "lcov",
+			},
+		},
+		"invalid parallelism": {
+			args: []string{"-parallelism=0"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    0,
+				CoverageFormat: "lcov",
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid parallelism",
+					"The -parallelism option must be at least 1.",
+				),
+			},
+		},
+		"coverage": {
+			args: []string{"-coverage"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				Coverage:       true,
+				CoverageFormat: "lcov",
+			},
+		},
+		"coverage-out": {
+			args: []string{"-coverage-out=coverage.json", "-coverage-format=json"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				Coverage:       true,
+				CoverageOut:    "coverage.json",
+				CoverageFormat: "json",
+			},
+		},
+		"invalid coverage format": {
+			args: []string{"-coverage-format=xml"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "xml",
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid coverage format",
+					`The -coverage-format option must be "lcov" or "json".`,
+				),
+			},
+		},
+		"update-snapshots": {
+			args: []string{"-update-snapshots"},
+			want: &Test{
+				Filter:          nil,
+				TestDirectory:   "tests",
+				ViewType:        ViewHuman,
+				Vars:            &Vars{},
+				Parallelism:     1,
+				CoverageFormat:  "lcov",
+				UpdateSnapshots: true,
+			},
+		},
+		"watch": {
+			args: []string{"-watch"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
+				Watch:          true,
+			},
+		},
+		"watch with json": {
+			args: []string{"-watch", "-json"},
+			want: &Test{
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewJSON,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
+				Watch:          true,
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command-line options",
+					"The -watch option can't be used with -json, because the watch status is only shown in the human-readable output."),
 			},
 		},
 		"unknown flag": {
 			args: []string{"-boop"},
 			want: &Test{
-				Filter:        nil,
-				TestDirectory: "tests",
-				ViewType:      ViewHuman,
-				Vars:          &Vars{},
+				Filter:         nil,
+				TestDirectory:  "tests",
+				ViewType:       ViewHuman,
+				Vars:           &Vars{},
+				Parallelism:    1,
+				CoverageFormat: "lcov",
 			},
 			wantDiags: tfdiags.Diagnostics{
 				tfdiags.Sourceless(
@@ -15,6 +15,11 @@ const (
 	ViewHuman ViewType = 'H'
 	ViewJSON  ViewType = 'J'
 	ViewRaw   ViewType = 'R'
+
+	// ViewMarkdown and ViewHTML render documents for display outside of a
+	// terminal, such as in pull request comments.
+	ViewMarkdown ViewType = 'M'
+	ViewHTML     ViewType = 'W'
 )
 
 func (vt ViewType) String() string {
@@ -27,6 +32,10 @@ func (vt ViewType) String() string {
 		return "json"
 	case ViewRaw:
 		return "raw"
+	case ViewMarkdown:
+		return "markdown"
+	case ViewHTML:
+		return "html"
 	default:
 		return "unknown"
 	}
@@ -6,11 +6,14 @@
 package cliconfig
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
 	"log"
 	"os"
+	"os/exec"
 	"os/user"
 	"path/filepath"
 	"runtime"
@@ -655,11 +658,20 @@ func (o ociRepositoryCredentialsConfig) CredentialsSourcesForRepository(_ contex
 	}
 }
 
-// ociCredentialsEnv implements ociauthconfig.ConfigDiscoveryEnvironment against the
-// real execution environment provided by the host operating system.
+// OCICredentialsLookupEnvironment returns the environment that callers should
+// pass to [ociauthconfig.CredentialsSource.Credentials] when obtaining the
+// credentials selected by the policy from [Config.OCICredentialsPolicy].
+func OCICredentialsLookupEnvironment() ociauthconfig.CredentialsLookupEnvironment {
+	return ociCredentialsEnv{}
+}
+
+// ociCredentialsEnv implements ociauthconfig.ConfigDiscoveryEnvironment and
+// ociauthconfig.CredentialsLookupEnvironment against the real execution
+// environment provided by the host operating system.
 type ociCredentialsEnv struct{}
 
 var _ ociauthconfig.ConfigDiscoveryEnvironment = ociCredentialsEnv{}
+var _ ociauthconfig.CredentialsLookupEnvironment = ociCredentialsEnv{}
 
 // EnvironmentVariableVal implements ociauthconfig.ConfigDiscoveryEnvironment.
 func (e ociCredentialsEnv) EnvironmentVariableVal(name string) string {
@@ -677,6 +689,40 @@ func (e ociCredentialsEnv) ReadFile(_ context.Context, path string) ([]byte, err
 	return os.ReadFile(path)
 }
 
+// QueryDockerCredentialHelper implements ociauthconfig.CredentialsLookupEnvironment
+// by running the helper's "get" command, following the protocol described in
+// https://github.com/docker/docker-credential-helpers .
+func (e ociCredentialsEnv) QueryDockerCredentialHelper(ctx context.Context, helperName string, serverURL string) (ociauthconfig.DockerCredentialHelperGetResult, error) {
+	var result ociauthconfig.DockerCredentialHelperGetResult
+
+	program := "docker-credential-" + helperName
+	log.Printf("[TRACE] Querying credential helper %s for %s", program, serverURL)
+	cmd := exec.CommandContext(ctx, program, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Helpers report errors on stdout, and report missing credentials with
+		// a specific message.
+		msg := strings.TrimSpace(stdout.String())
+		if msg == "credentials not found in native keychain" {
+			return result, ociauthconfig.NewCredentialsNotFoundError(fmt.Errorf("no credentials for %s", serverURL))
+		}
+		if msg == "" {
+			msg = strings.TrimSpace(stderr.String())
+		}
+		if msg != "" {
+			return result, fmt.Errorf("%s failed: %s", program, msg)
+		}
+		return result, fmt.Errorf("%s failed: %w", program, err)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return result, fmt.Errorf("%s returned invalid response: %w", program, err)
+	}
+	return result, nil
+}
+
 // UserHomeDirPath implements ociauthconfig.ConfigDiscoveryEnvironment.
 func (e ociCredentialsEnv) UserHomeDirPath() string {
 	// This is intentionally slightly different from the homeDir function
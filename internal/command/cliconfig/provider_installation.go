@@ -8,11 +8,13 @@ package cliconfig
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/hcl"
 	hclast "github.com/hashicorp/hcl/hcl/ast"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/cliconfig/ociauthconfig"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -215,6 +217,42 @@ func decodeProviderInstallationFromConfig(hclFile *hclast.File) ([]*ProviderInst
 				location = ProviderInstallationNetworkMirror(bodyContent.URL)
 				include = bodyContent.Include
 				exclude = bodyContent.Exclude
+			case "oci_mirror":
+				type BodyContent struct {
+					RepositoryTemplate string   `hcl:"repository_template"`
+					Include            []string `hcl:"include"`
+					Exclude            []string `hcl:"exclude"`
+				}
+				var bodyContent BodyContent
+				err := hcl.DecodeObject(&bodyContent, methodBody)
+				if err != nil {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: %s.", methodTypeStr, block.Pos(), err),
+					))
+					continue
+				}
+				if bodyContent.RepositoryTemplate == "" {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: \"repository_template\" argument is required.", methodTypeStr, block.Pos()),
+					))
+					continue
+				}
+				mirror := ProviderInstallationOCIMirror{RepositoryTemplate: bodyContent.RepositoryTemplate}
+				if err := mirror.Validate(); err != nil {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: invalid \"repository_template\": %s.", methodTypeStr, block.Pos(), err),
+					))
+					continue
+				}
+				location = mirror
+				include = bodyContent.Include
+				exclude = bodyContent.Exclude
 			case "dev_overrides":
 				if len(pi.Methods) > 0 {
 					// We require dev_overrides to appear first if it's present,
@@ -303,6 +341,7 @@ type ProviderInstallationMethod struct {
 //   - [ProviderInstallationDirect]:                 install from the provider's origin registry
 //   - [ProviderInstallationFilesystemMirror] (dir): install from a local filesystem mirror
 //   - [ProviderInstallationNetworkMirror] (host):   install from a network mirror
+//   - [ProviderInstallationOCIMirror]:              install from repositories in OCI registries
 type ProviderInstallationLocation interface {
 	providerInstallationLocation()
 }
@@ -341,3 +380,53 @@ func (i ProviderInstallationNetworkMirror) providerInstallationLocation() {}
 func (i ProviderInstallationNetworkMirror) GoString() string {
 	return fmt.Sprintf("cliconfig.ProviderInstallationNetworkMirror(%q)", i)
 }
+
+// ProviderInstallationOCIMirror is a ProviderInstallationSourceLocation
+// representing installation from repositories in OCI Distribution registries,
+// with one repository per provider.
+type ProviderInstallationOCIMirror struct {
+	// RepositoryTemplate is the address of the repository of each provider,
+	// such as "example.com/opentofu-providers/${namespace}/${type}", where
+	// ${hostname}, ${namespace} and ${type} are replaced by the parts of the
+	// provider's source address.
+	RepositoryTemplate string
+}
+
+func (i ProviderInstallationOCIMirror) providerInstallationLocation() {}
+
+func (i ProviderInstallationOCIMirror) GoString() string {
+	return fmt.Sprintf("cliconfig.ProviderInstallationOCIMirror{RepositoryTemplate: %q}", i.RepositoryTemplate)
+}
+
+// RepositoryForProvider returns the registry domain and the repository name
+// of the repository of the given provider.
+func (i ProviderInstallationOCIMirror) RepositoryForProvider(provider addrs.Provider) (registryDomain, repositoryName string, err error) {
+	addr := strings.NewReplacer(
+		"${hostname}", provider.Hostname.String(),
+		"${namespace}", provider.Namespace,
+		"${type}", provider.Type,
+	).Replace(i.RepositoryTemplate)
+	registryDomain, repositoryName, err = ociauthconfig.ParseRepositoryAddressPrefix(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid repository address %q for %s: %w", addr, provider, err)
+	}
+	if repositoryName == "" {
+		return "", "", fmt.Errorf("repository address %q for %s has no repository name", addr, provider)
+	}
+	return registryDomain, repositoryName, nil
+}
+
+// Validate checks that the repository template has a placeholder for the
+// namespace and type of a provider, and that it produces a valid repository
+// address.
+func (i ProviderInstallationOCIMirror) Validate() error {
+	rest := strings.NewReplacer("${hostname}", "", "${namespace}", "", "${type}", "").Replace(i.RepositoryTemplate)
+	if strings.Contains(rest, "${") {
+		return fmt.Errorf("the only placeholders allowed are ${hostname}, ${namespace} and ${type}")
+	}
+	if !strings.Contains(i.RepositoryTemplate, "${namespace}") || !strings.Contains(i.RepositoryTemplate, "${type}") {
+		return fmt.Errorf("must include the ${namespace} and ${type} placeholders, so that each provider has its own repository")
+	}
+	_, _, err := i.RepositoryForProvider(addrs.NewDefaultProvider("example"))
+	return err
+}
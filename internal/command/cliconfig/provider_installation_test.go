@@ -7,6 +7,7 @@ package cliconfig
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -38,6 +39,12 @@ func TestLoadConfig_providerInstallation(t *testing.T) {
 							{
 								Location: ProviderInstallationFilesystemMirror("/tmp/example2"),
 							},
+							{
+								Location: ProviderInstallationOCIMirror{
+									RepositoryTemplate: "example.net/opentofu-providers/${namespace}/${type}",
+								},
+								Include: []string{"registry.opentofu.org/hashicorp/*"},
+							},
 							{
 								Location: ProviderInstallationDirect,
 								Exclude:  []string{"example.com/*/*"},
@@ -61,15 +68,17 @@ func TestLoadConfig_providerInstallation(t *testing.T) {
 
 func TestLoadConfig_providerInstallationErrors(t *testing.T) {
 	_, diags := loadConfigFile(filepath.Join(fixtureDir, "provider-installation-errors"))
-	want := `7 problems:
+	want := `9 problems:
 
 - Invalid provider_installation method block: Unknown provider installation method "not_a_thing" at 2:3.
 - Invalid provider_installation method block: Invalid filesystem_mirror block at 1:1: "path" argument is required.
 - Invalid provider_installation method block: Invalid network_mirror block at 1:1: "url" argument is required.
+- Invalid provider_installation method block: Invalid oci_mirror block at 1:1: "repository_template" argument is required.
+- Invalid provider_installation method block: Invalid oci_mirror block at 1:1: invalid "repository_template": must include the ${namespace} and ${type} placeholders, so that each provider has its own repository.
 - Invalid provider_installation method block: The items inside the provider_installation block at 1:1 must all be blocks.
 - Invalid provider_installation method block: The blocks inside the provider_installation block at 1:1 may not have any labels.
-- Invalid provider_installation block: The provider_installation block at 9:1 must not have any labels.
-- Invalid provider_installation block: The provider_installation block at 11:1 must not be introduced with an equals sign.`
+- Invalid provider_installation block: The provider_installation block at 11:1 must not have any labels.
+- Invalid provider_installation block: The provider_installation block at 13:1 must not be introduced with an equals sign.`
 
 	// The above error messages include only line/column location information
 	// and not file location information because HCL 1 does not store
@@ -80,3 +89,30 @@ func TestLoadConfig_providerInstallationErrors(t *testing.T) {
 		t.Errorf("wrong diagnostics\ngot:\n%s\nwant:\n%s", got, want)
 	}
 }
+
+func TestProviderInstallationOCIMirror(t *testing.T) {
+	mirror := ProviderInstallationOCIMirror{
+		RepositoryTemplate: "example.net:5000/mirror/${hostname}/${namespace}/${type}",
+	}
+	gotDomain, gotName, err := mirror.RepositoryForProvider(addrs.MustParseProviderSourceString("hashicorp/aws"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := "example.net:5000"; gotDomain != want {
+		t.Errorf("wrong registry domain %q; want %q", gotDomain, want)
+	}
+	if want := "mirror/registry.opentofu.org/hashicorp/aws"; gotName != want {
+		t.Errorf("wrong repository name %q; want %q", gotName, want)
+	}
+
+	for template, wantErr := range map[string]string{
+		"example.net/${namespace}/${type}/${version}": "the only placeholders allowed are",
+		"example.net/${type}":                         "must include the ${namespace} and ${type} placeholders",
+		"example.net/Mirror/${namespace}/${type}":     "invalid repository address",
+	} {
+		err := ProviderInstallationOCIMirror{RepositoryTemplate: template}.Validate()
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("wrong error for %q: %v; want %q", template, err, wantErr)
+		}
+	}
+}
@@ -169,7 +169,8 @@ func (c *ConsoleCommand) Run(args []string) int {
 
 	// IO Loop
 	session := &repl.Session{
-		Scope: scope,
+		Scope:     scope,
+		Addresses: repl.ModuleAddresses(lr.Config.Module),
 	}
 
 	// Determine if stdin is a pipe. If so, we evaluate directly.
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/opentofu/opentofu/internal/repl"
@@ -19,12 +20,28 @@ import (
 )
 
 func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
+	// History is kept in the data directory of the working directory, if it
+	// has been initialized.
+	var historyFile string
+	if info, err := os.Stat(c.DataDir()); err == nil && info.IsDir() {
+		historyFile = filepath.Join(c.DataDir(), consoleHistoryFile)
+	}
+
+	// Tab completion only makes sense at a terminal. Otherwise, tabs in the
+	// input are kept as they are.
+	var completer readline.AutoCompleter
+	if readline.DefaultIsTerminal() {
+		completer = consoleCompleter{session}
+	}
+
 	// Configure input
 	l, err := readline.NewEx(&readline.Config{
 		Prompt:            "> ",
 		InterruptPrompt:   "^C",
 		EOFPrompt:         "exit",
+		HistoryFile:       historyFile,
 		HistorySearchFold: true,
+		AutoComplete:      completer,
 		Stdin:             os.Stdin,
 		Stdout:            os.Stdout,
 		Stderr:            os.Stderr,
@@ -80,3 +97,24 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 
 	return 0
 }
+
+// consoleHistoryFile is the name of the file in the data directory where the
+// console keeps the history of its input.
+const consoleHistoryFile = "console_history"
+
+// consoleCompleter implements readline.AutoCompleter with the completions of
+// a REPL session.
+type consoleCompleter struct {
+	session *repl.Session
+}
+
+func (c consoleCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	completions, length := c.session.Complete(string(line[:pos]))
+	ret := make([][]rune, len(completions))
+	for i, completion := range completions {
+		// readline wants the part of each completion after the word, which
+		// only has ASCII characters.
+		ret[i] = []rune(completion[length:])
+	}
+	return ret, length
+}
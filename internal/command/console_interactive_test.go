@@ -92,6 +92,10 @@ func TestConsole_multiline_interactive(t *testing.T) {
 			}`,
 			expected: "\n{\n  \"default\" = <<-EOT\n  lulululu\n  \n  EOT\n}\n",
 		},
+		"heredoc_with_brace": {
+			input:    "<<EOT\n{\nEOT\n",
+			expected: "<<EOT\n{\n\nEOT\n",
+		},
 		"quoted_braces": {
 			input:    "{\ndefault = format(\"%s%s%s\",\"{\",var.counts.lalala,\"}\")\n}",
 			expected: "{\n  \"default\" = \"{1}\"\n}\n",
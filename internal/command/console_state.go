@@ -17,6 +17,7 @@ type consoleBracketState struct {
 	brace       int
 	bracket     int
 	parentheses int
+	heredoc     int
 	buffer      []string
 }
 
@@ -28,7 +29,7 @@ type consoleBracketState struct {
 // for example "())" has too many close brackets
 // 0 is returned if the brackets are closed.
 // for examples "()" or "" would be in a close bracket state
-// >=1 is returned for the amount of open brackets.
+// >=1 is returned for the amount of open brackets and heredocs.
 // for example "({" would return 2. "({}" would return 1
 func (c *consoleBracketState) commandInOpenState() int {
 	switch {
@@ -46,6 +47,7 @@ func (c *consoleBracketState) commandInOpenState() int {
 	total += c.brace
 	total += c.bracket
 	total += c.parentheses
+	total += c.heredoc
 	return total
 }
 
@@ -55,20 +57,28 @@ func (c *consoleBracketState) UpdateState(line string) (string, int) {
 	// as new lines are a kind of "one off" we reset each update
 	c.openNewLine = 0
 
-	// escaped new lines are treated as a "one off" bracket
-	// the four \\\\ means we have a false positive for a new line, as it's just an escaped \..
-	if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
-		c.openNewLine++
-	}
+	// lines inside a heredoc are literal text, so they can neither escape
+	// a new line nor be skipped when empty
+	if c.heredoc == 0 {
+		// escaped new lines are treated as a "one off" bracket
+		// the four \\\\ means we have a false positive for a new line, as it's just an escaped \..
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			c.openNewLine++
+		}
 
-	line = strings.TrimSuffix(line, "\\")
-	if len(line) == 0 {
-		// we can skip empty lines
-		return c.getCommand(), c.commandInOpenState()
+		line = strings.TrimSuffix(line, "\\")
+		if len(line) == 0 {
+			// we can skip empty lines
+			return c.getCommand(), c.commandInOpenState()
+		}
 	}
 	c.buffer = append(c.buffer, line)
 
-	tokens, _ := hclsyntax.LexConfig([]byte(line), "<console-input>", hcl.Pos{Line: 1, Column: 1})
+	// we lex the whole command rather than just the new line, so that
+	// brackets inside heredocs are not counted. heredoc markers are only
+	// recognized at the end of a line, so we end the command with one
+	c.brace, c.bracket, c.parentheses, c.heredoc = 0, 0, 0, 0
+	tokens, _ := hclsyntax.LexConfig([]byte(c.getCommand()+"\n"), "<console-input>", hcl.Pos{Line: 1, Column: 1})
 	for _, token := range tokens {
 		switch token.Type { // we only care about these specific types
 		case hclsyntax.TokenOBrace:
@@ -83,6 +93,10 @@ func (c *consoleBracketState) UpdateState(line string) (string, int) {
 			c.parentheses++
 		case hclsyntax.TokenCParen:
 			c.parentheses--
+		case hclsyntax.TokenOHeredoc:
+			c.heredoc++
+		case hclsyntax.TokenCHeredoc:
+			c.heredoc--
 		}
 	}
 	return c.getCommand(), c.commandInOpenState()
@@ -97,5 +111,6 @@ func (c *consoleBracketState) getCommand() string {
 func (c *consoleBracketState) checkStateAndClearBuffer() {
 	if c.commandInOpenState() <= 0 {
 		c.buffer = []string{}
+		c.brace, c.bracket, c.parentheses, c.heredoc = 0, 0, 0, 0
 	}
 }
@@ -150,6 +150,18 @@ func Test_UpdateState(t *testing.T) {
 			inputs:   []string{"\\\\"},
 			expected: 0,
 		},
+		"heredoc": {
+			inputs:   []string{"<<EOT", "{", "", "EOT"},
+			expected: 0,
+		},
+		"open heredoc": {
+			inputs:   []string{"<<-EOT", "}", ""},
+			expected: 1,
+		},
+		"heredoc in braces": {
+			inputs:   []string{"{", "a = <<EOT", "[", "EOT", "}"},
+			expected: 0,
+		},
 	}
 
 	for testName, tc := range tests {
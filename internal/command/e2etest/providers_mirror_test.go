@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -85,6 +86,71 @@ func testOpenTofuProvidersMirror(t *testing.T, fixture string) {
 	}
 }
 
+func TestOpenTofuProvidersMirrorIncrementalPrune(t *testing.T) {
+	// This test reaches out to registry.opentofu.org to download the
+	// template and null providers, so it can only run if network access is
+	// allowed.
+	skipIfCannotAccessNetwork(t)
+
+	outputDir := t.TempDir()
+	t.Logf("creating mirror directory in %s", outputDir)
+
+	fixturePath := filepath.Join("testdata", "tofu-providers-mirror")
+	tf := e2e.NewBinary(t, tofuBin, fixturePath)
+
+	stdout, stderr, err := tf.Run("providers", "mirror", "-platform=linux_amd64", "-platform=windows_386", outputDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\nstdout:\n%s\nstderr:\n%s", err, stdout, stderr)
+	}
+
+	// The second run selects the same versions from a lock file, but only
+	// for one of the platforms, and so it should download nothing and prune
+	// the packages for the other platform.
+	lockFile, err := filepath.Abs(filepath.Join("testdata", "tofu-providers-mirror-with-lock-file", ".terraform.lock.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, err = tf.Run("providers", "mirror", "-lock-file="+lockFile, "-platform=linux_amd64", "-prune", outputDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\nstdout:\n%s\nstderr:\n%s", err, stdout, stderr)
+	}
+	if strings.Contains(stdout, "Downloading package") {
+		t.Errorf("second run downloaded packages again\n%s", stdout)
+	}
+
+	want := []string{
+		"registry.opentofu.org/hashicorp/null/2.1.0.json",
+		"registry.opentofu.org/hashicorp/null/index.json",
+		"registry.opentofu.org/hashicorp/null/terraform-provider-null_2.1.0_linux_amd64.zip",
+		"registry.opentofu.org/hashicorp/template/2.1.1.json",
+		"registry.opentofu.org/hashicorp/template/index.json",
+		"registry.opentofu.org/hashicorp/template/terraform-provider-template_2.1.1_linux_amd64.zip",
+	}
+	var got []string
+	walkErr := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil // we only care about leaf files for this test
+		}
+		relPath, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		got = append(got, filepath.ToSlash(relPath))
+		return nil
+	})
+	if walkErr != nil {
+		t.Fatal(walkErr)
+	}
+	sort.Strings(got)
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected files in result\n%s", diff)
+	}
+}
+
 // this test is based on testOpenTofuProvidersMirror above.
 func TestOpenTofuProvidersMirrorBadLockfile(t *testing.T) {
 	// This test reaches out to registry.opentofu.org to download the
@@ -9,9 +9,12 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/jsongraph"
 	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/lang/globalref"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -32,6 +35,11 @@ func (c *GraphCommand) Run(args []string) int {
 	var moduleDepth int
 	var verbose bool
 	var planPath string
+	var format string
+	var focus string
+	var depth int
+	var moduleStr string
+	var impactStr string
 
 	ctx := c.CommandContext()
 
@@ -43,6 +51,11 @@ func (c *GraphCommand) Run(args []string) int {
 	cmdFlags.IntVar(&moduleDepth, "module-depth", -1, "module-depth")
 	cmdFlags.BoolVar(&verbose, "verbose", false, "verbose")
 	cmdFlags.StringVar(&planPath, "plan", "", "plan")
+	cmdFlags.StringVar(&format, "format", "", "format")
+	cmdFlags.StringVar(&focus, "focus", "", "focus")
+	cmdFlags.IntVar(&depth, "depth", -1, "depth")
+	cmdFlags.StringVar(&moduleStr, "module", "", "module")
+	cmdFlags.StringVar(&impactStr, "impact", "", "impact")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -55,6 +68,66 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
+	var impact addrs.ConfigResource
+	if impactStr != "" {
+		if format == "" {
+			format = "text"
+		}
+		if format != "text" && format != "json" {
+			c.Ui.Error(fmt.Sprintf("Unsupported impact format %q. With -impact=..., the -format=... argument must be either \"text\" or \"json\".", format))
+			return 1
+		}
+		if focus != "" || moduleStr != "" {
+			c.Ui.Error("The -impact=... argument can't be combined with -focus=... or -module=....")
+			return 1
+		}
+		addr, addrDiags := addrs.ParseAbsResourceInstanceStr(impactStr)
+		if addrDiags.HasErrors() {
+			c.showDiagnostics(addrDiags)
+			return 1
+		}
+		// The graph has one node for each resource in the configuration
+		// rather than for each instance, so the impact of an instance is
+		// the impact of the whole resource.
+		hasKey := addr.Resource.Key != addrs.NoKey
+		for _, step := range addr.Module {
+			hasKey = hasKey || step.InstanceKey != addrs.NoKey
+		}
+		if hasKey {
+			c.Ui.Error(fmt.Sprintf("The -impact=... argument must be the address of a resource rather than of a resource instance, such as %s.", addr.ContainingResource().Config()))
+			return 1
+		}
+		impact = addr.ContainingResource().Config()
+	} else {
+		if format == "" {
+			format = "dot"
+		}
+		switch format {
+		case "dot", "json", "mermaid":
+		default:
+			c.Ui.Error(fmt.Sprintf("Unsupported graph format %q. The -format=... argument must be either \"dot\", \"json\", or \"mermaid\".", format))
+			return 1
+		}
+	}
+	if depth >= 0 && focus == "" {
+		c.Ui.Error("The -depth=... argument can only be used together with -focus=....")
+		return 1
+	}
+	// Only the unfiltered graph in DOT format can highlight cycles.
+	if drawCycles && (format != "dot" || focus != "" || moduleStr != "" || planPath != "") {
+		c.Ui.Error("The -draw-cycles argument can't be combined with -format=..., -focus=..., -module=..., -impact=... or -plan=....")
+		return 1
+	}
+	var module addrs.Module
+	if moduleStr != "" {
+		var moduleDiags tfdiags.Diagnostics
+		module, moduleDiags = addrs.ParseModuleStr(moduleStr)
+		if moduleDiags.HasErrors() {
+			c.showDiagnostics(moduleDiags)
+			return 1
+		}
+	}
+
 	// Check for user-supplied plugin path
 	if c.pluginPath, err = c.loadPluginPath(); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
@@ -225,14 +298,57 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
-	graphStr, err := tofu.GraphDot(g, &dag.DotOpts{
-		DrawCycles: drawCycles,
-		MaxDepth:   moduleDepth,
-		Verbose:    verbose,
-	})
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Error converting graph: %s", err))
-		return 1
+	var graphStr string
+	if impactStr != "" {
+		var impactDiags tfdiags.Diagnostics
+		graphStr, impactDiags = c.impact(lr, g, impact, format)
+		diags = diags.Append(impactDiags)
+		if impactDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+	} else if format == "dot" && focus == "" && moduleStr == "" && lr.Plan == nil {
+		// Without any filtering or annotation we render the full graph
+		// directly, which is the only form that can highlight cycles.
+		graphStr, err = tofu.GraphDot(g, &dag.DotOpts{
+			DrawCycles: drawCycles,
+			MaxDepth:   moduleDepth,
+			Verbose:    verbose,
+		})
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error converting graph: %s", err))
+			return 1
+		}
+	} else {
+		opts := jsongraph.Opts{Verbose: verbose}
+		if lr.Plan != nil {
+			opts.Changes = lr.Plan.Changes
+		}
+		jg := jsongraph.New(g, graphTypeStr, opts)
+		if moduleStr != "" {
+			jg = jg.FilterModule(module)
+		}
+		if focus != "" {
+			jg, err = jg.Focus(focus, depth)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error filtering graph: %s", err))
+				return 1
+			}
+		}
+
+		switch format {
+		case "json":
+			out, err := jsongraph.Marshal(jg)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error converting graph: %s", err))
+				return 1
+			}
+			graphStr = string(out)
+		case "mermaid":
+			graphStr = jg.Mermaid()
+		default:
+			graphStr = jg.Dot()
+		}
 	}
 
 	if diags.HasErrors() {
@@ -248,6 +364,65 @@ func (c *GraphCommand) Run(args []string) int {
 	return 0
 }
 
+// impact returns the objects that depend on the given resource in the given
+// graph, in the given format.
+func (c *GraphCommand) impact(lr *backend.LocalRun, g *tofu.Graph, target addrs.ConfigResource, format string) (string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	deps, err := tofu.GraphDependents(g, target)
+	if err != nil {
+		return "", diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid impact address",
+			fmt.Sprintf("Cannot analyze the impact of %s: %s.", target, err),
+		))
+	}
+
+	// The graph only tells us which objects depend on the resource, while
+	// the reference analyzer can tell which of its attributes they use.
+	schemas, schemaDiags := lr.Core.Schemas(lr.Config, lr.InputState)
+	diags = diags.Append(schemaDiags)
+	if schemaDiags.HasErrors() {
+		return "", diags
+	}
+	analyzer := globalref.NewAnalyzer(lr.Config, schemas.Providers)
+	impact := jsongraph.NewImpact(target, deps, analyzer)
+
+	if format == "json" {
+		out, err := jsongraph.MarshalImpact(impact)
+		if err != nil {
+			return "", diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to marshal impact",
+				fmt.Sprintf("Cannot convert the impact analysis to JSON: %s.", err),
+			))
+		}
+		return string(out), diags
+	}
+
+	if len(impact.Dependents) == 0 {
+		return fmt.Sprintf("No objects depend on %s.", impact.Address), diags
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Objects that depend on %s:\n\n", impact.Address)
+	fmt.Fprintf(&buf, "  %-8s %-8s %-50s %s\n", "DISTANCE", "KIND", "ADDRESS", "ATTRIBUTES")
+	for _, dep := range impact.Dependents {
+		attrs := "-"
+		if len(dep.Attributes) > 0 {
+			attrs = strings.Join(dep.Attributes, ", ")
+		}
+		fmt.Fprintf(&buf, "  %-8d %-8s %-50s %s\n", dep.Distance, dep.Kind, dep.Address, attrs)
+	}
+	if len(impact.Modules) > 0 {
+		buf.WriteString("\nModules containing dependent objects:\n\n")
+		for _, module := range impact.Modules {
+			fmt.Fprintf(&buf, "  %s\n", module)
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n"), diags
+}
+
 func (c *GraphCommand) Help() string {
 	helpText := `
 Usage: tofu [global options] graph [options]
@@ -255,17 +430,41 @@ Usage: tofu [global options] graph [options]
   Produces a representation of the dependency graph between different
   objects in the current configuration and state.
 
-  The graph is presented in the DOT language. The typical program that can
-  read this format is GraphViz, but many web services are also available
-  to read this format.
+  By default, the graph is presented in the DOT language. The typical
+  program that can read this format is GraphViz, but many web services are
+  also available to read this format.
 
 Options:
 
+  -format=dot      Output format of the graph. Can be: dot, json, or mermaid.
+                   Defaults to dot, or to text when -impact is set.
+                   The json format is a stable, documented representation
+                   intended for other tools.
+
+  -focus=addr      Only show the object with the given address, along with
+                   the objects it depends on and the objects that depend on
+                   it.
+
+  -depth=n         Used with -focus, limits the objects shown to those at
+                   most n dependencies away from the focused object.
+
+  -module=addr     Only show the objects of the given module and of its
+                   child modules, such as -module=module.network.
+
+  -impact=addr     Instead of the graph, list the resources, outputs and
+                   modules that depend directly or transitively on the
+                   resource with the given address, which must not have
+                   instance keys. The list is written as text, or as JSON
+                   with -format=json.
+
   -plan=tfplan     Render graph using the specified plan file instead of the
-                   configuration in the current directory.
+                   configuration in the current directory. The resources
+                   are annotated with their planned actions.
 
   -draw-cycles     Highlight any cycles in the graph with colored edges.
-                   This helps when diagnosing cycle errors.
+                   This helps when diagnosing cycle errors. Only supported
+                   for the unfiltered DOT output of a configuration, so it
+                   can't be combined with filtering options or -plan.
 
   -type=plan       Type of graph to output. Can be: plan, plan-refresh-only,
                    plan-destroy, or apply. By default OpenTofu chooses
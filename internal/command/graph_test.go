@@ -6,14 +6,17 @@
 package command
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/cli"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsongraph"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states"
 )
@@ -42,6 +45,199 @@ func TestGraph(t *testing.T) {
 	}
 }
 
+func TestGraph_json(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{"-format=json"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	var got jsongraph.Graph
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s", err)
+	}
+	if got.FormatVersion != jsongraph.FormatVersion || got.Type != "plan" {
+		t.Fatalf("wrong format version or type: %q, %q", got.FormatVersion, got.Type)
+	}
+
+	kinds := make(map[string]string)
+	for _, n := range got.Nodes {
+		kinds[n.Address] = n.Kind
+	}
+	if kinds["test_instance.foo"] != jsongraph.KindResource {
+		t.Fatalf("missing resource node: %#v", kinds)
+	}
+	if kinds[`provider["registry.opentofu.org/hashicorp/test"]`] != jsongraph.KindProvider {
+		t.Fatalf("missing provider node: %#v", kinds)
+	}
+	if len(got.Edges) != 1 || got.Edges[0].From != "test_instance.foo (expand)" {
+		t.Fatalf("wrong edges: %#v", got.Edges)
+	}
+}
+
+func TestGraph_mermaidFocus(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{"-format=mermaid", "-focus=test_instance.foo", "-depth=0"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	want := "flowchart LR\n    n0[\"test_instance.foo\"]\n"
+	if got := ui.OutputWriter.String(); strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Fatalf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGraph_focusNotFound(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{"-focus=test_instance.missing"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("expected failure, got output: \n%s", ui.OutputWriter.String())
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, `no node with the address "test_instance.missing"`) {
+		t.Fatalf("wrong error: %s", got)
+	}
+}
+
+func TestGraph_invalidOptions(t *testing.T) {
+	tests := map[string][]string{
+		"format":        {"-format=svg"},
+		"depth":         {"-depth=2"},
+		"module":        {"-module=module.foo[0"},
+		"impact format": {"-impact=test_instance.foo", "-format=mermaid"},
+		"impact focus":  {"-impact=test_instance.foo", "-focus=test_instance.foo"},
+		"impact key":    {"-impact=test_instance.foo[0]"},
+		"cycles format": {"-draw-cycles", "-format=json"},
+		"cycles focus":  {"-draw-cycles", "-focus=test_instance.foo"},
+		"cycles module": {"-draw-cycles", "-module=module.child"},
+		"cycles impact": {"-draw-cycles", "-impact=test_instance.foo"},
+		"cycles plan":   {"-draw-cycles", "-plan=tfplan"},
+		"impact module": {"-impact=module.child[\"a\"].test_instance.foo"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			ui := new(cli.MockUi)
+			c := &GraphCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+					Ui:               ui,
+				},
+			}
+
+			if code := c.Run(args); code != 1 {
+				t.Fatalf("expected failure, got output: \n%s", ui.OutputWriter.String())
+			}
+		})
+	}
+}
+
+func TestGraph_impact(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph-impact"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{"-impact=test_instance.vpc", "-format=json"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	var got jsongraph.Impact
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s", err)
+	}
+	want := jsongraph.Impact{
+		FormatVersion: jsongraph.FormatVersion,
+		Address:       "test_instance.vpc",
+		Dependents: []jsongraph.Dependent{
+			{Address: "test_instance.subnet", Kind: jsongraph.KindResource, Distance: 1, Attributes: []string{"id"}},
+			{Address: "output.subnet", Kind: jsongraph.KindOutput, Distance: 2, Attributes: []string{"id"}},
+		},
+		Modules: []string{},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong impact\n%s", diff)
+	}
+}
+
+func TestGraph_impactText(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph-impact"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{"-impact=test_instance.unrelated"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if got, want := strings.TrimSpace(ui.OutputWriter.String()), "No objects depend on test_instance.unrelated."; got != want {
+		t.Fatalf("wrong output\ngot:  %s\nwant: %s", got, want)
+	}
+
+	ui = new(cli.MockUi)
+	c = &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+	args = []string{"-impact=test_instance.missing"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("expected failure, got output: \n%s", ui.OutputWriter.String())
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "Cannot analyze the impact of test_instance.missing") {
+		t.Fatalf("wrong error: %s", got)
+	}
+}
+
 func TestGraph_multipleArgs(t *testing.T) {
 	ui := new(cli.MockUi)
 	c := &GraphCommand{
@@ -163,3 +359,74 @@ func TestGraph_plan(t *testing.T) {
 		t.Fatalf("doesn't look like digraph: %s", output)
 	}
 }
+
+func TestGraph_planAnnotations(t *testing.T) {
+	testCwd(t)
+
+	plan := &plans.Plan{
+		Changes: plans.NewChanges(),
+	}
+	plan.Changes.Resources = append(plan.Changes.Resources, &plans.ResourceInstanceChangeSrc{
+		Addr: addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: "foo",
+		}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+		ChangeSrc: plans.ChangeSrc{
+			Action: plans.Create,
+			Before: plans.DynamicValue(`null`),
+			After:  plans.DynamicValue(`{}`),
+		},
+		ProviderAddr: addrs.AbsProviderConfig{
+			Provider: addrs.NewDefaultProvider("test"),
+			Module:   addrs.RootModule,
+		},
+	})
+	beConfig := cty.ObjectVal(map[string]cty.Value{
+		"path":          cty.NilVal,
+		"workspace_dir": cty.NilVal,
+	})
+	emptyConfig, err := plans.NewDynamicValue(beConfig, beConfig.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.Backend = plans.Backend{
+		Type:   "local",
+		Config: emptyConfig,
+	}
+	_, configSnap := testModuleWithSnapshot(t, "graph")
+
+	planPath := testPlanFile(t, configSnap, states.NewState(), plan)
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{
+		"-plan", planPath,
+		"-type=plan",
+		"-format=json",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	var got jsongraph.Graph
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s", err)
+	}
+	for _, n := range got.Nodes {
+		if n.Address != "test_instance.foo" {
+			continue
+		}
+		if len(n.PlannedActions) != 1 || n.PlannedActions[0] != "create" {
+			t.Fatalf("wrong planned actions: %#v", n.PlannedActions)
+		}
+		return
+	}
+	t.Fatalf("missing resource node in %#v", got.Nodes)
+}
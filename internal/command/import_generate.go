@@ -0,0 +1,414 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/genconfig"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// ImportGenerateCommand is a cli.Command implementation that generates
+// import blocks and resource configuration for the existing objects listed
+// in a resource inventory file.
+type ImportGenerateCommand struct {
+	Meta
+}
+
+// importGenerated is the configuration generated for an inventory entry.
+type importGenerated struct {
+	entry  *genconfig.InventoryEntry
+	config string
+}
+
+func (c *ImportGenerateCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	var fromPath, outDir string
+	args = c.Meta.process(args)
+
+	cmdFlags := c.Meta.extendedFlagSet("import generate")
+	cmdFlags.StringVar(&fromPath, "from", "", "path")
+	cmdFlags.StringVar(&outDir, "out", ".", "path")
+	cmdFlags.IntVar(&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The import generate command expects no arguments.")
+		cmdFlags.Usage()
+		return 1
+	}
+	if fromPath == "" {
+		c.Ui.Error("The -from option is required.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	src, err := os.ReadFile(fromPath)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read inventory file",
+			fmt.Sprintf("Couldn't read the inventory file %s: %s.", fromPath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	entries, moreDiags := genconfig.ParseInventory(fromPath, src)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if len(entries) == 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Empty inventory file",
+			fmt.Sprintf("The inventory file %s doesn't list any objects to import.", fromPath),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if !c.dirIsConfigPath(".") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No OpenTofu configuration files",
+			"The current directory does not contain any OpenTofu configuration files (.tf or .tf.json). OpenTofu needs the provider configurations and requirements of the configuration to import the listed objects.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Check for user-supplied plugin path
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the backend
+	backendConfig, backendDiags := c.loadBackendConfig(".")
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	b, backendDiags := c.Backend(&BackendOpts{
+		Config: backendConfig,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We require a backend.Local to build a context, in the same way as the
+	// import command.
+	local, ok := b.(backend.Local)
+	if !ok {
+		c.Ui.Error(ErrUnsupportedLocalOp)
+		return 1
+	}
+
+	// Build the operation
+	opReq := c.Operation(b, arguments.ViewHuman, enc)
+	opReq.ConfigDir = "."
+	opReq.ConfigLoader, err = c.initConfigLoader()
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	opReq.Hooks = []tofu.Hook{c.uiHook()}
+	opReq.PlanMode = plans.NormalMode
+	opReq.GenerateConfigOut = outDir
+	{
+		// Setup required variables/call for operation (usually done in Meta.RunOperation)
+		var moreDiags, callDiags tfdiags.Diagnostics
+		opReq.Variables, moreDiags = c.collectVariableValues()
+		opReq.RootCall, callDiags = c.rootModuleCall(opReq.ConfigDir)
+		diags = diags.Append(moreDiags).Append(callDiags)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+	opReq.View = views.NewOperation(arguments.ViewHuman, c.RunningInAutomation, c.View)
+
+	// Check remote OpenTofu version is compatible
+	remoteVersionDiags := c.remoteVersionCheck(b, opReq.Workspace)
+	diags = diags.Append(remoteVersionDiags)
+	c.showDiagnostics(diags)
+	diags = nil
+	if remoteVersionDiags.HasErrors() {
+		return 1
+	}
+
+	// Get the context
+	lr, _, ctxDiags := local.LocalRun(ctx, opReq)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Successfully creating the context can result in a lock, so ensure we release it
+	defer func() {
+		diags := opReq.StateLocker.Unlock()
+		if diags.HasErrors() {
+			c.showDiagnostics(diags)
+		}
+	}()
+
+	// The generated resources must not collide with the resources that the
+	// configuration already declares or imports.
+	taken := make(map[string]bool)
+	for key := range lr.Config.Module.ManagedResources {
+		taken[key] = true
+	}
+	for _, imp := range lr.Config.Module.Import {
+		taken[imp.StaticTo.Resource.String()] = true
+	}
+	genconfig.AssignNames(entries, taken)
+
+	files := make(map[string]string)
+	var types []string
+	for _, entry := range entries {
+		if _, exists := files[entry.Type]; exists {
+			continue
+		}
+		path := filepath.Join(outDir, entry.Type+".tf")
+		diags = diags.Append(genconfig.ValidateTargetFile(path))
+		files[entry.Type] = path
+		types = append(types, entry.Type)
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Plan with an import block for each entry, which makes OpenTofu import
+	// the objects and generate their configuration. The plan itself is
+	// discarded, so neither the state nor the remote objects are changed.
+	for _, entry := range entries {
+		lr.Config.Module.Import = append(lr.Config.Module.Import, importGenerateConfig(lr.Config.Module, entry, fromPath))
+	}
+	plan, planDiags := lr.Core.Plan(ctx, lr.Config, lr.InputState, lr.PlanOpts)
+	diags = diags.Append(planDiags)
+	if planDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	generated := make([]*importGenerated, 0, len(entries))
+	for _, entry := range entries {
+		gen, moreDiags := importGeneratedForEntry(plan, entry)
+		diags = diags.Append(moreDiags)
+		if gen != nil {
+			generated = append(generated, gen)
+		}
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Values that match the ID of another imported object, or of a resource
+	// already in the state, become references to it.
+	refs := genconfig.NewReferences(plan)
+	var refCount int
+	for _, gen := range generated {
+		var count int
+		gen.config, count = refs.Replace(gen.entry.Addr(), gen.config)
+		refCount += count
+	}
+
+	writers := make(map[string]io.Writer)
+	for _, gen := range generated {
+		change := genconfig.Change{
+			Addr:            gen.entry.Addr().String(),
+			ImportID:        gen.entry.ID,
+			GeneratedConfig: genconfig.GenerateImportBlock(gen.entry.Addr(), gen.entry.ID) + "\n" + gen.config,
+		}
+		var moreDiags tfdiags.Diagnostics
+		writers[gen.entry.Type], _, moreDiags = change.MaybeWriteConfig(writers[gen.entry.Type], files[gen.entry.Type])
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			break
+		}
+	}
+	for _, w := range writers {
+		if closer, ok := w.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				diags = diags.Append(fmt.Errorf("Failed to write generated config: %w", err))
+			}
+		}
+	}
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "[reset][green]\nGenerated configuration for %d resources:\n", len(generated))
+	for _, typ := range types {
+		fmt.Fprintf(&buf, "  %s\n", files[typ])
+	}
+	if refCount > 0 {
+		fmt.Fprintf(&buf, "\nReplaced %d values with references to other resources.\n", refCount)
+	}
+	buf.WriteString("\n" + importGenerateSuccessMsg)
+	c.Ui.Output(c.Colorize().Color(buf.String()))
+	return 0
+}
+
+// importGenerateConfig returns a synthetic import block for an inventory
+// entry, as if the configuration declared it.
+func importGenerateConfig(mod *configs.Module, entry *genconfig.InventoryEntry, fromPath string) *configs.Import {
+	rng := hcl.Range{
+		Filename: fromPath,
+		Start:    hcl.InitialPos,
+		End:      hcl.InitialPos,
+	}
+	addr := entry.Addr()
+	to := &hclsyntax.ScopeTraversalExpr{
+		Traversal: hcl.Traversal{
+			hcl.TraverseRoot{Name: entry.Type, SrcRange: rng},
+			hcl.TraverseAttr{Name: entry.Name, SrcRange: rng},
+		},
+		SrcRange: rng,
+	}
+
+	imp := &configs.Import{
+		ID:         hcl.StaticExpr(cty.StringVal(entry.ID), rng),
+		To:         to,
+		StaticTo:   addr.ConfigResource(),
+		ResolvedTo: &addr,
+		DeclRange:  rng,
+	}
+	if implied, err := addrs.ParseProviderPart(addr.Resource.Resource.ImpliedProvider()); err == nil {
+		imp.Provider = mod.ImpliedProviderForUnqualifiedType(implied)
+	}
+	return imp
+}
+
+// importGeneratedForEntry returns the configuration that the plan generated
+// for an inventory entry.
+func importGeneratedForEntry(plan *plans.Plan, entry *genconfig.InventoryEntry) (*importGenerated, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	addr := entry.Addr()
+	change := plan.Changes.ResourceInstance(addr)
+	if change == nil || change.GeneratedConfig == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No configuration generated",
+			fmt.Sprintf("OpenTofu didn't generate configuration for %s, which imports the %s object %q. This is a bug in OpenTofu; please report it!", addr, entry.Type, entry.ID),
+		))
+		return nil, diags
+	}
+	return &importGenerated{
+		entry:  entry,
+		config: change.GeneratedConfig,
+	}, diags
+}
+
+func (c *ImportGenerateCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] import generate [options] -from=FILE
+
+  Generate import blocks and resource configuration for a list of existing
+  objects.
+
+  The inventory FILE lists the objects to import. A file with the extension
+  .json must contain an array of objects with the properties "type", "id",
+  and optionally "name". A file with the extension .csv must start with a
+  header row naming the "type", "id", and optionally "name" columns.
+
+  Objects without a name get one derived from their ID, and names that are
+  already in use get a numeric suffix. The configuration for each resource
+  type is written to a new file named after the type, and values that match
+  the ID of another listed object are replaced with references to it.
+
+  This command will not modify your state or your infrastructure, but it
+  will make network requests to read the listed objects. Run "tofu plan"
+  afterwards to review the imports.
+
+Options:
+
+  -from=path              The inventory file. Required.
+
+  -out=path               The directory to write the generated files to.
+                          Defaults to the current directory.
+
+  -compact-warnings       If OpenTofu produces any warnings that are not
+                          accompanied by errors, show them in a more compact
+                          form that includes only the summary messages.
+
+  -input=false            Disable interactive input prompts.
+
+  -lock=false             Don't hold a state lock during the operation. This is
+                          dangerous if others might concurrently run commands
+                          against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -no-color               If specified, output won't contain any color.
+
+  -parallelism=n          Limit the number of concurrent operations. Defaults
+                          to 10.
+
+  -var 'foo=bar'          Set a variable in the OpenTofu configuration. This
+                          flag can be set multiple times.
+
+  -var-file=foo           Set variables in the OpenTofu configuration from
+                          a file. If "terraform.tfvars" or any ".auto.tfvars"
+                          files are present, they will be automatically loaded.
+
+  -state is a legacy option supported for the local backend only. For more
+  information, see the local backend's documentation.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ImportGenerateCommand) Synopsis() string {
+	return "Generate configuration for a list of existing objects"
+}
+
+const importGenerateSuccessMsg = `Review the generated configuration, then run "tofu plan" to see the
+imports that OpenTofu will perform.
+`
@@ -0,0 +1,120 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+func TestImportGenerate(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("import-generate"), td)
+	defer testChdir(t, td)()
+
+	p := planFixtureProvider()
+	amis := map[string]string{
+		"base-1": "ami-123",
+		"child":  "base-1",
+	}
+	p.ImportResourceStateFn = func(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+		return providers.ImportResourceStateResponse{
+			ImportedResources: []providers.ImportedResource{
+				{
+					TypeName: req.TypeName,
+					State: cty.ObjectVal(map[string]cty.Value{
+						"id":                cty.StringVal(req.ID),
+						"ami":               cty.StringVal(amis[req.ID]),
+						"network_interface": cty.ListValEmpty(cty.Object(map[string]cty.Type{"device_index": cty.String, "description": cty.String})),
+					}),
+				},
+			},
+		}
+	}
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &ImportGenerateCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	if code := c.Run([]string{"-from=inventory.csv"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The child refers to the base by ID, and its name is taken by the
+	// resource of the same name in the configuration.
+	testFileEquals(t, filepath.Join(td, "test_instance.tf"), filepath.Join(td, "test_instance.tf.expected"))
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"Generated configuration for 2 resources",
+		"Replaced 1 values with references to other resources.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, output)
+		}
+	}
+}
+
+func TestImportGenerate_existingFile(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("import-generate"), td)
+	defer testChdir(t, td)()
+
+	if err := os.WriteFile("test_instance.tf", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := planFixtureProvider()
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &ImportGenerateCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	if code := c.Run([]string{"-from=inventory.csv"}); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "Target generated file already exists"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+	if p.ImportResourceStateCalled {
+		t.Error("imported objects although the target file exists")
+	}
+}
+
+func TestImportGenerate_badArgs(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no inventory":   {},
+		"extra argument": {"-from=inventory.csv", "foo"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ui := new(cli.MockUi)
+			c := &ImportGenerateCommand{
+				Meta: Meta{
+					Ui: ui,
+				},
+			}
+			if code := c.Run(args); code != 1 {
+				t.Fatalf("bad: %d", code)
+			}
+		})
+	}
+}
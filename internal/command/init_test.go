@@ -6,11 +6,13 @@
 package command
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,7 +21,9 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/cli"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/zclconf/go-cty/cty"
+	orasremote "oras.land/oras-go/v2/registry/remote"
 
 	"github.com/hashicorp/go-version"
 
@@ -28,7 +32,10 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/getmodules"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/ociregistry"
+	"github.com/opentofu/opentofu/internal/ociregistry/ocitest"
 	"github.com/opentofu/opentofu/internal/providercache"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
@@ -220,6 +227,170 @@ func TestInit_getUpgradeModules(t *testing.T) {
 	}
 }
 
+func TestInit_getLockedOCIModule(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-get-oci-locked"), td)
+	defer testChdir(t, td)()
+
+	// The lock file pins the module's OCI tag to the "package" directory, as
+	// if that was the digest that the tag referred to, so installing it
+	// needs no OCI registry. It also has a lock for a module that is no
+	// longer used.
+	const source = "oci://example.com/modules/vpc:1.0.0"
+	const unused = "oci://example.com/modules/unused:1.0.0"
+	pinned, _, err := getmodules.NormalizePackageAddress(filepath.Join(td, "package"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locks := depsfile.NewLocks()
+	locks.SetModule(source, pinned)
+	locks.SetModule(unused, pinned)
+	if diags := depsfile.SaveLocksToFile(locks, dependencyLockFilename); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	newCommand := func() (*InitCommand, *cli.MockUi) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		return &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		}, ui
+	}
+
+	// With a read-only lock file, removing the unused lock is an error.
+	c, ui := newCommand()
+	if code := c.Run([]string{"-lockfile=readonly"}); code == 0 {
+		t.Fatalf("command succeeded with a read-only lock file; want error\n%s", ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "Module dependency changes detected"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	c, ui = newCommand()
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("command did not complete successfully:\n%s", ui.ErrorWriter.String())
+	}
+
+	gotLocks, diags := depsfile.LoadLocksFromFile(dependencyLockFilename)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	wantLocks := depsfile.NewLocks()
+	wantLocks.SetModule(source, pinned)
+	if !gotLocks.Equal(wantLocks) {
+		t.Errorf("wrong module locks\ngot:  %#v\nwant: %#v", gotLocks.AllModules(), wantLocks.AllModules())
+	}
+	if _, err := os.Stat(filepath.Join(td, ".terraform", "modules", "vpc", "main.tf")); err != nil {
+		t.Errorf("module was not installed from the locked package: %s", err)
+	}
+}
+
+func TestInit_getOCIModule(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	// The tag of the module refers to an image manifest with a .zip archive
+	// of the module package in an in-process OCI registry.
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("output \"name\" {\n  value = \"vpc\"\n}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	registry := ocitest.NewRegistry()
+	manifest := registry.PushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: getmodules.OCIModuleArtifactType,
+		Config:       registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}")),
+		Layers:       []ocispec.Descriptor{registry.Push(getmodules.OCIModuleMediaType, archive.Bytes())},
+	})
+	registry.Tag("modules/vpc", "1.0.0", manifest)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	registryDomain := strings.TrimPrefix(server.URL, "http://")
+
+	source := "oci://" + registryDomain + "/modules/vpc:1.0.0"
+	config := fmt.Sprintf("module \"vpc\" {\n  source = %q\n}\n", source)
+	if err := os.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newCommand := func() (*InitCommand, *cli.MockUi) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		return &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+				OCIRepositoryStore: func(_ context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryStore, error) {
+					repo, err := orasremote.NewRepository(registryDomain + "/" + repositoryName)
+					if err != nil {
+						return nil, err
+					}
+					repo.PlainHTTP = true
+					repo.Client = server.Client()
+					return repo, nil
+				},
+			},
+		}, ui
+	}
+	pinned := "oci://" + registryDomain + "/modules/vpc@" + manifest.Digest.String()
+	checkLock := func() {
+		t.Helper()
+		gotLocks, diags := depsfile.LoadLocksFromFile(dependencyLockFilename)
+		if diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+		lock := gotLocks.Module(source)
+		if lock == nil {
+			t.Fatalf("no lock for %s", source)
+		}
+		if got := lock.Pinned(); got != pinned {
+			t.Errorf("wrong pinned package\ngot:  %s\nwant: %s", got, pinned)
+		}
+	}
+
+	// The lock file records the digest that the tag referred to.
+	c, ui := newCommand()
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("command did not complete successfully:\n%s", ui.ErrorWriter.String())
+	}
+	checkLock()
+	if _, err := os.Stat(filepath.Join(td, ".terraform", "modules", "vpc", "main.tf")); err != nil {
+		t.Errorf("module was not installed: %s", err)
+	}
+
+	// After the tag moves, a fresh installation still uses the locked digest.
+	if err := os.RemoveAll(".terraform"); err != nil {
+		t.Fatal(err)
+	}
+	registry.Tag("modules/vpc", "1.0.0", registry.PushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.example.other",
+		Config:       registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}")),
+	}))
+	c, ui = newCommand()
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("command did not complete successfully:\n%s", ui.ErrorWriter.String())
+	}
+	checkLock()
+	if _, err := os.Stat(filepath.Join(td, ".terraform", "modules", "vpc", "main.tf")); err != nil {
+		t.Errorf("module was not installed from the locked digest: %s", err)
+	}
+}
+
 func TestInit_backend(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
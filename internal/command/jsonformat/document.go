@@ -0,0 +1,575 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// DocumentFormat is a markup language that a plan can be rendered in, for
+// display outside of a terminal such as in a pull request comment.
+type DocumentFormat string
+
+const (
+	DocumentMarkdown DocumentFormat = "markdown"
+	DocumentHTML     DocumentFormat = "html"
+)
+
+// DefaultDocumentMaxLength is the default limit on the length of a rendered
+// plan document. It leaves some room below the 65536 character limit of
+// GitHub comments for any text that is added around the plan.
+const DefaultDocumentMaxLength = 60000
+
+// RenderDocumentPlan writes the plan as a markdown or HTML document, with a
+// summary of the changes by action and module followed by a collapsible
+// section for each resource.
+//
+// Sensitive values are always hidden, because documents are intended to be
+// shared. If the whole document would be longer than maxLength bytes then the
+// least important sections are left out, and replaced by counts of what was
+// omitted.
+func (renderer Renderer) RenderDocumentPlan(plan Plan, mode plans.Mode, docFormat DocumentFormat, maxLength int, opts ...plans.Quality) {
+	var markup documentMarkup
+	switch docFormat {
+	case DocumentMarkdown:
+		markup = markdownMarkup{}
+	case DocumentHTML:
+		markup = htmlMarkup{}
+	default:
+		panic(fmt.Sprintf("unsupported document format %q", docFormat))
+	}
+
+	// The diffs are rendered by the human renderer, without colors and with
+	// sensitive values redacted.
+	renderer.Colorize = &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true}
+	renderer.ShowSensitive = false
+
+	doc := plan.document(renderer, mode, opts...)
+	renderer.Streams.Print(doc.render(markup, maxLength))
+}
+
+// planDocument is the content of a rendered plan, independent of the markup
+// language it is written in.
+type planDocument struct {
+	title      string
+	paragraphs []string
+	table      [][]string
+	groups     []documentGroup
+}
+
+// documentGroup is a list of sections under a single heading.
+type documentGroup struct {
+	heading  string
+	sections []documentSection
+
+	// omitted describes a number of sections from this group that were left
+	// out of the document, such as "3 resource changes".
+	omitted func(sections []documentSection) string
+}
+
+// documentSection is a single resource or set of output changes within a plan
+// document.
+type documentSection struct {
+	// summary is shown for collapsible sections, which are collapsed by
+	// default. If summary is empty, the body is always shown.
+	summary string
+	body    string
+
+	action plans.Action
+
+	// priority decides which sections are left out of a document that is too
+	// long. Sections with a higher priority are omitted first.
+	priority int
+}
+
+func (plan Plan) document(renderer Renderer, mode plans.Mode, opts ...plans.Quality) planDocument {
+	checkOpts := func(target plans.Quality) bool {
+		for _, opt := range opts {
+			if opt == target {
+				return true
+			}
+		}
+		return false
+	}
+
+	doc := planDocument{
+		title: "OpenTofu plan",
+	}
+	if incompatibleVersions(jsonplan.FormatVersion, plan.PlanFormatVersion) || incompatibleVersions(jsonprovider.FormatVersion, plan.ProviderFormatVersion) {
+		doc.paragraphs = append(doc.paragraphs, "Warning: This plan was generated using a different version of OpenTofu, the diff presented here may be missing representations of recent features.")
+	}
+
+	diffs := precomputeDiffs(plan, mode)
+
+	var drift []documentSection
+	for _, dr := range diffs.drift {
+		if mode != plans.RefreshOnlyMode && (dr.diff.Action == plans.NoOp || diffs.Empty()) {
+			// As in the human output, we only show drift that is relevant to
+			// the plan.
+			continue
+		}
+		if section, ok := documentDiffSection(renderer, dr, detectedDrift); ok {
+			section.priority = 5
+			drift = append(drift, section)
+		}
+	}
+
+	summary := newDocumentSummary()
+	var changes []documentSection
+	for _, diff := range diffs.changes {
+		action := jsonplan.UnmarshalActions(diff.change.Change.Actions)
+		if action == plans.Delete && diff.change.Mode != jsonstate.ManagedResourceMode {
+			// Don't render anything for deleted data sources.
+			continue
+		}
+		section, ok := documentDiffSection(renderer, diff, proposedChange)
+		if !ok {
+			continue
+		}
+		summary.add(diff)
+		changes = append(changes, section)
+	}
+
+	outputs := renderHumanDiffOutputs(renderer, diffs.outputs)
+
+	switch {
+	case len(changes) == 0 && len(outputs) == 0 && checkOpts(plans.Errored):
+		doc.paragraphs = append(doc.paragraphs, "Planning failed. OpenTofu encountered an error while generating this plan.")
+	case len(changes) == 0 && len(outputs) == 0:
+		switch mode {
+		case plans.RefreshOnlyMode:
+			if len(drift) == 0 {
+				doc.paragraphs = append(doc.paragraphs, "No changes. Your infrastructure still matches the configuration.")
+			}
+		case plans.DestroyMode:
+			doc.paragraphs = append(doc.paragraphs, "No changes. No objects need to be destroyed.")
+		default:
+			doc.paragraphs = append(doc.paragraphs, "No changes. Your infrastructure matches the configuration.")
+		}
+	default:
+		if checkOpts(plans.Errored) {
+			doc.paragraphs = append(doc.paragraphs, "OpenTofu planned the following actions, but then encountered a problem.")
+		}
+		if len(changes) > 0 {
+			doc.paragraphs = append(doc.paragraphs, summary.sentence())
+			doc.table = summary.table()
+		}
+	}
+
+	if len(drift) > 0 {
+		doc.groups = append(doc.groups, documentGroup{
+			heading:  "Objects changed outside of OpenTofu",
+			sections: drift,
+			omitted: func(sections []documentSection) string {
+				return countNoun(len(sections), "changed object")
+			},
+		})
+	}
+	if len(changes) > 0 {
+		doc.groups = append(doc.groups, documentGroup{
+			heading:  "Resource changes",
+			sections: changes,
+			omitted: func(sections []documentSection) string {
+				omitted := newDocumentSummary()
+				for _, section := range sections {
+					omitted.counts[section.action]++
+				}
+				return fmt.Sprintf("%s (%s)", countNoun(len(sections), "resource change"), omitted.actions())
+			},
+		})
+	}
+	if len(outputs) > 0 {
+		doc.groups = append(doc.groups, documentGroup{
+			heading: "Changes to Outputs",
+			sections: []documentSection{{
+				body:     outputs,
+				priority: 2,
+			}},
+			omitted: func(sections []documentSection) string {
+				return "the changes to outputs"
+			},
+		})
+	}
+	return doc
+}
+
+// documentDiffSection returns a collapsible section for the change to a single
+// resource, or false if the change has nothing to show.
+func documentDiffSection(renderer Renderer, diff diff, cause string) (documentSection, bool) {
+	rendered, ok := renderHumanDiff(renderer, diff, cause)
+	if !ok {
+		return documentSection{}, false
+	}
+
+	// The first line of the human-readable diff is a comment describing the
+	// change, which we use as the summary of the section.
+	comment, body, _ := strings.Cut(rendered, "\n")
+	action := jsonplan.UnmarshalActions(diff.change.Change.Actions)
+	section := documentSection{
+		summary: strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(comment), "#")),
+		body:    body,
+		action:  action,
+	}
+
+	// When the document is too long, we keep the changes that are most likely
+	// to need a reviewer's attention.
+	switch action {
+	case plans.Delete, plans.DeleteThenCreate, plans.CreateThenDelete, plans.Forget:
+		section.priority = 0
+	case plans.Update:
+		section.priority = 1
+	case plans.Create:
+		section.priority = 3
+	default:
+		section.priority = 4
+	}
+	return section, true
+}
+
+// render writes the document in the given markup language, omitting sections
+// as necessary to keep the result within maxLength bytes.
+func (doc planDocument) render(markup documentMarkup, maxLength int) string {
+	var header strings.Builder
+	header.WriteString(markup.heading(2, doc.title))
+	for _, paragraph := range doc.paragraphs {
+		header.WriteString(markup.paragraph(paragraph))
+	}
+	if len(doc.table) > 0 {
+		header.WriteString(markup.table(doc.table))
+	}
+
+	// A single section can't use more than a fraction of the document, so
+	// that one very large resource can't push out all of the others.
+	sectionLimit := maxLength / 8
+
+	type candidate struct {
+		group, index int
+		rendered     string
+		priority     int
+	}
+	var candidates []candidate
+	remaining := maxLength - header.Len()
+	for g, group := range doc.groups {
+		// We always reserve enough space for the heading and a note about any
+		// sections that are omitted.
+		remaining -= len(markup.heading(3, group.heading)) + len(markup.paragraph(documentOmittedNote(group.omitted(group.sections))))
+		for i, section := range group.sections {
+			section.body = truncateDocumentBody(section.body, sectionLimit)
+			candidates = append(candidates, candidate{
+				group:    g,
+				index:    i,
+				rendered: markup.section(section),
+				priority: section.priority,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].priority < candidates[j].priority
+	})
+	included := make(map[[2]int]string)
+	for _, c := range candidates {
+		if len(c.rendered) > remaining {
+			continue
+		}
+		remaining -= len(c.rendered)
+		included[[2]int{c.group, c.index}] = c.rendered
+	}
+
+	var buf strings.Builder
+	buf.WriteString(header.String())
+	for g, group := range doc.groups {
+		buf.WriteString(markup.heading(3, group.heading))
+		var omitted []documentSection
+		for i, section := range group.sections {
+			rendered, ok := included[[2]int{g, i}]
+			if !ok {
+				omitted = append(omitted, section)
+				continue
+			}
+			buf.WriteString(rendered)
+		}
+		if len(omitted) > 0 {
+			buf.WriteString(markup.paragraph(documentOmittedNote(group.omitted(omitted))))
+		}
+	}
+	return buf.String()
+}
+
+func documentOmittedNote(what string) string {
+	return fmt.Sprintf("This document was too long to show %s. Run \"tofu show\" on the plan file to see the whole plan.", what)
+}
+
+// truncateDocumentBody shortens body to at most limit bytes by removing whole
+// lines from the end, and adds a line saying how many were removed.
+func truncateDocumentBody(body string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	lines := strings.Split(body, "\n")
+	const note = "\n... (%d more lines omitted)"
+	length := 0
+	for i, line := range lines {
+		length += len(line) + 1
+		if length+len(note)+10 > limit {
+			return strings.Join(lines[:i], "\n") + fmt.Sprintf(note, len(lines)-i)
+		}
+	}
+	return body
+}
+
+// documentSummary counts the changes in a plan by action and module.
+type documentSummary struct {
+	counts    map[plans.Action]int
+	importing int
+
+	modules map[string]map[string]int
+}
+
+// documentColumns are the columns of the summary table, in order.
+var documentColumns = []string{"Import", "Add", "Change", "Replace", "Destroy", "Read", "Forget", "Move"}
+
+func newDocumentSummary() *documentSummary {
+	return &documentSummary{
+		counts:  make(map[plans.Action]int),
+		modules: make(map[string]map[string]int),
+	}
+}
+
+func (s *documentSummary) add(diff diff) {
+	action := jsonplan.UnmarshalActions(diff.change.Change.Actions)
+	if action != plans.NoOp {
+		s.counts[action]++
+	}
+	if diff.Importing() {
+		s.importing++
+	}
+
+	module := diff.change.ModuleAddress
+	if module == "" {
+		module = "(root module)"
+	}
+	counts, ok := s.modules[module]
+	if !ok {
+		counts = make(map[string]int)
+		s.modules[module] = counts
+	}
+	if diff.Importing() {
+		counts["Import"]++
+	}
+	switch action {
+	case plans.Create:
+		counts["Add"]++
+	case plans.Update:
+		counts["Change"]++
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		counts["Replace"]++
+	case plans.Delete:
+		counts["Destroy"]++
+	case plans.Read:
+		counts["Read"]++
+	case plans.Forget:
+		counts["Forget"]++
+	case plans.NoOp:
+		if diff.Moved() {
+			counts["Move"]++
+		}
+	}
+}
+
+// sentence returns the same summary of the plan as the human-readable output.
+func (s *documentSummary) sentence() string {
+	return fmt.Sprintf("Plan: %s.", s.actions())
+}
+
+func (s *documentSummary) actions() string {
+	var parts []string
+	if s.importing > 0 {
+		parts = append(parts, fmt.Sprintf("%d to import", s.importing))
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d to add", s.counts[plans.Create]+s.counts[plans.DeleteThenCreate]+s.counts[plans.CreateThenDelete]),
+		fmt.Sprintf("%d to change", s.counts[plans.Update]),
+		fmt.Sprintf("%d to destroy", s.counts[plans.Delete]+s.counts[plans.DeleteThenCreate]+s.counts[plans.CreateThenDelete]))
+	if s.counts[plans.Forget] > 0 {
+		parts = append(parts, fmt.Sprintf("%d to forget", s.counts[plans.Forget]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// table returns the rows of a table of the number of changes of each kind in
+// each module, including only the kinds of change that the plan has.
+func (s *documentSummary) table() [][]string {
+	totals := make(map[string]int)
+	for _, counts := range s.modules {
+		for column, count := range counts {
+			totals[column] += count
+		}
+	}
+	header := []string{"Module"}
+	for _, column := range documentColumns {
+		if totals[column] > 0 {
+			header = append(header, column)
+		}
+	}
+	if len(header) == 1 {
+		return nil
+	}
+
+	modules := make([]string, 0, len(s.modules))
+	for module := range s.modules {
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		// The root module always comes first.
+		if (modules[i] == "(root module)") != (modules[j] == "(root module)") {
+			return modules[i] == "(root module)"
+		}
+		return modules[i] < modules[j]
+	})
+
+	rows := [][]string{header}
+	row := func(name string, counts map[string]int) []string {
+		ret := []string{name}
+		for _, column := range header[1:] {
+			ret = append(ret, fmt.Sprint(counts[column]))
+		}
+		return ret
+	}
+	for _, module := range modules {
+		rows = append(rows, row(module, s.modules[module]))
+	}
+	if len(modules) > 1 {
+		rows = append(rows, row("Total", totals))
+	}
+	return rows
+}
+
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// documentMarkup writes the parts of a plan document in a markup language. All
+// of the text given to it is plain text, which it escapes as necessary.
+type documentMarkup interface {
+	heading(level int, text string) string
+	paragraph(text string) string
+	table(rows [][]string) string
+	section(section documentSection) string
+}
+
+type markdownMarkup struct{}
+
+// markdownSpecialChars matches the characters that could be interpreted as
+// markdown syntax within text.
+var markdownSpecialChars = regexp.MustCompile("[\\\\`*_\\[\\]<>|#]")
+
+func (markdownMarkup) escape(text string) string {
+	return markdownSpecialChars.ReplaceAllString(text, `\$0`)
+}
+
+func (m markdownMarkup) heading(level int, text string) string {
+	return fmt.Sprintf("%s %s\n\n", strings.Repeat("#", level), m.escape(text))
+}
+
+func (m markdownMarkup) paragraph(text string) string {
+	return m.escape(text) + "\n\n"
+}
+
+func (m markdownMarkup) table(rows [][]string) string {
+	var buf strings.Builder
+	for i, row := range rows {
+		buf.WriteString("|")
+		for _, cell := range row {
+			fmt.Fprintf(&buf, " %s |", m.escape(cell))
+		}
+		buf.WriteString("\n")
+		if i == 0 {
+			buf.WriteString("|")
+			for j := range row {
+				if j == 0 {
+					buf.WriteString(" --- |")
+				} else {
+					buf.WriteString(" ---: |")
+				}
+			}
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// markdownDiffSymbol matches the change symbol at the start of a line of the
+// human-readable diff.
+var markdownDiffSymbol = regexp.MustCompile(`(?m)^( *)([-+~]|-/\+|\+/-|<=)( )`)
+
+func (markdownMarkup) section(section documentSection) string {
+	// We move the change symbols to the start of each line, so that the
+	// "diff" syntax highlighting of markdown renderers colors the lines.
+	body := markdownDiffSymbol.ReplaceAllString(section.body, "$2$1$3")
+
+	// The code block fence must be longer than any run of backticks in the
+	// body.
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	block := fmt.Sprintf("%sdiff\n%s\n%s\n", fence, body, fence)
+
+	if section.summary == "" {
+		return block + "\n"
+	}
+	return fmt.Sprintf("<details><summary>%s</summary>\n\n%s\n</details>\n\n", html.EscapeString(section.summary), block)
+}
+
+type htmlMarkup struct{}
+
+func (htmlMarkup) heading(level int, text string) string {
+	return fmt.Sprintf("<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+}
+
+func (htmlMarkup) paragraph(text string) string {
+	return fmt.Sprintf("<p>%s</p>\n", html.EscapeString(text))
+}
+
+func (htmlMarkup) table(rows [][]string) string {
+	var buf strings.Builder
+	buf.WriteString("<table>\n")
+	for i, row := range rows {
+		buf.WriteString("<tr>")
+		for _, cell := range row {
+			tag := "td"
+			if i == 0 {
+				tag = "th"
+			}
+			fmt.Fprintf(&buf, "<%s>%s</%s>", tag, html.EscapeString(cell), tag)
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</table>\n")
+	return buf.String()
+}
+
+func (htmlMarkup) section(section documentSection) string {
+	block := fmt.Sprintf("<pre>%s</pre>\n", html.EscapeString(section.body))
+	if section.summary == "" {
+		return block
+	}
+	return fmt.Sprintf("<details><summary>%s</summary>\n%s</details>\n", html.EscapeString(section.summary), block)
+}
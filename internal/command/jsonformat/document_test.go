@@ -0,0 +1,226 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+func TestRenderDocumentPlan(t *testing.T) {
+	plan := Plan{
+		ResourceChanges: []jsonplan.ResourceChange{
+			testDocumentResourceChange(t, "", "a", "create", nil, map[string]interface{}{"value": "<new>"}),
+			testDocumentResourceChange(t, "module.child", "b", "delete", map[string]interface{}{"value": "old"}, nil),
+		},
+		OutputChanges: map[string]jsonplan.Change{
+			"greeting": {
+				Actions: []string{"create"},
+				After:   marshalJson(t, "hello"),
+			},
+		},
+		ProviderSchemas: testDocumentSchemas(t),
+	}
+
+	tcs := map[string]struct {
+		format DocumentFormat
+		want   string
+	}{
+		"markdown": {
+			DocumentMarkdown,
+			`## OpenTofu plan
+
+Plan: 1 to add, 0 to change, 1 to destroy.
+
+| Module | Add | Destroy |
+| --- | ---: | ---: |
+| (root module) | 1 | 0 |
+| module.child | 0 | 1 |
+| Total | 1 | 1 |
+
+### Resource changes
+
+<details><summary>test_resource.a will be created</summary>
+
+` + "```" + `diff
++   resource "test_resource" "a" {
++       value = "<new>"
+    }
+` + "```" + `
+
+</details>
+
+<details><summary>module.child.test_resource.b will be destroyed</summary>
+
+` + "```" + `diff
+-   resource "test_resource" "b" {
+-       value = "old" -> null
+    }
+` + "```" + `
+
+</details>
+
+### Changes to Outputs
+
+` + "```" + `diff
++   greeting = "hello"
+` + "```" + `
+
+`,
+		},
+		"html": {
+			DocumentHTML,
+			`<h2>OpenTofu plan</h2>
+<p>Plan: 1 to add, 0 to change, 1 to destroy.</p>
+<table>
+<tr><th>Module</th><th>Add</th><th>Destroy</th></tr>
+<tr><td>(root module)</td><td>1</td><td>0</td></tr>
+<tr><td>module.child</td><td>0</td><td>1</td></tr>
+<tr><td>Total</td><td>1</td><td>1</td></tr>
+</table>
+<h3>Resource changes</h3>
+<details><summary>test_resource.a will be created</summary>
+<pre>  + resource &#34;test_resource&#34; &#34;a&#34; {
+      + value = &#34;&lt;new&gt;&#34;
+    }</pre>
+</details>
+<details><summary>module.child.test_resource.b will be destroyed</summary>
+<pre>  - resource &#34;test_resource&#34; &#34;b&#34; {
+      - value = &#34;old&#34; -&gt; null
+    }</pre>
+</details>
+<h3>Changes to Outputs</h3>
+<pre>  + greeting = &#34;hello&#34;</pre>
+`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			renderer := Renderer{Streams: streams}
+			renderer.RenderDocumentPlan(plan, plans.NormalMode, tc.format, DefaultDocumentMaxLength)
+
+			got := done(t).Stdout()
+			if diff := cmp.Diff(tc.want, got); len(diff) > 0 {
+				t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestRenderDocumentPlan_noChanges(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	renderer := Renderer{Streams: streams}
+	renderer.RenderDocumentPlan(Plan{}, plans.NormalMode, DocumentMarkdown, DefaultDocumentMaxLength)
+
+	want := `## OpenTofu plan
+
+No changes. Your infrastructure matches the configuration.
+
+`
+	got := done(t).Stdout()
+	if diff := cmp.Diff(want, got); len(diff) > 0 {
+		t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+	}
+}
+
+func TestRenderDocumentPlan_truncated(t *testing.T) {
+	plan := Plan{
+		ProviderSchemas: testDocumentSchemas(t),
+	}
+	for i := 0; i < 50; i++ {
+		plan.ResourceChanges = append(plan.ResourceChanges,
+			testDocumentResourceChange(t, "", fmt.Sprintf("new%d", i), "create", nil, map[string]interface{}{"value": strings.Repeat("x", 100)}))
+	}
+	plan.ResourceChanges = append(plan.ResourceChanges,
+		testDocumentResourceChange(t, "", "old", "delete", map[string]interface{}{"value": "old"}, nil))
+
+	streams, done := terminal.StreamsForTesting(t)
+	renderer := Renderer{Streams: streams}
+	renderer.RenderDocumentPlan(plan, plans.NormalMode, DocumentMarkdown, 4000)
+	got := done(t).Stdout()
+
+	if len(got) > 4000 {
+		t.Errorf("document is %d bytes, but the limit is 4000", len(got))
+	}
+	// Destroying is more important than creating, so it must be kept even
+	// though it comes last.
+	if !strings.Contains(got, "test_resource.old will be destroyed") {
+		t.Errorf("document does not include the resource to destroy:\n%s", got)
+	}
+	// The summary always counts everything.
+	if !strings.Contains(got, "Plan: 50 to add, 0 to change, 1 to destroy.") {
+		t.Errorf("document does not include the plan summary:\n%s", got)
+	}
+	if !strings.Contains(got, "resource changes (") || !strings.Contains(got, "to add, 0 to change, 0 to destroy).") {
+		t.Errorf("document does not describe the omitted changes:\n%s", got)
+	}
+}
+
+func TestTruncateDocumentBody(t *testing.T) {
+	body := strings.Repeat("0123456789\n", 100)
+	got := truncateDocumentBody(body, 200)
+	if len(got) > 200 {
+		t.Errorf("truncated body is %d bytes, but the limit is 200", len(got))
+	}
+	if !strings.HasSuffix(got, "more lines omitted)") {
+		t.Errorf("truncated body does not say how many lines were omitted:\n%s", got)
+	}
+
+	if got := truncateDocumentBody("short", 200); got != "short" {
+		t.Errorf("short body was changed to %q", got)
+	}
+}
+
+func testDocumentSchemas(t *testing.T) map[string]*jsonprovider.Provider {
+	return map[string]*jsonprovider.Provider{
+		"test": {
+			ResourceSchemas: map[string]*jsonprovider.Schema{
+				"test_resource": {
+					Block: &jsonprovider.Block{
+						Attributes: map[string]*jsonprovider.Attribute{
+							"value": {
+								AttributeType: marshalJson(t, "string"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testDocumentResourceChange(t *testing.T, module, name, action string, before, after map[string]interface{}) jsonplan.ResourceChange {
+	address := "test_resource." + name
+	if module != "" {
+		address = module + "." + address
+	}
+	change := jsonplan.ResourceChange{
+		Address:       address,
+		ModuleAddress: module,
+		Mode:          "managed",
+		Type:          "test_resource",
+		Name:          name,
+		ProviderName:  "test",
+		Change: jsonplan.Change{
+			Actions: []string{action},
+		},
+	}
+	if before != nil {
+		change.Change.Before = marshalJson(t, before)
+	}
+	if after != nil {
+		change.Change.After = marshalJson(t, after)
+	}
+	return change
+}
@@ -0,0 +1,7 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsongraph implements the machine-readable representation of the
+// dependency graph produced by "tofu graph", along with the filters and the
+// DOT and Mermaid renderings that are built from the same representation.
+package jsongraph
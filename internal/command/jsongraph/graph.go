@@ -0,0 +1,344 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsongraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// FormatVersion represents the version of the json format and will be
+// incremented for any change to this format that requires changes to a
+// consuming parser.
+const FormatVersion = "1.0"
+
+// The kinds of nodes in a graph, and of the dependents of an impact.
+const (
+	KindResource = "resource"
+	KindData     = "data"
+	KindProvider = "provider"
+	KindVariable = "variable"
+	KindLocal    = "local"
+	KindOutput   = "output"
+	KindModule   = "module"
+	KindOther    = "other"
+)
+
+// Graph is the top-level representation of a dependency graph.
+type Graph struct {
+	FormatVersion string `json:"format_version"`
+
+	// Type is the type of the graph, such as "plan" or "apply".
+	Type string `json:"type"`
+
+	Nodes []*Node `json:"nodes"`
+
+	// Edges point from a node to the nodes it depends on.
+	Edges []Edge `json:"edges"`
+}
+
+// Node is a single object of the configuration in the graph.
+type Node struct {
+	// ID uniquely identifies the node within the graph. It is the same
+	// name used for the node in the DOT output.
+	ID string `json:"id"`
+
+	// Address is the address of the object represented by the node, such
+	// as "module.network.aws_vpc.main".
+	Address string `json:"address"`
+
+	// Kind is one of the Kind constants.
+	Kind string `json:"kind"`
+
+	// Module is the address of the module containing the object, or an
+	// empty string for the root module.
+	Module string `json:"module,omitempty"`
+
+	// PlannedActions are the distinct actions planned for the instances of
+	// a resource, such as "create" or "replace". It is only set when the
+	// graph is built from a plan.
+	PlannedActions []string `json:"planned_actions,omitempty"`
+
+	module   addrs.Module
+	dotAttrs map[string]string
+}
+
+// Edge is a dependency between two nodes, identified by their IDs.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Opts are the options for building a Graph.
+type Opts struct {
+	// Verbose includes the nodes that are only shown in the verbose DOT
+	// output, such as local values and outputs.
+	Verbose bool
+
+	// Changes, when set, are used to annotate the resource nodes with their
+	// planned actions.
+	Changes *plans.Changes
+}
+
+// New builds the representation of the given graph.
+//
+// Only the nodes that appear in the DOT output are included. Dependencies
+// that go through other nodes are turned into direct edges between the
+// included nodes, so that filtering the graph keeps the relationships
+// between the remaining nodes intact.
+func New(g *tofu.Graph, graphType string, opts Opts) *Graph {
+	dotOpts := &dag.DotOpts{Verbose: opts.Verbose, MaxDepth: -1}
+	actions := plannedActions(opts.Changes)
+
+	result := &Graph{
+		FormatVersion: FormatVersion,
+		Type:          graphType,
+		Nodes:         []*Node{},
+		Edges:         []Edge{},
+	}
+
+	nodes := make(map[dag.Vertex]*Node)
+	for _, v := range g.Vertices() {
+		dotter, ok := v.(dag.GraphNodeDotter)
+		if !ok {
+			continue
+		}
+		name := dag.VertexName(v)
+		dotNode := dotter.DotNode(name, dotOpts)
+		if dotNode == nil {
+			continue
+		}
+
+		node := &Node{
+			ID:       name,
+			Address:  name,
+			Kind:     nodeKind(v),
+			dotAttrs: dotNode.Attrs,
+		}
+		if label, ok := dotNode.Attrs["label"]; ok {
+			node.Address = label
+		}
+		if mp, ok := v.(tofu.GraphNodeModulePath); ok {
+			node.module = mp.ModulePath()
+			node.Module = node.module.String()
+		}
+		switch v := v.(type) {
+		case tofu.GraphNodeResourceInstance:
+			node.PlannedActions = actions[v.ResourceInstanceAddr().String()]
+		case tofu.GraphNodeConfigResource:
+			node.PlannedActions = actions[v.ResourceAddr().String()]
+		}
+
+		nodes[v] = node
+		result.Nodes = append(result.Nodes, node)
+	}
+
+	// reachable memoizes the included nodes reachable from a vertex through
+	// vertices that are not included.
+	reachable := make(map[dag.Vertex][]*Node)
+	var visit func(v dag.Vertex) []*Node
+	visit = func(v dag.Vertex) []*Node {
+		if found, ok := reachable[v]; ok {
+			return found
+		}
+		// Guard against cycles, which only exist in invalid graphs.
+		reachable[v] = nil
+
+		var found []*Node
+		for _, target := range g.DownEdges(v) {
+			if node, ok := nodes[target]; ok {
+				found = append(found, node)
+				continue
+			}
+			found = append(found, visit(target)...)
+		}
+		reachable[v] = found
+		return found
+	}
+
+	seen := make(map[Edge]bool)
+	for v, from := range nodes {
+		for _, to := range visit(v) {
+			e := Edge{From: from.ID, To: to.ID}
+			if from == to || seen[e] {
+				continue
+			}
+			seen[e] = true
+			result.Edges = append(result.Edges, e)
+		}
+	}
+
+	result.sort()
+	return result
+}
+
+// Marshal returns the JSON representation of the graph.
+func Marshal(g *Graph) ([]byte, error) {
+	return json.Marshal(g)
+}
+
+// FilterModule returns the part of the graph that belongs to the given
+// module or to any of its descendants.
+func (g *Graph) FilterModule(module addrs.Module) *Graph {
+	return g.subgraph(func(n *Node) bool {
+		return len(n.module) >= len(module) && n.module[:len(module)].Equal(module)
+	})
+}
+
+// Focus returns the part of the graph made of the nodes with the given
+// address or ID, and of the nodes they depend on and that depend on them,
+// up to the given number of edges away. A negative depth has no limit.
+func (g *Graph) Focus(addr string, depth int) (*Graph, error) {
+	down := make(map[string][]string)
+	up := make(map[string][]string)
+	for _, e := range g.Edges {
+		down[e.From] = append(down[e.From], e.To)
+		up[e.To] = append(up[e.To], e.From)
+	}
+
+	var start []string
+	for _, n := range g.Nodes {
+		if n.Address == addr || n.ID == addr {
+			start = append(start, n.ID)
+		}
+	}
+	if len(start) == 0 {
+		return nil, fmt.Errorf("the graph has no node with the address %q", addr)
+	}
+
+	keep := make(map[string]bool)
+	for _, adjacent := range []map[string][]string{down, up} {
+		current := start
+		for _, id := range start {
+			keep[id] = true
+		}
+		visited := make(map[string]bool)
+		for i := 0; len(current) > 0 && (depth < 0 || i < depth); i++ {
+			var next []string
+			for _, id := range current {
+				for _, other := range adjacent[id] {
+					if !visited[other] {
+						visited[other] = true
+						keep[other] = true
+						next = append(next, other)
+					}
+				}
+			}
+			current = next
+		}
+	}
+
+	return g.subgraph(func(n *Node) bool { return keep[n.ID] }), nil
+}
+
+func (g *Graph) subgraph(include func(*Node) bool) *Graph {
+	result := &Graph{
+		FormatVersion: g.FormatVersion,
+		Type:          g.Type,
+		Nodes:         []*Node{},
+		Edges:         []Edge{},
+	}
+
+	kept := make(map[string]bool)
+	for _, n := range g.Nodes {
+		if include(n) {
+			kept[n.ID] = true
+			result.Nodes = append(result.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if kept[e.From] && kept[e.To] {
+			result.Edges = append(result.Edges, e)
+		}
+	}
+	return result
+}
+
+func (g *Graph) sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+}
+
+func nodeKind(v dag.Vertex) string {
+	switch v := v.(type) {
+	case tofu.GraphNodeProvider:
+		return KindProvider
+	case tofu.GraphNodeConfigResource:
+		if v.ResourceAddr().Resource.Mode == addrs.DataResourceMode {
+			return KindData
+		}
+		return KindResource
+	case tofu.GraphNodeReferenceable:
+		for _, addr := range v.ReferenceableAddrs() {
+			switch addr.(type) {
+			case addrs.InputVariable:
+				return KindVariable
+			case addrs.LocalValue:
+				return KindLocal
+			case addrs.OutputValue, addrs.ModuleCallInstanceOutput:
+				return KindOutput
+			}
+		}
+	}
+	return KindOther
+}
+
+// plannedActions returns the distinct actions planned for each resource,
+// keyed by both the resource instance and the resource addresses.
+func plannedActions(changes *plans.Changes) map[string][]string {
+	result := make(map[string][]string)
+	if changes == nil {
+		return result
+	}
+
+	add := func(key, action string) {
+		for _, existing := range result[key] {
+			if existing == action {
+				return
+			}
+		}
+		result[key] = append(result[key], action)
+		sort.Strings(result[key])
+	}
+	for _, rc := range changes.Resources {
+		action := actionName(rc.Action)
+		add(rc.Addr.String(), action)
+		add(rc.Addr.ConfigResource().String(), action)
+	}
+	return result
+}
+
+func actionName(action plans.Action) string {
+	switch action {
+	case plans.NoOp:
+		return "no-op"
+	case plans.Create:
+		return "create"
+	case plans.Read:
+		return "read"
+	case plans.Update:
+		return "update"
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		return "replace"
+	case plans.Delete:
+		return "delete"
+	case plans.Forget:
+		return "forget"
+	default:
+		return action.String()
+	}
+}
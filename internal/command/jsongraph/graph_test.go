@@ -0,0 +1,185 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsongraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func testGraph() *Graph {
+	node := func(id, kind string, module addrs.Module) *Node {
+		return &Node{ID: id, Address: id, Kind: kind, Module: module.String(), module: module}
+	}
+	child := addrs.RootModule.Child("child")
+
+	return &Graph{
+		FormatVersion: FormatVersion,
+		Type:          "plan",
+		Nodes: []*Node{
+			node("module.child.test_instance.c", KindResource, child),
+			node(`provider["registry.opentofu.org/hashicorp/test"]`, KindProvider, addrs.RootModule),
+			node("test_instance.a", KindResource, addrs.RootModule),
+			node("test_instance.b", KindResource, addrs.RootModule),
+			node("var.name", KindVariable, addrs.RootModule),
+		},
+		Edges: []Edge{
+			{From: "module.child.test_instance.c", To: "test_instance.b"},
+			{From: "test_instance.a", To: `provider["registry.opentofu.org/hashicorp/test"]`},
+			{From: "test_instance.a", To: "var.name"},
+			{From: "test_instance.b", To: "test_instance.a"},
+		},
+	}
+}
+
+func nodeIDs(g *Graph) []string {
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestGraphFocus(t *testing.T) {
+	tests := map[string]struct {
+		addr  string
+		depth int
+		want  []string
+	}{
+		"unlimited": {
+			"test_instance.b",
+			-1,
+			[]string{
+				"module.child.test_instance.c",
+				`provider["registry.opentofu.org/hashicorp/test"]`,
+				"test_instance.a",
+				"test_instance.b",
+				"var.name",
+			},
+		},
+		"depth 1": {
+			"test_instance.b",
+			1,
+			[]string{"module.child.test_instance.c", "test_instance.a", "test_instance.b"},
+		},
+		"depth 0": {
+			"test_instance.b",
+			0,
+			[]string{"test_instance.b"},
+		},
+		"leaf": {
+			"var.name",
+			-1,
+			[]string{"module.child.test_instance.c", "test_instance.a", "test_instance.b", "var.name"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := testGraph().Focus(test.addr, test.depth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, nodeIDs(got)); diff != "" {
+				t.Fatalf("wrong nodes\n%s", diff)
+			}
+			for _, e := range got.Edges {
+				if !contains(test.want, e.From) || !contains(test.want, e.To) {
+					t.Fatalf("edge %s -> %s refers to a removed node", e.From, e.To)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphFocus_notFound(t *testing.T) {
+	_, err := testGraph().Focus("test_instance.missing", -1)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestGraphFilterModule(t *testing.T) {
+	got := testGraph().FilterModule(addrs.RootModule.Child("child"))
+	if diff := cmp.Diff([]string{"module.child.test_instance.c"}, nodeIDs(got)); diff != "" {
+		t.Fatalf("wrong nodes\n%s", diff)
+	}
+	if len(got.Edges) != 0 {
+		t.Fatalf("unexpected edges: %#v", got.Edges)
+	}
+
+	got = testGraph().FilterModule(addrs.RootModule)
+	if len(got.Nodes) != 5 || len(got.Edges) != 4 {
+		t.Fatalf("filtering on the root module should keep the whole graph, got %d nodes and %d edges", len(got.Nodes), len(got.Edges))
+	}
+}
+
+func TestGraphMarshal(t *testing.T) {
+	g := testGraph()
+	g.Nodes[2].PlannedActions = []string{"create"}
+
+	got, err := Marshal(g.FilterModule(addrs.RootModule.Child("child")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"format_version":"1.0","type":"plan","nodes":[{"id":"module.child.test_instance.c","address":"module.child.test_instance.c","kind":"resource","module":"module.child"}],"edges":[]}`
+	if string(got) != want {
+		t.Fatalf("wrong JSON\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	g := testGraph()
+	g.Nodes[2].PlannedActions = []string{"create"}
+	g.Nodes[3].PlannedActions = []string{"create", "delete"}
+
+	got := g.Mermaid()
+	want := `flowchart LR
+    n0["module.child.test_instance.c"]
+    n1{{"provider[#quot;registry.opentofu.org/hashicorp/test#quot;]"}}
+    n2["test_instance.a (create)"]
+    n3["test_instance.b (create, delete)"]
+    n4>"var.name"]
+    n0 --> n3
+    n2 --> n1
+    n2 --> n4
+    n3 --> n2
+    classDef delete fill:#f8d7da
+    class n3 delete
+    classDef create fill:#d4edda
+    class n2 create
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong output\n%s", diff)
+	}
+}
+
+func TestGraphDot(t *testing.T) {
+	g := testGraph()
+	g.Nodes[2].PlannedActions = []string{"update"}
+
+	got := g.Dot()
+	for _, want := range []string{
+		`"[root] test_instance.a" [fillcolor = "#fff3cd", label = "test_instance.a\n(update)", style = "filled"]`,
+		`"[root] test_instance.b" [label = "test_instance.b"]`,
+		`"[root] test_instance.b" -> "[root] test_instance.a"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %s\n%s", want, got)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
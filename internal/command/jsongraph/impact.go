@@ -0,0 +1,141 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsongraph
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/lang/globalref"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// Impact is the representation of the objects that depend on a resource.
+type Impact struct {
+	FormatVersion string `json:"format_version"`
+
+	// Address is the address of the resource whose dependents are listed.
+	Address string `json:"address"`
+
+	Dependents []Dependent `json:"dependents"`
+
+	// Modules are the modules that contain at least one of the dependents,
+	// or whose module call depends on the resource.
+	Modules []string `json:"modules"`
+}
+
+// Dependent is an object that depends, directly or transitively, on the
+// resource of an Impact.
+type Dependent struct {
+	Address string `json:"address"`
+
+	// Kind is one of KindResource, KindData, KindOutput or KindModule.
+	Kind string `json:"kind"`
+
+	// Module is the address of the module containing the object, or an
+	// empty string for the root module.
+	Module string `json:"module,omitempty"`
+
+	// Distance is 1 for the objects that refer to the resource directly,
+	// and increases by one for each object on the path to the resource.
+	Distance int `json:"distance"`
+
+	// Attributes are the attributes of the resource that the object refers
+	// to, directly or through other objects, as far as they can be
+	// determined statically from the configuration.
+	Attributes []string `json:"attributes,omitempty"`
+}
+
+// NewImpact builds the representation of the given dependents of a resource.
+// If an analyzer is given, it is used to find which attributes of the
+// resource each dependent refers to, across module boundaries.
+func NewImpact(target addrs.ConfigResource, deps []tofu.GraphDependent, analyzer *globalref.Analyzer) *Impact {
+	result := &Impact{
+		FormatVersion: FormatVersion,
+		Address:       target.String(),
+		Dependents:    []Dependent{},
+		Modules:       []string{},
+	}
+
+	modules := make(map[string]bool)
+	addModule := func(module addrs.Module) {
+		for ; !module.IsRoot(); module = module.Parent() {
+			modules[module.String()] = true
+		}
+	}
+
+	for _, dep := range deps {
+		d := Dependent{
+			Address:  dep.Addr.String(),
+			Distance: dep.Distance,
+		}
+
+		var refs []globalref.Reference
+		switch addr := dep.Addr.(type) {
+		case addrs.ConfigResource:
+			d.Kind = KindResource
+			if addr.Resource.Mode == addrs.DataResourceMode {
+				d.Kind = KindData
+			}
+			d.Module = addr.Module.String()
+			addModule(addr.Module)
+			if analyzer != nil {
+				abs := addr.Absolute(addr.Module.UnkeyedInstanceShim())
+				refs = append(refs, analyzer.ReferencesFromResourceInstance(abs.Instance(addrs.NoKey))...)
+				refs = append(refs, analyzer.ReferencesFromResourceRepetition(abs)...)
+			}
+		case addrs.ConfigOutputValue:
+			d.Kind = KindOutput
+			d.Module = addr.Module.String()
+			addModule(addr.Module)
+			if analyzer != nil {
+				refs = analyzer.ReferencesFromOutputValue(addr.OutputValue.Absolute(addr.Module.UnkeyedInstanceShim()))
+			}
+		case addrs.Module:
+			d.Kind = KindModule
+			d.Module = addr.Parent().String()
+			addModule(addr)
+		}
+
+		if len(refs) > 0 {
+			d.Attributes = contributingAttributes(analyzer, target, refs)
+		}
+		result.Dependents = append(result.Dependents, d)
+	}
+
+	for module := range modules {
+		result.Modules = append(result.Modules, module)
+	}
+	sort.Strings(result.Modules)
+
+	return result
+}
+
+// MarshalImpact returns the JSON representation of the impact.
+func MarshalImpact(i *Impact) ([]byte, error) {
+	return json.Marshal(i)
+}
+
+// contributingAttributes returns the attributes of the target resource that
+// contribute to the given references, directly or indirectly.
+func contributingAttributes(analyzer *globalref.Analyzer, target addrs.ConfigResource, refs []globalref.Reference) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, ref := range analyzer.ContributingResourceReferences(refs...) {
+		attr, ok := ref.ResourceAttr()
+		if !ok || len(attr.Attr) == 0 || !attr.Resource.ConfigResource().Equal(target) {
+			continue
+		}
+		name := strings.TrimPrefix(tfdiags.FormatCtyPath(attr.Attr), ".")
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,47 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsongraph
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestNewImpact(t *testing.T) {
+	resource := func(module addrs.Module, mode addrs.ResourceMode, name string) addrs.ConfigResource {
+		return addrs.ConfigResource{
+			Module:   module,
+			Resource: addrs.Resource{Mode: mode, Type: "test_instance", Name: name},
+		}
+	}
+	network := addrs.RootModule.Child("network")
+	subnets := network.Child("subnets")
+
+	deps := []tofu.GraphDependent{
+		{Addr: resource(addrs.RootModule, addrs.ManagedResourceMode, "b"), Distance: 1},
+		{Addr: resource(subnets, addrs.DataResourceMode, "c"), Distance: 2},
+		{Addr: addrs.RootModule.Child("app"), Distance: 2},
+		{Addr: addrs.ConfigOutputValue{Module: subnets, OutputValue: addrs.OutputValue{Name: "id"}}, Distance: 3},
+	}
+
+	got := NewImpact(resource(addrs.RootModule, addrs.ManagedResourceMode, "a"), deps, nil)
+	want := &Impact{
+		FormatVersion: FormatVersion,
+		Address:       "test_instance.a",
+		Dependents: []Dependent{
+			{Address: "test_instance.b", Kind: KindResource, Distance: 1},
+			{Address: "module.network.module.subnets.data.test_instance.c", Kind: KindData, Module: "module.network.module.subnets", Distance: 2},
+			{Address: "module.app", Kind: KindModule, Distance: 2},
+			{Address: "module.network.module.subnets.output.id", Kind: KindOutput, Module: "module.network.module.subnets", Distance: 3},
+		},
+		Modules: []string{"module.app", "module.network", "module.network.module.subnets"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong impact\n%s", diff)
+	}
+}
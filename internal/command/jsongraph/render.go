@@ -0,0 +1,140 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsongraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// actionColors are the fill colors of the nodes with planned actions, in
+// order of precedence when a node has several planned actions.
+var actionColors = []struct {
+	action string
+	color  string
+}{
+	{"delete", "#f8d7da"},
+	{"replace", "#ffe5b4"},
+	{"update", "#fff3cd"},
+	{"create", "#d4edda"},
+	{"read", "#d1ecf1"},
+	{"forget", "#e2e3e5"},
+}
+
+// Dot returns the graph in the DOT language, using the same node names and
+// attributes as the unfiltered "tofu graph" output.
+func (g *Graph) Dot() string {
+	var buf strings.Builder
+	buf.WriteString("digraph {\n")
+	buf.WriteString("\tcompound = \"true\"\n")
+	buf.WriteString("\tnewrank = \"true\"\n")
+	buf.WriteString("\tsubgraph \"root\" {\n")
+
+	for _, n := range g.Nodes {
+		attrs := make(map[string]string, len(n.dotAttrs)+3)
+		for k, v := range n.dotAttrs {
+			attrs[k] = v
+		}
+		if _, ok := attrs["label"]; !ok {
+			attrs["label"] = n.Address
+		}
+		if len(n.PlannedActions) > 0 {
+			attrs["label"] = fmt.Sprintf("%s\n(%s)", attrs["label"], strings.Join(n.PlannedActions, ", "))
+			if color := nodeColor(n); color != "" {
+				attrs["style"] = "filled"
+				attrs["fillcolor"] = color
+			}
+		}
+
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s = %q", k, attrs[k]))
+		}
+		fmt.Fprintf(&buf, "\t\t%q [%s]\n", "[root] "+n.ID, strings.Join(parts, ", "))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "\t\t%q -> %q\n", "[root] "+e.From, "[root] "+e.To)
+	}
+
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	var buf strings.Builder
+	buf.WriteString("flowchart LR\n")
+
+	// Mermaid identifiers can't contain most of the characters used in
+	// addresses, so the nodes are numbered in order instead.
+	ids := make(map[string]string, len(g.Nodes))
+	classes := make(map[string][]string)
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+
+		label := n.Address
+		if len(n.PlannedActions) > 0 {
+			label = fmt.Sprintf("%s (%s)", label, strings.Join(n.PlannedActions, ", "))
+		}
+		label = strings.ReplaceAll(label, `"`, "#quot;")
+
+		switch n.Kind {
+		case KindProvider:
+			fmt.Fprintf(&buf, "    %s{{\"%s\"}}\n", id, label)
+		case KindData:
+			fmt.Fprintf(&buf, "    %s[(\"%s\")]\n", id, label)
+		case KindVariable, KindLocal, KindOutput:
+			fmt.Fprintf(&buf, "    %s>\"%s\"]\n", id, label)
+		default:
+			fmt.Fprintf(&buf, "    %s[\"%s\"]\n", id, label)
+		}
+
+		if action := nodeAction(n); action != "" {
+			classes[action] = append(classes[action], id)
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "    %s --> %s\n", ids[e.From], ids[e.To])
+	}
+
+	for _, ac := range actionColors {
+		if members, ok := classes[ac.action]; ok {
+			fmt.Fprintf(&buf, "    classDef %s fill:%s\n", ac.action, ac.color)
+			fmt.Fprintf(&buf, "    class %s %s\n", strings.Join(members, ","), ac.action)
+		}
+	}
+
+	return buf.String()
+}
+
+// nodeAction returns the planned action of the node that is highlighted in
+// the renderings, or an empty string if it has none.
+func nodeAction(n *Node) string {
+	for _, ac := range actionColors {
+		for _, action := range n.PlannedActions {
+			if action == ac.action {
+				return ac.action
+			}
+		}
+	}
+	return ""
+}
+
+func nodeColor(n *Node) string {
+	action := nodeAction(n)
+	for _, ac := range actionColors {
+		if ac.action == action {
+			return ac.color
+		}
+	}
+	return ""
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	legacy "github.com/opentofu/opentofu/internal/legacy/tofu"
+	"github.com/opentofu/opentofu/internal/ociregistry"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/states"
@@ -129,6 +130,13 @@ type Meta struct {
 	// provider version can be obtained.
 	ProviderSource getproviders.Source
 
+	// OCIRepositoryStore returns a client for an OCI repository, for
+	// installing modules that have "oci:" source addresses and for
+	// publishing providers with "tofu providers mirror", which
+	// authenticates using the OCI registry credentials from the CLI
+	// configuration.
+	OCIRepositoryStore ociregistry.RepositoryStoreFunc
+
 	// BrowserLauncher is used by commands that need to open a URL in a
 	// web browser.
 	BrowserLauncher webbrowser.Launcher
@@ -25,6 +25,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/initwd"
+	"github.com/opentofu/opentofu/internal/policy"
 	"github.com/opentofu/opentofu/internal/registry"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -266,6 +267,29 @@ func (m *Meta) loadHCLFile(filename string) (hcl.Body, tfdiags.Diagnostics) {
 	return body, diags
 }
 
+// loadPolicies loads the local policies in the given files and directories,
+// returning a nil set if no paths are given.
+func (m *Meta) loadPolicies(paths []string) (*policy.Set, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(paths) == 0 {
+		return nil, diags
+	}
+
+	loader, err := m.initConfigLoader()
+	if err != nil {
+		diags = diags.Append(err)
+		return nil, diags
+	}
+
+	normalized := make([]string, len(paths))
+	for i, path := range paths {
+		normalized[i] = m.normalizePath(path)
+	}
+	set, hclDiags := policy.LoadPaths(loader.Parser(), normalized)
+	diags = diags.Append(hclDiags)
+	return set, diags
+}
+
 // installModules reads a root module from the given directory and attempts
 // recursively to install all of its descendent modules.
 //
@@ -273,7 +297,12 @@ func (m *Meta) loadHCLFile(filename string) (hcl.Body, tfdiags.Diagnostics) {
 // can then be relayed to the end-user. The uiModuleInstallHooks type in
 // this package has a reasonable implementation for displaying notifications
 // via a provided cli.Ui.
-func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upgrade, installErrsOnly bool, hooks initwd.ModuleInstallHooks) (abort bool, diags tfdiags.Diagnostics) {
+//
+// The packages selected for remote module sources whose address can refer
+// to different contents over time, such as "oci:" sources that refer to a
+// tag, are recorded in the dependency lock file, unless readonlyLocks is set,
+// in which case any change to those selections is an error.
+func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upgrade, installErrsOnly, readonlyLocks bool, hooks initwd.ModuleInstallHooks) (abort bool, diags tfdiags.Diagnostics) {
 	ctx, span := tracer.Start(ctx, "install modules")
 	defer span.End()
 
@@ -291,7 +320,16 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 		return true, diags
 	}
 
+	previousLocks, moreDiags := m.lockedDependencies()
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return true, diags
+	}
+	locks := previousLocks.DeepCopy()
+
 	inst := initwd.NewModuleInstaller(m.modulesDir(), loader, m.registryClient())
+	inst.SetOCIRepositoryStore(m.OCIRepositoryStore)
+	inst.SetLocks(locks)
 
 	call, vDiags := m.rootModuleCall(rootDir)
 	diags = diags.Append(vDiags)
@@ -299,7 +337,7 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 		return true, diags
 	}
 
-	_, moreDiags := inst.InstallModules(ctx, rootDir, testsDir, upgrade, installErrsOnly, hooks, call)
+	_, moreDiags = inst.InstallModules(ctx, rootDir, testsDir, upgrade, installErrsOnly, hooks, call)
 	diags = diags.Append(moreDiags)
 
 	if ctx.Err() == context.Canceled {
@@ -308,6 +346,18 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 		return true, diags
 	}
 
+	if !locks.Equal(previousLocks) {
+		if readonlyLocks {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Module dependency changes detected",
+				`Changes to the module package selections were detected, but the lock file is read-only. To use and record these selections, run "tofu init" without the "-lockfile=readonly" flag.`,
+			))
+			return true, diags
+		}
+		diags = diags.Append(m.replaceLockedDependencies(locks))
+	}
+
 	return false, diags
 }
 
@@ -333,7 +383,7 @@ func (m *Meta) initDirFromModule(ctx context.Context, targetDir string, addr str
 	}
 
 	targetDir = m.normalizePath(targetDir)
-	moreDiags := initwd.DirFromModule(ctx, loader, targetDir, m.modulesDir(), addr, m.registryClient(), hooks)
+	moreDiags := initwd.DirFromModule(ctx, loader, targetDir, m.modulesDir(), addr, m.registryClient(), m.OCIRepositoryStore, hooks)
 	diags = diags.Append(moreDiags)
 	if ctx.Err() == context.Canceled {
 		m.showDiagnostics(diags)
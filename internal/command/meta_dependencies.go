@@ -46,18 +46,25 @@ const dependencyLockFilename = ".terraform.lock.hcl"
 // and does not update as a result of calling replaceLockedDependencies
 // or any other modification method.
 func (m *Meta) lockedDependencies() (*depsfile.Locks, tfdiags.Diagnostics) {
+	return m.lockedDependenciesFromFile(dependencyLockFilename)
+}
+
+// lockedDependenciesFromFile is like lockedDependencies, but reads the lock
+// file with the given name rather than the one in the current working
+// directory.
+func (m *Meta) lockedDependenciesFromFile(filename string) (*depsfile.Locks, tfdiags.Diagnostics) {
 	// We check that the file exists first, because the underlying HCL
 	// parser doesn't distinguish that error from other error types
 	// in a machine-readable way but we want to treat that as a success
 	// with no locks. There is in theory a race condition here in that
 	// the file could be created or removed in the meantime, but we're not
 	// promising to support two concurrent dependency installation processes.
-	_, err := os.Stat(dependencyLockFilename)
+	_, err := os.Stat(filename)
 	if os.IsNotExist(err) {
 		return m.annotateDependencyLocksWithOverrides(depsfile.NewLocks()), nil
 	}
 
-	ret, diags := depsfile.LoadLocksFromFile(dependencyLockFilename)
+	ret, diags := depsfile.LoadLocksFromFile(filename)
 	return m.annotateDependencyLocksWithOverrides(ret), diags
 }
 
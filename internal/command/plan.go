@@ -100,6 +100,15 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// Load the local policies to evaluate against the plan
+	policies, policyDiags := c.loadPolicies(args.PolicyPaths)
+	diags = diags.Append(policyDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+	opReq.Policies = policies
+
 	// Before we delegate to the backend, we'll print any warning diagnostics
 	// we've accumulated here, since the backend will start fresh with its own
 	// diagnostics.
@@ -304,6 +313,11 @@ Other Options:
   -parallelism=n             Limit the number of concurrent operations. Defaults
                              to 10.
 
+  -policy=path               Evaluate the local policies in the given file, or
+                             in the .tfpolicy.hcl files of the given directory,
+                             against the plan. The plan fails if a mandatory
+                             policy fails. Can be used multiple times.
+
   -state=statefile           A legacy option used for the local backend only.
                              See the local backend's documentation for more
                              information.
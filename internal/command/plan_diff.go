@@ -0,0 +1,429 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/plandiff"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// PlanDiffCommand is a Command implementation that compares the changes
+// proposed by two saved plan files.
+type PlanDiffCommand struct {
+	Meta
+}
+
+func (c *PlanDiffCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var jsonOutput, detailedExitCode bool
+	cmdFlags := c.Meta.defaultFlagSet("plan diff")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.BoolVar(&detailedExitCode, "detailed-exitcode", false, "detailed-exitcode")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+	if len(args) != 2 {
+		c.Ui.Error("The plan diff command expects exactly two arguments: the old and the new plan files.\n")
+		return cli.RunResultHelp
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	oldPlan, oldSchemas, diags := c.loadPlanForDiff(args[0], enc)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	newPlan, newSchemas, diags := c.loadPlanForDiff(args[1], enc)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	diff, err := plandiff.Compare(oldPlan.Changes, oldSchemas, newPlan.Changes, newSchemas)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to compare the plans: %s", err))
+		return 1
+	}
+
+	if jsonOutput {
+		out, err := marshalPlanDiff(diff)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal plan differences: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+	} else {
+		c.Ui.Output(c.Colorize().Color(renderPlanDiff(diff, args[0], args[1])))
+	}
+
+	if detailedExitCode && !diff.Empty() {
+		return 2
+	}
+	return 0
+}
+
+// loadPlanForDiff reads a local plan file and the schemas needed to decode its
+// changes.
+func (c *PlanDiffCommand) loadPlanForDiff(path string, enc encryption.Encryption) (*plans.Plan, *tofu.Schemas, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	rootCall, callDiags := c.rootModuleCall(".")
+	diags = diags.Append(callDiags)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	pf, err := planfile.OpenWrapped(path, enc.Plan())
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read plan file",
+			fmt.Sprintf("Couldn't read %s as a plan file: %s", path, err),
+		))
+		return nil, nil, diags
+	}
+	lp, ok := pf.Local()
+	if !ok {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported plan file",
+			fmt.Sprintf("%s is a saved cloud plan, but only local plan files can be compared.", path),
+		))
+		return nil, nil, diags
+	}
+
+	plan, stateFile, config, err := getDataFromPlanfileReader(lp, rootCall)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read plan file",
+			fmt.Sprintf("Couldn't read %s as a plan file: %s", path, err),
+		))
+		return nil, nil, diags
+	}
+
+	schemas, schemaDiags := c.MaybeGetSchemas(stateFile.State, config)
+	diags = diags.Append(schemaDiags)
+	if schemaDiags.HasErrors() {
+		return nil, nil, diags
+	}
+	if schemas == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to load provider schemas",
+			fmt.Sprintf("The provider schemas are required to compare the changes in %s. Run \"tofu init\" to install the providers of the configuration.", path),
+		))
+		return nil, nil, diags
+	}
+	return plan, schemas, diags
+}
+
+func (c *PlanDiffCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] plan diff [options] OLD-PLAN NEW-PLAN
+
+  Compares the changes proposed by two saved plan files, such as a plan
+  that was approved in review and a plan that was created again later.
+
+  The output lists the resource instances that only one of the plans
+  changes, the resource instances whose planned action or planned values
+  differ, and the differences between the changes to output values.
+
+  The providers of both plans must be installed in the working directory,
+  so that OpenTofu can decode the planned values.
+
+Options:
+
+  -detailed-exitcode  Return detailed exit codes when the command exits.
+                      This will change the meaning of exit codes to:
+                      0 - Succeeded, the plans propose the same changes
+                      1 - Errored
+                      2 - Succeeded, the plans propose different changes
+
+  -json               Produce the differences in a machine-readable JSON
+                      format.
+
+  -no-color           If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PlanDiffCommand) Synopsis() string {
+	return "Compare the changes of two saved plans"
+}
+
+// renderPlanDiff returns the human-readable description of the differences
+// between two plans, with color codes.
+func renderPlanDiff(diff *plandiff.Diff, oldPath, newPath string) string {
+	if diff.Empty() {
+		return fmt.Sprintf("[reset][bold][green]No differences.[reset] %s and %s propose the same changes.", oldPath, newPath)
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "[reset]Differences between [bold]%s[reset] and [bold]%s[reset]:\n", oldPath, newPath)
+
+	var added, removed, changed int
+	if len(diff.Resources) > 0 {
+		buf.WriteString("\n[bold]Resource changes:[reset]\n")
+	}
+	for _, rd := range diff.Resources {
+		addr := rd.Addr.String()
+		if rd.DeposedKey != "" {
+			addr = fmt.Sprintf("%s (deposed object %s)", addr, rd.DeposedKey)
+		}
+		switch rd.Kind {
+		case plandiff.Added:
+			added++
+			fmt.Fprintf(&buf, "  [green]+[reset] [bold]%s[reset] is now planned to %s\n", addr, planDiffActionName(rd.NewAction))
+		case plandiff.Removed:
+			removed++
+			fmt.Fprintf(&buf, "  [red]-[reset] [bold]%s[reset] is no longer planned to %s\n", addr, planDiffActionName(rd.OldAction))
+		case plandiff.Changed:
+			changed++
+			if rd.OldAction != rd.NewAction {
+				fmt.Fprintf(&buf, "  [yellow]~[reset] [bold]%s[reset] will now %s instead of %s\n", addr, planDiffActionName(rd.NewAction), planDiffActionName(rd.OldAction))
+			} else {
+				fmt.Fprintf(&buf, "  [yellow]~[reset] [bold]%s[reset] has different planned values\n", addr)
+			}
+		}
+		for _, ad := range rd.Attributes {
+			buf.WriteString(renderPlanDiffValues("      ", strings.TrimPrefix(tfdiags.FormatCtyPath(ad.Path), "."), ad.Old, ad.New))
+		}
+	}
+
+	if len(diff.Outputs) > 0 {
+		buf.WriteString("\n[bold]Output changes:[reset]\n")
+	}
+	for _, od := range diff.Outputs {
+		name := od.Addr.OutputValue.Name
+		switch {
+		case od.Kind == plandiff.Changed && od.OldAction != od.NewAction:
+			fmt.Fprintf(&buf, "  [yellow]~[reset] [bold]%s[reset] will now %s instead of %s\n", name, planDiffActionName(od.NewAction), planDiffActionName(od.OldAction))
+			buf.WriteString(renderPlanDiffValues("      ", "value", od.Old, od.New))
+		default:
+			buf.WriteString(renderPlanDiffValues("  ", name, od.Old, od.New))
+		}
+	}
+
+	var parts []string
+	if added > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", added))
+	}
+	if removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", removed))
+	}
+	if changed > 0 {
+		parts = append(parts, fmt.Sprintf("%d changed", changed))
+	}
+	if len(parts) > 0 {
+		fmt.Fprintf(&buf, "\n[bold]Resource differences:[reset] %s.", strings.Join(parts, ", "))
+	}
+	if len(diff.Outputs) > 0 {
+		buf.WriteString("\n")
+		fmt.Fprintf(&buf, "[bold]Output differences:[reset] %d.", len(diff.Outputs))
+	}
+	return buf.String()
+}
+
+// renderPlanDiffValues returns a line describing the difference between the
+// old and new value of something called name.
+func renderPlanDiffValues(indent, name string, oldVal, newVal cty.Value) string {
+	switch {
+	case oldVal == cty.NilVal:
+		return fmt.Sprintf("%s[green]+[reset] %s = %s\n", indent, name, renderPlanDiffValue(newVal))
+	case newVal == cty.NilVal:
+		return fmt.Sprintf("%s[red]-[reset] %s = %s\n", indent, name, renderPlanDiffValue(oldVal))
+	default:
+		return fmt.Sprintf("%s[yellow]~[reset] %s = %s -> %s\n", indent, name, renderPlanDiffValue(oldVal), renderPlanDiffValue(newVal))
+	}
+}
+
+// renderPlanDiffValue returns a compact representation of a planned value,
+// which hides sensitive values.
+func renderPlanDiffValue(val cty.Value) string {
+	switch {
+	case marks.Contains(val, marks.Sensitive):
+		return "(sensitive value)"
+	case !val.IsWhollyKnown():
+		return "(known after apply)"
+	}
+	val, _ = val.UnmarkDeep()
+	raw, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return "(unrepresentable value)"
+	}
+	return string(raw)
+}
+
+// planDiffActionName returns a verb describing an action, to complete
+// sentences such as "is now planned to ...".
+func planDiffActionName(action plans.Action) string {
+	switch action {
+	case plans.Create:
+		return "create"
+	case plans.Read:
+		return "read"
+	case plans.Update:
+		return "update"
+	case plans.Delete:
+		return "destroy"
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		return "replace"
+	case plans.Forget:
+		return "forget"
+	case plans.NoOp:
+		return "not change"
+	default:
+		return strings.ToLower(action.String())
+	}
+}
+
+// marshalPlanDiff returns the JSON representation of the differences between
+// two plans.
+func marshalPlanDiff(diff *plandiff.Diff) ([]byte, error) {
+	type value struct {
+		Value     json.RawMessage `json:"value,omitempty"`
+		Unknown   bool            `json:"unknown,omitempty"`
+		Sensitive bool            `json:"sensitive,omitempty"`
+	}
+	type attribute struct {
+		Path string `json:"path"`
+		Old  *value `json:"old,omitempty"`
+		New  *value `json:"new,omitempty"`
+	}
+	type resource struct {
+		Address    string      `json:"address"`
+		Deposed    string      `json:"deposed,omitempty"`
+		Difference string      `json:"difference"`
+		OldAction  string      `json:"old_action"`
+		NewAction  string      `json:"new_action"`
+		Attributes []attribute `json:"attributes,omitempty"`
+	}
+	type output struct {
+		Name       string `json:"name"`
+		Difference string `json:"difference"`
+		OldAction  string `json:"old_action"`
+		NewAction  string `json:"new_action"`
+		Old        *value `json:"old,omitempty"`
+		New        *value `json:"new,omitempty"`
+	}
+	type result struct {
+		FormatVersion   string     `json:"format_version"`
+		ResourceChanges []resource `json:"resource_changes"`
+		OutputChanges   []output   `json:"output_changes"`
+	}
+
+	marshalValue := func(val cty.Value) (*value, error) {
+		switch {
+		case val == cty.NilVal:
+			return nil, nil
+		case marks.Contains(val, marks.Sensitive):
+			return &value{Sensitive: true}, nil
+		case !val.IsWhollyKnown():
+			return &value{Unknown: true}, nil
+		}
+		val, _ = val.UnmarkDeep()
+		raw, err := ctyjson.Marshal(val, val.Type())
+		if err != nil {
+			return nil, err
+		}
+		return &value{Value: raw}, nil
+	}
+
+	ret := result{
+		FormatVersion:   "1.0",
+		ResourceChanges: []resource{},
+		OutputChanges:   []output{},
+	}
+	for _, rd := range diff.Resources {
+		r := resource{
+			Address:    rd.Addr.String(),
+			Deposed:    string(rd.DeposedKey),
+			Difference: string(rd.Kind),
+			OldAction:  planDiffJSONAction(rd.OldAction),
+			NewAction:  planDiffJSONAction(rd.NewAction),
+		}
+		for _, ad := range rd.Attributes {
+			oldVal, err := marshalValue(ad.Old)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rd.Addr, err)
+			}
+			newVal, err := marshalValue(ad.New)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rd.Addr, err)
+			}
+			r.Attributes = append(r.Attributes, attribute{
+				Path: strings.TrimPrefix(tfdiags.FormatCtyPath(ad.Path), "."),
+				Old:  oldVal,
+				New:  newVal,
+			})
+		}
+		ret.ResourceChanges = append(ret.ResourceChanges, r)
+	}
+	for _, od := range diff.Outputs {
+		oldVal, err := marshalValue(od.Old)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", od.Addr, err)
+		}
+		newVal, err := marshalValue(od.New)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", od.Addr, err)
+		}
+		ret.OutputChanges = append(ret.OutputChanges, output{
+			Name:       od.Addr.OutputValue.Name,
+			Difference: string(od.Kind),
+			OldAction:  planDiffJSONAction(od.OldAction),
+			NewAction:  planDiffJSONAction(od.NewAction),
+			Old:        oldVal,
+			New:        newVal,
+		})
+	}
+	return json.Marshal(ret)
+}
+
+// planDiffJSONAction returns the name of an action in the JSON output, which
+// matches the names of actions in the JSON plan format.
+func planDiffJSONAction(action plans.Action) string {
+	switch action {
+	case plans.NoOp:
+		return "no-op"
+	case plans.Create:
+		return "create"
+	case plans.Read:
+		return "read"
+	case plans.Update:
+		return "update"
+	case plans.Delete:
+		return "delete"
+	case plans.DeleteThenCreate:
+		return "delete-then-create"
+	case plans.CreateThenDelete:
+		return "create-then-delete"
+	case plans.Forget:
+		return "forget"
+	default:
+		return strings.ToLower(action.String())
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+func TestPlanDiff(t *testing.T) {
+	oldPath := showFixturePlanFile(t, plans.Create)
+	newPath := showFixturePlanFile(t, plans.DeleteThenCreate)
+
+	ui := cli.NewMockUi()
+	c := &PlanDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-no-color", "-detailed-exitcode", oldPath, newPath}); code != 2 {
+		t.Fatalf("unexpected exit status %d; want 2\n%s", code, ui.ErrorWriter.String())
+	}
+
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		"~ test_instance.foo will now replace instead of create",
+		"Resource differences: 1 changed.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q\n%s", want, got)
+		}
+	}
+}
+
+func TestPlanDiff_json(t *testing.T) {
+	oldPath := showFixturePlanFile(t, plans.Create)
+	newPath := showFixturePlanFile(t, plans.Update)
+
+	ui := cli.NewMockUi()
+	c := &PlanDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-json", oldPath, newPath}); code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got struct {
+		ResourceChanges []struct {
+			Address    string `json:"address"`
+			Difference string `json:"difference"`
+			OldAction  string `json:"old_action"`
+			NewAction  string `json:"new_action"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	if len(got.ResourceChanges) != 1 {
+		t.Fatalf("got %d resource changes, want 1", len(got.ResourceChanges))
+	}
+	rc := got.ResourceChanges[0]
+	if rc.Address != "test_instance.foo" || rc.Difference != "changed" || rc.OldAction != "create" || rc.NewAction != "update" {
+		t.Errorf("unexpected resource change: %#v", rc)
+	}
+}
+
+func TestPlanDiff_same(t *testing.T) {
+	planPath := showFixturePlanFile(t, plans.Create)
+
+	ui := cli.NewMockUi()
+	c := &PlanDiffCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-no-color", "-detailed-exitcode", planPath, planPath}); code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "propose the same changes"; !strings.Contains(got, want) {
+		t.Errorf("output does not contain %q\n%s", want, got)
+	}
+}
+
+func TestPlanDiff_badArgs(t *testing.T) {
+	ui := cli.NewMockUi()
+	c := &PlanDiffCommand{
+		Meta: Meta{Ui: ui},
+	}
+	if code := c.Run([]string{"only-one.tfplan"}); code != cli.RunResultHelp {
+		t.Fatalf("unexpected exit status %d; want %d", code, cli.RunResultHelp)
+	}
+	if got, want := ui.ErrorWriter.String(), "expects exactly two arguments"; !strings.Contains(got, want) {
+		t.Errorf("error does not contain %q\n%s", want, got)
+	}
+}
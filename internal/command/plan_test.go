@@ -295,6 +295,50 @@ func TestPlan_generatedConfigPath(t *testing.T) {
 	testFileEquals(t, genPath, filepath.Join(td, "generated.tf.expected"))
 }
 
+func TestPlan_generatedConfigReferences(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-import-config-gen-references"), td)
+	defer testChdir(t, td)()
+
+	statePath := testStateFile(t, testState())
+	genPath := filepath.Join(td, "generated.tf")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	// The imported object refers to test_instance.foo by its ID, "bar".
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_instance",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id":  cty.StringVal("child"),
+					"ami": cty.StringVal("bar"),
+				}),
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-generate-config-out", genPath,
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	testFileEquals(t, genPath, filepath.Join(td, "generated.tf.expected"))
+}
+
 func TestPlan_outPath(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -6,6 +6,7 @@
 package command
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -14,9 +15,16 @@ import (
 
 	"github.com/apparentlymart/go-versions/versions"
 	"github.com/hashicorp/go-getter"
+	"github.com/mitchellh/cli"
 
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/internal/ociregistry"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -36,8 +44,15 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	cmdFlags := c.Meta.defaultFlagSet("providers mirror")
 	c.Meta.varFlagSet(cmdFlags)
-	var optPlatforms FlagStringSlice
+	var optPlatforms, optConfigDirs, optLockFiles FlagStringSlice
+	var optPrune, optIndex bool
+	var optOCIRepository string
 	cmdFlags.Var(&optPlatforms, "platform", "target platform")
+	cmdFlags.Var(&optConfigDirs, "config", "configuration directory")
+	cmdFlags.Var(&optLockFiles, "lock-file", "dependency lock file")
+	cmdFlags.BoolVar(&optPrune, "prune", false, "remove packages that are no longer required")
+	cmdFlags.BoolVar(&optIndex, "index", true, "write network mirror index files")
+	cmdFlags.StringVar(&optOCIRepository, "oci-repository", "", "OCI repository template to publish to")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -77,36 +92,33 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 		}
 	}
 
-	// Installation steps can be cancelled by SIGINT and similar.
-	ctx, done := c.InterruptibleContext(c.CommandContext())
-	defer done()
-
-	config, confDiags := c.loadConfig(".")
-	diags = diags.Append(confDiags)
-	reqs, _, moreDiags := config.ProviderRequirements()
-	diags = diags.Append(moreDiags)
-
-	// Read lock file
-	lockedDeps, lockedDepsDiags := c.Meta.lockedDependencies()
-	diags = diags.Append(lockedDepsDiags)
-
-	// If we have any error diagnostics already then we won't proceed further.
-	if diags.HasErrors() {
-		c.showDiagnostics(diags)
-		return 1
-	}
-
-	// If lock file is present, validate it against configuration
-	if !lockedDeps.Empty() {
-		if errs := config.VerifyDependencySelections(lockedDeps); len(errs) > 0 {
+	var ociMirror *cliconfig.ProviderInstallationOCIMirror
+	if optOCIRepository != "" {
+		ociMirror = &cliconfig.ProviderInstallationOCIMirror{RepositoryTemplate: optOCIRepository}
+		if err := ociMirror.Validate(); err != nil {
 			diags = diags.Append(tfdiags.Sourceless(
 				tfdiags.Error,
-				"Inconsistent dependency lock file",
-				fmt.Sprintf("To update the locked dependency selections to match a changed configuration, run:\n  tofu init -upgrade\n got:%v", errs),
+				"Invalid OCI repository template",
+				fmt.Sprintf("The value %q given in the -oci-repository option is not a valid repository template: %s.", optOCIRepository, err),
 			))
+			c.showDiagnostics(diags)
+			return 1
 		}
 	}
 
+	// By default we mirror the providers for the configuration in the
+	// current working directory, but the caller can instead give any number
+	// of configuration directories and standalone lock files so that a
+	// single mirror can serve many configurations.
+	configDirs := []string(optConfigDirs)
+	if len(configDirs) == 0 && len(optLockFiles) == 0 {
+		configDirs = []string{"."}
+	}
+
+	// Installation steps can be cancelled by SIGINT and similar.
+	ctx, done := c.InterruptibleContext(c.CommandContext())
+	defer done()
+
 	// Unlike other commands, this command always consults the origin registry
 	// for every provider so that it can be used to update a local mirror
 	// directory without needing to first disable that local mirror
@@ -134,10 +146,126 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 	// - It can mirror packages for potentially many different target platforms,
 	//   so that we can construct a multi-platform mirror regardless of which
 	//   platform we run this command on.
-	// - It ignores what's already present and just always downloads everything
-	//   that the configuration requires. This is a command intended to be run
-	//   infrequently to update a mirror, so it doesn't need to optimize away
-	//   fetches of packages that might already be present.
+	// - It skips downloading any package that is already present in the
+	//   mirror with a hash that the origin registry reports for it, so that
+	//   the command can be run regularly to keep a mirror in sync.
+	mirror := &providersMirror{
+		outputDir:  outputDir,
+		platforms:  platforms,
+		source:     source,
+		httpGetter: &httpGetter,
+		ui:         c.Ui,
+		required:   make(map[providersMirrorPackage]struct{}),
+		ociMirror:  ociMirror,
+		ociStore:   c.OCIRepositoryStore,
+	}
+
+	for _, configDir := range configDirs {
+		diags = diags.Append(c.mirrorConfig(ctx, mirror, configDir))
+	}
+	for _, lockFile := range optLockFiles {
+		diags = diags.Append(c.mirrorLockFile(ctx, mirror, lockFile))
+	}
+
+	// We only prune if everything else succeeded, because otherwise we might
+	// remove packages that are still required but that we failed to select.
+	if optPrune {
+		if diags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Mirror directory not pruned",
+				"Because of the errors above, OpenTofu did not remove any packages from the mirror directory.",
+			))
+		} else {
+			diags = diags.Append(mirror.prune())
+		}
+	}
+
+	if optIndex {
+		diags = diags.Append(mirror.writeIndexes())
+	}
+
+	// Publishing replaces the index manifest of each version, so we don't
+	// publish a mirror directory that might be missing some packages.
+	if ociMirror != nil {
+		if diags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Mirror not published",
+				"Because of the errors above, OpenTofu did not publish the mirror directory to the OCI repositories.",
+			))
+		} else {
+			diags = diags.Append(mirror.publishOCI(ctx))
+		}
+	}
+
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// providersMirrorPackage identifies a single package in a mirror directory.
+type providersMirrorPackage struct {
+	provider addrs.Provider
+	version  getproviders.Version
+	platform getproviders.Platform
+}
+
+// providersMirror is the state of a single run of the providers mirror
+// command, which can mirror the providers of many configurations and lock
+// files into the same output directory.
+type providersMirror struct {
+	outputDir  string
+	platforms  []getproviders.Platform
+	source     getproviders.Source
+	httpGetter *getter.HttpGetter
+	ui         cli.Ui
+
+	// required records each package that this run selected, whether or not
+	// it needed to be downloaded, so that prune can remove all of the others.
+	required map[providersMirrorPackage]struct{}
+
+	// ociMirror, if set, gives the OCI repository of each provider that
+	// publishOCI publishes the mirror directory to, using ociStore.
+	ociMirror *cliconfig.ProviderInstallationOCIMirror
+	ociStore  ociregistry.RepositoryStoreFunc
+}
+
+// mirrorConfig mirrors the providers that the configuration in the given
+// directory requires, selecting the versions recorded in its dependency lock
+// file if it has one.
+func (c *ProvidersMirrorCommand) mirrorConfig(ctx context.Context, mirror *providersMirror, configDir string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	config, confDiags := c.loadMirrorConfig(configDir)
+	diags = diags.Append(confDiags)
+	if confDiags.HasErrors() {
+		return diags
+	}
+	reqs, _, moreDiags := config.ProviderRequirements()
+	diags = diags.Append(moreDiags)
+
+	// Read lock file
+	lockedDeps, lockedDepsDiags := c.Meta.lockedDependenciesFromFile(filepath.Join(configDir, dependencyLockFilename))
+	diags = diags.Append(lockedDepsDiags)
+
+	// If we have any error diagnostics already then we won't proceed further.
+	if diags.HasErrors() {
+		return diags
+	}
+
+	// If lock file is present, validate it against configuration
+	if !lockedDeps.Empty() {
+		if errs := config.VerifyDependencySelections(lockedDeps); len(errs) > 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Inconsistent dependency lock file",
+				fmt.Sprintf("To update the locked dependency selections to match a changed configuration, run:\n  tofu init -upgrade\n got:%v", errs),
+			))
+		}
+	}
 
 	for provider, constraints := range reqs {
 		if provider.IsBuiltIn() {
@@ -149,7 +277,7 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 		// First we'll look for the latest version that matches the given
 		// constraint, which we'll then try to mirror for each target platform.
 		acceptable := versions.MeetingConstraints(constraints)
-		avail, _, err := source.AvailableVersions(ctx, provider)
+		avail, _, err := mirror.source.AvailableVersions(ctx, provider)
 		candidates := avail.Filter(acceptable)
 		if err == nil && len(candidates) == 0 {
 			err = fmt.Errorf("no releases match the given constraints %s", constraintsStr)
@@ -179,88 +307,239 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 		} else {
 			c.Ui.Output(fmt.Sprintf("  - Selected v%s with no constraints", selected.String()))
 		}
-		for _, platform := range platforms {
-			c.Ui.Output(fmt.Sprintf("  - Downloading package for %s...", platform.String()))
-			meta, err := source.PackageMeta(ctx, provider, selected, platform)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Provider release not available",
-					fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
-				))
-				continue
-			}
-			urlStr, ok := meta.Location.(getproviders.PackageHTTPURL)
-			if !ok {
-				// We don't expect to get non-HTTP locations here because we're
-				// using the registry source, so this seems like a bug in the
-				// registry source.
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Provider release not available",
-					fmt.Sprintf("Failed to download %s v%s for %s: OpenTofu's provider registry client returned unexpected location type %T. This is a bug in OpenTofu.", provider.String(), selected.String(), platform.String(), meta.Location),
-				))
+		diags = diags.Append(mirror.mirrorVersion(ctx, provider, selected))
+	}
+
+	return diags
+}
+
+// mirrorLockFile mirrors the provider versions selected in the given
+// dependency lock file, without reference to any configuration.
+func (c *ProvidersMirrorCommand) mirrorLockFile(ctx context.Context, mirror *providersMirror, filename string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	locks, moreDiags := depsfile.LoadLocksFromFile(filename)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	for provider, lock := range locks.AllProviders() {
+		c.Ui.Output(fmt.Sprintf("- Mirroring %s...", provider.ForDisplay()))
+		c.Ui.Output(fmt.Sprintf("  - Selected v%s to match dependency lock file %s", lock.Version().String(), filename))
+		diags = diags.Append(mirror.mirrorVersion(ctx, provider, lock.Version()))
+	}
+
+	return diags
+}
+
+// loadMirrorConfig loads the configuration in the given directory. For any
+// directory other than the current working directory, the modules of the
+// configuration must have been installed in the default data directory
+// inside it, as "tofu init" would do when run in that directory.
+func (c *ProvidersMirrorCommand) loadMirrorConfig(dir string) (*configs.Config, tfdiags.Diagnostics) {
+	if filepath.Clean(dir) == "." {
+		return c.loadConfig(dir)
+	}
+
+	var diags tfdiags.Diagnostics
+	loader, err := configload.NewLoader(&configload.Config{
+		ModulesDir: filepath.Join(dir, DefaultDataDir, "modules"),
+		Services:   c.Services,
+	})
+	if err != nil {
+		diags = diags.Append(err)
+		return nil, diags
+	}
+	loader.AllowLanguageExperiments(c.AllowExperimentalFeatures)
+
+	call, callDiags := c.rootModuleCall(dir)
+	diags = diags.Append(callDiags)
+	if callDiags.HasErrors() {
+		return nil, diags
+	}
+
+	config, hclDiags := loader.LoadConfig(dir, call)
+	diags = diags.Append(hclDiags)
+	return config, diags
+}
+
+// mirrorVersion places the packages of the given version of the given
+// provider for each of the target platforms into the mirror directory,
+// unless they are already present with a hash that the origin registry
+// reports for them.
+func (m *providersMirror) mirrorVersion(ctx context.Context, provider addrs.Provider, selected getproviders.Version) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	for _, platform := range m.platforms {
+		pkg := providersMirrorPackage{provider: provider, version: selected, platform: platform}
+		if _, done := m.required[pkg]; done {
+			// Another configuration or lock file already selected this
+			// package during this run.
+			continue
+		}
+		m.required[pkg] = struct{}{}
+
+		meta, err := m.source.PackageMeta(ctx, provider, selected, platform)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Provider release not available",
+				fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
+			))
+			continue
+		}
+		// targetPath is the path where we ultimately want to place the
+		// downloaded archive, but we'll place it initially at stagingPath
+		// so we can verify its checksums and signatures before making
+		// it discoverable to mirror clients. (stagingPath intentionally
+		// does not follow the filesystem mirror file naming convention.)
+		targetPath := meta.PackedFilePath(m.outputDir)
+		stagingPath := filepath.Join(filepath.Dir(targetPath), "."+filepath.Base(targetPath))
+
+		// If the package is already present then we only download it again
+		// if it doesn't match the registry's checksum, which could be because
+		// a previous run was interrupted or the file was modified.
+		if _, err := os.Stat(targetPath); err == nil {
+			if matches, err := getproviders.PackageMatchesAnyHash(getproviders.PackageLocalArchive(targetPath), meta.AcceptableHashes()); err == nil && matches {
+				m.ui.Output(fmt.Sprintf("  - Package for %s is already up to date", platform.String()))
 				continue
 			}
-			urlObj, err := url.Parse(string(urlStr))
+		}
+
+		m.ui.Output(fmt.Sprintf("  - Downloading package for %s...", platform.String()))
+		urlStr, ok := meta.Location.(getproviders.PackageHTTPURL)
+		if !ok {
+			// We don't expect to get non-HTTP locations here because we're
+			// using the registry source, so this seems like a bug in the
+			// registry source.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Provider release not available",
+				fmt.Sprintf("Failed to download %s v%s for %s: OpenTofu's provider registry client returned unexpected location type %T. This is a bug in OpenTofu.", provider.String(), selected.String(), platform.String(), meta.Location),
+			))
+			continue
+		}
+		urlObj, err := url.Parse(string(urlStr))
+		if err != nil {
+			// We don't expect to get non-HTTP locations here because we're
+			// using the registry source, so this seems like a bug in the
+			// registry source.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid URL for provider release",
+				fmt.Sprintf("The origin registry for %s returned an invalid URL for v%s on %s: %s.", provider.String(), selected.String(), platform.String(), err),
+			))
+			continue
+		}
+		err = m.httpGetter.GetFile(stagingPath, urlObj)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Cannot download provider release",
+				fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
+			))
+			continue
+		}
+		if meta.Authentication != nil {
+			result, err := meta.Authentication.AuthenticatePackage(getproviders.PackageLocalArchive(stagingPath))
 			if err != nil {
-				// We don't expect to get non-HTTP locations here because we're
-				// using the registry source, so this seems like a bug in the
-				// registry source.
 				diags = diags.Append(tfdiags.Sourceless(
 					tfdiags.Error,
-					"Invalid URL for provider release",
-					fmt.Sprintf("The origin registry for %s returned an invalid URL for v%s on %s: %s.", provider.String(), selected.String(), platform.String(), err),
+					"Invalid provider package",
+					fmt.Sprintf("Failed to authenticate %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
 				))
 				continue
 			}
-			// targetPath is the path where we ultimately want to place the
-			// downloaded archive, but we'll place it initially at stagingPath
-			// so we can verify its checksums and signatures before making
-			// it discoverable to mirror clients. (stagingPath intentionally
-			// does not follow the filesystem mirror file naming convention.)
-			targetPath := meta.PackedFilePath(outputDir)
-			stagingPath := filepath.Join(filepath.Dir(targetPath), "."+filepath.Base(targetPath))
-			err = httpGetter.GetFile(stagingPath, urlObj)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Cannot download provider release",
-					fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
-				))
+			m.ui.Output(fmt.Sprintf("  - Package authenticated: %s", result))
+		}
+		os.Remove(targetPath) // okay if it fails because we're going to try to rename over it next anyway
+		err = os.Rename(stagingPath, targetPath)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Cannot download provider release",
+				fmt.Sprintf("Failed to place %s package into mirror directory: %s.", provider.String(), err),
+			))
+			continue
+		}
+	}
+
+	return diags
+}
+
+// prune removes each package from the mirror directory that this run didn't
+// select, along with the JSON index files of any versions and providers that
+// have no packages left.
+func (m *providersMirror) prune() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	available, err := getproviders.SearchLocalDirectory(m.outputDir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to prune mirror directory",
+			fmt.Sprintf("Could not scan the output directory to find packages that are no longer required: %s.", err),
+		))
+		return diags
+	}
+
+	removeFile := func(filename string) {
+		err := os.Remove(filename)
+		if err != nil && !os.IsNotExist(err) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to prune mirror directory",
+				fmt.Sprintf("Failed to remove %s: %s.", filename, err),
+			))
+		}
+	}
+
+	for provider, metas := range available {
+		indexDir := filepath.Dir(getproviders.PackedFilePathForPackage(
+			m.outputDir, provider, versions.Unspecified, getproviders.CurrentPlatform,
+		))
+		remaining := make(map[getproviders.Version]bool)
+		var removed []getproviders.Version
+		for _, meta := range metas {
+			archivePath, ok := meta.Location.(getproviders.PackageLocalArchive)
+			if !ok {
+				// This command only ever produces archives, so we leave
+				// anything else alone.
 				continue
 			}
-			if meta.Authentication != nil {
-				result, err := meta.Authentication.AuthenticatePackage(getproviders.PackageLocalArchive(stagingPath))
-				if err != nil {
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Invalid provider package",
-						fmt.Sprintf("Failed to authenticate %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
-					))
-					continue
-				}
-				c.Ui.Output(fmt.Sprintf("  - Package authenticated: %s", result))
-			}
-			os.Remove(targetPath) // okay if it fails because we're going to try to rename over it next anyway
-			err = os.Rename(stagingPath, targetPath)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Cannot download provider release",
-					fmt.Sprintf("Failed to place %s package into mirror directory: %s.", provider.String(), err),
-				))
+			pkg := providersMirrorPackage{provider: provider, version: meta.Version, platform: meta.TargetPlatform}
+			if _, ok := m.required[pkg]; ok {
+				remaining[meta.Version] = true
 				continue
 			}
+			m.ui.Output(fmt.Sprintf("- Removing %s v%s for %s", provider.ForDisplay(), meta.Version.String(), meta.TargetPlatform.String()))
+			removeFile(string(archivePath))
+			removed = append(removed, meta.Version)
+		}
+		for _, version := range removed {
+			if !remaining[version] {
+				removeFile(filepath.Join(indexDir, version.String()+".json"))
+			}
+		}
+		if len(removed) > 0 && len(remaining) == 0 {
+			removeFile(filepath.Join(indexDir, "index.json"))
 		}
 	}
 
-	// Now we'll generate or update the JSON index files in the directory.
+	return diags
+}
+
+// writeIndexes generates or updates the JSON index files that allow serving
+// the mirror directory as a network mirror.
+func (m *providersMirror) writeIndexes() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
 	// We do this by scanning the directory to see what is present, rather than
-	// by relying on the selections we made above, because we want to still
-	// include in the indices any packages that were already present and
+	// by relying on the selections that this run made, because we want to
+	// still include in the indices any packages that were already present and
 	// not affected by the changes we just made.
-	available, err := getproviders.SearchLocalDirectory(outputDir)
+	available, err := getproviders.SearchLocalDirectory(m.outputDir)
 	if err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -278,7 +557,7 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 		// we'll ask the getproviders package to build an archive filename
 		// for a fictitious package and then use the directory portion of it.
 		indexDir := filepath.Dir(getproviders.PackedFilePathForPackage(
-			outputDir, provider, versions.Unspecified, getproviders.CurrentPlatform,
+			m.outputDir, provider, versions.Unspecified, getproviders.CurrentPlatform,
 		))
 		indexVersions := map[string]interface{}{}
 		indexArchives := map[getproviders.Version]map[string]interface{}{}
@@ -355,11 +634,107 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 		}
 	}
 
-	c.showDiagnostics(diags)
-	if diags.HasErrors() {
-		return 1
+	return diags
+}
+
+// publishOCI publishes every version of every provider in the mirror
+// directory to the OCI repository of the provider, in the layout that the
+// oci_mirror provider installation method reads. Versions whose packages are
+// already published aren't changed.
+func (m *providersMirror) publishOCI(ctx context.Context) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	available, err := getproviders.SearchLocalDirectory(m.outputDir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to publish mirror",
+			fmt.Sprintf("Could not scan the output directory to find the packages to publish: %s.", err),
+		))
+		return diags
 	}
-	return 0
+
+	for provider, metas := range available {
+		archives := make(map[getproviders.Version]map[getproviders.Platform]getproviders.PackageLocalArchive)
+		for _, meta := range metas {
+			archivePath, ok := meta.Location.(getproviders.PackageLocalArchive)
+			if !ok {
+				// This command only ever produces archives, so we leave
+				// anything else alone.
+				continue
+			}
+			if archives[meta.Version] == nil {
+				archives[meta.Version] = make(map[getproviders.Platform]getproviders.PackageLocalArchive)
+			}
+			archives[meta.Version][meta.TargetPlatform] = archivePath
+		}
+		if len(archives) == 0 {
+			continue
+		}
+
+		registryDomain, repositoryName, err := m.ociMirror.RepositoryForProvider(provider)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to publish provider",
+				fmt.Sprintf("Cannot publish %s: %s.", provider, err),
+			))
+			continue
+		}
+		m.ui.Output(fmt.Sprintf("- Publishing %s to %s/%s...", provider.ForDisplay(), registryDomain, repositoryName))
+		store, err := m.ociPushStore(ctx, registryDomain, repositoryName)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to publish provider",
+				fmt.Sprintf("Cannot publish %s to %s/%s: %s.", provider, registryDomain, repositoryName, err),
+			))
+			continue
+		}
+
+		versionList := make(getproviders.VersionList, 0, len(archives))
+		for version := range archives {
+			versionList = append(versionList, version)
+		}
+		versionList.Sort()
+		for _, version := range versionList {
+			changed, err := getproviders.PushToOCIRepository(ctx, store, version, archives[version])
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to publish provider",
+					fmt.Sprintf("Failed to publish %s v%s to %s/%s: %s.", provider, version, registryDomain, repositoryName, err),
+				))
+				continue
+			}
+			if changed {
+				m.ui.Output(fmt.Sprintf("  - Published v%s", version))
+			} else {
+				m.ui.Output(fmt.Sprintf("  - v%s is already up to date", version))
+			}
+		}
+	}
+
+	return diags
+}
+
+// ociPushStore returns the store for the given OCI repository, which must
+// support publishing.
+func (m *providersMirror) ociPushStore(ctx context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryPushStore, error) {
+	if m.ociStore == nil {
+		return nil, fmt.Errorf("OCI registries are not available")
+	}
+	store, err := m.ociStore(ctx, registryDomain, repositoryName)
+	if err != nil {
+		return nil, err
+	}
+	pushStore, ok := store.(ociregistry.RepositoryPushStore)
+	if !ok {
+		// The stores that OpenTofu CLI uses all support publishing, so this
+		// would be a bug.
+		return nil, fmt.Errorf("the OCI repository client doesn't support publishing")
+	}
+	return pushStore, nil
 }
 
 func (c *ProvidersMirrorCommand) Help() string {
@@ -376,8 +751,35 @@ Usage: tofu [global options] providers mirror [options] <target-dir>
   a network mirror. Those index files will be ignored if the directory is
   used instead as a local filesystem mirror.
 
+  Packages that are already in the mirror directory are only downloaded
+  again if they don't match the checksums from their origin registry.
+
 Options:
 
+  -config=dir        Mirror the providers required by the configuration in
+                     the given directory instead of the current working
+                     directory. Use this option more than once to mirror the
+                     providers of several configurations.
+
+  -lock-file=path    Mirror the provider versions selected in the given
+                     dependency lock file, in addition to any configurations.
+                     Use this option more than once to include more than one
+                     lock file.
+
+  -prune             Remove any packages from the mirror directory that this
+                     run didn't select, such as versions that are no longer
+                     required by any of the given configurations.
+
+  -index=false       Don't write the JSON index files for a network mirror.
+
+  -oci-repository=template
+                     Also publish the mirror directory to OCI repositories,
+                     in the layout that the oci_mirror provider installation
+                     method reads. The template gives the repository of each
+                     provider, such as
+                     "example.com/opentofu-providers/${namespace}/${type}".
+                     Each version is published as a tag of the repository.
+
   -platform=os_arch  Choose which target platform to build a mirror for.
                      By default OpenTofu will obtain plugin packages
                      suitable for the platform where you run this command.
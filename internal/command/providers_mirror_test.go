@@ -6,10 +6,29 @@
 package command
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-getter"
 	"github.com/mitchellh/cli"
+	orasremote "oras.land/oras-go/v2/registry/remote"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/ociregistry"
+	"github.com/opentofu/opentofu/internal/ociregistry/ocitest"
 )
 
 // More thorough tests for providers mirror can be found in the e2etest
@@ -39,3 +58,248 @@ func TestProvidersMirror(t *testing.T) {
 		}
 	})
 }
+
+func TestProvidersMirror_incremental(t *testing.T) {
+	provider := addrs.MustParseProviderSourceString("example.com/test/foo")
+	platform := getproviders.Platform{OS: "linux", Arch: "amd64"}
+	oldVersion := getproviders.MustParseVersion("0.9.0")
+	version := getproviders.MustParseVersion("1.0.0")
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if _, err := zw.Create("terraform-provider-foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.Bytes()
+	sum := sha256.Sum256(content)
+	hash := getproviders.HashSchemeZip.New(hex.EncodeToString(sum[:]))
+
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			downloads++
+		}
+		_, _ = resp.Write(content)
+	}))
+	defer server.Close()
+
+	source := getproviders.NewMockSource([]getproviders.PackageMeta{
+		{
+			Provider:       provider,
+			Version:        version,
+			TargetPlatform: platform,
+			Filename:       "terraform-provider-foo_1.0.0_linux_amd64.zip",
+			Location:       getproviders.PackageHTTPURL(server.URL + "/terraform-provider-foo_1.0.0_linux_amd64.zip"),
+			Authentication: getproviders.NewPackageHashAuthentication(platform, []getproviders.Hash{hash}),
+		},
+	}, nil)
+
+	outputDir := t.TempDir()
+	indexDir := filepath.Join(outputDir, "example.com", "test", "foo")
+	targetPath := getproviders.PackedFilePathForPackage(outputDir, provider, version, platform)
+	oldPath := getproviders.PackedFilePathForPackage(outputDir, provider, oldVersion, platform)
+
+	// The mirror already has a package for a version that's no longer
+	// required, along with its index file.
+	if err := os.MkdirAll(indexDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldPath, []byte("package for 0.9.0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(indexDir, "0.9.0.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newMirror := func() (*providersMirror, *cli.MockUi) {
+		ui := cli.NewMockUi()
+		return &providersMirror{
+			outputDir:  outputDir,
+			platforms:  []getproviders.Platform{platform},
+			source:     source,
+			httpGetter: &getter.HttpGetter{Client: server.Client()},
+			ui:         ui,
+			required:   make(map[providersMirrorPackage]struct{}),
+		}, ui
+	}
+
+	// The first run downloads the package, because it isn't present yet.
+	mirror, ui := newMirror()
+	if diags := mirror.mirrorVersion(context.Background(), provider, version); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if downloads != 1 {
+		t.Errorf("wrong number of downloads %d; want 1\n%s", downloads, ui.OutputWriter.String())
+	}
+
+	// The second run skips the package, because it matches the hash that
+	// the source reports for it.
+	mirror, ui = newMirror()
+	if diags := mirror.mirrorVersion(context.Background(), provider, version); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if downloads != 1 {
+		t.Errorf("package was downloaded again")
+	}
+	if got, want := ui.OutputWriter.String(), "Package for linux_amd64 is already up to date"; !strings.Contains(got, want) {
+		t.Errorf("wrong output\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// A package that doesn't match is downloaded again.
+	if err := os.WriteFile(targetPath, []byte("corrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mirror, _ = newMirror()
+	if diags := mirror.mirrorVersion(context.Background(), provider, version); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if downloads != 2 {
+		t.Errorf("wrong number of downloads %d; want 2", downloads)
+	}
+	if got, err := os.ReadFile(targetPath); err != nil || string(got) != string(content) {
+		t.Errorf("wrong package content %q (error %v)", got, err)
+	}
+
+	// Pruning removes the package and index of the version that this run
+	// didn't select, and the indexes then only include the remaining one.
+	if diags := mirror.prune(); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if diags := mirror.writeIndexes(); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	entries, err := os.ReadDir(indexDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotFiles []string
+	for _, entry := range entries {
+		gotFiles = append(gotFiles, entry.Name())
+	}
+	wantFiles := []string{"1.0.0.json", "index.json", "terraform-provider-foo_1.0.0_linux_amd64.zip"}
+	if diff := cmp.Diff(wantFiles, gotFiles); diff != "" {
+		t.Errorf("wrong files in mirror\n%s", diff)
+	}
+	raw, err := os.ReadFile(filepath.Join(indexDir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index struct {
+		Versions map[string]any `json:"versions"`
+	}
+	if err := json.Unmarshal(raw, &index); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]any{"1.0.0": map[string]any{}}, index.Versions); diff != "" {
+		t.Errorf("wrong versions in index\n%s", diff)
+	}
+}
+
+func TestProvidersMirror_publishOCI(t *testing.T) {
+	provider := addrs.MustParseProviderSourceString("example.com/test/foo")
+	version := getproviders.MustParseVersion("1.0.0")
+	platforms := []getproviders.Platform{
+		{OS: "linux", Arch: "amd64"},
+		{OS: "darwin", Arch: "arm64"},
+	}
+
+	// The mirror directory already has the packages of the provider.
+	outputDir := t.TempDir()
+	for _, platform := range platforms {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("terraform-provider-foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(platform.String())); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		targetPath := getproviders.PackedFilePathForPackage(outputDir, provider, version, platform)
+		if err := os.MkdirAll(filepath.Dir(targetPath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(targetPath, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := ocitest.NewRegistry()
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	registryDomain := strings.TrimPrefix(server.URL, "http://")
+	ociStore := func(_ context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryStore, error) {
+		repo, err := orasremote.NewRepository(registryDomain + "/" + repositoryName)
+		if err != nil {
+			return nil, err
+		}
+		repo.PlainHTTP = true
+		repo.Client = server.Client()
+		return repo, nil
+	}
+	ociMirror := &cliconfig.ProviderInstallationOCIMirror{
+		RepositoryTemplate: registryDomain + "/providers/${namespace}/${type}",
+	}
+
+	newMirror := func() (*providersMirror, *cli.MockUi) {
+		ui := cli.NewMockUi()
+		return &providersMirror{
+			outputDir: outputDir,
+			ui:        ui,
+			required:  make(map[providersMirrorPackage]struct{}),
+			ociMirror: ociMirror,
+			ociStore:  ociStore,
+		}, ui
+	}
+
+	mirror, ui := newMirror()
+	if diags := mirror.publishOCI(context.Background()); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if got, want := ui.OutputWriter.String(), "Published v1.0.0"; !strings.Contains(got, want) {
+		t.Errorf("wrong output\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Publishing again leaves the repository unchanged.
+	mirror, ui = newMirror()
+	if diags := mirror.publishOCI(context.Background()); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if got, want := ui.OutputWriter.String(), "v1.0.0 is already up to date"; !strings.Contains(got, want) {
+		t.Errorf("wrong output\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// The oci_mirror installation method finds the packages of both
+	// platforms, matching the archives in the mirror directory.
+	source := getproviders.NewOCIRegistryMirrorSource(ociMirror.RepositoryForProvider, ociStore)
+	for _, platform := range platforms {
+		meta, err := source.PackageMeta(context.Background(), provider, version, platform)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", platform, err)
+		}
+		archivePath := getproviders.PackedFilePathForPackage(outputDir, provider, version, platform)
+		if matches, err := getproviders.PackageMatchesAnyHash(getproviders.PackageLocalArchive(archivePath), meta.AcceptableHashes()); err != nil || !matches {
+			t.Errorf("published package for %s doesn't match the mirror directory (error %v)", platform, err)
+		}
+	}
+}
+
+func TestProvidersMirror_invalidOCIRepository(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &ProvidersMirrorCommand{
+		Meta: Meta{Ui: ui},
+	}
+	code := c.Run([]string{"-oci-repository=example.com/providers", t.TempDir()})
+	if code != 1 {
+		t.Fatalf("wrong exit code. expected 1, got %d", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid OCI repository template"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -119,6 +119,11 @@ Options:
   -json               If specified, output the OpenTofu plan or state in
                       a machine-readable form.
 
+  -format=format      Output a saved plan as a document for display outside
+                      of a terminal, such as in a pull request comment. The
+                      format can be "markdown" or "html". Sensitive values
+                      are always hidden, and very large plans are shortened.
+
   -show-sensitive     If specified, sensitive values will be displayed.
 
   -var 'foo=bar'      Set a value for one of the input variables in the root
@@ -0,0 +1,176 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// StateHistoryCommand is a Command implementation that lists the retained
+// versions of the state of the current workspace.
+type StateHistoryCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateHistoryCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var jsonOutput bool
+	cmdFlags := c.Meta.defaultFlagSet("state history")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The state history command expects no arguments.\n")
+		return cli.RunResultHelp
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(nil, enc.State())
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(backendDiags)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	env, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	stateMgr, err := b.StateMgr(env)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	versioner, err := stateVersioner(stateMgr)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	versions, err := versioner.Versions()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to list state versions: %s", err))
+		return 1
+	}
+
+	if jsonOutput {
+		out, err := marshalStateVersions(versions)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal state versions: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+		return 0
+	}
+
+	if len(versions) == 0 {
+		c.Ui.Output("No state versions have been retained for this workspace.")
+		return 0
+	}
+
+	c.Ui.Output(fmt.Sprintf("%-20s %-8s %-36s %-20s %s", "ID", "SERIAL", "LINEAGE", "CREATED", "LOCKED BY"))
+	for _, v := range versions {
+		lockedBy := "-"
+		if v.LockInfo != nil {
+			lockedBy = fmt.Sprintf("%s (%s)", v.LockInfo.Who, v.LockInfo.Operation)
+		}
+		c.Ui.Output(fmt.Sprintf("%-20s %-8d %-36s %-20s %s", v.ID, v.Serial, v.Lineage, v.Created.UTC().Format(time.RFC3339), lockedBy))
+	}
+
+	return 0
+}
+
+func (c *StateHistoryCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state history [options]
+
+  List the previous versions of the state that have been retained by the
+  backend for the current workspace, from the most recent to the oldest.
+
+  This command requires a backend that supports state history, such as the
+  "pg" and "sqlite" backends with history enabled. A version can be restored
+  using the "tofu state rollback" command.
+
+Options:
+
+  -json  Produce the list of versions in a machine-readable JSON format.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateHistoryCommand) Synopsis() string {
+	return "List previous versions of the state"
+}
+
+// stateVersioner returns the ClientVersioner of the given state manager, or
+// an error if its backend doesn't retain previous versions of the state.
+func stateVersioner(stateMgr statemgr.Full) (remote.ClientVersioner, error) {
+	if rs, ok := stateMgr.(*remote.State); ok {
+		if versioner, ok := rs.Client.(remote.ClientVersioner); ok {
+			return versioner, nil
+		}
+	}
+	return nil, errors.New(errStateHistoryUnsupported)
+}
+
+func marshalStateVersions(versions []*remote.StateVersion) ([]byte, error) {
+	type lockInfo struct {
+		ID        string `json:"id"`
+		Operation string `json:"operation"`
+		Who       string `json:"who"`
+	}
+	type stateVersion struct {
+		ID       string    `json:"id"`
+		Serial   uint64    `json:"serial"`
+		Lineage  string    `json:"lineage"`
+		Created  time.Time `json:"created"`
+		LockInfo *lockInfo `json:"lock_info,omitempty"`
+	}
+
+	result := make([]stateVersion, 0, len(versions))
+	for _, v := range versions {
+		sv := stateVersion{
+			ID:      v.ID,
+			Serial:  v.Serial,
+			Lineage: v.Lineage,
+			Created: v.Created.UTC(),
+		}
+		if v.LockInfo != nil {
+			sv.LockInfo = &lockInfo{
+				ID:        v.LockInfo.ID,
+				Operation: v.LockInfo.Operation,
+				Who:       v.LockInfo.Who,
+			}
+		}
+		result = append(result, sv)
+	}
+	return json.Marshal(result)
+}
+
+const errStateHistoryUnsupported = `The configured backend does not retain previous versions of the state.
+
+State history is only available for backends that support it, such as the
+"pg" and "sqlite" backends with "enable_history" set to true.`
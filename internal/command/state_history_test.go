@@ -0,0 +1,209 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/backend/remote-state/sqlite"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+func TestStateHistoryAndRollback(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("state-history-sqlite"), td)
+	defer testChdir(t, td)()
+
+	// init the backend
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	initCmd := &InitCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := initCmd.Run([]string{}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	// Write two versions of the state with different resources.
+	b := backend.TestBackendConfig(t, sqlite.New(encryption.StateEncryptionDisabled()), backend.TestWrapConfig(map[string]interface{}{
+		"path":           "state.db",
+		"enable_history": true,
+	}))
+	stateMgr, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stateMgr.RefreshState(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		state := states.BuildState(func(s *states.SyncState) {
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: "test_instance",
+					Name: name,
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"` + name + `"}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		})
+		if err := statemgr.WriteAndPersist(stateMgr, state, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ui = cli.NewMockUi()
+	historyCmd := &StateHistoryCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := historyCmd.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	lines := strings.Split(strings.TrimSpace(ui.OutputWriter.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 versions, got:\n%s", ui.OutputWriter.String())
+	}
+	for i, serial := range []string{"2", "1"} {
+		if fields := strings.Fields(lines[i+1]); fields[1] != serial {
+			t.Fatalf("expected version %d to have serial %s, got:\n%s", i, serial, lines[i+1])
+		}
+	}
+
+	ui = cli.NewMockUi()
+	rollbackCmd := &StateRollbackCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := rollbackCmd.Run([]string{"-serial=1"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if err := stateMgr.RefreshState(); err != nil {
+		t.Fatal(err)
+	}
+	state := stateMgr.State()
+	fooAddr, _ := addrs.ParseAbsResourceInstanceStr("test_instance.foo")
+	barAddr, _ := addrs.ParseAbsResourceInstanceStr("test_instance.bar")
+	if state.ResourceInstance(fooAddr) == nil {
+		t.Fatalf("expected test_instance.foo after rollback, got:\n%s", state)
+	}
+	if state.ResourceInstance(barAddr) != nil {
+		t.Fatalf("expected no test_instance.bar after rollback, got:\n%s", state)
+	}
+	if got := stateMgr.(statemgr.PersistentMeta).StateSnapshotMeta().Serial; got != 3 {
+		t.Fatalf("expected the rollback to be written with serial 3, got %d", got)
+	}
+
+	ui = cli.NewMockUi()
+	rollbackCmd = &StateRollbackCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := rollbackCmd.Run([]string{"-serial=10"}); code != 1 {
+		t.Fatalf("expected an error for an unknown serial, got %d", code)
+	}
+}
+
+func TestStateHistory_unsupported(t *testing.T) {
+	testCwd(t)
+
+	ui := cli.NewMockUi()
+	c := &StateHistoryCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "does not retain previous versions"; !strings.Contains(got, want) {
+		t.Fatalf("expected error to contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestStateRollback_unsupported(t *testing.T) {
+	testCwd(t)
+
+	ui := cli.NewMockUi()
+	c := &StateRollbackCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-serial=1"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "does not retain previous versions"; !strings.Contains(got, want) {
+		t.Fatalf("expected error to contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestStateRollback_noSerial(t *testing.T) {
+	testCwd(t)
+
+	ui := cli.NewMockUi()
+	c := &StateRollbackCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run(nil); code != cli.RunResultHelp {
+		t.Fatalf("wrong exit code %d; want %d", code, cli.RunResultHelp)
+	}
+	if got, want := ui.ErrorWriter.String(), "-serial option is required"; !strings.Contains(got, want) {
+		t.Fatalf("expected error to contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestMarshalStateVersions(t *testing.T) {
+	versions := []*remote.StateVersion{
+		{
+			ID:      "2",
+			Serial:  5,
+			Lineage: "lineage",
+			Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			LockInfo: &statemgr.LockInfo{
+				ID:        "lock-id",
+				Operation: "OperationTypeApply",
+				Who:       "me@host",
+			},
+		},
+		{
+			ID:      "1",
+			Serial:  4,
+			Lineage: "lineage",
+			Created: time.Date(2024, 1, 1, 3, 4, 5, 0, time.UTC),
+		},
+	}
+
+	got, err := marshalStateVersions(versions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"id":"2","serial":5,"lineage":"lineage","created":"2024-01-02T03:04:05Z","lock_info":{"id":"lock-id","operation":"OperationTypeApply","who":"me@host"}},{"id":"1","serial":4,"lineage":"lineage","created":"2024-01-01T03:04:05Z"}]`
+	if string(got) != want {
+		t.Fatalf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
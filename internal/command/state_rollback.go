@@ -0,0 +1,215 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// StateRollbackCommand is a Command implementation that restores a
+// previously-retained version of the state of the current workspace.
+type StateRollbackCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateRollbackCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var flagSerial int64
+	var flagForce bool
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state rollback")
+	cmdFlags.Int64Var(&flagSerial, "serial", -1, "serial")
+	cmdFlags.BoolVar(&flagForce, "force", false, "")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The state rollback command expects no arguments.\n")
+		return cli.RunResultHelp
+	}
+	if flagSerial < 0 {
+		c.Ui.Error("The -serial option is required and must not be negative.\n")
+		return cli.RunResultHelp
+	}
+
+	if diags := c.Meta.checkRequiredVersion(); diags != nil {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(nil, enc.State())
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(backendDiags)
+		return 1
+	}
+
+	// Determine the workspace name
+	workspace, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+
+	// Check remote OpenTofu version is compatible
+	remoteVersionDiags := c.remoteVersionCheck(b, workspace)
+	c.showDiagnostics(remoteVersionDiags)
+	if remoteVersionDiags.HasErrors() {
+		return 1
+	}
+
+	stateMgr, err := b.StateMgr(workspace)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	versioner, err := stateVersioner(stateMgr)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state-rollback"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+		return 1
+	}
+
+	versions, err := versioner.Versions()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to list state versions: %s", err))
+		return 1
+	}
+
+	// Versions are ordered from the most recent, so if the same serial was
+	// written more than once (e.g. by a forced push) we restore the latest.
+	var version *remote.StateVersion
+	for _, v := range versions {
+		if v.Serial == uint64(flagSerial) {
+			version = v
+			break
+		}
+	}
+	if version == nil {
+		c.Ui.Error(fmt.Sprintf("No retained state version has serial %d. Use \"tofu state history\" to list the available versions.", flagSerial))
+		return 1
+	}
+
+	payload, err := versioner.GetVersion(version.ID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read state version %s: %s", version.ID, err))
+		return 1
+	}
+	if payload == nil {
+		c.Ui.Error(fmt.Sprintf("State version %s is no longer retained by the backend.", version.ID))
+		return 1
+	}
+	srcStateFile, err := statefile.Read(bytes.NewReader(payload.Data), enc.State())
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read state version %s: %s", version.ID, err))
+		return 1
+	}
+
+	// The restored snapshot is written as a new version of the current
+	// state, so that the serial keeps increasing and the rollback itself
+	// can be undone in the same way.
+	current := statemgr.Export(stateMgr)
+	if current.Lineage != "" && srcStateFile.Lineage != current.Lineage {
+		if !flagForce {
+			c.Ui.Error(fmt.Sprintf(errStateRollbackLineage, srcStateFile.Lineage, current.Lineage))
+			return 1
+		}
+		srcStateFile.Serial = current.Serial
+		if err := statemgr.Import(srcStateFile, stateMgr, true); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to write state: %s", err))
+			return 1
+		}
+	} else if err := stateMgr.WriteState(srcStateFile.State); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write state: %s", err))
+		return 1
+	}
+	if err := stateMgr.PersistState(nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to persist state: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Rolled back the state to the version with serial %d.", flagSerial))
+	return 0
+}
+
+func (c *StateRollbackCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state rollback [options] -serial=SERIAL
+
+  Restore a previous version of the state of the current workspace.
+
+  The version with the given serial is written as a new version of the
+  state, so the serial keeps increasing and the rollback can be undone by
+  rolling back again. Use "tofu state history" to list the versions that
+  are retained by the backend.
+
+  This command requires a backend that supports state history, such as the
+  "pg" and "sqlite" backends with history enabled.
+
+Options:
+
+  -serial=SERIAL      The serial of the state version to restore. Required.
+
+  -force              Restore the version even if its lineage doesn't match
+                      the lineage of the current state.
+
+  -lock=false         Don't hold a state lock during the operation. This is
+                      dangerous if others might concurrently run commands
+                      against the same workspace.
+
+  -lock-timeout=0s    Duration to retry a state lock.
+
+  -ignore-remote-version  A rare option used for the remote backend only. See
+                          the remote backend documentation for more information.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateRollbackCommand) Synopsis() string {
+	return "Restore a previous version of the state"
+}
+
+const errStateRollbackLineage = `The state version to restore has lineage %q, which doesn't match the lineage %q of the current state.
+
+This usually means that the state was replaced by an unrelated state since
+the version was written. Use the -force option to restore it anyway.`
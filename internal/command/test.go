@@ -10,9 +10,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
@@ -72,6 +75,16 @@ Options:
                         will be performed. All locations, for all errors
                         will be listed. Disabled by default
 
+  -coverage             Print a summary of the resources, output values, input
+                        variable validations and check blocks exercised by
+                        the run blocks.
+
+  -coverage-out=path    Also write a coverage report to the given path. Implies
+                        -coverage.
+
+  -coverage-format=fmt  The format of the coverage report, either "lcov" or
+                        "json". Defaults to "lcov".
+
   -filter=testfile      If specified, OpenTofu will only execute the test files
                         specified by this flag. You can use this option multiple
                         times to execute more than one test file.
@@ -79,12 +92,33 @@ Options:
   -json                 If specified, machine readable output will be printed in
                         JSON format
 
+  -junit-xml=path       If specified, OpenTofu will also write a JUnit XML
+                        report of the test results to the given path, with a
+                        testsuite for each test file and a testcase for each
+                        run block.
+
   -no-color             If specified, output won't contain any color.
 
+  -parallelism=n        Execute up to n test files at the same time. Test
+                        files that replace every provider with a mock_provider
+                        block, or whose run blocks all set a state_key that no
+                        other file uses, run alongside other files. Other
+                        files run one at a time. The output for each file is
+                        printed once it completes. Defaults to 1.
+
   -test-directory=path  Set the OpenTofu test directory, defaults to "tests". When set, the
                         test command will search for test files in the current directory and
                         in the one specified by the flag.
 
+  -watch                Keep running after the tests complete, and run the
+                        affected test files again whenever the configuration
+                        or the test files change. Press Ctrl-C to exit.
+
+  -update-snapshots     Write the plan snapshots of run blocks that set
+                        snapshot = true, instead of comparing the plans
+                        against them. Without this option, a missing
+                        snapshot fails the run block.
+
   -var 'foo=bar'        Set a value for one of the input variables in the root
                         module of the configuration. Use this option more than
                         once to set more than one variable.
@@ -127,7 +161,7 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
-	view := views.NewTest(args.ViewType, c.View)
+	view := c.newTestView(args)
 
 	// Users can also specify variables via the command line, so we'll parse
 	// all that here.
@@ -154,16 +188,58 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	suite, fileDiags := testSuite(config, args.Filter)
+	diags = diags.Append(fileDiags)
+	if fileDiags.HasErrors() {
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
+	opts, err := c.contextOpts()
+	if err != nil {
+		diags = diags.Append(err)
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
+	// Don't use encryption during testing
+	opts.Encryption = encryption.Disabled()
+
+	// Print out all the diagnostics we have from the setup. These will just be
+	// warnings, and we want them out of the way before we start the actual
+	// testing.
+	view.Diagnostics(nil, nil, diags)
+
+	code, interrupted := c.runTestSuite(ctx, args, view, suite, config, variables, opts)
+	if !args.Watch || interrupted {
+		return code
+	}
+	return c.watchTests(ctx, args, opts, config, code)
+}
+
+// newTestView returns the view for the test results, including a JUnit XML
+// report if requested.
+func (c *TestCommand) newTestView(args *arguments.Test) views.Test {
+	view := views.NewTest(args.ViewType, c.View)
+	if args.JUnitXMLFile != "" {
+		view = views.TestMulti{view, views.NewTestJUnitXMLFile(args.JUnitXMLFile, c.View)}
+	}
+	return view
+}
+
+// testSuite builds the suite for the test files in config, limited to the
+// given files if filter is not empty.
+func testSuite(config *configs.Config, filter []string) (*moduletest.Suite, tfdiags.Diagnostics) {
 	runCount := 0
 	fileCount := 0
 
 	var fileDiags tfdiags.Diagnostics
-	suite := moduletest.Suite{
+	suite := &moduletest.Suite{
 		Files: func() map[string]*moduletest.File {
 			files := make(map[string]*moduletest.File)
 
-			if len(args.Filter) > 0 {
-				for _, name := range args.Filter {
+			if len(filter) > 0 {
+				for _, name := range filter {
 					file, ok := config.Module.Tests[name]
 					if !ok {
 						// If the filter is invalid, we'll simply skip this
@@ -178,14 +254,8 @@ func (c *TestCommand) Run(rawArgs []string) int {
 
 					fileCount++
 
-					var runs []*moduletest.Run
-					for ix, run := range file.Runs {
-						runs = append(runs, &moduletest.Run{
-							Config: run,
-							Index:  ix,
-							Name:   run.Name,
-						})
-					}
+					runs, runDiags := moduletest.ExpandRuns(file)
+					fileDiags = fileDiags.Append(runDiags)
 
 					runCount += len(runs)
 					files[name] = &moduletest.File{
@@ -202,14 +272,8 @@ func (c *TestCommand) Run(rawArgs []string) int {
 			for name, file := range config.Module.Tests {
 				fileCount++
 
-				var runs []*moduletest.Run
-				for ix, run := range file.Runs {
-					runs = append(runs, &moduletest.Run{
-						Config: run,
-						Index:  ix,
-						Name:   run.Name,
-					})
-				}
+				runs, runDiags := moduletest.ExpandRuns(file)
+				fileDiags = fileDiags.Append(runDiags)
 
 				runCount += len(runs)
 				files[name] = &moduletest.File{
@@ -223,28 +287,13 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	}
 
 	log.Printf("[DEBUG] TestCommand: found %d files with %d run blocks", fileCount, runCount)
+	return suite, fileDiags
+}
 
-	diags = diags.Append(fileDiags)
-	if fileDiags.HasErrors() {
-		view.Diagnostics(nil, nil, diags)
-		return 1
-	}
-
-	opts, err := c.contextOpts()
-	if err != nil {
-		diags = diags.Append(err)
-		view.Diagnostics(nil, nil, diags)
-		return 1
-	}
-
-	// Don't use encryption during testing
-	opts.Encryption = encryption.Disabled()
-
-	// Print out all the diagnostics we have from the setup. These will just be
-	// warnings, and we want them out of the way before we start the actual
-	// testing.
-	view.Diagnostics(nil, nil, diags)
-
+// runTestSuite executes the given suite and reports the results to view. It
+// returns the exit code for the command, and whether the user interrupted the
+// tests.
+func (c *TestCommand) runTestSuite(ctx context.Context, args *arguments.Test, view views.Test, suite *moduletest.Suite, config *configs.Config, variables map[string]backend.UnparsedVariableValue, opts *tofu.ContextOpts) (int, bool) {
 	// We have two levels of interrupt here. A 'stop' and a 'cancel'. A 'stop'
 	// is a soft request to stop. We'll finish the current test, do the tidy up,
 	// but then skip all remaining tests and run blocks. A 'cancel' is a hard
@@ -260,7 +309,7 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	runner := &TestSuiteRunner{
 		command: c,
 
-		Suite:  &suite,
+		Suite:  suite,
 		Config: config,
 		View:   view,
 
@@ -275,10 +324,16 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		Cancelled: false,
 		Stopped:   false,
 
-		Verbose: args.Verbose,
+		Verbose:         args.Verbose,
+		Parallelism:     args.Parallelism,
+		TestDirectory:   args.TestDirectory,
+		UpdateSnapshots: args.UpdateSnapshots,
+	}
+	if args.Coverage {
+		runner.Coverage = moduletest.NewCoverage(config, c.configSources())
 	}
 
-	view.Abstract(&suite)
+	view.Abstract(suite)
 
 	panicHandler := logging.PanicHandlerWithTraceFn()
 	go func() {
@@ -324,15 +379,56 @@ func (c *TestCommand) Run(rawArgs []string) int {
 
 	if runner.Cancelled {
 		// Don't print out the conclusion if the test was cancelled.
-		return 1
+		return 1, true
 	}
 
-	view.Conclusion(&suite)
+	view.Conclusion(suite)
+
+	if runner.Coverage != nil {
+		view.Coverage(runner.Coverage)
+
+		if args.CoverageOut != "" {
+			if diags := writeTestCoverage(runner.Coverage, args.CoverageOut, args.CoverageFormat); diags.HasErrors() {
+				view.Diagnostics(nil, nil, diags)
+				return 1, runner.Stopped
+			}
+		}
+	}
 
 	if suite.Status != moduletest.Pass {
-		return 1
+		return 1, runner.Stopped
 	}
-	return 0
+	return 0, runner.Stopped
+}
+
+// writeTestCoverage writes the coverage report to filename in the given
+// format.
+func writeTestCoverage(coverage *moduletest.Coverage, filename string, format string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var src []byte
+	switch format {
+	case arguments.CoverageFormatJSON:
+		var err error
+		src, err = coverage.JSON()
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to render coverage report",
+				fmt.Sprintf("OpenTofu could not render the coverage report: %s.", err)))
+			return diags
+		}
+	default:
+		src = coverage.LCOV()
+	}
+
+	if err := os.WriteFile(filename, src, 0644); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write coverage report",
+			fmt.Sprintf("OpenTofu could not write the coverage report to %s: %s.", filename, err)))
+	}
+	return diags
 }
 
 // test runner
@@ -366,6 +462,27 @@ type TestSuiteRunner struct {
 
 	// Verbose tells the runner to print out plan files during each test run.
 	Verbose bool
+
+	// Parallelism is the maximum number of test files to execute at the same
+	// time.
+	Parallelism int
+
+	// TestDirectory is the directory the test files in Config were loaded
+	// from, which is needed to load another copy of Config for each worker
+	// when executing test files in parallel.
+	TestDirectory string
+
+	// UpdateSnapshots tells the runner to overwrite the plan snapshots of run
+	// blocks instead of comparing against them.
+	UpdateSnapshots bool
+
+	// Coverage records the objects in Config exercised by the run blocks,
+	// if the user requested a coverage report.
+	Coverage *moduletest.Coverage
+
+	// statusLock protects the status of the Suite while test files are
+	// executing concurrently.
+	statusLock sync.Mutex
 }
 
 func (runner *TestSuiteRunner) Start(ctx context.Context) {
@@ -376,33 +493,202 @@ func (runner *TestSuiteRunner) Start(ctx context.Context) {
 	sort.Strings(files) // execute the files in alphabetical order
 
 	runner.Suite.Status = moduletest.Pass
+
+	if runner.Parallelism > 1 && len(files) > 1 {
+		runner.startParallel(ctx, files)
+		return
+	}
+
 	for _, name := range files {
 		if runner.Cancelled {
 			return
 		}
 
+		runner.executeFile(ctx, runner.Suite.Files[name], runner.Config, runner.View)
+	}
+}
+
+// startParallel executes the named test files using up to
+// runner.Parallelism workers.
+//
+// Test files that replace all of their providers with mock providers can't
+// interfere with each other, and test files whose run blocks all use state
+// keys of their own declare that they manage separate infrastructure, so
+// those are shared between all the workers. Any other test file may create
+// infrastructure that other test files also manage, so those are executed
+// one at a time by the first worker before it joins the others.
+func (runner *TestSuiteRunner) startParallel(ctx context.Context, files []string) {
+	stateKeyFiles := make(map[string]int)
+	for _, name := range files {
+		for key := range testFileStateKeys(runner.Suite.Files[name]) {
+			stateKeyFiles[key]++
+		}
+	}
+
+	var sequential []*moduletest.File
+	isolated := make(chan *moduletest.File, len(files))
+	for _, name := range files {
 		file := runner.Suite.Files[name]
+		if isolatedTestFile(runner.Config, file) || stateKeyIsolatedTestFile(file, stateKeyFiles) {
+			isolated <- file
+			continue
+		}
+		sequential = append(sequential, file)
+	}
+	close(isolated)
 
-		fileRunner := &TestFileRunner{
-			Suite: runner,
-			States: map[string]*TestFileState{
-				MainStateIdentifier: {
-					Run:   nil,
-					State: states.NewState(),
-				},
+	// Run blocks modify the configuration while they execute, so every
+	// worker needs its own copy of it, loaded in the same way.
+	workers := []*configs.Config{runner.Config}
+	for len(workers) < min(runner.Parallelism, len(files)) {
+		config, diags := runner.command.loadConfigWithTests(".", runner.TestDirectory)
+		if diags.HasErrors() {
+			// We managed to load the configuration once already, so this
+			// shouldn't happen. We'll make do with the workers we have.
+			log.Printf("[WARN] TestSuiteRunner: failed to load configuration for worker: %s", diags.Err())
+			break
+		}
+		workers = append(workers, config)
+	}
+
+	log.Printf("[DEBUG] TestSuiteRunner: executing %d isolated and %d sequential files with %d workers", len(files)-len(sequential), len(sequential), len(workers))
+
+	var outputLock sync.Mutex
+	var wg sync.WaitGroup
+	panicHandler := logging.PanicHandlerWithTraceFn()
+	for ix, config := range workers {
+		wg.Add(1)
+		go func() {
+			defer panicHandler()
+			defer wg.Done()
+
+			execute := func(file *moduletest.File) {
+				if runner.Cancelled {
+					return
+				}
+
+				view := views.NewTestBuffer(runner.View, &outputLock)
+				runner.executeFile(ctx, file, config, view)
+				view.Flush()
+			}
+
+			if ix == 0 {
+				for _, file := range sequential {
+					execute(file)
+				}
+			}
+			for file := range isolated {
+				execute(file)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// executeFile executes the given test file against config, and cleans up
+// any infrastructure it created afterwards.
+func (runner *TestSuiteRunner) executeFile(ctx context.Context, file *moduletest.File, config *configs.Config, view views.Test) {
+	fileRunner := &TestFileRunner{
+		Suite:  runner,
+		Config: config,
+		View:   view,
+		States: map[string]*TestFileState{
+			MainStateIdentifier: {
+				Run:   nil,
+				State: states.NewState(),
 			},
+		},
+	}
+
+	start := time.Now()
+	fileRunner.ExecuteTestFile(ctx, file)
+	fileRunner.Cleanup(ctx, file)
+	file.Duration = time.Since(start)
+
+	runner.statusLock.Lock()
+	defer runner.statusLock.Unlock()
+	runner.Suite.Status = runner.Suite.Status.Merge(file.Status)
+}
+
+// isolatedTestFile returns true if the given test file replaces every
+// provider used by the configurations it tests with a mock provider, which
+// means it can execute alongside other test files without interfering with
+// them.
+func isolatedTestFile(config *configs.Config, file *moduletest.File) bool {
+	if len(file.Config.MockProviders) == 0 || len(file.Config.Providers) > 0 {
+		return false
+	}
+
+	mocked := make(map[addrs.Provider]bool)
+	for _, provider := range file.Config.MockProviders {
+		mocked[config.Module.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: provider.Name})] = true
+	}
+
+	required := config.ProviderTypes()
+	for _, run := range file.Runs {
+		if run.Config.ConfigUnderTest != nil {
+			required = append(required, run.Config.ConfigUnderTest.ProviderTypes()...)
 		}
+	}
 
-		fileRunner.ExecuteTestFile(ctx, file)
-		fileRunner.Cleanup(ctx, file)
-		runner.Suite.Status = runner.Suite.Status.Merge(file.Status)
+	for _, provider := range required {
+		if provider.IsBuiltIn() {
+			// Built-in providers don't manage any real infrastructure.
+			continue
+		}
+		if !mocked[provider] {
+			return false
+		}
 	}
+	return true
+}
+
+// testFileStateKeys returns the state keys that the run blocks in the given
+// test file set explicitly.
+func testFileStateKeys(file *moduletest.File) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, run := range file.Config.Runs {
+		if run.StateKey != "" {
+			keys[run.StateKey] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// stateKeyIsolatedTestFile returns true if every run block in the given test
+// file sets a state key that no other test file uses, according to the given
+// number of test files that use each state key. The author of such a file
+// declares that it manages separate infrastructure, so it can execute
+// alongside other test files.
+func stateKeyIsolatedTestFile(file *moduletest.File, stateKeyFiles map[string]int) bool {
+	if len(file.Config.Runs) == 0 {
+		return false
+	}
+	for _, run := range file.Config.Runs {
+		if run.StateKey == "" || stateKeyFiles[run.StateKey] > 1 {
+			return false
+		}
+	}
+	return true
 }
 
 type TestFileRunner struct {
 	Suite *TestSuiteRunner
 
+	// Config is the configuration the test file executes against, unless
+	// a run block specifies an alternate module.
+	Config *configs.Config
+
+	// View receives the output for this test file.
+	View views.Test
+
 	States map[string]*TestFileState
+
+	// ExpandedRunOutputs holds the output values of the runs expanded from
+	// run blocks with for_each, by the name of the run block and then by the
+	// key of the run, so that later run blocks can refer to them as
+	// run.<name>[<key>].
+	ExpandedRunOutputs map[string]map[string]cty.Value
 }
 
 type TestFileState struct {
@@ -438,7 +724,7 @@ func (runner *TestFileRunner) ExecuteTestFile(ctx context.Context, file *modulet
 		}
 
 		key := MainStateIdentifier
-		config := runner.Suite.Config
+		config := runner.Config
 		if run.Config.ConfigUnderTest != nil {
 			config = run.Config.ConfigUnderTest
 			// Then we need to load an alternate state and not the main one.
@@ -460,15 +746,20 @@ func (runner *TestFileRunner) ExecuteTestFile(ctx context.Context, file *modulet
 				continue // Abort!
 			}
 
-			if _, exists := runner.States[key]; !exists {
-				runner.States[key] = &TestFileState{
-					Run:   nil,
-					State: states.NewState(),
-				}
+		}
+		if run.Config.StateKey != "" {
+			key = run.Config.StateKey
+		}
+		if _, exists := runner.States[key]; !exists {
+			runner.States[key] = &TestFileState{
+				Run:   nil,
+				State: states.NewState(),
 			}
 		}
 
+		start := time.Now()
 		state, updatedState := runner.ExecuteTestRun(ctx, run, file, runner.States[key].State, config)
+		run.Duration = time.Since(start)
 		if updatedState {
 			var err error
 
@@ -496,15 +787,44 @@ func (runner *TestFileRunner) ExecuteTestFile(ctx context.Context, file *modulet
 			// configuration.
 			runner.States[key].State = state
 			runner.States[key].Run = run
+
+			if run.Key != addrs.NoKey {
+				runner.recordExpandedRunOutputs(run, state)
+			}
 		}
 
 		file.Status = file.Status.Merge(run.Status)
 	}
 
-	runner.Suite.View.File(file)
+	runner.View.File(file)
 	for _, run := range file.Runs {
-		runner.Suite.View.Run(run, file)
+		runner.View.Run(run, file)
+	}
+}
+
+// recordExpandedRunOutputs records the root module output values in state as
+// the outputs of the given run, which was expanded from a run block with
+// for_each.
+func (runner *TestFileRunner) recordExpandedRunOutputs(run *moduletest.Run, state *states.State) {
+	if runner.ExpandedRunOutputs == nil {
+		runner.ExpandedRunOutputs = make(map[string]map[string]cty.Value)
+	}
+	outputs := make(map[string]cty.Value)
+	for name, out := range state.RootModule().OutputValues {
+		outputs[name] = out.Value
+	}
+
+	var key string
+	switch k := run.Key.(type) {
+	case addrs.StringKey:
+		key = string(k)
+	case addrs.IntKey:
+		key = strconv.Itoa(int(k))
+	}
+	if runner.ExpandedRunOutputs[run.Config.Name] == nil {
+		runner.ExpandedRunOutputs[run.Config.Name] = make(map[string]cty.Value)
 	}
+	runner.ExpandedRunOutputs[run.Config.Name][key] = cty.ObjectVal(outputs)
 }
 
 func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletest.Run, file *moduletest.File, state *states.State, config *configs.Config) (*states.State, bool) {
@@ -551,7 +871,15 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 		return state, false
 	}
 
+	// The state is only updated by run blocks that apply, we record the
+	// coverage once the run block has finished.
+	var coveredState *states.State
 	planCtx, plan, planDiags := runner.plan(ctx, config, state, run, file)
+	coveredPlan := coveragePlan(plan)
+	defer func() {
+		runner.recordCoverage(run, coveredPlan, coveredState)
+	}()
+
 	if run.Config.Command == configs.PlanTestCommand {
 		expectedFailures, sourceRanges := run.BuildExpectedFailuresAndSourceMaps()
 		// Then we want to assess our conditions and diagnostics differently.
@@ -562,6 +890,10 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 			return state, false
 		}
 
+		if run.Config.Snapshot {
+			runner.checkSnapshot(planCtx, config, run, file, plan)
+		}
+
 		variables, resetVariables, variableDiags := runner.prepareInputVariablesForAssertions(config, run, file, runner.Suite.GlobalVariables)
 		defer resetVariables()
 
@@ -612,6 +944,10 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 		return state, false
 	}
 
+	if run.Config.Snapshot {
+		runner.checkSnapshot(planCtx, config, run, file, plan)
+	}
+
 	// Since we're carrying on an executing the apply operation as well, we're
 	// just going to do some post processing of the diagnostics. We remove the
 	// warnings generated from check blocks, as the apply operation will either
@@ -627,6 +963,7 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 	run.Diagnostics = filteredDiags
 
 	applyCtx, updated, applyDiags := runner.apply(ctx, plan, state, config, run, file)
+	coveredState = updated
 
 	// Remove expected diagnostics, and add diagnostics in case anything that should have failed didn't.
 	applyDiags = run.ValidateExpectedFailures(expectedFailures, sourceRanges, applyDiags)
@@ -677,6 +1014,31 @@ func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletes
 	return updated, true
 }
 
+// recordCoverage adds the objects exercised by the given run block to the
+// coverage report, if one was requested. Run blocks that execute against an
+// alternate module don't contribute to the coverage of the main configuration.
+func (runner *TestFileRunner) recordCoverage(run *moduletest.Run, plan *plans.Plan, state *states.State) {
+	if runner.Suite.Coverage == nil || run.Config.ConfigUnderTest != nil {
+		return
+	}
+	runner.Suite.Coverage.Record(plan, state)
+}
+
+// coveragePlan returns a copy of the plan for recording coverage. The apply
+// operation removes changes from the plan as it applies them, so we take a
+// copy of the changes before the run block applies the plan.
+func coveragePlan(plan *plans.Plan) *plans.Plan {
+	if plan == nil || plan.Changes == nil {
+		return plan
+	}
+	ret := *plan
+	ret.Changes = &plans.Changes{
+		Resources: slices.Clone(plan.Changes.Resources),
+		Outputs:   slices.Clone(plan.Changes.Outputs),
+	}
+	return &ret
+}
+
 func (runner *TestFileRunner) validate(ctx context.Context, config *configs.Config, run *moduletest.Run, file *moduletest.File) tfdiags.Diagnostics {
 	log.Printf("[TRACE] TestFileRunner: called validate for %s/%s", file.Name, run.Name)
 
@@ -722,7 +1084,7 @@ func (runner *TestFileRunner) destroy(ctx context.Context, config *configs.Confi
 
 	var diags tfdiags.Diagnostics
 
-	evalCtx, ctxDiags := getEvalContextForTest(runner.States, config, runner.Suite.GlobalVariables)
+	evalCtx, ctxDiags := getEvalContextForTest(runner.States, runner.ExpandedRunOutputs, config, run, runner.Suite.GlobalVariables)
 	diags = diags.Append(ctxDiags)
 
 	variables, variableDiags := buildInputVariablesForTest(run, file, config, runner.Suite.GlobalVariables, evalCtx)
@@ -788,7 +1150,7 @@ func (runner *TestFileRunner) plan(ctx context.Context, config *configs.Config,
 	references, referenceDiags := run.GetReferences()
 	diags = diags.Append(referenceDiags)
 
-	evalCtx, ctxDiags := getEvalContextForTest(runner.States, config, runner.Suite.GlobalVariables)
+	evalCtx, ctxDiags := getEvalContextForTest(runner.States, runner.ExpandedRunOutputs, config, run, runner.Suite.GlobalVariables)
 	diags = diags.Append(ctxDiags)
 
 	variables, variableDiags := buildInputVariablesForTest(run, file, config, runner.Suite.GlobalVariables, evalCtx)
@@ -922,12 +1284,12 @@ func (runner *TestFileRunner) wait(ctx *tofu.Context, runningCtx context.Context
 		states := make(map[*moduletest.Run]*states.State)
 		states[nil] = runner.States[MainStateIdentifier].State
 		for key, module := range runner.States {
-			if key == MainStateIdentifier {
+			if key == MainStateIdentifier || module.Run == nil {
 				continue
 			}
 			states[module.Run] = module.State
 		}
-		runner.Suite.View.FatalInterruptSummary(run, file, states, created)
+		runner.View.FatalInterruptSummary(run, file, states, created)
 
 		cancelled = true
 		go ctx.Stop()
@@ -1000,7 +1362,7 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 
 			var diags tfdiags.Diagnostics
 			diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Inconsistent state", fmt.Sprintf("Found inconsistent state while cleaning up %s. This is a bug in OpenTofu - please report it", file.Name)))
-			runner.Suite.View.DestroySummary(diags, nil, file, state.State)
+			runner.View.DestroySummary(diags, nil, file, state.State)
 			continue
 		}
 
@@ -1029,7 +1391,7 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 
 		isMainState := state.Run.Config.Module == nil
 		if isMainState {
-			runConfig = runner.Suite.Config
+			runConfig = runner.Config
 		} else {
 			runConfig = state.Run.Config.ConfigUnderTest
 		}
@@ -1043,10 +1405,10 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 			updated, destroyDiags = runner.destroy(ctx, runConfig, state.State, state.Run, file)
 			diags = diags.Append(destroyDiags)
 		}
-		runner.Suite.View.DestroySummary(diags, state.Run, file, updated)
+		runner.View.DestroySummary(diags, state.Run, file, updated)
 
 		if updated.HasManagedResourceInstanceObjects() {
-			views.SaveErroredTestStateFile(updated, state.Run, file, runner.Suite.View)
+			views.SaveErroredTestStateFile(updated, state.Run, file, runner.View)
 		}
 		reset()
 	}
@@ -1105,12 +1467,15 @@ func buildInputVariablesForTest(run *moduletest.Run, file *moduletest.File, conf
 // getEvalContextForTest constructs an hcl.EvalContext based on the provided map of
 // TestFileState instances, configuration and global variables.
 // It extracts the relevant information from the input parameters to create a
-// context suitable for HCL evaluation.
-func getEvalContextForTest(states map[string]*TestFileState, config *configs.Config, globals map[string]backend.UnparsedVariableValue) (*hcl.EvalContext, tfdiags.Diagnostics) {
+// context suitable for HCL evaluation. The runs expanded from a run block with
+// for_each are available as an object with an attribute for each key, from
+// expandedOutputs. If the run was itself expanded from a run block with
+// for_each, the context also includes each.key and each.value.
+func getEvalContextForTest(states map[string]*TestFileState, expandedOutputs map[string]map[string]cty.Value, config *configs.Config, run *moduletest.Run, globals map[string]backend.UnparsedVariableValue) (*hcl.EvalContext, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	runCtx := make(map[string]cty.Value)
 	for _, state := range states {
-		if state.Run == nil {
+		if state.Run == nil || state.Run.Key != addrs.NoKey {
 			continue
 		}
 		outputs := make(map[string]cty.Value)
@@ -1120,6 +1485,9 @@ func getEvalContextForTest(states map[string]*TestFileState, config *configs.Con
 		}
 		runCtx[state.Run.Name] = cty.ObjectVal(outputs)
 	}
+	for name, runs := range expandedOutputs {
+		runCtx[name] = cty.ObjectVal(runs)
+	}
 
 	// If the variable is referenced in the tfvars file or TF_VAR_ environment variable, then lookup the value
 	// in global variables; otherwise, assign the default value.
@@ -1137,6 +1505,13 @@ func getEvalContextForTest(states map[string]*TestFileState, config *configs.Con
 			"var": cty.ObjectVal(varCtx),
 		},
 	}
+
+	if run != nil && run.Key != addrs.NoKey {
+		ctx.Variables["each"] = cty.ObjectVal(map[string]cty.Value{
+			"key":   run.Repetition.EachKey,
+			"value": run.Repetition.EachValue,
+		})
+	}
 	return ctx, diags
 }
 
@@ -1219,7 +1594,7 @@ func parseAndApplyDefaultValues(unparsedVariables map[string]backend.UnparsedVar
 // the config which must be called so the config can be reused going forward.
 func (runner *TestFileRunner) prepareInputVariablesForAssertions(config *configs.Config, run *moduletest.Run, file *moduletest.File, globals map[string]backend.UnparsedVariableValue) (tofu.InputValues, func(), tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
-	ctx, ctxDiags := getEvalContextForTest(runner.States, config, globals)
+	ctx, ctxDiags := getEvalContextForTest(runner.States, runner.ExpandedRunOutputs, config, run, globals)
 	diags = diags.Append(ctxDiags)
 
 	variables := make(map[string]backend.UnparsedVariableValue)
@@ -0,0 +1,393 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+const (
+	// snapshotDirectory is the directory, alongside each test file, that
+	// holds the plan snapshots for its run blocks.
+	snapshotDirectory = "__snapshots__"
+
+	snapshotFormatVersion = "1.0"
+
+	snapshotUnknownValue   = "(known after apply)"
+	snapshotSensitiveValue = "(sensitive value)"
+)
+
+// planSnapshot is the content of a snapshot file. It holds the planned
+// changes to resources and output values, keyed by address so the order is
+// stable.
+type planSnapshot struct {
+	FormatVersion   string                            `json:"format_version"`
+	ResourceChanges map[string]snapshotResourceChange `json:"resource_changes"`
+	OutputChanges   map[string]snapshotChange         `json:"output_changes"`
+}
+
+type snapshotResourceChange struct {
+	snapshotChange
+	ActionReason string `json:"action_reason,omitempty"`
+}
+
+type snapshotChange struct {
+	Actions []string    `json:"actions"`
+	Before  interface{} `json:"before"`
+	After   interface{} `json:"after"`
+}
+
+// snapshotPath returns the path of the snapshot for the given run block, in
+// a directory alongside the test file named after it. Runs expanded from a run
+// block with for_each are named after the run block and their key, without
+// quotes. The key is escaped as in a URL path, so that keys such as "a/b" or
+// "../x" can't place the snapshot outside of the snapshot directory.
+func snapshotPath(file *moduletest.File, run *moduletest.Run) string {
+	name := filepath.Base(file.Name)
+	for _, ext := range []string{".tftest.hcl", ".tftest.json", ".tofutest.hcl", ".tofutest.json"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	runName := run.Name
+	if key, ok := run.Key.(addrs.StringKey); ok {
+		runName = fmt.Sprintf("%s[%s]", run.Config.Name, url.PathEscape(string(key)))
+	}
+	return filepath.Join(filepath.Dir(file.Name), snapshotDirectory, name, runName+".json")
+}
+
+// renderPlanSnapshot renders the resource and output changes in plan as a
+// snapshot. Unknown and sensitive values are replaced with placeholders, so
+// the snapshot doesn't depend on values that are only known after apply and
+// doesn't store secrets.
+func renderPlanSnapshot(plan *plans.Plan, schemas *tofu.Schemas) ([]byte, error) {
+	snapshot := planSnapshot{
+		FormatVersion:   snapshotFormatVersion,
+		ResourceChanges: make(map[string]snapshotResourceChange),
+		OutputChanges:   make(map[string]snapshotChange),
+	}
+
+	resources, err := jsonplan.MarshalResourceChanges(plan.Changes.Resources, schemas)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		change, err := normalizeSnapshotChange(resource.Change)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render change for %s: %w", resource.Address, err)
+		}
+
+		key := resource.Address
+		if resource.Deposed != "" {
+			key = fmt.Sprintf("%s (deposed %s)", key, resource.Deposed)
+		}
+		snapshot.ResourceChanges[key] = snapshotResourceChange{
+			snapshotChange: change,
+			ActionReason:   resource.ActionReason,
+		}
+	}
+
+	outputs, err := jsonplan.MarshalOutputChanges(plan.Changes)
+	if err != nil {
+		return nil, err
+	}
+	for name, output := range outputs {
+		change, err := normalizeSnapshotChange(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render change for output.%s: %w", name, err)
+		}
+		snapshot.OutputChanges[name] = change
+	}
+
+	src, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(src, '\n'), nil
+}
+
+func normalizeSnapshotChange(change jsonplan.Change) (snapshotChange, error) {
+	var ret snapshotChange
+	ret.Actions = change.Actions
+
+	var before, after, afterUnknown, beforeSensitive, afterSensitive interface{}
+	for _, field := range []struct {
+		raw json.RawMessage
+		dst *interface{}
+	}{
+		{change.Before, &before},
+		{change.After, &after},
+		{change.AfterUnknown, &afterUnknown},
+		{change.BeforeSensitive, &beforeSensitive},
+		{change.AfterSensitive, &afterSensitive},
+	} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.dst); err != nil {
+			return ret, err
+		}
+	}
+
+	ret.Before = maskSnapshotValue(before, beforeSensitive, snapshotSensitiveValue)
+	ret.After = maskSnapshotValue(maskSnapshotValue(after, afterUnknown, snapshotUnknownValue), afterSensitive, snapshotSensitiveValue)
+	return ret, nil
+}
+
+// maskSnapshotValue replaces the parts of value marked as true in mask with
+// the given placeholder. The mask mirrors the structure of the value, as in
+// the after_unknown and sensitive fields of the JSON plan.
+func maskSnapshotValue(value interface{}, mask interface{}, placeholder string) interface{} {
+	switch mask := mask.(type) {
+	case bool:
+		if mask {
+			return placeholder
+		}
+		return value
+	case map[string]interface{}:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			if value != nil {
+				return value
+			}
+			// Unknown attributes are missing from the value entirely.
+			obj = make(map[string]interface{})
+		}
+		ret := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			ret[k] = v
+		}
+		for k, m := range mask {
+			masked := maskSnapshotValue(ret[k], m, placeholder)
+			if masked != nil {
+				ret[k] = masked
+			}
+		}
+		return ret
+	case []interface{}:
+		list, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		ret := make([]interface{}, len(list))
+		for i, v := range list {
+			if i < len(mask) {
+				ret[i] = maskSnapshotValue(v, mask[i], placeholder)
+				continue
+			}
+			ret[i] = v
+		}
+		return ret
+	default:
+		return value
+	}
+}
+
+// diffSnapshot returns a line for each difference between the expected and
+// actual snapshots, describing the path to the value that changed.
+func diffSnapshot(expected, actual []byte) ([]string, error) {
+	var want, got interface{}
+	if err := json.Unmarshal(expected, &want); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if err := json.Unmarshal(actual, &got); err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	diffSnapshotValue("", want, got, &diffs)
+	return diffs, nil
+}
+
+func diffSnapshotValue(path string, want, got interface{}, diffs *[]string) {
+	if reflect.DeepEqual(want, got) {
+		return
+	}
+
+	wantObj, wantIsObj := want.(map[string]interface{})
+	gotObj, gotIsObj := got.(map[string]interface{})
+	if wantIsObj && gotIsObj {
+		keys := make(map[string]struct{})
+		for k := range wantObj {
+			keys[k] = struct{}{}
+		}
+		for k := range gotObj {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			child := snapshotChildPath(path, k)
+			w, inWant := wantObj[k]
+			g, inGot := gotObj[k]
+			switch {
+			case !inWant:
+				*diffs = append(*diffs, fmt.Sprintf("+ %s: %s", child, compactSnapshotValue(g)))
+			case !inGot:
+				*diffs = append(*diffs, fmt.Sprintf("- %s: %s", child, compactSnapshotValue(w)))
+			default:
+				diffSnapshotValue(child, w, g, diffs)
+			}
+		}
+		return
+	}
+
+	wantList, wantIsList := want.([]interface{})
+	gotList, gotIsList := got.([]interface{})
+	if wantIsList && gotIsList && len(wantList) == len(gotList) {
+		for i := range wantList {
+			diffSnapshotValue(fmt.Sprintf("%s[%d]", path, i), wantList[i], gotList[i], diffs)
+		}
+		return
+	}
+
+	*diffs = append(*diffs, fmt.Sprintf("~ %s: %s => %s", path, compactSnapshotValue(want), compactSnapshotValue(got)))
+}
+
+func snapshotChildPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	if strings.ContainsAny(key, ".[]\" ") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	return path + "." + key
+}
+
+func compactSnapshotValue(value interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// checkSnapshot compares the plan for the given run block against its
+// snapshot, or writes the snapshot if it doesn't exist yet or the user asked
+// for the snapshots to be updated. A mismatch fails the run block.
+func (runner *TestFileRunner) checkSnapshot(tfCtx *tofu.Context, config *configs.Config, run *moduletest.Run, file *moduletest.File, plan *plans.Plan) {
+	schemas, diags := tfCtx.Schemas(config, plan.PlannedState)
+	if !diags.HasErrors() {
+		diags = diags.Append(runner.compareSnapshot(run, file, plan, schemas))
+	}
+
+	run.Diagnostics = run.Diagnostics.Append(diags)
+	if diags.HasErrors() {
+		run.Status = run.Status.Merge(moduletest.Fail)
+	}
+}
+
+func (runner *TestFileRunner) compareSnapshot(run *moduletest.Run, file *moduletest.File, plan *plans.Plan, schemas *tofu.Schemas) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	filename := snapshotPath(file, run)
+	actual, err := renderPlanSnapshot(plan, schemas)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to render plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not render the plan for %s as a snapshot: %s.", run.Name, err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return diags
+	}
+
+	expected, err := os.ReadFile(filename)
+	switch {
+	case err == nil && !runner.Suite.UpdateSnapshots:
+		differences, err := diffSnapshot(expected, actual)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid plan snapshot",
+				Detail:   fmt.Sprintf("OpenTofu could not read the snapshot at %s: %s. Run tofu test with -update-snapshots to replace it.", filename, err),
+				Subject:  run.Config.SnapshotDeclRange.Ptr(),
+			})
+			return diags
+		}
+		if len(differences) > 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Plan does not match snapshot",
+				Detail:   fmt.Sprintf("The plan differs from the snapshot at %s:\n\n  %s\n\nIf the change is expected, run tofu test with -update-snapshots to update the snapshot.", filename, strings.Join(differences, "\n  ")),
+				Subject:  run.Config.SnapshotDeclRange.Ptr(),
+			})
+		}
+		return diags
+	case errors.Is(err, fs.ErrNotExist) && !runner.Suite.UpdateSnapshots:
+		// A missing snapshot fails the run, so that a snapshot that was
+		// never committed or was deleted can't pass unnoticed, for example
+		// in CI. Snapshots are only ever created on request.
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing plan snapshot",
+			Detail:   fmt.Sprintf("There is no snapshot for %s at %s. Run tofu test with -update-snapshots to create it, then check the snapshot is correct and commit it alongside the test file.", run.Name, filename),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return diags
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not read the snapshot at %s: %s.", filename, err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return diags
+	case err == nil && bytes.Equal(expected, actual):
+		// Nothing to update.
+		return diags
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to write plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not create the snapshot directory for %s: %s.", filename, err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return diags
+	}
+	if err := os.WriteFile(filename, actual, 0644); err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to write plan snapshot",
+			Detail:   fmt.Sprintf("OpenTofu could not write the snapshot to %s: %s.", filename, err),
+			Subject:  run.Config.SnapshotDeclRange.Ptr(),
+		})
+		return diags
+	}
+
+	diags = diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Plan snapshot written",
+		Detail:   fmt.Sprintf("OpenTofu wrote the plan for %s to %s. Check the snapshot is correct and commit it alongside the test file.", run.Name, filename),
+		Subject:  run.Config.SnapshotDeclRange.Ptr(),
+	})
+	return diags
+}
@@ -6,7 +6,11 @@
 package command
 
 import (
+	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -17,7 +21,9 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	testing_command "github.com/opentofu/opentofu/internal/command/testing"
 	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/moduletest"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/terminal"
 )
@@ -579,6 +585,518 @@ variable.
 	}
 }
 
+func TestTest_JUnitXML(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "junit_xml")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-junit-xml=results.xml", "-no-color"})
+	output := done(t)
+
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+
+	if !strings.Contains(output.Stdout(), "2 passed, 2 failed, 1 skipped.") {
+		t.Errorf("unexpected human output:\n%s", output.Stdout())
+	}
+
+	raw, err := os.ReadFile("results.xml")
+	if err != nil {
+		t.Fatalf("failed to read JUnit XML report: %s", err)
+	}
+
+	// Durations vary between executions, so we normalize them.
+	actual := regexp.MustCompile(`time="[0-9.]+"`).ReplaceAllString(string(raw), `time="0.000"`)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5" failures="1" errors="1" skipped="1" time="0.000">
+  <testsuite name="main.tftest.hcl" tests="3" failures="1" errors="0" skipped="0" time="0.000">
+    <testcase name="pass" classname="main.tftest.hcl" time="0.000"></testcase>
+    <testcase name="fail" classname="main.tftest.hcl" time="0.000">
+      <failure message="Test assertion failed" type="failure"><![CDATA[Error: Test assertion failed
+
+  on main.tftest.hcl line 18, in run "fail":
+  18:     condition     = test_resource.foo.value == "zap"
+    ├────────────────
+    │ test_resource.foo.value is "bar"
+
+invalid value
+]]></failure>
+    </testcase>
+    <testcase name="expected_failure" classname="main.tftest.hcl" time="0.000"></testcase>
+  </testsuite>
+  <testsuite name="other.tftest.hcl" tests="2" failures="0" errors="1" skipped="1" time="0.000">
+    <testcase name="error" classname="other.tftest.hcl" time="0.000">
+      <error message="Unknown variable" type="error"><![CDATA[Error: Unknown variable
+
+  on other.tftest.hcl line 7, in run "error":
+   7:     condition     = test_resource.missing.value == "bar"
+
+There is no variable named "test_resource".
+
+Error: Reference to undeclared resource
+
+  on other.tftest.hcl line 7, in run "error":
+   7:     condition     = test_resource.missing.value == "bar"
+
+A managed resource "test_resource" "missing" has not been declared in the root module.
+]]></error>
+    </testcase>
+    <testcase name="skipped" classname="other.tftest.hcl" time="0.000">
+      <skipped message="Skipped due to an earlier error in the test file"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+	if diff := cmp.Diff(expected, actual); len(diff) > 0 {
+		t.Errorf("unexpected JUnit XML report:\n%s", diff)
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+	}
+}
+
+func TestTest_Parallelism(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "parallel")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-parallelism=3", "-no-color"})
+	output := done(t)
+
+	if code != 0 {
+		t.Errorf("expected status code 0 but got %d: %s", code, output.All())
+	}
+
+	// The files can complete in any order, but the output for each file must
+	// be printed as a single block.
+	stdout := output.Stdout()
+	for _, name := range []string{"mocked_a", "mocked_b", "mocked_c"} {
+		block := fmt.Sprintf("%s.tftest.hcl... pass\n  run \"first\"... pass\n  run \"second\"... pass\n", name)
+		if !strings.Contains(stdout, block) {
+			t.Errorf("expected output to contain:\n%s\nbut got:\n%s", block, stdout)
+		}
+	}
+	for _, name := range []string{"keyed_a", "keyed_b"} {
+		block := fmt.Sprintf("%s.tftest.hcl... pass\n  run \"create\"... pass\n  run \"separate\"... pass\n  run \"shared\"... pass\n  run \"isolated\"... pass\n", name)
+		if !strings.Contains(stdout, block) {
+			t.Errorf("expected output to contain:\n%s\nbut got:\n%s", block, stdout)
+		}
+	}
+	if block := "real.tftest.hcl... pass\n  run \"real\"... pass\n"; !strings.Contains(stdout, block) {
+		t.Errorf("expected output to contain:\n%s\nbut got:\n%s", block, stdout)
+	}
+	if !strings.Contains(stdout, "Success! 15 passed, 0 failed.") {
+		t.Errorf("unexpected summary:\n%s", stdout)
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+	}
+}
+
+func TestIsolatedTestFile(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "parallel")), td)
+	defer testChdir(t, td)()
+
+	c := &TestCommand{}
+	config, diags := c.loadConfigWithTests(".", "tests")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	tcs := map[string]bool{
+		"mocked_a.tftest.hcl": true,
+		"real.tftest.hcl":     false,
+	}
+	for name, want := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file := &moduletest.File{
+				Config: config.Module.Tests[name],
+				Name:   name,
+			}
+			for _, run := range file.Config.Runs {
+				file.Runs = append(file.Runs, &moduletest.Run{Config: run, Name: run.Name})
+			}
+
+			if got := isolatedTestFile(config, file); got != want {
+				t.Errorf("expected %t but got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestStateKeyIsolatedTestFile(t *testing.T) {
+	newFile := func(name string, keys ...string) *moduletest.File {
+		file := &moduletest.File{
+			Name:   name,
+			Config: &configs.TestFile{},
+		}
+		for ix, key := range keys {
+			file.Config.Runs = append(file.Config.Runs, &configs.TestRun{
+				Name:     fmt.Sprintf("run%d", ix),
+				StateKey: key,
+			})
+		}
+		return file
+	}
+
+	files := []*moduletest.File{
+		newFile("own.tftest.hcl", "own", "own-other"),
+		newFile("shared_a.tftest.hcl", "shared", "shared-a"),
+		newFile("shared_b.tftest.hcl", "shared"),
+		newFile("partial.tftest.hcl", "partial", ""),
+		newFile("empty.tftest.hcl"),
+	}
+	stateKeyFiles := make(map[string]int)
+	for _, file := range files {
+		for key := range testFileStateKeys(file) {
+			stateKeyFiles[key]++
+		}
+	}
+
+	want := map[string]bool{
+		"own.tftest.hcl":      true,
+		"shared_a.tftest.hcl": false,
+		"shared_b.tftest.hcl": false,
+		"partial.tftest.hcl":  false,
+		"empty.tftest.hcl":    false,
+	}
+	for _, file := range files {
+		if got := stateKeyIsolatedTestFile(file, stateKeyFiles); got != want[file.Name] {
+			t.Errorf("%s: expected %t but got %t", file.Name, want[file.Name], got)
+		}
+	}
+}
+
+func TestTest_ForEach(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "for_each")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-no-color"})
+	output := done(t)
+
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d: %s", code, output.All())
+	}
+
+	expectedOut := `main.tftest.hcl... fail
+  run "valid"[0]... pass
+  run "valid"[1]... pass
+  run "valid"[2]... pass
+  run "invalid"["eight"]... pass
+  run "invalid"["six"]... pass
+  run "apply"["x"]... pass
+  run "apply"["y"]... fail
+  run "downstream"... pass
+
+Failure! 7 passed, 1 failed.
+`
+	if diff := cmp.Diff(expectedOut, output.Stdout()); len(diff) > 0 {
+		t.Errorf("unexpected output:\n%s", diff)
+	}
+
+	if !strings.Contains(output.Stderr(), "expected x but got y") {
+		t.Errorf("expected the failing run to report its assertion, got:\n%s", output.Stderr())
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+	}
+}
+
+func TestTest_Snapshot(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "snapshot")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+
+	run := func(args ...string) (int, *terminal.TestOutput) {
+		view, done := testView(t)
+		c := &TestCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(provider.Provider),
+				View:             view,
+			},
+		}
+		code := c.Run(append(args, "-no-color"))
+		return code, done(t)
+	}
+
+	snapshot := filepath.Join("__snapshots__", "main", "plan.json")
+
+	// A missing snapshot fails the run, without writing the snapshot.
+	code, output := run("-var=input=bar")
+	if code != 1 {
+		t.Fatalf("expected status code 1 but got %d: %s", code, output.All())
+	}
+	if !strings.Contains(output.All(), "Missing plan snapshot") {
+		t.Errorf("expected a missing snapshot error, got:\n%s", output.All())
+	}
+	if _, err := os.Stat(snapshot); !os.IsNotExist(err) {
+		t.Fatalf("expected no snapshot to be written, got error %v", err)
+	}
+
+	// Updating the snapshots creates the snapshot.
+	code, output = run("-var=input=bar", "-update-snapshots")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d: %s", code, output.All())
+	}
+	if !strings.Contains(output.All(), "Plan snapshot written") {
+		t.Errorf("expected the snapshot to be written, got:\n%s", output.All())
+	}
+
+	raw, err := os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %s", err)
+	}
+	expected := `{
+  "format_version": "1.0",
+  "resource_changes": {
+    "test_resource.foo": {
+      "actions": [
+        "create"
+      ],
+      "before": null,
+      "after": {
+        "id": "(known after apply)",
+        "interrupt_count": null,
+        "value": "bar"
+      }
+    },
+    "test_resource.secret": {
+      "actions": [
+        "create"
+      ],
+      "before": null,
+      "after": {
+        "id": "(known after apply)",
+        "interrupt_count": null,
+        "value": "(sensitive value)"
+      }
+    }
+  },
+  "output_changes": {
+    "value": {
+      "actions": [
+        "create"
+      ],
+      "before": null,
+      "after": "bar"
+    }
+  }
+}
+`
+	if diff := cmp.Diff(expected, string(raw)); len(diff) > 0 {
+		t.Fatalf("unexpected snapshot:\n%s", diff)
+	}
+
+	// A matching snapshot passes without rewriting the file.
+	code, output = run("-var=input=bar")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d: %s", code, output.All())
+	}
+	if strings.Contains(output.All(), "Plan snapshot written") {
+		t.Errorf("expected the snapshot to be left alone, got:\n%s", output.All())
+	}
+
+	// A different plan fails, and describes the differences.
+	code, output = run("-var=input=baz")
+	if code != 1 {
+		t.Fatalf("expected status code 1 but got %d: %s", code, output.All())
+	}
+	for _, want := range []string{
+		"Plan does not match snapshot",
+		`~ output_changes.value.after: "bar" => "baz"`,
+		`~ resource_changes["test_resource.foo"].after.value: "bar" => "baz"`,
+	} {
+		if !strings.Contains(output.All(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output.All())
+		}
+	}
+
+	// Updating the snapshots accepts the new plan.
+	code, output = run("-var=input=baz", "-update-snapshots")
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d: %s", code, output.All())
+	}
+	raw, err = os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %s", err)
+	}
+	if !strings.Contains(string(raw), `"baz"`) {
+		t.Errorf("expected the snapshot to be updated, got:\n%s", raw)
+	}
+}
+
+func TestSnapshotPath(t *testing.T) {
+	file := &moduletest.File{Name: filepath.Join("tests", "main.tftest.hcl")}
+	dir := filepath.Join("tests", "__snapshots__", "main")
+
+	tcs := map[string]struct {
+		run  *moduletest.Run
+		want string
+	}{
+		"no key": {
+			run:  &moduletest.Run{Name: "plan", Config: &configs.TestRun{Name: "plan"}, Key: addrs.NoKey},
+			want: filepath.Join(dir, "plan.json"),
+		},
+		"int key": {
+			run:  &moduletest.Run{Name: "plan[0]", Config: &configs.TestRun{Name: "plan"}, Key: addrs.IntKey(0)},
+			want: filepath.Join(dir, "plan[0].json"),
+		},
+		"string key": {
+			run:  &moduletest.Run{Name: `plan["a"]`, Config: &configs.TestRun{Name: "plan"}, Key: addrs.StringKey("a")},
+			want: filepath.Join(dir, "plan[a].json"),
+		},
+		"separator in key": {
+			run:  &moduletest.Run{Name: `plan["a/b"]`, Config: &configs.TestRun{Name: "plan"}, Key: addrs.StringKey("a/b")},
+			want: filepath.Join(dir, "plan[a%2Fb].json"),
+		},
+		"parent directory in key": {
+			run:  &moduletest.Run{Name: `plan["../../x"]`, Config: &configs.TestRun{Name: "plan"}, Key: addrs.StringKey("../../x")},
+			want: filepath.Join(dir, "plan[..%2F..%2Fx].json"),
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			if got := snapshotPath(file, tc.run); got != tc.want {
+				t.Errorf("wrong path\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTest_Coverage(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "coverage")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-coverage-out=coverage.info", "-no-color"})
+	output := done(t)
+
+	if code != 0 {
+		t.Errorf("expected status code 0 but got %d: %s", code, output.All())
+	}
+
+	expected := `main.tftest.hcl... pass
+  run "plan"... pass
+  run "apply"... pass
+  run "reapply"... pass
+
+Success! 3 passed, 0 failed.
+
+Coverage: 4 of 5 objects covered (80.0%).
+  resource   1/2
+  output     1/1
+  variable   1/1
+  check      1/1
+
+Not covered:
+  - test_resource.bar (main.tf:14)
+`
+	if diff := cmp.Diff(expected, output.Stdout()); len(diff) > 0 {
+		t.Errorf("unexpected output:\n%s", diff)
+	}
+
+	raw, err := os.ReadFile("coverage.info")
+	if err != nil {
+		t.Fatalf("failed to read coverage report: %s", err)
+	}
+
+	expectedLCOV := `TN:
+SF:main.tf
+FN:1,var.input
+FN:10,test_resource.foo
+FN:14,test_resource.bar
+FN:19,output.value
+FN:23,check.value
+FNDA:3,var.input
+FNDA:2,test_resource.foo
+FNDA:0,test_resource.bar
+FNDA:2,output.value
+FNDA:3,check.value
+FNF:5
+FNH:4
+DA:1,3
+DA:2,3
+DA:3,3
+DA:4,3
+DA:5,3
+DA:6,3
+DA:7,3
+DA:8,3
+DA:10,2
+DA:11,2
+DA:12,2
+DA:14,0
+DA:15,0
+DA:16,0
+DA:17,0
+DA:19,2
+DA:20,2
+DA:21,2
+DA:23,3
+DA:24,3
+DA:25,3
+DA:26,3
+DA:27,3
+DA:28,3
+LF:24
+LH:20
+end_of_record
+`
+	if diff := cmp.Diff(expectedLCOV, string(raw)); len(diff) > 0 {
+		t.Errorf("unexpected coverage report:\n%s", diff)
+	}
+}
+
 func TestTest_Verbose(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath(path.Join("test", "plan_then_apply")), td)
@@ -0,0 +1,388 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// testWatchDebounce is how long the watcher waits after a change for further
+// changes before re-running the tests, so saving several files at once only
+// runs the tests once.
+const testWatchDebounce = 250 * time.Millisecond
+
+// watchTests waits for changes to the configuration or the test files, and
+// re-runs the affected test files after each change until the user interrupts
+// it. The given code is the exit code of the initial run, and the most recent
+// exit code is returned when watching stops.
+//
+// The context options, and so the provider plugins, are reused between runs
+// while the configuration and variables are reloaded for each run.
+func (c *TestCommand) watchTests(ctx context.Context, args *arguments.Test, opts *tofu.ContextOpts, config *configs.Config, code int) int {
+	watchView := views.NewTestWatch(c.View)
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		var diags tfdiags.Diagnostics
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to watch for changes",
+			fmt.Sprintf("OpenTofu could not watch the configuration for changes: %s.", err)))
+		c.View.Diagnostics(diags)
+		return 1
+	}
+	defer fsWatcher.Close()
+
+	watcher := newTestWatcher(fsWatcher, args)
+	watcher.watch(config, args.TestDirectory)
+	watchView.Watching(code == 0)
+
+	for {
+		changed, ok := watcher.wait(c.ShutdownCh)
+		if !ok {
+			return code
+		}
+
+		// The configuration loader caches the files it has parsed, so we need
+		// a new one to see the changes.
+		c.configLoader = nil
+
+		view := c.newTestView(args)
+
+		variables, diags := c.collectVariableValuesWithTests(args.TestDirectory)
+		newConfig, configDiags := c.loadConfigWithTests(".", args.TestDirectory)
+		diags = diags.Append(configDiags)
+		if diags.HasErrors() {
+			watchView.Changed(watcher.relative(changed), nil)
+			view.Diagnostics(nil, nil, diags)
+			code = 1
+			watchView.Watching(false)
+			continue
+		}
+		config = newConfig
+		watcher.watch(config, args.TestDirectory)
+
+		files := affectedTestFiles(config, changed)
+		if len(args.Filter) > 0 {
+			files = filterTestFiles(files, args.Filter)
+		}
+		watchView.Changed(watcher.relative(changed), files)
+		if len(files) == 0 {
+			watchView.Watching(code == 0)
+			continue
+		}
+
+		suite, fileDiags := testSuite(config, files)
+		diags = diags.Append(fileDiags)
+		view.Diagnostics(nil, nil, diags)
+		if fileDiags.HasErrors() {
+			code = 1
+			watchView.Watching(false)
+			continue
+		}
+
+		var interrupted bool
+		code, interrupted = c.runTestSuite(ctx, args, view, suite, config, variables, opts)
+		if interrupted {
+			return code
+		}
+		watchView.Watching(code == 0)
+	}
+}
+
+// testWatcher tracks the directories watched for changes by tofu test -watch.
+type testWatcher struct {
+	watcher *fsnotify.Watcher
+
+	// wd is the working directory, used to report changed files relative
+	// to it.
+	wd string
+
+	// dirs records the directories that are already watched.
+	dirs map[string]bool
+
+	// ignore holds the files written by the test command itself, which must
+	// not trigger another run.
+	ignore map[string]bool
+}
+
+func newTestWatcher(watcher *fsnotify.Watcher, args *arguments.Test) *testWatcher {
+	wd, _ := os.Getwd()
+	w := &testWatcher{
+		watcher: watcher,
+		wd:      wd,
+		dirs:    make(map[string]bool),
+		ignore:  make(map[string]bool),
+	}
+	for _, path := range []string{args.JUnitXMLFile, args.CoverageOut, "errored_test.tfstate"} {
+		if path == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			w.ignore[abs] = true
+		}
+	}
+	return w
+}
+
+// watch starts watching the source directories of all the modules used by
+// the configuration and its tests, the test directory, and any directories
+// within them. Directories that are already watched are skipped.
+func (w *testWatcher) watch(config *configs.Config, testDirectory string) {
+	roots := []string{testDirectory}
+	for dir := range testSourceDirs(config) {
+		roots = append(roots, dir)
+	}
+	for _, file := range config.Module.Tests {
+		for _, run := range file.Runs {
+			if run.ConfigUnderTest != nil {
+				for dir := range testSourceDirs(run.ConfigUnderTest) {
+					roots = append(roots, dir)
+				}
+			}
+		}
+	}
+
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil || w.ignored(abs) {
+			continue
+		}
+		w.add(abs)
+	}
+}
+
+// add watches dir and the directories within it.
+func (w *testWatcher) add(dir string) {
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if path != dir && w.ignored(path) {
+			return filepath.SkipDir
+		}
+		if w.dirs[path] {
+			return nil
+		}
+		if err := w.watcher.Add(path); err != nil {
+			log.Printf("[WARN] TestCommand: failed to watch %s: %s", path, err)
+			return nil
+		}
+		log.Printf("[TRACE] TestCommand: watching %s", path)
+		w.dirs[path] = true
+		return nil
+	})
+}
+
+// ignored reports whether changes to path should be ignored. This covers the
+// files written by the test command, installed modules and providers, and
+// hidden and temporary files written by editors.
+func (w *testWatcher) ignored(path string) bool {
+	if w.ignore[path] {
+		return true
+	}
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == snapshotDirectory || part == ".terraform" {
+			return true
+		}
+	}
+
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") ||
+		strings.HasSuffix(name, "~") ||
+		strings.HasSuffix(name, ".swp") ||
+		strings.HasSuffix(name, ".swx") ||
+		name == "4913" // Vim creates this file to check it can write to the directory.
+}
+
+// wait blocks until there are changes to the watched files, and returns the
+// changed files once no further changes have happened for testWatchDebounce.
+// It returns false if the user interrupted OpenTofu, or the watcher stopped.
+func (w *testWatcher) wait(shutdownCh <-chan struct{}) ([]string, bool) {
+	changed := make(map[string]bool)
+	var settled <-chan time.Time
+
+	for {
+		select {
+		case <-shutdownCh:
+			return nil, false
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil, false
+			}
+			if event.Op == fsnotify.Chmod || w.ignored(event.Name) {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.add(event.Name)
+				}
+			}
+
+			log.Printf("[TRACE] TestCommand: %s", event)
+			changed[event.Name] = true
+			settled = time.After(testWatchDebounce)
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil, false
+			}
+			log.Printf("[WARN] TestCommand: error watching for changes: %s", err)
+
+		case <-settled:
+			var paths []string
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			return paths, true
+		}
+	}
+}
+
+// relative returns the given paths relative to the working directory, for
+// display.
+func (w *testWatcher) relative(paths []string) []string {
+	var ret []string
+	for _, path := range paths {
+		if rel, err := filepath.Rel(w.wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		ret = append(ret, path)
+	}
+	return ret
+}
+
+// affectedTestFiles returns the names of the test files in config that should
+// run again after the given files changed, in order.
+//
+// A test file is affected if it changed itself, or if one of the changed
+// files belongs to a module used by the test file. A changed file belongs to
+// the module with the closest source directory containing it, so changes
+// within the directory of a nested local module only affect the tests that
+// use that module. Changes outside of every module affect all the test files.
+func affectedTestFiles(config *configs.Config, changed []string) []string {
+	type testFile struct {
+		path string
+		dirs map[string]bool
+	}
+
+	all := make(map[string]bool)
+	files := make(map[string]testFile)
+	for name, file := range config.Module.Tests {
+		path, _ := filepath.Abs(name)
+		dirs := make(map[string]bool)
+		usesRoot := len(file.Runs) == 0
+		for _, run := range file.Runs {
+			if run.ConfigUnderTest == nil {
+				usesRoot = true
+				continue
+			}
+			for dir := range testSourceDirs(run.ConfigUnderTest) {
+				dirs[dir] = true
+			}
+		}
+		if usesRoot {
+			for dir := range testSourceDirs(config) {
+				dirs[dir] = true
+			}
+		}
+		for dir := range dirs {
+			all[dir] = true
+		}
+		files[name] = testFile{path: path, dirs: dirs}
+	}
+
+	var affected []string
+	for name, file := range files {
+		for _, change := range changed {
+			if change == file.path {
+				affected = append(affected, name)
+				break
+			}
+			if isTestFilePath(change) {
+				// Test files only affect themselves.
+				continue
+			}
+
+			owner := owningDir(change, all)
+			if owner == "" || file.dirs[owner] {
+				affected = append(affected, name)
+				break
+			}
+		}
+	}
+	sort.Strings(affected)
+	return affected
+}
+
+// testSourceDirs returns the absolute source directories of all the modules
+// within config.
+func testSourceDirs(config *configs.Config) map[string]bool {
+	dirs := make(map[string]bool)
+	config.DeepEach(func(c *configs.Config) {
+		if c.Module == nil {
+			return
+		}
+		if dir, err := filepath.Abs(c.Module.SourceDir); err == nil {
+			dirs[dir] = true
+		}
+	})
+	return dirs
+}
+
+// owningDir returns the deepest of the given directories that contains path,
+// or an empty string if none of them do.
+func owningDir(path string, dirs map[string]bool) string {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if dirs[dir] {
+			return dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+func isTestFilePath(path string) bool {
+	for _, ext := range []string{".tftest.hcl", ".tftest.json", ".tofutest.hcl", ".tofutest.json"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTestFiles returns the files that are also in filter.
+func filterTestFiles(files []string, filter []string) []string {
+	allowed := make(map[string]bool)
+	for _, name := range filter {
+		allowed[name] = true
+	}
+
+	var ret []string
+	for _, file := range files {
+		if allowed[file] {
+			ret = append(ret, file)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,176 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	testing_command "github.com/opentofu/opentofu/internal/command/testing"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+// initWatchFixture copies the watch fixture into a temporary directory and
+// initializes it, returning the meta to use for the test command.
+func initWatchFixture(t *testing.T) (Meta, func(*testing.T) *terminal.TestOutput) {
+	t.Helper()
+
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "watch")), td)
+	t.Cleanup(testChdir(t, td))
+
+	provider := testing_command.NewProvider(nil)
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.0.0"},
+	})
+	t.Cleanup(close)
+
+	streams, done := terminal.StreamsForTesting(t)
+	ui := new(cli.MockUi)
+	meta := Meta{
+		testingOverrides: metaOverridesForProvider(provider.Provider),
+		Ui:               ui,
+		View:             views.NewView(streams),
+		Streams:          streams,
+		ProviderSource:   providerSource,
+	}
+
+	init := &InitCommand{
+		Meta: meta,
+	}
+	if code := init.Run(nil); code != 0 {
+		t.Fatalf("expected status code 0 but got %d: %s", code, ui.ErrorWriter)
+	}
+	return meta, done
+}
+
+func TestAffectedTestFiles(t *testing.T) {
+	meta, _ := initWatchFixture(t)
+
+	c := &TestCommand{Meta: meta}
+	config, diags := c.loadConfigWithTests(".", "tests")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	abs := func(name string) string {
+		path, err := filepath.Abs(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tcs := map[string]struct {
+		changed []string
+		want    []string
+	}{
+		"root module": {
+			changed: []string{abs("main.tf")},
+			want:    []string{"root.tftest.hcl"},
+		},
+		"nested module": {
+			changed: []string{abs("child/main.tf")},
+			want:    []string{"root.tftest.hcl"},
+		},
+		"alternate module": {
+			changed: []string{abs("other/main.tf")},
+			want:    []string{"other.tftest.hcl"},
+		},
+		"test file": {
+			changed: []string{abs("other.tftest.hcl")},
+			want:    []string{"other.tftest.hcl"},
+		},
+		"multiple": {
+			changed: []string{abs("child/main.tf"), abs("other.tftest.hcl")},
+			want:    []string{"other.tftest.hcl", "root.tftest.hcl"},
+		},
+		"outside every module": {
+			changed: []string{filepath.Join(filepath.Dir(abs(".")), "terraform.tfvars")},
+			want:    []string{"other.tftest.hcl", "root.tftest.hcl"},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := affectedTestFiles(config, tc.changed)
+			if diff := cmp.Diff(tc.want, got); len(diff) > 0 {
+				t.Errorf("unexpected test files:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTest_Watch(t *testing.T) {
+	meta, done := initWatchFixture(t)
+
+	shutdownCh := make(chan struct{})
+	meta.ShutdownCh = shutdownCh
+
+	c := &TestCommand{Meta: meta}
+
+	result := make(chan int)
+	go func() {
+		result <- c.Run([]string{"-watch", "-no-color", "-junit-xml=report.xml"})
+	}()
+
+	// The report is written at the end of each run, and only lists the test
+	// files that ran.
+	waitForReport := func(want string) string {
+		t.Helper()
+		deadline := time.Now().Add(30 * time.Second)
+		for time.Now().Before(deadline) {
+			raw, err := os.ReadFile("report.xml")
+			if err == nil && strings.Contains(string(raw), want) {
+				return string(raw)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for a report containing %q", want)
+		return ""
+	}
+
+	waitForReport(`<testsuites tests="2"`)
+	if err := os.Remove("report.xml"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Give the watcher a moment to start, then change the alternate module.
+	time.Sleep(500 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join("other", "main.tf"), []byte("resource \"test_resource\" \"bar\" {\n  value = \"baz\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := waitForReport(`<testsuites tests="1"`)
+	if !strings.Contains(report, `name="other.tftest.hcl"`) || strings.Contains(report, `name="root.tftest.hcl"`) {
+		t.Errorf("expected only other.tftest.hcl to run again, got:\n%s", report)
+	}
+
+	close(shutdownCh)
+	code := <-result
+	output := done(t)
+
+	if code != 1 {
+		t.Errorf("expected status code 1 after the failing run but got %d", code)
+	}
+
+	for _, want := range []string{
+		"Tests passing. Watching for changes, press Ctrl-C to exit.",
+		"Changed: other/main.tf\nRunning 1 test file.",
+		`run "other"... fail`,
+		"Tests failing. Watching for changes, press Ctrl-C to exit.",
+	} {
+		if !strings.Contains(output.Stdout(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output.Stdout())
+		}
+	}
+}
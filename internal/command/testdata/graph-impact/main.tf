@@ -0,0 +1,19 @@
+resource "test_instance" "vpc" {
+  ami = "bar"
+}
+
+locals {
+  vpc_id = test_instance.vpc.id
+}
+
+resource "test_instance" "subnet" {
+  ami = local.vpc_id
+}
+
+resource "test_instance" "unrelated" {
+  ami = "baz"
+}
+
+output "subnet" {
+  value = test_instance.subnet.ami
+}
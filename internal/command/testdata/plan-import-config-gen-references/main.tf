@@ -0,0 +1,7 @@
+resource "test_instance" "foo" {
+}
+
+import {
+  id = "child"
+  to = test_instance.child
+}
@@ -0,0 +1,6 @@
+terraform {
+  backend "sqlite" {
+    path           = "state.db"
+    enable_history = true
+  }
+}
@@ -0,0 +1,28 @@
+variable "input" {
+  type = string
+
+  validation {
+    condition     = length(var.input) > 0
+    error_message = "input must not be empty"
+  }
+}
+
+resource "test_resource" "foo" {
+  value = var.input
+}
+
+resource "test_resource" "bar" {
+  count = var.input == "bar" ? 1 : 0
+  value = var.input
+}
+
+output "value" {
+  value = test_resource.foo.value
+}
+
+check "value" {
+  assert {
+    condition     = test_resource.foo.value != ""
+    error_message = "value is empty"
+  }
+}
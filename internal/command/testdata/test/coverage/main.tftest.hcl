@@ -0,0 +1,26 @@
+variables {
+  input = "foo"
+}
+
+run "plan" {
+  command = plan
+
+  assert {
+    condition     = test_resource.foo.value == "foo"
+    error_message = "invalid value"
+  }
+}
+
+run "apply" {
+  assert {
+    condition     = output.value == "foo"
+    error_message = "invalid value"
+  }
+}
+
+run "reapply" {
+  assert {
+    condition     = output.value == "foo"
+    error_message = "invalid value"
+  }
+}
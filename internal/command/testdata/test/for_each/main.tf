@@ -0,0 +1,16 @@
+variable "input" {
+  type = string
+
+  validation {
+    condition     = length(var.input) <= 5
+    error_message = "input must be at most 5 characters"
+  }
+}
+
+resource "test_resource" "foo" {
+  value = var.input
+}
+
+output "value" {
+  value = test_resource.foo.value
+}
@@ -0,0 +1,53 @@
+run "valid" {
+  command  = plan
+  for_each = ["a", "abc", "abcde"]
+
+  variables {
+    input = each.value
+  }
+
+  assert {
+    condition     = test_resource.foo.value == each.value
+    error_message = "value for case ${each.key} is wrong"
+  }
+}
+
+run "invalid" {
+  command = plan
+  for_each = {
+    six   = "abcdef"
+    eight = "abcdefgh"
+  }
+
+  variables {
+    input = each.value
+  }
+
+  expect_failures = [
+    var.input,
+  ]
+}
+
+run "apply" {
+  for_each = toset(["x", "y"])
+
+  variables {
+    input = each.key
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "x"
+    error_message = "expected x but got ${test_resource.foo.value}"
+  }
+}
+
+run "downstream" {
+  variables {
+    input = "${run.apply["x"].value}${run.apply["y"].value}"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "xy"
+    error_message = "expected xy but got ${test_resource.foo.value}"
+  }
+}
@@ -0,0 +1,14 @@
+variable "input" {
+  type = string
+}
+
+resource "test_resource" "foo" {
+  value = var.input
+
+  lifecycle {
+    postcondition {
+      condition     = self.value != "bad"
+      error_message = "value must not be bad"
+    }
+  }
+}
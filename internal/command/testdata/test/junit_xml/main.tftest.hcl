@@ -0,0 +1,33 @@
+run "pass" {
+  variables {
+    input = "bar"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "bar"
+    error_message = "invalid value"
+  }
+}
+
+run "fail" {
+  variables {
+    input = "bar"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "zap"
+    error_message = "invalid value"
+  }
+}
+
+run "expected_failure" {
+  command = plan
+
+  variables {
+    input = "bad"
+  }
+
+  expect_failures = [
+    test_resource.foo,
+  ]
+}
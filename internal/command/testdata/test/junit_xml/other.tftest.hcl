@@ -0,0 +1,16 @@
+run "error" {
+  variables {
+    input = "bar"
+  }
+
+  assert {
+    condition     = test_resource.missing.value == "bar"
+    error_message = "invalid value"
+  }
+}
+
+run "skipped" {
+  variables {
+    input = "bar"
+  }
+}
@@ -0,0 +1,47 @@
+run "create" {
+  state_key = "a"
+
+  variables {
+    input = "a"
+  }
+}
+
+run "separate" {
+  state_key = "a-separate"
+
+  variables {
+    input = "a"
+  }
+}
+
+# The plans below set the value of the resource to the ID of the resource
+# created by the create run, and compare it against the ID of the resource
+# in their state.
+
+run "shared" {
+  state_key = "a"
+  command   = plan
+
+  variables {
+    input = run.create.id
+  }
+
+  assert {
+    condition     = test_resource.foo.id == test_resource.foo.value
+    error_message = "the run doesn't share the state of the create run"
+  }
+}
+
+run "isolated" {
+  state_key = "a-separate"
+  command   = plan
+
+  variables {
+    input = run.create.id
+  }
+
+  assert {
+    condition     = test_resource.foo.id != test_resource.foo.value
+    error_message = "the run shares the state of another state key"
+  }
+}
@@ -0,0 +1,47 @@
+run "create" {
+  state_key = "b"
+
+  variables {
+    input = "b"
+  }
+}
+
+run "separate" {
+  state_key = "b-separate"
+
+  variables {
+    input = "b"
+  }
+}
+
+# The plans below set the value of the resource to the ID of the resource
+# created by the create run, and compare it against the ID of the resource
+# in their state.
+
+run "shared" {
+  state_key = "b"
+  command   = plan
+
+  variables {
+    input = run.create.id
+  }
+
+  assert {
+    condition     = test_resource.foo.id == test_resource.foo.value
+    error_message = "the run doesn't share the state of the create run"
+  }
+}
+
+run "isolated" {
+  state_key = "b-separate"
+  command   = plan
+
+  variables {
+    input = run.create.id
+  }
+
+  assert {
+    condition     = test_resource.foo.id != test_resource.foo.value
+    error_message = "the run shares the state of another state key"
+  }
+}
@@ -0,0 +1,11 @@
+variable "input" {
+  type = string
+}
+
+resource "test_resource" "foo" {
+  value = var.input
+}
+
+output "id" {
+  value = test_resource.foo.id
+}
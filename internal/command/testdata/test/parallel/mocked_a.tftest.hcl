@@ -0,0 +1,23 @@
+mock_provider "test" {}
+
+run "first" {
+  variables {
+    input = "a"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "a"
+    error_message = "invalid value"
+  }
+}
+
+run "second" {
+  variables {
+    input = "aa"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "aa"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,23 @@
+mock_provider "test" {}
+
+run "first" {
+  variables {
+    input = "b"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "b"
+    error_message = "invalid value"
+  }
+}
+
+run "second" {
+  variables {
+    input = "bb"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "bb"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,23 @@
+mock_provider "test" {}
+
+run "first" {
+  variables {
+    input = "c"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "c"
+    error_message = "invalid value"
+  }
+}
+
+run "second" {
+  variables {
+    input = "cc"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "cc"
+    error_message = "invalid value"
+  }
+}
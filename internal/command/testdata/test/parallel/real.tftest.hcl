@@ -0,0 +1,10 @@
+run "real" {
+  variables {
+    input = "real"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "real"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,20 @@
+variable "input" {
+  type = string
+}
+
+variable "secret" {
+  type      = string
+  sensitive = true
+}
+
+resource "test_resource" "foo" {
+  value = var.input
+}
+
+resource "test_resource" "secret" {
+  value = var.secret
+}
+
+output "value" {
+  value = test_resource.foo.value
+}
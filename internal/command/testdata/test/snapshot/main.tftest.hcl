@@ -0,0 +1,8 @@
+variables {
+  secret = "hunter2"
+}
+
+run "plan" {
+  command  = plan
+  snapshot = true
+}
@@ -0,0 +1,7 @@
+module "child" {
+  source = "./child"
+}
+
+resource "test_resource" "foo" {
+  value = module.child.value
+}
@@ -0,0 +1,12 @@
+run "other" {
+  command = plan
+
+  module {
+    source = "./other"
+  }
+
+  assert {
+    condition     = test_resource.bar.value == "bar"
+    error_message = "wrong value"
+  }
+}
@@ -0,0 +1,8 @@
+run "root" {
+  command = plan
+
+  assert {
+    condition     = test_resource.foo.value == "child"
+    error_message = "wrong value"
+  }
+}
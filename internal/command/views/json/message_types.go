@@ -18,6 +18,7 @@ const (
 	MessagePlannedChange MessageType = "planned_change"
 	MessageChangeSummary MessageType = "change_summary"
 	MessageOutputs       MessageType = "outputs"
+	MessagePolicyResults MessageType = "policy_results"
 
 	// Hook-driven messages
 	MessageApplyStart        MessageType = "apply_start"
@@ -40,4 +41,5 @@ const (
 	MessageTestSummary   MessageType = "test_summary"
 	MessageTestCleanup   MessageType = "test_cleanup"
 	MessageTestInterrupt MessageType = "test_interrupt"
+	MessageTestCoverage  MessageType = "test_coverage"
 )
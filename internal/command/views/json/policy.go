@@ -0,0 +1,50 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/policy"
+)
+
+// PolicyResults summarizes the outcome of evaluating local policies against a
+// plan.
+type PolicyResults struct {
+	Passed          int             `json:"passed"`
+	AdvisoryFailed  int             `json:"advisory_failed"`
+	MandatoryFailed int             `json:"mandatory_failed"`
+	Policies        []PolicyOutcome `json:"policies"`
+}
+
+type PolicyOutcome struct {
+	Name             string `json:"name"`
+	Description      string `json:"description,omitempty"`
+	EnforcementLevel string `json:"enforcement_level"`
+	Passed           bool   `json:"passed"`
+	Message          string `json:"message,omitempty"`
+}
+
+func NewPolicyResults(results *policy.Results) *PolicyResults {
+	ret := &PolicyResults{
+		Passed:          results.Passed(),
+		AdvisoryFailed:  results.Failed(policy.Advisory),
+		MandatoryFailed: results.Failed(policy.Mandatory),
+		Policies:        make([]PolicyOutcome, 0, len(results.Outcomes)),
+	}
+	for _, outcome := range results.Outcomes {
+		ret.Policies = append(ret.Policies, PolicyOutcome{
+			Name:             outcome.Policy.Name,
+			Description:      outcome.Policy.Description,
+			EnforcementLevel: string(outcome.Policy.EnforcementLevel),
+			Passed:           outcome.Passed,
+			Message:          outcome.Message,
+		})
+	}
+	return ret
+}
+
+func (r *PolicyResults) String() string {
+	return fmt.Sprintf("Policies: %d passed, %d advisory failed, %d mandatory failed.", r.Passed, r.AdvisoryFailed, r.MandatoryFailed)
+}
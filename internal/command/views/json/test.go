@@ -34,6 +34,18 @@ type TestSuiteSummary struct {
 	Skipped int        `json:"skipped"`
 }
 
+type TestCoverage struct {
+	Covered   int                            `json:"covered"`
+	Total     int                            `json:"total"`
+	Kinds     map[string]TestCoverageSummary `json:"kinds"`
+	Uncovered []string                       `json:"uncovered,omitempty"`
+}
+
+type TestCoverageSummary struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
 type TestFileCleanup struct {
 	FailedResources []TestFailedResource `json:"failed_resources"`
 }
@@ -130,6 +130,14 @@ func (v *JSONView) Outputs(outputs json.Outputs) {
 	)
 }
 
+func (v *JSONView) PolicyResults(results *json.PolicyResults) {
+	v.log.Info(
+		results.String(),
+		"type", json.MessagePolicyResults,
+		"policies", results,
+	)
+}
+
 // Output is designed for supporting command.WrappedUi
 func (v *JSONView) Output(message string) {
 	v.log.Info(message, "type", "output")
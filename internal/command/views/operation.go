@@ -19,6 +19,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/views/json"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/policy"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -35,6 +36,7 @@ type Operation interface {
 	PlannedChange(change *plans.ResourceInstanceChangeSrc)
 	Plan(plan *plans.Plan, schemas *tofu.Schemas)
 	PlanNextStep(planPath string, genConfigPath string)
+	PolicyResults(results *policy.Results)
 
 	Diagnostics(diags tfdiags.Diagnostics)
 }
@@ -171,6 +173,33 @@ func (v *OperationHuman) PlanNextStep(planPath string, genConfigPath string) {
 	}
 }
 
+// PolicyResults shows the outcome of each local policy, followed by the error
+// messages of the policies that failed.
+func (v *OperationHuman) PolicyResults(results *policy.Results) {
+	if len(results.Outcomes) == 0 {
+		// Every policy failed to evaluate, which the diagnostics explain.
+		return
+	}
+	summary := json.NewPolicyResults(results)
+	v.view.streams.Println(v.view.colorize.Color(fmt.Sprintf("\n[reset][bold]Policy evaluation:[reset] %d passed, %d advisory failed, %d mandatory failed.\n",
+		summary.Passed, summary.AdvisoryFailed, summary.MandatoryFailed)))
+
+	for _, outcome := range results.Outcomes {
+		switch {
+		case outcome.Passed:
+			v.view.streams.Println(v.view.colorize.Color(fmt.Sprintf("  [green]%c[reset] %s", policyPassedSymbol, outcome.Policy.Name)))
+		case outcome.Policy.EnforcementLevel == policy.Advisory:
+			v.view.streams.Println(v.view.colorize.Color(fmt.Sprintf("  [yellow]%c[reset] %s [dim](advisory)", policyAdvisorySymbol, outcome.Policy.Name)))
+		default:
+			v.view.streams.Println(v.view.colorize.Color(fmt.Sprintf("  [red]%c[reset] %s [dim](mandatory)", policyFailedSymbol, outcome.Policy.Name)))
+		}
+		if !outcome.Passed {
+			msg := format.WordWrap(outcome.Message, v.view.outputColumns()-6)
+			v.view.streams.Println("      " + strings.ReplaceAll(msg, "\n", "\n      "))
+		}
+	}
+}
+
 func (v *OperationHuman) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -284,10 +313,20 @@ func (v *OperationJSON) PlannedChange(change *plans.ResourceInstanceChangeSrc) {
 func (v *OperationJSON) PlanNextStep(planPath string, genConfigPath string) {
 }
 
+func (v *OperationJSON) PolicyResults(results *policy.Results) {
+	v.view.PolicyResults(json.NewPolicyResults(results))
+}
+
 func (v *OperationJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
 
+const (
+	policyPassedSymbol   = '\u2713'
+	policyAdvisorySymbol = '\u24d8'
+	policyFailedSymbol   = '\u00d7'
+)
+
 const fatalInterrupt = `
 Two interrupts received. Exiting immediately. Note that data loss may have occurred.
 `
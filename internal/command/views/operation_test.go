@@ -16,6 +16,7 @@ import (
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/lang/globalref"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/policy"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/terminal"
@@ -476,6 +477,26 @@ func TestOperation_planNextStepInAutomation(t *testing.T) {
 	}
 }
 
+func TestOperation_policyResults(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewOperation(arguments.ViewHuman, false, NewView(streams))
+
+	v.PolicyResults(testPolicyResults())
+
+	want := `
+Policy evaluation: 1 passed, 1 advisory failed, 1 mandatory failed.
+
+  ✓ tags
+  ⓘ cost (advisory)
+      The plan is too expensive.
+  × public (mandatory)
+      Buckets must not be public.
+`
+	if got := done(t).Stdout(); got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 // Test all the trivial OperationJSON methods together. Y'know, for brevity.
 // This test is not a realistic stream of messages.
 func TestOperationJSON_logs(t *testing.T) {
@@ -524,6 +545,68 @@ func TestOperationJSON_logs(t *testing.T) {
 	testJSONViewOutputEquals(t, done(t).Stdout(), want)
 }
 
+func TestOperationJSON_policyResults(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := &OperationJSON{view: NewJSONView(NewView(streams))}
+
+	v.PolicyResults(testPolicyResults())
+
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "Policies: 1 passed, 1 advisory failed, 1 mandatory failed.",
+			"@module":  "tofu.ui",
+			"type":     "policy_results",
+			"policies": map[string]interface{}{
+				"passed":           float64(1),
+				"advisory_failed":  float64(1),
+				"mandatory_failed": float64(1),
+				"policies": []interface{}{
+					map[string]interface{}{
+						"name":              "tags",
+						"description":       "Resources must be tagged.",
+						"enforcement_level": "mandatory",
+						"passed":            true,
+					},
+					map[string]interface{}{
+						"name":              "cost",
+						"enforcement_level": "advisory",
+						"passed":            false,
+						"message":           "The plan is too expensive.",
+					},
+					map[string]interface{}{
+						"name":              "public",
+						"enforcement_level": "mandatory",
+						"passed":            false,
+						"message":           "Buckets must not be public.",
+					},
+				},
+			},
+		},
+	}
+
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
+func testPolicyResults() *policy.Results {
+	return &policy.Results{
+		Outcomes: []*policy.Outcome{
+			{
+				Policy: &policy.Policy{Name: "tags", Description: "Resources must be tagged.", EnforcementLevel: policy.Mandatory},
+				Passed: true,
+			},
+			{
+				Policy:  &policy.Policy{Name: "cost", EnforcementLevel: policy.Advisory},
+				Message: "The plan is too expensive.",
+			},
+			{
+				Policy:  &policy.Policy{Name: "public", EnforcementLevel: policy.Mandatory},
+				Message: "Buckets must not be public.",
+			},
+		},
+	}
+}
+
 // This is a fairly circular test, but it's such a rarely executed code path
 // that I think it's probably still worth having. We're not testing against
 // a fixed state JSON output because this test ought not fail just because
@@ -37,6 +37,10 @@ func NewShow(vt arguments.ViewType, view *View) Show {
 		return &ShowJSON{view: view}
 	case arguments.ViewHuman:
 		return &ShowHuman{view: view}
+	case arguments.ViewMarkdown:
+		return &ShowDocument{view: view, format: jsonformat.DocumentMarkdown}
+	case arguments.ViewHTML:
+		return &ShowDocument{view: view, format: jsonformat.DocumentHTML}
 	default:
 		panic(fmt.Sprintf("unknown view type %v", vt))
 	}
@@ -170,3 +174,70 @@ func (v *ShowJSON) Display(config *configs.Config, plan *plans.Plan, planJSON *c
 func (v *ShowJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
+
+// ShowDocument renders a plan as a markdown or HTML document, for display
+// outside of a terminal such as in a pull request comment.
+type ShowDocument struct {
+	view   *View
+	format jsonformat.DocumentFormat
+}
+
+var _ Show = (*ShowDocument)(nil)
+
+func (v *ShowDocument) Display(config *configs.Config, plan *plans.Plan, planJSON *cloudplan.RemotePlanJSON, stateFile *statefile.File, schemas *tofu.Schemas) int {
+	renderer := jsonformat.Renderer{
+		Streams:             v.view.streams,
+		RunningInAutomation: v.view.runningInAutomation,
+	}
+
+	if planJSON != nil {
+		if !planJSON.Redacted {
+			v.view.streams.Eprintf("Didn't get renderable JSON plan format for %s display", v.format)
+			return 1
+		}
+		p := jsonformat.Plan{}
+		r := bytes.NewReader(planJSON.JSONBytes)
+		if err := json.NewDecoder(r).Decode(&p); err != nil {
+			v.view.streams.Eprintf("Couldn't decode renderable JSON plan format: %s", err)
+			return 1
+		}
+		renderer.RenderDocumentPlan(p, planJSON.Mode, v.format, jsonformat.DefaultDocumentMaxLength, planJSON.Qualities...)
+		return 0
+	}
+
+	if plan == nil {
+		v.view.streams.Eprintf("The %s format is only available for plan files.\n", v.format)
+		return 1
+	}
+
+	outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
+	if err != nil {
+		v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
+		return 1
+	}
+
+	jplan := jsonformat.Plan{
+		PlanFormatVersion:     jsonplan.FormatVersion,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		OutputChanges:         outputs,
+		ResourceChanges:       changed,
+		ResourceDrift:         drift,
+		ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
+		RelevantAttributes:    attrs,
+	}
+
+	var opts []plans.Quality
+	if !plan.CanApply() {
+		opts = append(opts, plans.NoChanges)
+	}
+	if plan.Errored {
+		opts = append(opts, plans.Errored)
+	}
+
+	renderer.RenderDocumentPlan(jplan, plan.UIMode, v.format, jsonformat.DefaultDocumentMaxLength, opts...)
+	return 0
+}
+
+func (v *ShowDocument) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
@@ -118,6 +118,67 @@ func TestShowHuman(t *testing.T) {
 	}
 }
 
+func TestShowDocument(t *testing.T) {
+	testCases := map[string]struct {
+		viewType   arguments.ViewType
+		plan       *plans.Plan
+		stateFile  *statefile.File
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		"markdown plan file": {
+			arguments.ViewMarkdown,
+			testPlan(t),
+			nil,
+			0,
+			"<details><summary>test_resource.foo will be created</summary>",
+			"",
+		},
+		"html plan file": {
+			arguments.ViewHTML,
+			testPlan(t),
+			nil,
+			0,
+			"<details><summary>test_resource.foo will be created</summary>",
+			"",
+		},
+		"statefile": {
+			arguments.ViewMarkdown,
+			nil,
+			&statefile.File{
+				Serial:  0,
+				Lineage: "fake-for-testing",
+				State:   testState(),
+			},
+			1,
+			"",
+			"The markdown format is only available for plan files.",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			view := NewView(streams)
+			view.Configure(&arguments.View{NoColor: true})
+			v := NewShow(testCase.viewType, view)
+
+			code := v.Display(nil, testCase.plan, nil, testCase.stateFile, testSchemas())
+			if code != testCase.wantCode {
+				t.Errorf("expected %d return code, got %d", testCase.wantCode, code)
+			}
+
+			output := done(t)
+			if got := output.Stdout(); !strings.Contains(got, testCase.wantStdout) {
+				t.Errorf("unexpected output\ngot: %s\nwant: %s", got, testCase.wantStdout)
+			}
+			if got := output.Stderr(); !strings.Contains(got, testCase.wantStderr) {
+				t.Errorf("unexpected error output\ngot: %s\nwant: %s", got, testCase.wantStderr)
+			}
+		})
+	}
+}
+
 func TestShowJSON(t *testing.T) {
 	unredactedPath := "../testdata/show-json/basic-create/output.json"
 	unredactedPlanJson, err := os.ReadFile(unredactedPath)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/mitchellh/colorstring"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/format"
 	"github.com/opentofu/opentofu/internal/command/jsonformat"
@@ -42,6 +43,11 @@ type Test interface {
 	// completed status.
 	Conclusion(suite *moduletest.Suite)
 
+	// Coverage prints out a summary of the objects in the configuration that
+	// were exercised by the tests. This is only called if the user requested
+	// coverage, after Conclusion.
+	Coverage(coverage *moduletest.Coverage)
+
 	// File prints out the summary for an entire test file.
 	File(file *moduletest.File)
 
@@ -135,13 +141,41 @@ func (t *TestHuman) Conclusion(suite *moduletest.Suite) {
 	}
 }
 
+func (t *TestHuman) Coverage(coverage *moduletest.Coverage) {
+	summary := coverage.Summary()
+	total := summary[""]
+
+	t.view.streams.Println()
+	t.view.streams.Printf("Coverage: %d of %d objects covered (%s).\n", total.Covered, total.Total, coveragePercentage(total))
+	for _, kind := range moduletest.CoverageKinds {
+		if summary[kind].Total == 0 {
+			continue
+		}
+		t.view.streams.Printf("  %-10s %d/%d\n", kind, summary[kind].Covered, summary[kind].Total)
+	}
+
+	var uncovered []string
+	for _, item := range coverage.Sorted() {
+		if item.Status == moduletest.NotCovered {
+			uncovered = append(uncovered, fmt.Sprintf("  - %s (%s:%d)", item.Addr, item.Range.Filename, item.Range.Start.Line))
+		}
+	}
+	if len(uncovered) > 0 {
+		t.view.streams.Println()
+		t.view.streams.Println("Not covered:")
+		for _, line := range uncovered {
+			t.view.streams.Println(line)
+		}
+	}
+}
+
 func (t *TestHuman) File(file *moduletest.File) {
 	t.view.streams.Printf("%s... %s\n", file.Name, colorizeTestStatus(file.Status, t.view.colorize))
 	t.Diagnostics(nil, file, file.Diagnostics)
 }
 
 func (t *TestHuman) Run(run *moduletest.Run, file *moduletest.File) {
-	t.view.streams.Printf("  run %q... %s\n", run.Name, colorizeTestStatus(run.Status, t.view.colorize))
+	t.view.streams.Printf("  run %s... %s\n", quotedRunName(run), colorizeTestStatus(run.Status, t.view.colorize))
 
 	if run.Verbose != nil {
 		// We're going to be more verbose about what we print, here's the plan
@@ -271,7 +305,13 @@ func (t *TestHuman) FatalInterruptSummary(run *moduletest.Run, file *moduletest.
 			continue
 		}
 
-		t.view.streams.Eprint(format.WordWrap(fmt.Sprintf("\nOpenTofu has already created the following resources for %q from %q:\n", run.Name, run.Config.Module.Source), t.view.errorColumns()))
+		// A run block with a state key can keep its state apart from the
+		// main state without loading an alternate module.
+		module := "the module under test"
+		if run.Config.Module != nil {
+			module = fmt.Sprintf("%q", run.Config.Module.Source)
+		}
+		t.view.streams.Eprint(format.WordWrap(fmt.Sprintf("\nOpenTofu has already created the following resources for %q from %s:\n", run.Name, module), t.view.errorColumns()))
 		for _, resource := range state.AllResourceInstanceObjectAddrs() {
 			if resource.DeposedKey != states.NotDeposed {
 				t.view.streams.Eprintf("  - %s (%s)\n", resource.Instance, resource.DeposedKey)
@@ -390,6 +430,32 @@ func (t *TestJSON) Conclusion(suite *moduletest.Suite) {
 		json.MessageTestSummary, summary)
 }
 
+func (t *TestJSON) Coverage(coverage *moduletest.Coverage) {
+	summary := coverage.Summary()
+
+	report := json.TestCoverage{
+		Covered: summary[""].Covered,
+		Total:   summary[""].Total,
+		Kinds:   make(map[string]json.TestCoverageSummary),
+	}
+	for _, kind := range moduletest.CoverageKinds {
+		report.Kinds[string(kind)] = json.TestCoverageSummary{
+			Covered: summary[kind].Covered,
+			Total:   summary[kind].Total,
+		}
+	}
+	for _, item := range coverage.Sorted() {
+		if item.Status == moduletest.NotCovered {
+			report.Uncovered = append(report.Uncovered, item.Addr.String())
+		}
+	}
+
+	t.view.log.Info(
+		fmt.Sprintf("Coverage: %d of %d objects covered (%s).", report.Covered, report.Total, coveragePercentage(summary[""])),
+		"type", json.MessageTestCoverage,
+		json.MessageTestCoverage, report)
+}
+
 func (t *TestJSON) File(file *moduletest.File) {
 	t.view.log.Info(
 		fmt.Sprintf("%s... %s", file.Name, testStatus(file.Status)),
@@ -401,7 +467,7 @@ func (t *TestJSON) File(file *moduletest.File) {
 
 func (t *TestJSON) Run(run *moduletest.Run, file *moduletest.File) {
 	t.view.log.Info(
-		fmt.Sprintf("  %q... %s", run.Name, testStatus(run.Status)),
+		fmt.Sprintf("  %s... %s", quotedRunName(run), testStatus(run.Status)),
 		"type", json.MessageTestRun,
 		json.MessageTestRun, json.TestRunStatus{file.Name, run.Name, json.ToTestStatus(run.Status)},
 		"@testfile", file.Name,
@@ -571,6 +637,22 @@ func testStatus(status moduletest.Status) string {
 	}
 }
 
+// quotedRunName returns the name of the run as it appears in the test file,
+// followed by the key of the run for run blocks that set for_each.
+func quotedRunName(run *moduletest.Run) string {
+	if run.Key == addrs.NoKey {
+		return fmt.Sprintf("%q", run.Name)
+	}
+	return fmt.Sprintf("%q%s", run.Config.Name, run.Key)
+}
+
+func coveragePercentage(summary moduletest.CoverageSummary) string {
+	if summary.Total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(summary.Covered)*100/float64(summary.Total))
+}
+
 // SaveErroredTestStateFile is a helper function to invoked in DestroySummary
 // to store the state to errored_test.tfstate and handle associated diagnostics and errors with this operation
 func SaveErroredTestStateFile(state *states.State, run *moduletest.Run, file *moduletest.File, view Test) {
@@ -579,6 +661,21 @@ func SaveErroredTestStateFile(state *states.State, run *moduletest.Run, file *mo
 	stateFile := statemgr.NewStateFile()
 	stateFile.State = state
 
+	if buffer, ok := view.(*TestBuffer); ok {
+		// The messages about the state file must stay in order with the rest
+		// of the buffered output, so we write the state when it is flushed.
+		buffer.record(func(view Test) {
+			SaveErroredTestStateFile(state, run, file, view)
+		})
+		return
+	}
+
+	if multi, ok := view.(TestMulti); ok && len(multi) > 0 {
+		// The first view is the primary one, and is responsible for telling
+		// the user about the state file.
+		view = multi[0]
+	}
+
 	//creating an operation to invoke EmergencyDumpState()
 	var op Operation
 	switch v := view.(type) {
@@ -0,0 +1,94 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// TestBuffer is a Test view that holds back the output for a single test file
+// until Flush is called, and then replays it to the wrapped view.
+//
+// This is used when multiple test files execute concurrently, so the output
+// of each file is printed as a single block instead of being interleaved with
+// the output of the other files. The lock is shared between all the buffers
+// writing to the same view.
+type TestBuffer struct {
+	view Test
+	lock *sync.Mutex
+
+	calls []func(view Test)
+}
+
+var _ Test = (*TestBuffer)(nil)
+
+func NewTestBuffer(view Test, lock *sync.Mutex) *TestBuffer {
+	return &TestBuffer{
+		view: view,
+		lock: lock,
+	}
+}
+
+// Flush replays everything recorded so far to the wrapped view.
+func (b *TestBuffer) Flush() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, call := range b.calls {
+		call(b.view)
+	}
+	b.calls = nil
+}
+
+func (b *TestBuffer) record(call func(view Test)) {
+	b.calls = append(b.calls, call)
+}
+
+func (b *TestBuffer) Abstract(suite *moduletest.Suite) {
+	b.record(func(view Test) { view.Abstract(suite) })
+}
+
+func (b *TestBuffer) Conclusion(suite *moduletest.Suite) {
+	b.record(func(view Test) { view.Conclusion(suite) })
+}
+
+func (b *TestBuffer) Coverage(coverage *moduletest.Coverage) {
+	b.record(func(view Test) { view.Coverage(coverage) })
+}
+
+func (b *TestBuffer) File(file *moduletest.File) {
+	b.record(func(view Test) { view.File(file) })
+}
+
+func (b *TestBuffer) Run(run *moduletest.Run, file *moduletest.File) {
+	b.record(func(view Test) { view.Run(run, file) })
+}
+
+func (b *TestBuffer) DestroySummary(diags tfdiags.Diagnostics, run *moduletest.Run, file *moduletest.File, state *states.State) {
+	b.record(func(view Test) { view.DestroySummary(diags, run, file, state) })
+}
+
+func (b *TestBuffer) Diagnostics(run *moduletest.Run, file *moduletest.File, diags tfdiags.Diagnostics) {
+	b.record(func(view Test) { view.Diagnostics(run, file, diags) })
+}
+
+func (b *TestBuffer) Interrupted() {
+	b.record(func(view Test) { view.Interrupted() })
+}
+
+func (b *TestBuffer) FatalInterrupt() {
+	b.record(func(view Test) { view.FatalInterrupt() })
+}
+
+func (b *TestBuffer) FatalInterruptSummary(run *moduletest.Run, file *moduletest.File, states map[*moduletest.Run]*states.State, created []*plans.ResourceInstanceChangeSrc) {
+	// OpenTofu may exit shortly after a fatal interrupt, so we print this
+	// and anything held back before it straight away.
+	b.record(func(view Test) { view.FatalInterruptSummary(run, file, states, created) })
+	b.Flush()
+}
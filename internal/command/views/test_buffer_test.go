@@ -0,0 +1,50 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+func TestTestBuffer(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTest(arguments.ViewHuman, NewView(streams))
+
+	var lock sync.Mutex
+	first := NewTestBuffer(view, &lock)
+	second := NewTestBuffer(view, &lock)
+
+	one := &moduletest.File{Name: "one.tftest.hcl", Status: moduletest.Pass, Runs: []*moduletest.Run{{Name: "a", Status: moduletest.Pass}}}
+	two := &moduletest.File{Name: "two.tftest.hcl", Status: moduletest.Fail, Runs: []*moduletest.Run{{Name: "b", Status: moduletest.Fail}}}
+
+	first.File(one)
+	second.File(two)
+	second.Run(two.Runs[0], two)
+	first.Run(one.Runs[0], one)
+
+	if output := done(t).All(); len(output) > 0 {
+		t.Fatalf("expected no output before flushing but got:\n%s", output)
+	}
+
+	streams, done = terminal.StreamsForTesting(t)
+	view = NewTest(arguments.ViewHuman, NewView(streams))
+	first.view, second.view = view, view
+
+	second.Flush()
+	first.Flush()
+
+	expected := `two.tftest.hcl... fail
+  run "b"... fail
+one.tftest.hcl... pass
+  run "a"... pass
+`
+	if actual := done(t).Stdout(); actual != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// TestMulti is a Test view that forwards every call to each of the views it
+// contains, in order.
+//
+// This allows additional reports, such as a JUnit XML file, to be produced
+// alongside the normal human or JSON output.
+type TestMulti []Test
+
+var _ Test = (TestMulti)(nil)
+
+func (m TestMulti) Abstract(suite *moduletest.Suite) {
+	for _, view := range m {
+		view.Abstract(suite)
+	}
+}
+
+func (m TestMulti) Conclusion(suite *moduletest.Suite) {
+	for _, view := range m {
+		view.Conclusion(suite)
+	}
+}
+
+func (m TestMulti) Coverage(coverage *moduletest.Coverage) {
+	for _, view := range m {
+		view.Coverage(coverage)
+	}
+}
+
+func (m TestMulti) File(file *moduletest.File) {
+	for _, view := range m {
+		view.File(file)
+	}
+}
+
+func (m TestMulti) Run(run *moduletest.Run, file *moduletest.File) {
+	for _, view := range m {
+		view.Run(run, file)
+	}
+}
+
+func (m TestMulti) DestroySummary(diags tfdiags.Diagnostics, run *moduletest.Run, file *moduletest.File, state *states.State) {
+	for _, view := range m {
+		view.DestroySummary(diags, run, file, state)
+	}
+}
+
+func (m TestMulti) Diagnostics(run *moduletest.Run, file *moduletest.File, diags tfdiags.Diagnostics) {
+	for _, view := range m {
+		view.Diagnostics(run, file, diags)
+	}
+}
+
+func (m TestMulti) Interrupted() {
+	for _, view := range m {
+		view.Interrupted()
+	}
+}
+
+func (m TestMulti) FatalInterrupt() {
+	for _, view := range m {
+		view.FatalInterrupt()
+	}
+}
+
+func (m TestMulti) FatalInterruptSummary(run *moduletest.Run, file *moduletest.File, states map[*moduletest.Run]*states.State, created []*plans.ResourceInstanceChangeSrc) {
+	for _, view := range m {
+		view.FatalInterruptSummary(run, file, states, created)
+	}
+}
+
+// TestJUnitXMLFile is a Test view that writes a JUnit XML report of the test
+// results to a file once the suite has concluded.
+//
+// Each test file becomes a testsuite and each run block a testcase. Failures
+// to clean up the infrastructure created by a test file are reported as an
+// additional "cleanup" testcase within the relevant testsuite.
+type TestJUnitXMLFile struct {
+	filename string
+	view     *View
+
+	// cleanup records the diagnostics and left-over resources reported by
+	// DestroySummary, keyed by test file name.
+	cleanup map[string]*junitCleanup
+
+	interrupted bool
+}
+
+var _ Test = (*TestJUnitXMLFile)(nil)
+
+// NewTestJUnitXMLFile returns a Test view that writes a JUnit XML report to
+// filename. The given view is used to look up configuration sources for
+// diagnostics and to report any failure to write the file.
+func NewTestJUnitXMLFile(filename string, view *View) *TestJUnitXMLFile {
+	return &TestJUnitXMLFile{
+		filename: filename,
+		view:     view,
+		cleanup:  make(map[string]*junitCleanup),
+	}
+}
+
+type junitCleanup struct {
+	diags     tfdiags.Diagnostics
+	resources []string
+}
+
+func (t *TestJUnitXMLFile) Abstract(_ *moduletest.Suite) {}
+
+func (t *TestJUnitXMLFile) Conclusion(suite *moduletest.Suite) {
+	src, err := t.marshal(suite)
+	if err == nil {
+		err = os.WriteFile(t.filename, src, 0644)
+	}
+	if err != nil {
+		var diags tfdiags.Diagnostics
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write JUnit XML report",
+			fmt.Sprintf("OpenTofu could not write the test report to %s: %s.", t.filename, err),
+		))
+		t.view.Diagnostics(diags)
+	}
+}
+
+func (t *TestJUnitXMLFile) Coverage(_ *moduletest.Coverage) {}
+
+func (t *TestJUnitXMLFile) File(_ *moduletest.File) {}
+
+func (t *TestJUnitXMLFile) Run(_ *moduletest.Run, _ *moduletest.File) {}
+
+func (t *TestJUnitXMLFile) DestroySummary(diags tfdiags.Diagnostics, _ *moduletest.Run, file *moduletest.File, state *states.State) {
+	if !diags.HasErrors() && !state.HasManagedResourceInstanceObjects() {
+		return
+	}
+
+	cleanup, ok := t.cleanup[file.Name]
+	if !ok {
+		cleanup = new(junitCleanup)
+		t.cleanup[file.Name] = cleanup
+	}
+	cleanup.diags = cleanup.diags.Append(diags)
+	for _, resource := range state.AllResourceInstanceObjectAddrs() {
+		if resource.DeposedKey != states.NotDeposed {
+			cleanup.resources = append(cleanup.resources, fmt.Sprintf("%s (%s)", resource.Instance, resource.DeposedKey))
+			continue
+		}
+		cleanup.resources = append(cleanup.resources, resource.Instance.String())
+	}
+}
+
+func (t *TestJUnitXMLFile) Diagnostics(*moduletest.Run, *moduletest.File, tfdiags.Diagnostics) {}
+
+func (t *TestJUnitXMLFile) Interrupted() {
+	t.interrupted = true
+}
+
+func (t *TestJUnitXMLFile) FatalInterrupt() {
+	t.interrupted = true
+}
+
+func (t *TestJUnitXMLFile) FatalInterruptSummary(_ *moduletest.Run, _ *moduletest.File, _ map[*moduletest.Run]*states.State, _ []*plans.ResourceInstanceChangeSrc) {
+}
+
+// marshal builds the JUnit XML document for the given suite.
+func (t *TestJUnitXMLFile) marshal(suite *moduletest.Suite) ([]byte, error) {
+	var names []string
+	for name := range suite.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := junitTestSuites{}
+	var total time.Duration
+	for _, name := range names {
+		file := suite.Files[name]
+		ts := t.testSuite(file)
+
+		report.Tests += ts.Tests
+		report.Failures += ts.Failures
+		report.Errors += ts.Errors
+		report.Skipped += ts.Skipped
+		total += file.Duration
+
+		report.Suites = append(report.Suites, ts)
+	}
+	report.Time = junitDuration(total)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func (t *TestJUnitXMLFile) testSuite(file *moduletest.File) junitTestSuite {
+	ts := junitTestSuite{
+		Name: file.Name,
+		Time: junitDuration(file.Duration),
+	}
+
+	for _, run := range file.Runs {
+		tc := junitTestCase{
+			Name:      run.Name,
+			Classname: file.Name,
+			Time:      junitDuration(run.Duration),
+		}
+
+		switch run.Status {
+		case moduletest.Fail:
+			message, body := t.describe(run.Diagnostics, "Test assertions failed")
+			tc.Failure = &junitFailure{Message: message, Type: "failure", Body: body}
+			ts.Failures++
+		case moduletest.Error:
+			message, body := t.describe(run.Diagnostics, "Test run errored")
+			tc.Error = &junitFailure{Message: message, Type: "error", Body: body}
+			ts.Errors++
+		case moduletest.Skip, moduletest.Pending:
+			tc.Skipped = &junitSkipped{Message: t.skipReason(file)}
+			ts.Skipped++
+		}
+
+		ts.Cases = append(ts.Cases, tc)
+	}
+
+	if cleanup, ok := t.cleanup[file.Name]; ok {
+		message, body := t.describe(cleanup.diags, "Failed to destroy test infrastructure")
+		if len(cleanup.resources) > 0 {
+			if !cleanup.diags.HasErrors() {
+				message = "Test infrastructure was left in state"
+			}
+			body = fmt.Sprintf("%s\nOpenTofu left the following resources in state:\n  - %s\n", body, strings.Join(cleanup.resources, "\n  - "))
+		}
+
+		ts.Cases = append(ts.Cases, junitTestCase{
+			Name:      "cleanup",
+			Classname: file.Name,
+			Time:      junitDuration(0),
+			Error:     &junitFailure{Message: message, Type: "cleanup", Body: strings.TrimLeft(body, "\n")},
+		})
+		ts.Errors++
+	}
+
+	if file.Diagnostics.HasErrors() {
+		_, body := t.describe(file.Diagnostics, "")
+		ts.SystemErr = &junitOutput{Body: body}
+	}
+
+	ts.Tests = len(ts.Cases)
+	return ts
+}
+
+// describe renders the error diagnostics in diags, returning the summary of
+// the first error as a short message and the plain text rendering of all the
+// errors as the body. The fallback message is used if there are no errors.
+func (t *TestJUnitXMLFile) describe(diags tfdiags.Diagnostics, fallback string) (string, string) {
+	message := fallback
+	sources := t.view.configSources()
+
+	var body strings.Builder
+	first := true
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Error {
+			continue
+		}
+		if first {
+			message = diag.Description().Summary
+			first = false
+		}
+		body.WriteString(format.DiagnosticPlain(diag, sources, 0))
+	}
+	return message, strings.TrimLeft(body.String(), "\n")
+}
+
+func (t *TestJUnitXMLFile) skipReason(file *moduletest.File) string {
+	switch {
+	case t.interrupted:
+		return "Testing was interrupted"
+	case file.Status == moduletest.Error:
+		return "Skipped due to an earlier error in the test file"
+	default:
+		return ""
+	}
+}
+
+func junitDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemErr *junitOutput    `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",cdata"`
+}
+
+type junitOutput struct {
+	Body string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
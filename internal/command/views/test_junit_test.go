@@ -0,0 +1,133 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestTestJUnitXMLFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.xml")
+
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTestJUnitXMLFile(filename, NewView(streams))
+
+	file := &moduletest.File{
+		Name:     "main.tftest.hcl",
+		Status:   moduletest.Error,
+		Duration: 1500 * time.Millisecond,
+		Runs: []*moduletest.Run{
+			{
+				Name:     "setup",
+				Status:   moduletest.Pass,
+				Duration: 250 * time.Millisecond,
+			},
+			{
+				Name:     "check",
+				Status:   moduletest.Fail,
+				Duration: time.Second,
+				Diagnostics: tfdiags.Diagnostics{
+					tfdiags.Sourceless(tfdiags.Warning, "a warning", "this is ignored"),
+					tfdiags.Sourceless(tfdiags.Error, "Test assertion failed", "the value was wrong"),
+				},
+			},
+			{
+				Name:   "interrupted",
+				Status: moduletest.Skip,
+			},
+		},
+	}
+	suite := &moduletest.Suite{
+		Status: moduletest.Error,
+		Files: map[string]*moduletest.File{
+			file.Name: file,
+		},
+	}
+
+	state := states.BuildState(func(state *states.SyncState) {
+		state.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test",
+				Name: "foo",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				Status: states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Module:   addrs.RootModule,
+				Provider: addrs.NewDefaultProvider("test"),
+			}, addrs.NoKey)
+	})
+
+	view.Abstract(suite)
+	view.Interrupted()
+	view.DestroySummary(tfdiags.Diagnostics{
+		tfdiags.Sourceless(tfdiags.Error, "Failed to destroy", "the provider refused"),
+	}, file.Runs[0], file, state)
+	view.Conclusion(suite)
+
+	if output := done(t); len(output.All()) > 0 {
+		t.Errorf("expected no output but got:\n%s", output.All())
+	}
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read report: %s", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4" failures="1" errors="1" skipped="1" time="1.500">
+  <testsuite name="main.tftest.hcl" tests="4" failures="1" errors="1" skipped="1" time="1.500">
+    <testcase name="setup" classname="main.tftest.hcl" time="0.250"></testcase>
+    <testcase name="check" classname="main.tftest.hcl" time="1.000">
+      <failure message="Test assertion failed" type="failure"><![CDATA[Error: Test assertion failed
+
+the value was wrong
+]]></failure>
+    </testcase>
+    <testcase name="interrupted" classname="main.tftest.hcl" time="0.000">
+      <skipped message="Testing was interrupted"></skipped>
+    </testcase>
+    <testcase name="cleanup" classname="main.tftest.hcl" time="0.000">
+      <error message="Failed to destroy" type="cleanup"><![CDATA[Error: Failed to destroy
+
+the provider refused
+
+OpenTofu left the following resources in state:
+  - test.foo
+]]></error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(expected, string(raw)); len(diff) > 0 {
+		t.Errorf("unexpected report:\n%s", diff)
+	}
+}
+
+func TestTestJUnitXMLFile_writeError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing", "results.xml")
+
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTestJUnitXMLFile(filename, NewView(streams))
+	view.Conclusion(&moduletest.Suite{})
+
+	output := done(t)
+	if got, want := output.Stderr(), "Failed to write JUnit XML report"; !strings.Contains(got, want) {
+		t.Errorf("expected stderr to contain %q but got:\n%s", want, got)
+	}
+}
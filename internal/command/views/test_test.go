@@ -486,6 +486,16 @@ some warning happened during this test
 `,
 		},
 
+		"for_each": {
+			Run: &moduletest.Run{
+				Config: &configs.TestRun{Name: "run_block"},
+				Name:   "run_block[\"a\"]",
+				Key:    addrs.StringKey("a"),
+				Status: moduletest.Pass,
+			},
+			StdOut: "  run \"run_block\"[\"a\"]... pass\n",
+		},
+
 		"pending": {
 			Run:    &moduletest.Run{Name: "run_block", Status: moduletest.Pending},
 			StdOut: "  run \"run_block\"... pending\n",
@@ -1122,6 +1132,39 @@ OpenTofu has already created the following resources from the module under
 test:
   - test_instance.one
   - test_instance.two
+`,
+		},
+		"state_key_state_no_plan": {
+			states: map[*moduletest.Run]*states.State{
+				&moduletest.Run{
+					Name: "keyed_block",
+					Config: &configs.TestRun{
+						StateKey: "keyed",
+					},
+				}: states.BuildState(func(state *states.SyncState) {
+					state.SetResourceInstanceCurrent(
+						addrs.AbsResourceInstance{
+							Module: addrs.RootModuleInstance,
+							Resource: addrs.ResourceInstance{
+								Resource: addrs.Resource{
+									Mode: addrs.ManagedResourceMode,
+									Type: "test_instance",
+									Name: "one",
+								},
+							},
+						},
+						&states.ResourceInstanceObjectSrc{},
+						addrs.AbsProviderConfig{}, addrs.NoKey)
+				}),
+			},
+			created: nil,
+			want: `
+OpenTofu was interrupted while executing main.tftest.hcl, and may not have
+performed the expected cleanup operations.
+
+OpenTofu has already created the following resources for "keyed_block" from
+the module under test:
+  - test_instance.one
 `,
 		},
 		"run_states_no_plan": {
@@ -2484,6 +2527,30 @@ func TestTestJSON_Run(t *testing.T) {
 			},
 		},
 
+		"for_each": {
+			run: &moduletest.Run{
+				Config: &configs.TestRun{Name: "run_block"},
+				Name:   "run_block[0]",
+				Key:    addrs.IntKey(0),
+				Status: moduletest.Pass,
+			},
+			want: []map[string]interface{}{
+				{
+					"@level":    "info",
+					"@message":  "  \"run_block\"[0]... pass",
+					"@module":   "tofu.ui",
+					"@testfile": "main.tftest.hcl",
+					"@testrun":  "run_block[0]",
+					"test_run": map[string]interface{}{
+						"path":   "main.tftest.hcl",
+						"run":    "run_block[0]",
+						"status": "pass",
+					},
+					"type": "test_run",
+				},
+			},
+		},
+
 		"pass_with_diags": {
 			run: &moduletest.Run{
 				Name:        "run_block",
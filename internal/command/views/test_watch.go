@@ -0,0 +1,46 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TestWatch renders the status of tofu test -watch between test runs. The
+// status is only shown in the human-readable output, so there is no JSON
+// implementation.
+type TestWatch struct {
+	view *View
+}
+
+func NewTestWatch(view *View) *TestWatch {
+	return &TestWatch{view: view}
+}
+
+// Watching reports that OpenTofu is waiting for changes, along with the
+// outcome of the most recent test run.
+func (t *TestWatch) Watching(passed bool) {
+	status := t.view.colorize.Color("[green]passing[reset]")
+	if !passed {
+		status = t.view.colorize.Color("[red]failing[reset]")
+	}
+	t.view.streams.Printf("\nTests %s. Watching for changes, press Ctrl-C to exit.\n", status)
+}
+
+// Changed reports the files that changed, and the test files that OpenTofu is
+// about to run again because of them.
+func (t *TestWatch) Changed(changed []string, files []string) {
+	t.view.streams.Printf("\n%s\n", t.view.colorize.Color(fmt.Sprintf("[bold]Changed: %s[reset]", strings.Join(changed, ", "))))
+	if len(files) == 0 {
+		t.view.streams.Println("No test files are affected.")
+		return
+	}
+
+	noun := "files"
+	if len(files) == 1 {
+		noun = "file"
+	}
+	t.view.streams.Printf("Running %d test %s.\n\n", len(files), noun)
+}
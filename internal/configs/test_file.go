@@ -184,7 +184,27 @@ type TestRun struct {
 	// Underlying modules shouldn't be called.
 	OverrideModules []*OverrideModule
 
+	// Snapshot tells the test command to compare the plan created by this run
+	// block against a snapshot stored alongside the test file.
+	Snapshot bool
+
+	// ForEach, if set, expands this run block into one run for each element
+	// of the list or map it evaluates to. The variables and assertions of
+	// each run can refer to the current element with each.key and each.value.
+	//
+	// The expression is evaluated when the test file is loaded, so it can only
+	// refer to literal values and functions.
+	ForEach hcl.Expression
+
+	// StateKey, if set, is the key of the state this run block executes
+	// against, instead of the state of the configuration under test. Run
+	// blocks with the same state key share their state, and a test file
+	// whose run blocks all set a state key that no other test file uses
+	// can execute alongside other test files.
+	StateKey string
+
 	NameDeclRange      hcl.Range
+	SnapshotDeclRange  hcl.Range
 	VariablesDeclRange hcl.Range
 	DeclRange          hcl.Range
 }
@@ -636,6 +656,29 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 		r.ExpectFailures = failures
 	}
 
+	if attr, exists := content.Attributes["snapshot"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.Snapshot)
+		diags = append(diags, valDiags...)
+		r.SnapshotDeclRange = attr.Range
+	}
+
+	if attr, exists := content.Attributes["for_each"]; exists {
+		r.ForEach = attr.Expr
+	}
+
+	if attr, exists := content.Attributes["state_key"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.StateKey)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() && r.StateKey == "" {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid \"state_key\" argument",
+				Detail:   "The \"state_key\" argument must not be empty.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
 	return &r, diags
 }
 
@@ -1080,6 +1123,12 @@ var testRunBlockSchema = &hcl.BodySchema{
 		{Name: "providers"},
 		// expect_failures indicates whether test failures are expected.
 		{Name: "expect_failures"},
+		// snapshot compares the plan against a stored snapshot.
+		{Name: "snapshot"},
+		// for_each expands the run block into one run for each case.
+		{Name: "for_each"},
+		// state_key selects the state the run block executes against.
+		{Name: "state_key"},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
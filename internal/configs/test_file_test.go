@@ -97,3 +97,49 @@ func parseTraversal(t *testing.T, addr string) hcl.Traversal {
 	}
 	return traversal
 }
+
+func TestDecodeTestRunBlock_stateKey(t *testing.T) {
+	tcs := map[string]struct {
+		src        string
+		want       string
+		diagnostic string
+	}{
+		"unset": {
+			src: `run "test" {}`,
+		},
+		"set": {
+			src:  `run "test" { state_key = "network" }`,
+			want: "network",
+		},
+		"empty": {
+			src:        `run "test" { state_key = "" }`,
+			diagnostic: `The "state_key" argument must not be empty.`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(tc.src), "test.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			content, diags := file.Body.Content(testFileSchema)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			run, diags := decodeTestRunBlock(content.Blocks[0])
+			if tc.diagnostic != "" {
+				if len(diags) != 1 || diags[0].Detail != tc.diagnostic {
+					t.Fatalf("wrong diagnostics\ngot:  %s\nwant: %s", diags.Error(), tc.diagnostic)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if run.StateKey != tc.want {
+				t.Errorf("wrong state key\ngot:  %q\nwant: %q", run.StateKey, tc.want)
+			}
+		})
+	}
+}
@@ -37,11 +37,12 @@ type Locks struct {
 	// settings, environment variables, or whatever similar sources.
 	overriddenProviders map[addrs.Provider]struct{}
 
-	// TODO: In future we'll also have module locks, but the design of that
-	// still needs some more work and we're deferring that to get the
-	// provider locking capability out sooner, because it's more common to
-	// directly depend on providers maintained outside your organization than
-	// modules maintained outside your organization.
+	// modules records the exact package selected for each remote module
+	// package whose address can refer to different contents over time,
+	// keyed by the package address written in the configuration. Packages
+	// whose address always refers to the same contents, including all
+	// registry modules, have no lock here.
+	modules map[string]*ModuleLock
 
 	// sources is a copy of the map of source buffers produced by the HCL
 	// parser during loading, which we retain only so that the caller can
@@ -54,6 +55,7 @@ type Locks struct {
 func NewLocks() *Locks {
 	return &Locks{
 		providers: make(map[addrs.Provider]*ProviderLock),
+		modules:   make(map[string]*ModuleLock),
 
 		// no "sources" here, because that's only for locks objects loaded
 		// from files.
@@ -120,6 +122,49 @@ func (l *Locks) RemoveProvider(addr addrs.Provider) {
 	delete(l.providers, addr)
 }
 
+// Module returns the stored lock for the given module package address, or nil
+// if that package currently has no lock.
+func (l *Locks) Module(source string) *ModuleLock {
+	return l.modules[source]
+}
+
+// AllModules returns a map describing all of the module locks in the
+// receiver, keyed by their package addresses.
+func (l *Locks) AllModules() map[string]*ModuleLock {
+	// We return a copy of our internal map so that future calls to
+	// SetModule won't modify the map we're returning, or vice-versa.
+	ret := make(map[string]*ModuleLock, len(l.modules))
+	for k, v := range l.modules {
+		ret[k] = v
+	}
+	return ret
+}
+
+// SetModule creates a new lock or replaces the existing lock for the given
+// module package address, recording that it was pinned to exactly the
+// package at the pinned address.
+//
+// SetModule returns the newly-created module lock object, which invalidates
+// any ModuleLock object previously returned from Module or SetModule for the
+// given package address.
+func (l *Locks) SetModule(source, pinned string) *ModuleLock {
+	new := &ModuleLock{
+		source: source,
+		pinned: pinned,
+	}
+	l.modules[source] = new
+	return new
+}
+
+// RemoveModule removes any existing lock file entry for the given module
+// package address.
+//
+// If the given package did not already have a lock entry, RemoveModule is
+// a no-op.
+func (l *Locks) RemoveModule(source string) {
+	delete(l.modules, source)
+}
+
 // SetProviderOverridden records that this particular OpenTofu process will
 // not pay attention to the recorded lock entry for the given provider, and
 // will instead access that provider's functionality in some other special
@@ -278,6 +323,16 @@ func (l *Locks) Equal(other *Locks) bool {
 	// We don't need to worry about providers that are in "other" but not
 	// in the receiver, because we tested the lengths being equal above.
 
+	if len(l.modules) != len(other.modules) {
+		return false
+	}
+	for source, thisLock := range l.modules {
+		otherLock, ok := other.modules[source]
+		if !ok || thisLock.pinned != otherLock.pinned {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -303,7 +358,7 @@ func (l *Locks) EqualProviderAddress(other *Locks) bool {
 // UI code might wish to use this to distinguish a lock file being
 // written for the first time from subsequent updates to that lock file.
 func (l *Locks) Empty() bool {
-	return len(l.providers) == 0
+	return len(l.providers) == 0 && len(l.modules) == 0
 }
 
 // DeepCopy creates a new Locks that represents the same information as the
@@ -324,6 +379,9 @@ func (l *Locks) DeepCopy() *Locks {
 		}
 		ret.SetProvider(addr, lock.version, lock.versionConstraints, hashes)
 	}
+	for source, lock := range l.modules {
+		ret.SetModule(source, lock.pinned)
+	}
 	return ret
 }
 
@@ -442,3 +500,26 @@ func (l *ProviderLock) ContainsAll(target *ProviderLock) bool {
 func (l *ProviderLock) PreferredHashes() []getproviders.Hash {
 	return getproviders.PreferredHashes(l.hashes)
 }
+
+// ModuleLock represents lock information for a remote module package whose
+// address can refer to different contents over time, such as an "oci:"
+// address that refers to a tag rather than to a digest.
+type ModuleLock struct {
+	// source is the package address as written in the configuration, and
+	// pinned is the address of exactly the package that was selected for
+	// it, such as an "oci:" address that refers to the digest that the tag
+	// referred to at the time.
+	source string
+	pinned string
+}
+
+// Source returns the package address this lock applies to.
+func (l *ModuleLock) Source() string {
+	return l.source
+}
+
+// Pinned returns the address of exactly the package that was selected for
+// the package address returned by Source.
+func (l *ModuleLock) Pinned() string {
+	return l.pinned
+}
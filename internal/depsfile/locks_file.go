@@ -20,7 +20,6 @@ import (
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/replacefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
-	"github.com/opentofu/opentofu/version"
 )
 
 // LoadLocksFromFile reads locks from the given file, expecting it to be a
@@ -163,6 +162,19 @@ func SaveLocksToBytes(locks *Locks) ([]byte, tfdiags.Diagnostics) {
 		}
 	}
 
+	modules := make([]string, 0, len(locks.modules))
+	for source := range locks.modules {
+		modules = append(modules, source)
+	}
+	sort.Strings(modules)
+
+	for _, source := range modules {
+		lock := locks.modules[source]
+		rootBody.AppendNewline()
+		block := rootBody.AppendNewBlock("module", []string{lock.source})
+		block.Body().SetAttributeValue("pinned", cty.StringVal(lock.pinned))
+	}
+
 	return f.Bytes(), diags
 }
 
@@ -175,21 +187,16 @@ func decodeLocksFromHCL(locks *Locks, body hcl.Body) tfdiags.Diagnostics {
 				Type:       "provider",
 				LabelNames: []string{"source_addr"},
 			},
-
-			// "module" is just a placeholder for future enhancement, so we
-			// can mostly-ignore the this block type we intend to add in
-			// future, but warn in case someone tries to use one e.g. if they
-			// downgraded to an earlier version of OpenTofu.
 			{
 				Type:       "module",
-				LabelNames: []string{"path"},
+				LabelNames: []string{"source_addr"},
 			},
 		},
 	})
 	diags = diags.Append(hclDiags)
 
 	seenProviders := make(map[addrs.Provider]hcl.Range)
-	seenModule := false
+	seenModules := make(map[string]hcl.Range)
 	for _, block := range content.Blocks {
 
 		switch block.Type {
@@ -212,19 +219,22 @@ func decodeLocksFromHCL(locks *Locks, body hcl.Body) tfdiags.Diagnostics {
 			seenProviders[lock.addr] = block.DefRange
 
 		case "module":
-			// We'll just take the first module block to use for a single warning,
-			// because that's sufficient to get the point across without swamping
-			// the output with warning noise.
-			if !seenModule {
-				currentVersion := version.SemVer.String()
+			lock, moreDiags := decodeModuleLockFromHCL(block)
+			diags = diags.Append(moreDiags)
+			if lock == nil {
+				continue
+			}
+			if previousRng, exists := seenModules[lock.source]; exists {
 				diags = diags.Append(&hcl.Diagnostic{
-					Severity: hcl.DiagWarning,
-					Summary:  "Dependency locks for modules are not yet supported",
-					Detail:   fmt.Sprintf("OpenTofu v%s only supports dependency locks for providers, not for modules. This configuration may be intended for a later version of OpenTofu that also supports dependency locks for modules.", currentVersion),
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate module lock",
+					Detail:   fmt.Sprintf("This lockfile already declared a lock for module package %s at %s.", lock.source, previousRng.String()),
 					Subject:  block.TypeRange.Ptr(),
 				})
-				seenModule = true
+				continue
 			}
+			locks.modules[lock.source] = lock
+			seenModules[lock.source] = block.DefRange
 
 		default:
 			// Shouldn't get here because this should be exhaustive for
@@ -320,6 +330,55 @@ func decodeProviderLockFromHCL(block *hcl.Block) (*ProviderLock, tfdiags.Diagnos
 	return ret, diags
 }
 
+func decodeModuleLockFromHCL(block *hcl.Block) (*ModuleLock, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	source := block.Labels[0]
+	if source == "" {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid module package address",
+			Detail:   "The package address for a module lock must not be empty.",
+			Subject:  block.LabelRanges[0].Ptr(),
+		})
+		return nil, diags
+	}
+
+	content, hclDiags := block.Body.Content(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "pinned", Required: true},
+		},
+	})
+	diags = diags.Append(hclDiags)
+	attr := content.Attributes["pinned"]
+	if attr == nil {
+		// It's not okay to omit this argument, but the diagnostics from
+		// decoding the content above already say so.
+		return nil, diags
+	}
+
+	var pinned string
+	hclDiags = gohcl.DecodeExpression(attr.Expr, nil, &pinned)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	if pinned == "" {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid pinned module package address",
+			Detail:   fmt.Sprintf("The pinned package address for module package %s must not be empty.", source),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+
+	return &ModuleLock{
+		source: source,
+		pinned: pinned,
+	}, diags
+}
+
 func decodeProviderVersionArgument(provider addrs.Provider, attr *hcl.Attribute) (getproviders.Version, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	if attr == nil {
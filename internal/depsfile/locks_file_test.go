@@ -107,6 +107,18 @@ func TestLoadLocksFromFile(t *testing.T) {
 					t.Errorf("wrong number of providers %d; want %d", got, want)
 				}
 
+			case "valid-module-locks.hcl":
+				if got, want := len(locks.modules), 1; got != want {
+					t.Errorf("wrong number of modules %d; want %d", got, want)
+				}
+				lock := locks.Module("oci://example.com/modules/vpc:1.0.0")
+				if lock == nil {
+					t.Fatalf("no lock for the vpc module")
+				}
+				if got, want := lock.Pinned(), "oci://example.com/modules/vpc@sha256:9c1d5b2b3a4f47a1c5c2b0a9f0e6e4b8f1d0c9b8a7e6d5c4b3a2f1e0d9c8b7a6"; got != want {
+					t.Errorf("wrong pinned package\ngot:  %s\nwant: %s", got, want)
+				}
+
 			case "valid-provider-locks.hcl":
 				if got, want := len(locks.providers), 3; got != want {
 					t.Errorf("wrong number of providers %d; want %d", got, want)
@@ -223,6 +235,8 @@ func TestSaveLocksToFile(t *testing.T) {
 	locks.SetProvider(barProvider, oneDotTwo, pessimisticOneDotOh, nil)
 	locks.SetProvider(bazProvider, oneDotTwo, nil, nil)
 	locks.SetProvider(booProvider, oneDotTwo, abbreviatedOneDotTwo, nil)
+	locks.SetModule("oci://example.com/modules/vpc:1.0.0", "oci://example.com/modules/vpc@sha256:1111111111111111111111111111111111111111111111111111111111111111")
+	locks.SetModule("oci://example.com/modules/nat:1.0.0", "oci://example.com/modules/nat@sha256:2222222222222222222222222222222222222222222222222222222222222222")
 
 	dir := t.TempDir()
 
@@ -271,6 +285,14 @@ provider "registry.opentofu.org/test/foo" {
     "test:cccccccccccccccccccccccccccccccccccccccccccccccc",
   ]
 }
+
+module "oci://example.com/modules/nat:1.0.0" {
+  pinned = "oci://example.com/modules/nat@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+}
+
+module "oci://example.com/modules/vpc:1.0.0" {
+  pinned = "oci://example.com/modules/vpc@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+}
 `
 	if diff := cmp.Diff(wantContent, gotContent); diff != "" {
 		t.Errorf("wrong result\n%s", diff)
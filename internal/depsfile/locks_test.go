@@ -22,6 +22,9 @@ func TestLocksEqual(t *testing.T) {
 	hash1 := getproviders.HashScheme("test").New("1")
 	hash2 := getproviders.HashScheme("test").New("2")
 	hash3 := getproviders.HashScheme("test").New("3")
+	vpcModule := "oci://example.com/modules/vpc:1.0.0"
+	vpcDigest1 := "oci://example.com/modules/vpc@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	vpcDigest2 := "oci://example.com/modules/vpc@sha256:2222222222222222222222222222222222222222222222222222222222222222"
 
 	equalBothWays := func(t *testing.T, a, b *Locks) {
 		t.Helper()
@@ -85,6 +88,26 @@ func TestLocksEqual(t *testing.T) {
 		b.SetProvider(boopProvider, v2, v2EqConstraints, hashesB)
 		nonEqualBothWays(t, a, b)
 	})
+	t.Run("an extra module lock", func(t *testing.T) {
+		a := NewLocks()
+		b := NewLocks()
+		b.SetModule(vpcModule, vpcDigest1)
+		nonEqualBothWays(t, a, b)
+	})
+	t.Run("both have vpc module with same pin", func(t *testing.T) {
+		a := NewLocks()
+		b := NewLocks()
+		a.SetModule(vpcModule, vpcDigest1)
+		b.SetModule(vpcModule, vpcDigest1)
+		equalBothWays(t, a, b)
+	})
+	t.Run("both have vpc module with different pins", func(t *testing.T) {
+		a := NewLocks()
+		b := NewLocks()
+		a.SetModule(vpcModule, vpcDigest1)
+		b.SetModule(vpcModule, vpcDigest2)
+		nonEqualBothWays(t, a, b)
+	})
 }
 
 func TestLocksEqualProviderAddress(t *testing.T) {
@@ -222,6 +245,36 @@ func TestLocksProviderSetRemove(t *testing.T) {
 	}
 }
 
+func TestLocksModuleSetRemove(t *testing.T) {
+	vpcModule := "oci://example.com/modules/vpc:1.0.0"
+	vpcDigest := "oci://example.com/modules/vpc@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+	locks := NewLocks()
+	if !locks.Empty() {
+		t.Fatalf("fresh locks object is not empty")
+	}
+
+	locks.SetModule(vpcModule, vpcDigest)
+	if locks.Empty() {
+		t.Fatalf("locks object with a module lock is empty")
+	}
+	lock := locks.Module(vpcModule)
+	if lock == nil {
+		t.Fatalf("no lock for %s after SetModule", vpcModule)
+	}
+	if got, want := lock.Pinned(), vpcDigest; got != want {
+		t.Errorf("wrong pinned package\ngot:  %s\nwant: %s", got, want)
+	}
+	if got := locks.DeepCopy(); !got.Equal(locks) {
+		t.Errorf("copy of locks is not equal to the original")
+	}
+
+	locks.RemoveModule(vpcModule)
+	if got, want := len(locks.AllModules()), 0; got != want {
+		t.Fatalf("wrong number of modules %d after RemoveModule; want %d", got, want)
+	}
+}
+
 func TestProviderLockContainsAll(t *testing.T) {
 	provider := addrs.NewDefaultProvider("provider")
 	v2 := getproviders.MustParseVersion("2.0.0")
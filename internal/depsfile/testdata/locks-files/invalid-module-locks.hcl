@@ -0,0 +1,14 @@
+module "oci://example.com/modules/vpc:1.0.0" {
+  pinned = "oci://example.com/modules/vpc@sha256:9c1d5b2b3a4f47a1c5c2b0a9f0e6e4b8f1d0c9b8a7e6d5c4b3a2f1e0d9c8b7a6"
+}
+
+module "oci://example.com/modules/vpc:1.0.0" { # ERROR: Duplicate module lock
+  pinned = "oci://example.com/modules/vpc@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+}
+
+module "oci://example.com/modules/subnet:1.0.0" { # ERROR: Missing required argument
+}
+
+module "oci://example.com/modules/nat:1.0.0" {
+  pinned = "" # ERROR: Invalid pinned module package address
+}
@@ -0,0 +1,3 @@
+module "oci://example.com/modules/vpc:1.0.0" {
+  pinned = "oci://example.com/modules/vpc@sha256:9c1d5b2b3a4f47a1c5c2b0a9f0e6e4b8f1d0c9b8a7e6d5c4b3a2f1e0d9c8b7a6"
+}
@@ -68,6 +68,25 @@ func WrapResourceContents(addr addrs.AbsResourceInstance, config string) string
 	return string(formatted)
 }
 
+// GenerateImportBlock generates an import block that imports the object with
+// the given ID into the given resource instance.
+func GenerateImportBlock(addr addrs.AbsResourceInstance, id string) string {
+	file := hclwrite.NewEmptyFile()
+	block := file.Body().AppendNewBlock("import", nil)
+	block.Body().SetAttributeTraversal("to", addrTraversal(addr))
+	block.Body().SetAttributeValue("id", cty.StringVal(id))
+	return string(file.Bytes())
+}
+
+func addrTraversal(addr addrs.AbsResourceInstance) hcl.Traversal {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(addr.String()), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		// Addresses always have valid traversal syntax.
+		panic(diags.Error())
+	}
+	return traversal
+}
+
 func writeConfigAttributes(addr addrs.AbsResourceInstance, buf *strings.Builder, attrs map[string]*configschema.Attribute, indent int) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
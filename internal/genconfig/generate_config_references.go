@@ -0,0 +1,221 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package genconfig
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// referenceAttributes are the attributes whose values identify the object
+// that has them, in order of preference. Attributes such as vpc_id, which
+// hold the identifiers of other objects, are never matched, because the
+// object that they identify is the one to refer to.
+var referenceAttributes = []string{"id", "arn", "self_link"}
+
+// References replaces the literal values in generated configuration that
+// match an identifying attribute of another resource instance, such as its
+// "id" or "arn", with a reference to that attribute, such as
+// aws_vpc.main.id, so that the generated resources depend on the objects
+// they refer to.
+type References struct {
+	// targets maps each identifying value to the resource instance attribute
+	// that has it, or to nil if more than one resource instance has it.
+	targets map[string]*referenceTarget
+
+	// deps records the references made so far, by the address of the
+	// referring resource instance, to avoid creating dependency cycles.
+	deps map[string][]addrs.AbsResourceInstance
+}
+
+type referenceTarget struct {
+	addr addrs.AbsResourceInstance
+	attr string
+}
+
+// NewReferences returns the References for the root module resource
+// instances in the prior state of a plan, which includes the objects that
+// the plan imports. Resource instances that the plan deletes or forgets are
+// never referred to.
+func NewReferences(plan *plans.Plan) *References {
+	refs := &References{
+		targets: make(map[string]*referenceTarget),
+		deps:    make(map[string][]addrs.AbsResourceInstance),
+	}
+	if plan.PriorState == nil {
+		return refs
+	}
+	mod := plan.PriorState.RootModule()
+	if mod == nil {
+		return refs
+	}
+	for _, rs := range mod.Resources {
+		for key, is := range rs.Instances {
+			addr := rs.Addr.Instance(key)
+			if change := plan.Changes.ResourceInstance(addr); change != nil && (change.Action == plans.Delete || change.Action == plans.Forget) {
+				continue
+			}
+			if is.Current == nil || len(is.Current.AttrsJSON) == 0 {
+				continue
+			}
+			var attrs map[string]interface{}
+			if err := json.Unmarshal(is.Current.AttrsJSON, &attrs); err != nil {
+				continue
+			}
+			for _, name := range referenceAttributes {
+				if value, ok := attrs[name].(string); ok && value != "" {
+					refs.Add(addr, name, value)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// Add records that the given attribute of the resource instance has the
+// given identifying value. If the resource instance has the same value in
+// more than one attribute, references are to the first one added.
+func (r *References) Add(addr addrs.AbsResourceInstance, attr, value string) {
+	if prev, exists := r.targets[value]; exists {
+		if prev != nil && !prev.addr.Equal(addr) {
+			r.targets[value] = nil
+		}
+		return
+	}
+	r.targets[value] = &referenceTarget{addr: addr, attr: attr}
+}
+
+// Replace rewrites the generated configuration of the given resource
+// instance, and returns it with the number of values that it replaced.
+//
+// Values that more than one resource instance has are ambiguous and never
+// replaced, and neither are references that would create a dependency cycle
+// with an earlier call to Replace.
+func (r *References) Replace(addr addrs.AbsResourceInstance, config string) (string, int) {
+	var count int
+	ret, diags := ReplaceReferences(config, func(value string) hcl.Traversal {
+		target := r.targets[value]
+		if target == nil || r.reaches(target.addr, addr) {
+			return nil
+		}
+		r.deps[addr.String()] = append(r.deps[addr.String()], target.addr)
+		count++
+		return append(addrTraversal(target.addr), hcl.TraverseAttr{Name: target.attr})
+	})
+	if diags.HasErrors() {
+		// Generated configuration is always valid, so this is a bug, but
+		// the literal values are still usable.
+		log.Printf("[WARN] genconfig: failed to replace references for %s: %s", addr, diags.Error())
+		return config, 0
+	}
+	return ret, count
+}
+
+// reaches returns true if from is to, or if from refers to to directly or
+// indirectly.
+func (r *References) reaches(from, to addrs.AbsResourceInstance) bool {
+	if from.Equal(to) {
+		return true
+	}
+	for _, dep := range r.deps[from.String()] {
+		if r.reaches(dep, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplaceReferences rewrites the string literals in generated configuration
+// that the given function resolves to a reference, so that generated
+// resources can refer to one another instead of repeating values such as
+// IDs. The function returns nil to keep a literal as it is.
+//
+// Attributes are visited in lexical order, block by block, so the function
+// is called in a predictable order.
+func ReplaceReferences(config string, ref func(value string) hcl.Traversal) (string, hcl.Diagnostics) {
+	file, diags := hclwrite.ParseConfig([]byte(config), "generated.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return config, diags
+	}
+	replaceBodyReferences(file.Body(), ref)
+	return string(hclwrite.Format(file.Bytes())), diags
+}
+
+func replaceBodyReferences(body *hclwrite.Body, ref func(value string) hcl.Traversal) {
+	attrs := body.Attributes()
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tokens := attrs[name].Expr().BuildTokens(nil)
+		if replaced, ok := replaceTokenReferences(tokens, ref); ok {
+			body.SetAttributeRaw(name, replaced)
+		}
+	}
+	for _, block := range body.Blocks() {
+		replaceBodyReferences(block.Body(), ref)
+	}
+}
+
+// replaceTokenReferences replaces each string literal in an expression that
+// ref resolves to a reference. Object keys are never replaced.
+func replaceTokenReferences(tokens hclwrite.Tokens, ref func(value string) hcl.Traversal) (hclwrite.Tokens, bool) {
+	var ret hclwrite.Tokens
+	var changed bool
+	for i := 0; i < len(tokens); i++ {
+		if i+2 < len(tokens) &&
+			tokens[i].Type == hclsyntax.TokenOQuote &&
+			tokens[i+1].Type == hclsyntax.TokenQuotedLit &&
+			tokens[i+2].Type == hclsyntax.TokenCQuote &&
+			!isObjectKey(tokens[i+3:]) {
+			if value, ok := unquoteLiteral(tokens[i : i+3]); ok {
+				if traversal := ref(value); traversal != nil {
+					ret = append(ret, hclwrite.TokensForTraversal(traversal)...)
+					changed = true
+					i += 2
+					continue
+				}
+			}
+		}
+		ret = append(ret, tokens[i])
+	}
+	return ret, changed
+}
+
+func isObjectKey(rest hclwrite.Tokens) bool {
+	for _, token := range rest {
+		switch token.Type {
+		case hclsyntax.TokenNewline:
+			continue
+		case hclsyntax.TokenEqual, hclsyntax.TokenColon:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+func unquoteLiteral(tokens hclwrite.Tokens) (string, bool) {
+	expr, diags := hclsyntax.ParseExpression(tokens.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", false
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.Type().Equals(cty.String) || val.IsNull() {
+		return "", false
+	}
+	return val.AsString(), true
+}
@@ -0,0 +1,139 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package genconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestReplaceReferences(t *testing.T) {
+	config := `resource "aws_subnet" "a" {
+  cidr_block = "10.0.0.0/24"
+  vpc_id     = "vpc-123"
+  tags = {
+    "vpc-123" = "vpc-123"
+  }
+  policy = jsonencode({
+    Resource = ["vpc-123", "vpc-456"]
+  })
+  route {
+    gateway_id = "igw-1"
+    note       = "vpc-123 and igw-1"
+  }
+}
+`
+	refs := map[string]hcl.Traversal{
+		"vpc-123": {hcl.TraverseRoot{Name: "aws_vpc"}, hcl.TraverseAttr{Name: "main"}, hcl.TraverseAttr{Name: "id"}},
+		"igw-1":   {hcl.TraverseRoot{Name: "aws_internet_gateway"}, hcl.TraverseAttr{Name: "gw"}, hcl.TraverseAttr{Name: "id"}},
+	}
+
+	var calls []string
+	got, diags := ReplaceReferences(config, func(value string) hcl.Traversal {
+		calls = append(calls, value)
+		return refs[value]
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	want := `resource "aws_subnet" "a" {
+  cidr_block = "10.0.0.0/24"
+  vpc_id     = aws_vpc.main.id
+  tags = {
+    "vpc-123" = aws_vpc.main.id
+  }
+  policy = jsonencode({
+    Resource = [aws_vpc.main.id, "vpc-456"]
+  })
+  route {
+    gateway_id = aws_internet_gateway.gw.id
+    note       = "vpc-123 and igw-1"
+  }
+}
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong config\n%s", diff)
+	}
+
+	// Attributes are visited in lexical order, and object keys are skipped.
+	wantCalls := "10.0.0.0/24,vpc-123,vpc-456,vpc-123,vpc-123,igw-1,vpc-123 and igw-1"
+	if got := strings.Join(calls, ","); got != wantCalls {
+		t.Errorf("wrong calls\ngot:  %s\nwant: %s", got, wantCalls)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	resource := func(typ, name string) addrs.AbsResourceInstance {
+		return addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, typ, name, addrs.NoKey)
+	}
+	vpc := resource("aws_vpc", "main")
+	oldVPC := resource("aws_vpc", "old")
+	subnetA := resource("aws_subnet", "a")
+	subnetB := resource("aws_subnet", "b")
+	moduleVPC := addrs.RootModuleInstance.Child("net", addrs.NoKey).ResourceInstance(addrs.ManagedResourceMode, "aws_vpc", "main", addrs.NoKey)
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, obj := range []struct {
+			addr  addrs.AbsResourceInstance
+			attrs string
+		}{
+			{vpc, `{"id":"vpc-123"}`},
+			{oldVPC, `{"id":"vpc-456"}`},
+			{moduleVPC, `{"id":"vpc-789"}`},
+			{subnetA, `{"id":"subnet-a","vpc_id":"vpc-000","cidr_block":"10.0.0.0/24"}`},
+			{subnetB, `{"id":"subnet-b","vpc_id":"vpc-000","cidr_block":"10.0.0.0/24"}`},
+			{resource("aws_iam_role", "main"), `{"id":"main","arn":"arn:aws:iam::1:role/main","name":"main"}`},
+			{resource("aws_route_table", "x"), `{"id":"dup"}`},
+			{resource("aws_route_table", "y"), `{"id":"dup"}`},
+		} {
+			s.SetResourceInstanceCurrent(obj.addr, &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(obj.attrs),
+				Status:    states.ObjectReady,
+			}, addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("aws"),
+				Module:   obj.addr.Module.Module(),
+			}, addrs.NoKey)
+		}
+	})
+	changes := plans.NewChanges()
+	changes.Resources = append(changes.Resources, &plans.ResourceInstanceChangeSrc{
+		Addr:        oldVPC,
+		PrevRunAddr: oldVPC,
+		ChangeSrc:   plans.ChangeSrc{Action: plans.Delete},
+	})
+	refs := NewReferences(&plans.Plan{PriorState: state, Changes: changes})
+
+	got, count := refs.Replace(subnetA, `resource "aws_subnet" "a" {
+  vpc_id = "vpc-123"
+  role   = "arn:aws:iam::1:role/main"
+  peers  = ["subnet-a", "subnet-b", "vpc-456", "vpc-789", "vpc-000", "dup", "10.0.0.0/24"]
+}`)
+	want := `resource "aws_subnet" "a" {
+  vpc_id = aws_vpc.main.id
+  role   = aws_iam_role.main.arn
+  peers  = ["subnet-a", aws_subnet.b.id, "vpc-456", "vpc-789", "vpc-000", "dup", "10.0.0.0/24"]
+}`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong config\n%s", diff)
+	}
+	if count != 3 {
+		t.Errorf("replaced %d values, want 3", count)
+	}
+
+	// Referring back to subnet a would create a cycle.
+	got, count = refs.Replace(subnetB, `resource "aws_subnet" "b" {
+  peer = "subnet-a"
+}`)
+	if !strings.Contains(got, `peer = "subnet-a"`) || count != 0 {
+		t.Errorf("replaced a reference that creates a cycle\n%s", got)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package genconfig
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// InventoryEntry is an existing object listed in a resource inventory file,
+// for which configuration should be generated.
+type InventoryEntry struct {
+	// Type is the resource type of the object, such as "aws_instance".
+	Type string
+
+	// ID is the import ID of the object.
+	ID string
+
+	// Name is the name of the resource in the generated configuration. If
+	// the inventory doesn't set one, AssignNames derives it from the ID.
+	Name string
+}
+
+// Addr returns the address of the root module resource instance that the
+// object of the entry is imported into.
+func (e *InventoryEntry) Addr() addrs.AbsResourceInstance {
+	return addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, e.Type, e.Name, addrs.NoKey)
+}
+
+type inventoryJSONEntry struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ParseInventory parses a resource inventory. Files with the extension .json
+// must contain an array of objects, and files with the extension .csv must
+// start with a header row. In both formats, each entry has a "type" and an
+// "id", and optionally a "name".
+//
+// Entries listing the same object more than once are returned only once,
+// with a warning.
+func ParseInventory(filename string, src []byte) ([]*InventoryEntry, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	var entries []*InventoryEntry
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		entries, err = parseInventoryJSON(src)
+	case ".csv":
+		entries, err = parseInventoryCSV(src)
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported inventory format",
+			fmt.Sprintf("The inventory file %s must have the extension .json or .csv.", filename),
+		))
+		return nil, diags
+	}
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid inventory file",
+			fmt.Sprintf("Failed to parse the inventory file %s: %s.", filename, err),
+		))
+		return nil, diags
+	}
+
+	seen := make(map[[2]string]int)
+	ret := make([]*InventoryEntry, 0, len(entries))
+	for i, entry := range entries {
+		switch {
+		case !hclsyntax.ValidIdentifier(entry.Type):
+			diags = diags.Append(invalidInventoryEntry(filename, i, fmt.Sprintf("The resource type %q is not valid.", entry.Type)))
+			continue
+		case entry.ID == "":
+			diags = diags.Append(invalidInventoryEntry(filename, i, "The import ID must not be empty."))
+			continue
+		case entry.Name != "" && !hclsyntax.ValidIdentifier(entry.Name):
+			diags = diags.Append(invalidInventoryEntry(filename, i, fmt.Sprintf("The resource name %q is not valid. A name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.", entry.Name)))
+			continue
+		}
+
+		key := [2]string{entry.Type, entry.ID}
+		if prev, exists := seen[key]; exists {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Duplicate inventory entry",
+				fmt.Sprintf("Entry %d of %s lists the %s object %q again, which entry %d already listed. OpenTofu will ignore the duplicate.", i+1, filename, entry.Type, entry.ID, prev+1),
+			))
+			continue
+		}
+		seen[key] = i
+		ret = append(ret, entry)
+	}
+	return ret, diags
+}
+
+func invalidInventoryEntry(filename string, idx int, detail string) tfdiags.Diagnostic {
+	return tfdiags.Sourceless(
+		tfdiags.Error,
+		"Invalid inventory entry",
+		fmt.Sprintf("Entry %d of %s is invalid: %s", idx+1, filename, detail),
+	)
+}
+
+func parseInventoryJSON(src []byte) ([]*InventoryEntry, error) {
+	var raw []inventoryJSONEntry
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, err
+	}
+	ret := make([]*InventoryEntry, len(raw))
+	for i, entry := range raw {
+		ret[i] = &InventoryEntry{
+			Type: entry.Type,
+			ID:   entry.ID,
+			Name: entry.Name,
+		}
+	}
+	return ret, nil
+}
+
+func parseInventoryCSV(src []byte) ([]*InventoryEntry, error) {
+	r := csv.NewReader(bytes.NewReader(src))
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("the header row is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{"type": -1, "id": -1, "name": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range []string{"type", "id"} {
+		if columns[name] < 0 {
+			return nil, fmt.Errorf("the header row has no %q column", name)
+		}
+	}
+
+	var ret []*InventoryEntry
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry := &InventoryEntry{
+			Type: strings.TrimSpace(record[columns["type"]]),
+			ID:   strings.TrimSpace(record[columns["id"]]),
+		}
+		if col := columns["name"]; col >= 0 {
+			entry.Name = strings.TrimSpace(record[col])
+		}
+		ret = append(ret, entry)
+	}
+	return ret, nil
+}
+
+// AssignNames gives each entry a resource name that is unique for its
+// resource type. Entries without a name get one derived from their ID, and
+// names that are already in use get a numeric suffix.
+//
+// The taken map contains the resources that already exist, as "type.name"
+// keys, and is updated with the names assigned to the entries.
+func AssignNames(entries []*InventoryEntry, taken map[string]bool) {
+	for _, entry := range entries {
+		base := entry.Name
+		if base == "" {
+			base = nameFromID(entry.ID)
+		}
+		name := base
+		for i := 2; taken[entry.Type+"."+name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		entry.Name = name
+		taken[entry.Type+"."+name] = true
+	}
+}
+
+// nameFromID derives a resource name from an import ID, by replacing each
+// run of characters that aren't lowercase letters or digits with a single
+// underscore.
+func nameFromID(id string) string {
+	var buf strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(id) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pending && buf.Len() > 0 {
+				buf.WriteByte('_')
+			}
+			pending = false
+			buf.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+
+	name := buf.String()
+	switch {
+	case name == "":
+		return "imported"
+	case name[0] >= '0' && name[0] <= '9':
+		return "r_" + name
+	}
+	return name
+}
@@ -0,0 +1,163 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package genconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseInventory(t *testing.T) {
+	want := []*InventoryEntry{
+		{Type: "aws_vpc", ID: "vpc-123", Name: "main"},
+		{Type: "aws_subnet", ID: "subnet-456"},
+	}
+
+	tcs := map[string]struct {
+		filename string
+		src      string
+	}{
+		"json": {
+			"inventory.json",
+			`[
+  {"type": "aws_vpc", "id": "vpc-123", "name": "main", "region": "eu-west-1"},
+  {"type": "aws_subnet", "id": "subnet-456"}
+]`,
+		},
+		"csv": {
+			"inventory.CSV",
+			`Type, ID, Name
+aws_vpc, vpc-123, main
+aws_subnet, subnet-456,
+`,
+		},
+		"csv without names": {
+			"inventory.csv",
+			`id,type
+vpc-123,aws_vpc
+subnet-456,aws_subnet
+`,
+		},
+		"duplicates": {
+			"inventory.json",
+			`[
+  {"type": "aws_vpc", "id": "vpc-123", "name": "main"},
+  {"type": "aws_subnet", "id": "subnet-456"},
+  {"type": "aws_vpc", "id": "vpc-123", "name": "other"}
+]`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParseInventory(tc.filename, []byte(tc.src))
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Err())
+			}
+			want := want
+			if name == "csv without names" {
+				want = []*InventoryEntry{
+					{Type: "aws_vpc", ID: "vpc-123"},
+					{Type: "aws_subnet", ID: "subnet-456"},
+				}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("wrong entries\n%s", diff)
+			}
+			if got, want := len(diags), strings.Count(tc.src, "vpc-123")-1; got != want {
+				t.Errorf("got %d warnings, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestParseInventory_errors(t *testing.T) {
+	tcs := map[string]struct {
+		filename string
+		src      string
+		want     string
+	}{
+		"unsupported format": {
+			"inventory.yaml",
+			"",
+			"must have the extension .json or .csv",
+		},
+		"invalid json": {
+			"inventory.json",
+			`{"type": "aws_vpc"}`,
+			"Failed to parse the inventory file inventory.json",
+		},
+		"missing header": {
+			"inventory.csv",
+			"",
+			"the header row is missing",
+		},
+		"missing column": {
+			"inventory.csv",
+			"type,name\naws_vpc,main\n",
+			`the header row has no "id" column`,
+		},
+		"missing id": {
+			"inventory.json",
+			`[{"type": "aws_vpc"}]`,
+			"Entry 1 of inventory.json is invalid: The import ID must not be empty.",
+		},
+		"invalid type": {
+			"inventory.csv",
+			"type,id\naws_vpc,vpc-1\naws vpc,vpc-2\n",
+			`Entry 2 of inventory.csv is invalid: The resource type "aws vpc" is not valid.`,
+		},
+		"invalid name": {
+			"inventory.json",
+			`[{"type": "aws_vpc", "id": "vpc-1", "name": "1st"}]`,
+			`The resource name "1st" is not valid.`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseInventory(tc.filename, []byte(tc.src))
+			if !diags.HasErrors() {
+				t.Fatal("expected errors")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAssignNames(t *testing.T) {
+	entries := []*InventoryEntry{
+		{Type: "aws_vpc", ID: "vpc-123"},
+		{Type: "aws_subnet", ID: "arn:aws:ec2::Subnet/ABC--def"},
+		{Type: "aws_vpc", ID: "vpc-456", Name: "main"},
+		{Type: "aws_vpc", ID: "vpc-789", Name: "main"},
+		{Type: "aws_subnet", ID: "42"},
+		{Type: "aws_subnet", ID: "---"},
+		{Type: "aws_vpc", ID: "vpc_123"},
+	}
+	taken := map[string]bool{"aws_vpc.main": true}
+	AssignNames(entries, taken)
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Addr().String())
+	}
+	want := []string{
+		"aws_vpc.vpc_123",
+		"aws_subnet.arn_aws_ec2_subnet_abc_def",
+		"aws_vpc.main_2",
+		"aws_vpc.main_3",
+		"aws_subnet.r_42",
+		"aws_subnet.imported",
+		"aws_vpc.vpc_123_2",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong names\n%s", diff)
+	}
+	if !taken["aws_vpc.main_3"] {
+		t.Error("assigned names weren't marked as taken")
+	}
+}
@@ -122,12 +122,15 @@ type reusingGetter map[string]string
 // use a string here but assume that the caller got that value by calling
 // the String method on a valid addrs.ModulePackage value.
 //
+// getters is the set of getters to use, which is goGetterGetters plus any
+// getters that belong to a specific PackageFetcher.
+//
 // The errors returned by this function are those surfaced by the underlying
 // go-getter library, which have very inconsistent quality as
 // end-user-actionable error messages. At this time we do not have any
 // reasonable way to improve these error messages at this layer because
 // the underlying errors are not separately recognizable.
-func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAddr string) error {
+func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAddr string, getters map[string]getter.Getter) error {
 	var err error
 
 	if prevDir, exists := g[packageAddr]; exists {
@@ -151,7 +154,7 @@ func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAdd
 
 			Detectors:     goGetterNoDetectors, // our caller should've already done detection
 			Decompressors: goGetterDecompressors,
-			Getters:       goGetterGetters,
+			Getters:       getters,
 			Ctx:           ctx,
 		}
 		err = client.Get()
@@ -7,6 +7,11 @@ package getmodules
 
 import (
 	"context"
+	"net/url"
+
+	getter "github.com/hashicorp/go-getter"
+
+	"github.com/opentofu/opentofu/internal/ociregistry"
 )
 
 // PackageFetcher is a low-level utility for fetching remote module packages
@@ -23,12 +28,25 @@ import (
 // no way to reset this cache, so a particular PackageFetcher instance should
 // live only for the duration of a single initialization process.
 type PackageFetcher struct {
-	getter reusingGetter
+	getter  reusingGetter
+	getters map[string]getter.Getter
+	oci     *ociGetter
 }
 
-func NewPackageFetcher() *PackageFetcher {
+// NewPackageFetcher returns a new PackageFetcher that uses ociStore to
+// access the repositories of any "oci:" package addresses. ociStore may be
+// nil, in which case installing such packages fails.
+func NewPackageFetcher(ociStore ociregistry.RepositoryStoreFunc) *PackageFetcher {
+	oci := &ociGetter{store: ociStore}
+	getters := make(map[string]getter.Getter, len(goGetterGetters)+1)
+	for scheme, g := range goGetterGetters {
+		getters[scheme] = g
+	}
+	getters["oci"] = oci
 	return &PackageFetcher{
-		getter: reusingGetter{},
+		getter:  reusingGetter{},
+		getters: getters,
+		oci:     oci,
 	}
 }
 
@@ -45,5 +63,22 @@ func NewPackageFetcher() *PackageFetcher {
 // caller must resolve that itself, possibly with the help of the
 // getmodules.SplitPackageSubdir and getmodules.ExpandSubdirGlobs functions.
 func (f *PackageFetcher) FetchPackage(ctx context.Context, instDir string, packageAddr string) error {
-	return f.getter.getWithGoGetter(ctx, instDir, packageAddr)
+	return f.getter.getWithGoGetter(ctx, instDir, packageAddr, f.getters)
+}
+
+// ResolvePackage returns the address of exactly the package that the given
+// package address currently refers to, for addresses whose target can change
+// over time without the address changing.
+//
+// Currently that's only "oci:" addresses that refer to a tag, for which the
+// result refers to the digest that the tag currently refers to instead. All
+// other addresses are returned unchanged.
+//
+// packageAddr must be formatted in the same way as for FetchPackage.
+func (f *PackageFetcher) ResolvePackage(ctx context.Context, packageAddr string) (string, error) {
+	u, err := url.Parse(packageAddr)
+	if err != nil || u.Scheme != "oci" {
+		return packageAddr, nil
+	}
+	return f.oci.resolve(ctx, u)
 }
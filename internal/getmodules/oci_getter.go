@@ -0,0 +1,171 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+
+	getter "github.com/hashicorp/go-getter"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orascontent "oras.land/oras-go/v2/content"
+	orasregistry "oras.land/oras-go/v2/registry"
+
+	"github.com/opentofu/opentofu/internal/ociregistry"
+)
+
+const (
+	// OCIModuleArtifactType is the artifact type of the image manifest that
+	// an "oci:" module source address refers to.
+	OCIModuleArtifactType = "application/vnd.opentofu.modulepkg"
+
+	// OCIModuleMediaType is the media type of the single layer of an image
+	// manifest of type [OCIModuleArtifactType], which is a .zip archive of
+	// the module package.
+	OCIModuleMediaType = "archive/zip"
+)
+
+// ociGetter is a go-getter getter for "oci:" package addresses, which refer
+// to a tag or digest of an artifact of type [OCIModuleArtifactType] in an
+// OCI Distribution registry, such as oci://example.com/modules/vpc:1.0.0.
+//
+// Unlike the other getters, ociGetter is not stateless: it uses the OCI
+// repository store function of the PackageFetcher it belongs to, so that
+// the registries can use the credentials from the CLI configuration.
+type ociGetter struct {
+	store  ociregistry.RepositoryStoreFunc
+	client *getter.Client
+}
+
+var _ getter.Getter = (*ociGetter)(nil)
+
+func (g *ociGetter) ClientMode(*url.URL) (getter.ClientMode, error) {
+	return getter.ClientModeDir, nil
+}
+
+func (g *ociGetter) SetClient(c *getter.Client) {
+	g.client = c
+}
+
+func (g *ociGetter) GetFile(string, *url.URL) error {
+	return fmt.Errorf("an OCI module package can only be installed as a directory")
+}
+
+func (g *ociGetter) Get(dst string, u *url.URL) error {
+	ctx := context.Background()
+	var umask os.FileMode
+	if g.client != nil {
+		if g.client.Ctx != nil {
+			ctx = g.client.Ctx
+		}
+		umask = g.client.Umask
+	}
+
+	ref, err := parseOCIPackageURL(u)
+	if err != nil {
+		return err
+	}
+	store, err := g.repository(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	manifestDesc, err := store.Resolve(ctx, ref.Reference)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	if ref.ValidateReferenceAsDigest() == nil && manifestDesc.Digest.String() != ref.Reference {
+		return fmt.Errorf("registry returned manifest %s for %s", manifestDesc.Digest, ref)
+	}
+	if manifestDesc.MediaType != ocispec.MediaTypeImageManifest {
+		return fmt.Errorf("%s refers to %s rather than an image manifest", ref, manifestDesc.MediaType)
+	}
+	var manifest ocispec.Manifest
+	if err := ociregistry.FetchManifest(ctx, store, manifestDesc, &manifest); err != nil {
+		return fmt.Errorf("failed to fetch manifest of %s: %w", ref, err)
+	}
+	if manifest.ArtifactType != OCIModuleArtifactType {
+		return fmt.Errorf("%s is an artifact of type %q rather than an OpenTofu module package", ref, manifest.ArtifactType)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != OCIModuleMediaType {
+		return fmt.Errorf("manifest of %s must have exactly one layer of type %q", ref, OCIModuleMediaType)
+	}
+	blob := manifest.Layers[0]
+
+	log.Printf("[TRACE] getmodules: fetching %s@%s from %s", ref.Registry+"/"+ref.Repository, blob.Digest, ref)
+	rc, err := store.Fetch(ctx, blob)
+	if err != nil {
+		return fmt.Errorf("failed to fetch package of %s: %w", ref, err)
+	}
+	defer rc.Close()
+
+	f, err := os.CreateTemp("", "tofu-module")
+	if err != nil {
+		return fmt.Errorf("failed to open temporary file to download %s: %w", ref, err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	vr := orascontent.NewVerifyReader(rc, blob)
+	if _, err := getter.Copy(ctx, f, vr); err != nil {
+		return fmt.Errorf("failed to fetch package of %s: %w", ref, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("failed to fetch package of %s: %w", ref, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return goGetterDecompressors["zip"].Decompress(dst, f.Name(), true, umask)
+}
+
+// resolve returns the "oci:" package address that refers to the digest of
+// the manifest that the tag in the given address currently refers to. An
+// address that already refers to a digest is returned unchanged.
+func (g *ociGetter) resolve(ctx context.Context, u *url.URL) (string, error) {
+	ref, err := parseOCIPackageURL(u)
+	if err != nil {
+		return "", err
+	}
+	if ref.ValidateReferenceAsDigest() == nil {
+		return u.String(), nil
+	}
+	store, err := g.repository(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	desc, err := store.Resolve(ctx, ref.Reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	ref.Reference = desc.Digest.String()
+	return "oci://" + ref.String(), nil
+}
+
+func (g *ociGetter) repository(ctx context.Context, ref orasregistry.Reference) (ociregistry.RepositoryStore, error) {
+	if g.store == nil {
+		return nil, fmt.Errorf("OCI registries are not available for installing %s", ref)
+	}
+	return g.store(ctx, ref.Registry, ref.Repository)
+}
+
+// parseOCIPackageURL parses the registry, repository and tag or digest out
+// of an "oci:" package address.
+func parseOCIPackageURL(u *url.URL) (orasregistry.Reference, error) {
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return orasregistry.Reference{}, fmt.Errorf("invalid OCI module source %q: must be oci://REGISTRY/REPOSITORY followed by :TAG or @DIGEST", u.String())
+	}
+	ref, err := orasregistry.ParseReference(u.Host + u.Path)
+	if err != nil {
+		return orasregistry.Reference{}, fmt.Errorf("invalid OCI module source %q: %w", u.String(), err)
+	}
+	if ref.Reference == "" {
+		return orasregistry.Reference{}, fmt.Errorf("invalid OCI module source %q: must specify a tag or a digest", u.String())
+	}
+	return ref, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orasremote "oras.land/oras-go/v2/registry/remote"
+
+	"github.com/opentofu/opentofu/internal/ociregistry"
+	"github.com/opentofu/opentofu/internal/ociregistry/ocitest"
+)
+
+func TestPackageFetcher_oci(t *testing.T) {
+	registry := ocitest.NewRegistry()
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	registryDomain := strings.TrimPrefix(server.URL, "http://")
+
+	config := registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	pushModule := func(content string) ocispec.Descriptor {
+		return registry.PushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: OCIModuleArtifactType,
+			Config:       config,
+			Layers:       []ocispec.Descriptor{registry.Push(OCIModuleMediaType, testOCIModuleArchive(t, content))},
+		})
+	}
+	v1 := pushModule("# v1\n")
+	registry.Tag("modules/vpc", "1.0.0", v1)
+	registry.Tag("modules/vpc", "image", registry.PushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.example.other",
+		Config:       config,
+	}))
+
+	fetcher := NewPackageFetcher(func(_ context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryStore, error) {
+		repo, err := orasremote.NewRepository(registryDomain + "/" + repositoryName)
+		if err != nil {
+			return nil, err
+		}
+		repo.PlainHTTP = true
+		repo.Client = server.Client()
+		return repo, nil
+	})
+	tagAddr := "oci://" + registryDomain + "/modules/vpc:1.0.0"
+	digestAddr := "oci://" + registryDomain + "/modules/vpc@" + v1.Digest.String()
+
+	t.Run("resolve tag", func(t *testing.T) {
+		got, err := fetcher.ResolvePackage(context.Background(), tagAddr)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != digestAddr {
+			t.Errorf("wrong result\ngot:  %s\nwant: %s", got, digestAddr)
+		}
+	})
+	t.Run("resolve digest", func(t *testing.T) {
+		got, err := fetcher.ResolvePackage(context.Background(), digestAddr)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != digestAddr {
+			t.Errorf("wrong result\ngot:  %s\nwant: %s", got, digestAddr)
+		}
+	})
+	t.Run("resolve other address", func(t *testing.T) {
+		const addr = "git::https://example.com/vpc.git"
+		got, err := fetcher.ResolvePackage(context.Background(), addr)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != addr {
+			t.Errorf("wrong result\ngot:  %s\nwant: %s", got, addr)
+		}
+	})
+	t.Run("fetch digest after the tag moved", func(t *testing.T) {
+		registry.Tag("modules/vpc", "1.0.0", pushModule("# v2\n"))
+		defer registry.Tag("modules/vpc", "1.0.0", v1)
+
+		instDir := filepath.Join(t.TempDir(), "vpc")
+		if err := fetcher.FetchPackage(context.Background(), instDir, digestAddr); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got, err := os.ReadFile(filepath.Join(instDir, "main.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "# v1\n" {
+			t.Errorf("wrong module content %q", got)
+		}
+	})
+	t.Run("fetch artifact that isn't a module", func(t *testing.T) {
+		err := fetcher.FetchPackage(context.Background(), filepath.Join(t.TempDir(), "vpc"), "oci://"+registryDomain+"/modules/vpc:image")
+		if err == nil || !strings.Contains(err.Error(), `artifact of type "application/vnd.example.other" rather than an OpenTofu module package`) {
+			t.Errorf("wrong error: %v", err)
+		}
+	})
+	t.Run("fetch without OCI registry access", func(t *testing.T) {
+		err := NewPackageFetcher(nil).FetchPackage(context.Background(), filepath.Join(t.TempDir(), "vpc"), tagAddr)
+		if err == nil || !strings.Contains(err.Error(), "OCI registries are not available") {
+			t.Errorf("wrong error: %v", err)
+		}
+	})
+}
+
+// testOCIModuleArchive returns a module package archive with a single
+// main.tf file with the given content.
+func testOCIModuleArchive(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -6,6 +6,8 @@
 package getmodules
 
 import (
+	"net/url"
+
 	getter "github.com/hashicorp/go-getter"
 )
 
@@ -70,5 +72,14 @@ func NormalizePackageAddress(given string) (packageAddr, subDir string, err erro
 	}
 
 	packageAddr, subDir = SplitPackageSubdir(result)
+
+	// go-getter doesn't know about our "oci:" addresses, so we check that
+	// they are valid here so that the problem is reported while decoding
+	// the configuration rather than only during installation.
+	if u, err := url.Parse(packageAddr); err == nil && u.Scheme == "oci" {
+		if _, err := parseOCIPackageURL(u); err != nil {
+			return "", "", err
+		}
+	}
 	return packageAddr, subDir, nil
 }
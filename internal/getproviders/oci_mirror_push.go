@@ -0,0 +1,132 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orascontent "oras.land/oras-go/v2/content"
+
+	"github.com/opentofu/opentofu/internal/ociregistry"
+)
+
+// PushToOCIRepository publishes the given packages of a single version of a
+// provider to an OCI repository, in the layout that OCIRegistryMirrorSource
+// reads: a tag for the version that refers to an index manifest, which in
+// turn refers to an image manifest for each platform whose single layer is
+// the package's .zip archive.
+//
+// Content that is already in the repository isn't pushed again. The result
+// is false if the tag already referred to the same index manifest, in which
+// case the repository is unchanged.
+func PushToOCIRepository(ctx context.Context, store ociregistry.RepositoryPushStore, version Version, archives map[Platform]PackageLocalArchive) (bool, error) {
+	config := ocispec.DescriptorEmptyJSON
+	if err := ociPushIfMissing(ctx, store, config, bytes.NewReader(config.Data)); err != nil {
+		return false, fmt.Errorf("pushing manifest config: %w", err)
+	}
+
+	platforms := make([]Platform, 0, len(archives))
+	for platform := range archives {
+		platforms = append(platforms, platform)
+	}
+	sort.Slice(platforms, func(i, j int) bool {
+		return platforms[i].LessThan(platforms[j])
+	})
+
+	index := ocispec.Index{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: OCIIndexArtifactType,
+	}
+	for _, platform := range platforms {
+		blob, err := ociPushArchive(ctx, store, string(archives[platform]))
+		if err != nil {
+			return false, fmt.Errorf("pushing package for %s: %w", platform, err)
+		}
+		manifest, err := ociPushManifest(ctx, store, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			Versioned:    specs.Versioned{SchemaVersion: 2},
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: OCIPackageArtifactType,
+			Config:       config,
+			Layers:       []ocispec.Descriptor{blob},
+		})
+		if err != nil {
+			return false, fmt.Errorf("pushing manifest for %s: %w", platform, err)
+		}
+		manifest.ArtifactType = OCIPackageArtifactType
+		manifest.Platform = &ocispec.Platform{OS: platform.OS, Architecture: platform.Arch}
+		index.Manifests = append(index.Manifests, manifest)
+	}
+
+	indexDesc, err := ociPushManifest(ctx, store, ocispec.MediaTypeImageIndex, index)
+	if err != nil {
+		return false, fmt.Errorf("pushing index manifest: %w", err)
+	}
+	if current, err := store.Resolve(ctx, version.String()); err == nil && current.Digest == indexDesc.Digest {
+		return false, nil
+	}
+	if err := store.Tag(ctx, indexDesc, version.String()); err != nil {
+		return false, fmt.Errorf("tagging version %s: %w", version, err)
+	}
+	return true, nil
+}
+
+// ociPushArchive pushes the .zip archive at the given path as a blob, and
+// returns its descriptor.
+func ociPushArchive(ctx context.Context, store ociregistry.RepositoryPushStore, filename string) (ocispec.Descriptor, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer f.Close()
+
+	digester := digest.SHA256.Digester()
+	size, err := io.Copy(digester.Hash(), f)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: OCIPackageMediaType,
+		Digest:    digester.Digest(),
+		Size:      size,
+	}
+	return desc, ociPushIfMissing(ctx, store, desc, f)
+}
+
+// ociPushManifest pushes the JSON encoding of the given manifest, and returns
+// its descriptor.
+func ociPushManifest(ctx context.Context, store ociregistry.RepositoryPushStore, mediaType string, manifest any) (ocispec.Descriptor, error) {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := orascontent.NewDescriptorFromBytes(mediaType, content)
+	return desc, ociPushIfMissing(ctx, store, desc, bytes.NewReader(content))
+}
+
+// ociPushIfMissing pushes the given content, unless the repository already
+// has it.
+func ociPushIfMissing(ctx context.Context, store ociregistry.RepositoryPushStore, desc ocispec.Descriptor, content io.Reader) error {
+	exists, err := store.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return store.Push(ctx, desc, content)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	orasremote "oras.land/oras-go/v2/registry/remote"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/ociregistry"
+	"github.com/opentofu/opentofu/internal/ociregistry/ocitest"
+)
+
+func TestPushToOCIRepository(t *testing.T) {
+	registry := ocitest.NewRegistry()
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	registryDomain := strings.TrimPrefix(server.URL, "http://")
+
+	repository := func(_ context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryStore, error) {
+		repo, err := orasremote.NewRepository(registryDomain + "/" + repositoryName)
+		if err != nil {
+			return nil, err
+		}
+		repo.PlainHTTP = true
+		repo.Client = server.Client()
+		return repo, nil
+	}
+	store, err := repository(context.Background(), registryDomain, "test/exists")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "terraform-provider-exists_1.0.0_linux_amd64.zip")
+	if err := os.WriteFile(archivePath, testOCIProviderArchive(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+	linuxPlatform := Platform{OS: "linux", Arch: "amd64"}
+	archives := map[Platform]PackageLocalArchive{linuxPlatform: PackageLocalArchive(archivePath)}
+	version := MustParseVersion("1.0.0")
+
+	changed, err := PushToOCIRepository(context.Background(), store.(ociregistry.RepositoryPushStore), version, archives)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !changed {
+		t.Error("first push reported no change")
+	}
+
+	// Pushing the same packages again leaves the repository unchanged.
+	changed, err = PushToOCIRepository(context.Background(), store.(ociregistry.RepositoryPushStore), version, archives)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if changed {
+		t.Error("second push reported a change")
+	}
+
+	// The OCI mirror source can install what was pushed.
+	source := NewOCIRegistryMirrorSource(
+		func(provider addrs.Provider) (string, string, error) {
+			return registryDomain, provider.Namespace + "/" + provider.Type, nil
+		},
+		repository,
+	)
+	provider := addrs.MustParseProviderSourceString("example.com/test/exists")
+	got, _, err := source.AvailableVersions(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(VersionList{version}, got); diff != "" {
+		t.Errorf("wrong versions\n%s", diff)
+	}
+	meta, err := source.PackageMeta(context.Background(), provider, version, linuxPlatform)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantHash, err := PackageHashV1(PackageLocalArchive(archivePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	targetDir := t.TempDir()
+	if _, err := meta.Location.InstallProviderPackage(context.Background(), meta, targetDir, nil); err != nil {
+		t.Fatalf("unexpected install error: %s", err)
+	}
+	if matches, err := PackageMatchesHash(PackageLocalDir(targetDir), wantHash); err != nil || !matches {
+		t.Errorf("installed package doesn't match the pushed archive (error %v)", err)
+	}
+	if _, err := source.PackageMeta(context.Background(), provider, version, Platform{OS: "darwin", Arch: "arm64"}); err == nil {
+		t.Error("expected an error for a platform that wasn't pushed")
+	}
+}
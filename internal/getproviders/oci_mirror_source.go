@@ -0,0 +1,225 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/ociregistry"
+)
+
+const (
+	// OCIIndexArtifactType is the artifact type of the index manifest that
+	// a tag of a provider's OCI repository refers to, with one descriptor
+	// for each platform that the tagged version of the provider supports.
+	OCIIndexArtifactType = "application/vnd.opentofu.provider"
+
+	// OCIPackageArtifactType is the artifact type of the image manifest of
+	// a provider package for a single platform.
+	OCIPackageArtifactType = "application/vnd.opentofu.provider-target"
+
+	// OCIPackageMediaType is the media type of the single layer of an image
+	// manifest of type [OCIPackageArtifactType], which is the provider's
+	// .zip archive for that platform.
+	OCIPackageMediaType = "archive/zip"
+)
+
+// OCIRepositoryAddrFunc returns the registry domain and the repository name
+// of the OCI repository where the given provider is mirrored.
+type OCIRepositoryAddrFunc func(provider addrs.Provider) (registryDomain, repositoryName string, err error)
+
+// OCIRegistryMirrorSource is a source that reads providers from repositories
+// in OCI Distribution registries, with one repository per provider.
+//
+// Each version of a provider is a tag of its repository that refers to an
+// index manifest of type [OCIIndexArtifactType], which in turn refers to an
+// image manifest of type [OCIPackageArtifactType] for each platform that
+// the version supports. The single layer of each of those is the provider's
+// .zip archive for that platform.
+type OCIRegistryMirrorSource struct {
+	repositoryAddr OCIRepositoryAddrFunc
+	store          ociregistry.RepositoryStoreFunc
+}
+
+var _ Source = (*OCIRegistryMirrorSource)(nil)
+
+// NewOCIRegistryMirrorSource constructs and returns a new OCI mirror source
+// that uses repositoryAddr to decide where to find each provider and store
+// to access the repositories.
+func NewOCIRegistryMirrorSource(repositoryAddr OCIRepositoryAddrFunc, store ociregistry.RepositoryStoreFunc) *OCIRegistryMirrorSource {
+	return &OCIRegistryMirrorSource{
+		repositoryAddr: repositoryAddr,
+		store:          store,
+	}
+}
+
+// AvailableVersions returns the versions of the given provider that have
+// tags in its OCI repository. Tags that aren't valid versions are ignored.
+func (s *OCIRegistryMirrorSource) AvailableVersions(ctx context.Context, provider addrs.Provider) (VersionList, Warnings, error) {
+	store, mirrorURL, err := s.repository(ctx, provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[DEBUG] Querying available versions of provider %s at OCI mirror %s", provider, mirrorURL)
+
+	var ret VersionList
+	err = store.Tags(ctx, "", func(tags []string) error {
+		for _, tag := range tags {
+			version, err := ParseVersion(tag)
+			if err != nil {
+				log.Printf("[TRACE] Ignoring tag %q of %s, which is not a version", tag, mirrorURL)
+				continue
+			}
+			ret = append(ret, version)
+		}
+		return nil
+	})
+	if err != nil {
+		if ociIsNotFound(err) {
+			return nil, nil, ErrProviderNotFound{
+				Provider: provider,
+			}
+		}
+		return nil, nil, s.errQueryFailed(provider, mirrorURL, err)
+	}
+
+	ret.Sort()
+	return ret, nil, nil
+}
+
+// PackageMeta returns the metadata of the package of the given version of
+// the given provider for the given platform, whose location is a blob in
+// the provider's OCI repository.
+func (s *OCIRegistryMirrorSource) PackageMeta(ctx context.Context, provider addrs.Provider, version Version, target Platform) (PackageMeta, error) {
+	store, mirrorURL, err := s.repository(ctx, provider)
+	if err != nil {
+		return PackageMeta{}, err
+	}
+	log.Printf("[DEBUG] Finding package of %s v%s for %s at OCI mirror %s", provider, version, target, mirrorURL)
+
+	indexDesc, err := store.Resolve(ctx, version.String())
+	if err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, mirrorURL, fmt.Errorf("resolving tag for version %s: %w", version, err))
+	}
+	if indexDesc.MediaType != ocispec.MediaTypeImageIndex {
+		return PackageMeta{}, s.errQueryFailed(provider, mirrorURL, fmt.Errorf("tag %s refers to %s rather than an index manifest", version, indexDesc.MediaType))
+	}
+	var index ocispec.Index
+	if err := ociregistry.FetchManifest(ctx, store, indexDesc, &index); err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, mirrorURL, fmt.Errorf("fetching index manifest for version %s: %w", version, err))
+	}
+	if index.ArtifactType != OCIIndexArtifactType {
+		return PackageMeta{}, s.errQueryFailed(provider, mirrorURL, fmt.Errorf("tag %s refers to an artifact of type %q rather than an OpenTofu provider", version, index.ArtifactType))
+	}
+
+	var manifestDesc *ocispec.Descriptor
+	for i, desc := range index.Manifests {
+		if desc.ArtifactType != OCIPackageArtifactType || desc.Platform == nil {
+			continue
+		}
+		if desc.Platform.OS == target.OS && desc.Platform.Architecture == target.Arch {
+			manifestDesc = &index.Manifests[i]
+			break
+		}
+	}
+	if manifestDesc == nil {
+		return PackageMeta{}, ErrPlatformNotSupported{
+			Provider:  provider,
+			Version:   version,
+			Platform:  target,
+			MirrorURL: mirrorURL,
+		}
+	}
+	var manifest ocispec.Manifest
+	if err := ociregistry.FetchManifest(ctx, store, *manifestDesc, &manifest); err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, mirrorURL, fmt.Errorf("fetching manifest for version %s on %s: %w", version, target, err))
+	}
+	if manifest.ArtifactType != OCIPackageArtifactType || len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != OCIPackageMediaType {
+		return PackageMeta{}, s.errQueryFailed(provider, mirrorURL, fmt.Errorf("manifest for version %s on %s must have exactly one layer of type %q", version, target, OCIPackageMediaType))
+	}
+	blob := manifest.Layers[0]
+
+	// The digest of the blob is the SHA256 checksum of the .zip archive,
+	// which is exactly what our "zh:" hashes are. The archive must match it
+	// regardless, but recording it allows the lock file to verify the same
+	// package from other sources too.
+	var hashes []Hash
+	if blob.Digest.Algorithm() == "sha256" {
+		hashes = append(hashes, HashSchemeZip.New(blob.Digest.Encoded()))
+	}
+
+	return PackageMeta{
+		Provider:       provider,
+		Version:        version,
+		TargetPlatform: target,
+
+		Location: PackageOCIBlobArchive{
+			Store:      store,
+			Descriptor: blob,
+			MirrorURL:  mirrorURL,
+		},
+		Filename:       fmt.Sprintf("terraform-provider-%s_%s_%s.zip", provider.Type, version, target),
+		Authentication: NewPackageHashAuthentication(target, hashes),
+	}, nil
+}
+
+// ForDisplay returns a string description of the source for user-facing output.
+func (s *OCIRegistryMirrorSource) ForDisplay(provider addrs.Provider) string {
+	registryDomain, repositoryName, err := s.repositoryAddr(provider)
+	if err != nil {
+		return "OCI provider mirror"
+	}
+	return "OCI provider mirror at " + registryDomain + "/" + repositoryName
+}
+
+// repository returns the store for the repository of the given provider and
+// a URL representing the repository in error messages.
+func (s *OCIRegistryMirrorSource) repository(ctx context.Context, provider addrs.Provider) (ociregistry.RepositoryStore, *url.URL, error) {
+	registryDomain, repositoryName, err := s.repositoryAddr(provider)
+	if err != nil {
+		return nil, nil, ErrQueryFailed{
+			Provider: provider,
+			Wrapped:  fmt.Errorf("no OCI repository for this provider: %w", err),
+		}
+	}
+	mirrorURL := &url.URL{Scheme: "oci", Host: registryDomain, Path: "/" + repositoryName}
+	store, err := s.store(ctx, registryDomain, repositoryName)
+	if err != nil {
+		return nil, nil, s.errQueryFailed(provider, mirrorURL, err)
+	}
+	return store, mirrorURL, nil
+}
+
+func (s *OCIRegistryMirrorSource) errQueryFailed(provider addrs.Provider, mirrorURL *url.URL, err error) error {
+	if errors.Is(err, context.Canceled) {
+		// This one has a special error type so that callers can
+		// handle it in a different way.
+		return ErrRequestCanceled{}
+	}
+	return ErrQueryFailed{
+		Provider:  provider,
+		Wrapped:   err,
+		MirrorURL: mirrorURL,
+	}
+}
+
+// ociIsNotFound returns true if the given error from an OCI repository
+// indicates that the requested repository or object doesn't exist.
+func ociIsNotFound(err error) bool {
+	if errors.Is(err, errdef.ErrNotFound) {
+		return true
+	}
+	var respErr *errcode.ErrorResponse
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
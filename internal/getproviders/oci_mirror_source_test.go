@@ -0,0 +1,178 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orasremote "oras.land/oras-go/v2/registry/remote"
+	orasauth "oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/ociregistry"
+	"github.com/opentofu/opentofu/internal/ociregistry/ocitest"
+)
+
+func TestOCIRegistryMirrorSource(t *testing.T) {
+	registry := ocitest.NewRegistry()
+	registry.Username, registry.Password = "user", "password"
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	registryDomain := strings.TrimPrefix(server.URL, "http://")
+
+	// The repository of the "exists" provider has two versions, one of which
+	// is available for linux_amd64 and the other for no platform at all.
+	archive := testOCIProviderArchive(t)
+	blob := registry.Push(OCIPackageMediaType, archive)
+	config := registry.Push(ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	manifest := registry.PushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: OCIPackageArtifactType,
+		Config:       config,
+		Layers:       []ocispec.Descriptor{blob},
+	})
+	manifest.ArtifactType = OCIPackageArtifactType
+	manifest.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	registry.Tag("test/exists", "1.0.0", registry.PushJSON(ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: OCIIndexArtifactType,
+		Manifests:    []ocispec.Descriptor{manifest},
+	}))
+	registry.Tag("test/exists", "1.1.0", registry.PushJSON(ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType:    ocispec.MediaTypeImageIndex,
+		ArtifactType: OCIIndexArtifactType,
+	}))
+	registry.Tag("test/exists", "latest", manifest)
+
+	newSource := func(password string) *OCIRegistryMirrorSource {
+		return NewOCIRegistryMirrorSource(
+			func(provider addrs.Provider) (string, string, error) {
+				return registryDomain, provider.Namespace + "/" + provider.Type, nil
+			},
+			func(_ context.Context, registryDomain, repositoryName string) (ociregistry.RepositoryStore, error) {
+				repo, err := orasremote.NewRepository(registryDomain + "/" + repositoryName)
+				if err != nil {
+					return nil, err
+				}
+				repo.PlainHTTP = true
+				repo.Client = &orasauth.Client{
+					Client: server.Client(),
+					Credential: orasauth.StaticCredential(registryDomain, orasauth.Credential{
+						Username: "user",
+						Password: password,
+					}),
+				}
+				return repo, nil
+			},
+		)
+	}
+	source := newSource("password")
+
+	existingProvider := addrs.MustParseProviderSourceString("example.com/test/exists")
+	missingProvider := addrs.MustParseProviderSourceString("example.com/test/missing")
+	linuxPlatform := Platform{OS: "linux", Arch: "amd64"}
+
+	t.Run("AvailableVersions for provider that exists", func(t *testing.T) {
+		got, _, err := source.AvailableVersions(context.Background(), existingProvider)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := VersionList{
+			MustParseVersion("1.0.0"),
+			MustParseVersion("1.1.0"),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+	t.Run("AvailableVersions for provider that doesn't exist", func(t *testing.T) {
+		_, _, err := source.AvailableVersions(context.Background(), missingProvider)
+		var notFound ErrProviderNotFound
+		if !errors.As(err, &notFound) {
+			t.Fatalf("wrong error %#v; want ErrProviderNotFound", err)
+		}
+	})
+	t.Run("AvailableVersions with wrong credentials", func(t *testing.T) {
+		_, _, err := newSource("wrong").AvailableVersions(context.Background(), existingProvider)
+		var queryFailed ErrQueryFailed
+		if !errors.As(err, &queryFailed) {
+			t.Fatalf("wrong error %#v; want ErrQueryFailed", err)
+		}
+		if got, want := err.Error(), "401"; !strings.Contains(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("PackageMeta and install", func(t *testing.T) {
+		meta, err := source.PackageMeta(context.Background(), existingProvider, MustParseVersion("1.0.0"), linuxPlatform)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		wantHash := HashSchemeZip.New(blob.Digest.Encoded())
+		if got := meta.AcceptableHashes(); !cmp.Equal(got, []Hash{wantHash}) {
+			t.Errorf("wrong hashes %s; want %s", got, wantHash)
+		}
+		if got, want := meta.Location.String(), registryDomain+"/test/exists@"+blob.Digest.String(); got != want {
+			t.Errorf("wrong location\ngot:  %s\nwant: %s", got, want)
+		}
+
+		targetDir := t.TempDir()
+		if _, err := meta.Location.InstallProviderPackage(context.Background(), meta, targetDir, []Hash{wantHash}); err != nil {
+			t.Fatalf("unexpected install error: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(targetDir, "terraform-provider-exists")); err != nil {
+			t.Errorf("package wasn't extracted: %s", err)
+		}
+
+		_, err = meta.Location.InstallProviderPackage(context.Background(), meta, t.TempDir(), []Hash{HashSchemeZip.New(strings.Repeat("0", 64))})
+		if err == nil || !strings.Contains(err.Error(), "doesn't match any of the checksums previously recorded in the dependency lock file") {
+			t.Errorf("wrong error for mismatched lock file hash: %v", err)
+		}
+	})
+	t.Run("PackageMeta for unsupported platform", func(t *testing.T) {
+		_, err := source.PackageMeta(context.Background(), existingProvider, MustParseVersion("1.1.0"), linuxPlatform)
+		var notSupported ErrPlatformNotSupported
+		if !errors.As(err, &notSupported) {
+			t.Fatalf("wrong error %#v; want ErrPlatformNotSupported", err)
+		}
+		if got, want := err.Error(), "provider mirror oci://"+registryDomain+"/test/exists does not have a package"; !strings.Contains(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("PackageMeta for a tag that isn't an index", func(t *testing.T) {
+		registry.Tag("test/exists", "2.0.0", manifest)
+		_, err := source.PackageMeta(context.Background(), existingProvider, MustParseVersion("2.0.0"), linuxPlatform)
+		if err == nil || !strings.Contains(err.Error(), "rather than an index manifest") {
+			t.Errorf("wrong error: %v", err)
+		}
+	})
+}
+
+// testOCIProviderArchive returns a provider package archive with a single
+// executable file in it.
+func testOCIProviderArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("terraform-provider-exists")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("#!/bin/sh\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -24,8 +24,10 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getmodules"
 	"github.com/opentofu/opentofu/internal/modsdir"
+	"github.com/opentofu/opentofu/internal/ociregistry"
 	"github.com/opentofu/opentofu/internal/registry"
 	"github.com/opentofu/opentofu/internal/registry/regsrc"
 	"github.com/opentofu/opentofu/internal/registry/response"
@@ -33,9 +35,11 @@ import (
 )
 
 type ModuleInstaller struct {
-	modsDir string
-	loader  *configload.Loader
-	reg     *registry.Client
+	modsDir  string
+	loader   *configload.Loader
+	reg      *registry.Client
+	ociStore ociregistry.RepositoryStoreFunc
+	locks    *depsfile.Locks
 
 	// The keys in moduleVersions are resolved and trimmed registry source
 	// addresses and the values are the registry response.
@@ -61,6 +65,25 @@ func NewModuleInstaller(modsDir string, loader *configload.Loader, reg *registry
 	}
 }
 
+// SetOCIRepositoryStore sets the function that the installer uses to access
+// OCI repositories when installing modules with "oci:" source addresses.
+// Installing such modules fails if this is never called.
+func (i *ModuleInstaller) SetOCIRepositoryStore(store ociregistry.RepositoryStoreFunc) {
+	i.ociStore = store
+}
+
+// SetLocks sets the dependency locks that the installer uses to choose
+// exactly which package to install for remote module packages whose address
+// can refer to different contents over time, such as "oci:" addresses that
+// refer to a tag. InstallModules updates the given locks to record the
+// packages it selected, unless installation fails.
+//
+// If this is never called then such addresses are resolved again whenever
+// a module is installed from them.
+func (i *ModuleInstaller) SetLocks(locks *depsfile.Locks) {
+	i.locks = locks
+}
+
 // InstallModules analyses the root module in the given directory and installs
 // all of its direct and transitive dependencies into the given modules
 // directory, which must already exist.
@@ -74,8 +97,10 @@ func NewModuleInstaller(modsDir string, loader *configload.Loader, reg *registry
 // process.
 //
 // If modules are already installed in the target directory, they will be
-// skipped unless their source address or version have changed or unless
-// the upgrade flag is set.
+// skipped unless their source address or version have changed, unless the
+// package selected for them in the dependency locks has changed, or unless
+// the upgrade flag is set. The upgrade flag also disregards the dependency
+// locks, so that tags are resolved again.
 //
 // InstallModules never deletes any directory, except in the case where it
 // needs to replace a directory that is already present with a newly-extracted
@@ -124,7 +149,7 @@ func (i *ModuleInstaller) InstallModules(ctx context.Context, rootDir, testsDir
 		return nil, diags
 	}
 
-	fetcher := getmodules.NewPackageFetcher()
+	fetcher := getmodules.NewPackageFetcher(i.ociStore)
 
 	if hooks == nil {
 		// Use our no-op implementation as a placeholder
@@ -137,15 +162,72 @@ func (i *ModuleInstaller) InstallModules(ctx context.Context, rootDir, testsDir
 		Key: "",
 		Dir: rootDir,
 	}
-	walker := i.moduleInstallWalker(ctx, manifest, upgrade, hooks, fetcher)
+	pins := make(map[string]string)
+	walker := i.moduleInstallWalker(ctx, manifest, upgrade, hooks, fetcher, pins)
 
 	cfg, instDiags := i.installDescendentModules(rootMod, manifest, walker, installErrsOnly)
 	diags = append(diags, instDiags...)
 
+	if i.locks != nil && !diags.HasErrors() {
+		i.updateModuleLocks(pins)
+	}
+
 	return cfg, diags
 }
 
-func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest modsdir.Manifest, upgrade bool, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher) configs.ModuleWalker {
+// updateModuleLocks replaces the module locks in the installer's dependency
+// locks with the given packages selected during installation, which are keyed
+// by the package address that they were selected for.
+func (i *ModuleInstaller) updateModuleLocks(pins map[string]string) {
+	for source := range i.locks.AllModules() {
+		if _, used := pins[source]; !used {
+			log.Printf("[TRACE] ModuleInstaller: module package %s is no longer used, removing its lock", source)
+			i.locks.RemoveModule(source)
+		}
+	}
+	for source, pinned := range pins {
+		if pinned == source {
+			// Nothing to lock, because the address always refers to
+			// the same package.
+			i.locks.RemoveModule(source)
+			continue
+		}
+		i.locks.SetModule(source, pinned)
+	}
+}
+
+// lockedPackage returns the address of exactly the package that must be
+// installed for the given remote package address, because it was already
+// selected earlier in this installation or recorded in the dependency locks,
+// or an empty string if the address is not yet locked. The dependency locks
+// are disregarded when upgrading.
+func (i *ModuleInstaller) lockedPackage(packageAddr string, pins map[string]string, upgrade bool) string {
+	if pinned, ok := pins[packageAddr]; ok {
+		return pinned
+	}
+	if upgrade || i.locks == nil {
+		return ""
+	}
+	if lock := i.locks.Module(packageAddr); lock != nil {
+		return lock.Pinned()
+	}
+	return ""
+}
+
+// installedPackage returns the address of exactly the package that the given
+// manifest record for a module with a remote source address was installed
+// from.
+func installedPackage(record modsdir.Record, addr addrs.ModuleSourceRemote) string {
+	if record.PinnedPackage != "" {
+		return record.PinnedPackage
+	}
+	return addr.Package.String()
+}
+
+// moduleInstallWalker returns a walker that installs each requested module.
+// The packages selected for remote source addresses are recorded in pins,
+// keyed by the package address they were selected for.
+func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest modsdir.Manifest, upgrade bool, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher, pins map[string]string) configs.ModuleWalker {
 	return configs.ModuleWalkerFunc(
 		func(req *configs.ModuleRequest) (*configs.Module, *version.Version, hcl.Diagnostics) {
 			var diags hcl.Diagnostics
@@ -183,6 +265,7 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 			replace := upgrade
 			if !replace {
 				record, recorded := manifest[key]
+				remoteAddr, remote := req.SourceAddr.(addrs.ModuleSourceRemote)
 				switch {
 				case !recorded:
 					log.Printf("[TRACE] ModuleInstaller: %s is not yet installed", key)
@@ -193,6 +276,12 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 				case record.Version != nil && !req.VersionConstraint.Required.Check(record.Version):
 					log.Printf("[TRACE] ModuleInstaller: %s version %s no longer compatible with constraints %s", key, record.Version, req.VersionConstraint.Required)
 					replace = true
+				case remote:
+					locked := i.lockedPackage(remoteAddr.Package.String(), pins, upgrade)
+					if installed := installedPackage(record, remoteAddr); locked != "" && locked != installed {
+						log.Printf("[TRACE] ModuleInstaller: %s locked package has changed from %q to %q", key, installed, locked)
+						replace = true
+					}
 				}
 			}
 
@@ -255,6 +344,10 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 						diags = diags.Extend(mDiags)
 					}
 
+					if addr, ok := req.SourceAddr.(addrs.ModuleSourceRemote); ok {
+						pins[addr.Package.String()] = installedPackage(record, addr)
+					}
+
 					log.Printf("[TRACE] ModuleInstaller: Module installer: %s %s already installed in %s", key, record.Version, record.Dir)
 					return mod, record.Version, diags
 				}
@@ -281,7 +374,7 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 
 			case addrs.ModuleSourceRemote:
 				log.Printf("[TRACE] ModuleInstaller: %s address %q will be handled by go-getter", key, addr.String())
-				mod, mDiags := i.installGoGetterModule(ctx, req, key, instPath, manifest, hooks, fetcher)
+				mod, mDiags := i.installGoGetterModule(ctx, req, key, instPath, manifest, upgrade, hooks, fetcher, pins)
 				diags = append(diags, mDiags...)
 				return mod, nil, diags
 
@@ -751,7 +844,7 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 	return mod, latestMatch, diags
 }
 
-func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *configs.ModuleRequest, key string, instPath string, manifest modsdir.Manifest, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher) (*configs.Module, hcl.Diagnostics) {
+func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *configs.ModuleRequest, key string, instPath string, manifest modsdir.Manifest, upgrade bool, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher, pins map[string]string) (*configs.Module, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	// Report up to the caller that we're about to start downloading.
@@ -769,7 +862,32 @@ func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *config
 		return nil, diags
 	}
 
-	err := fetcher.FetchPackage(ctx, instPath, packageAddr.String())
+	// If the package is locked then we install exactly the package that
+	// was selected for it before, so that e.g. the tag of an "oci:" source
+	// can only move when the module is upgraded.
+	fetchAddr := i.lockedPackage(packageAddr.String(), pins, upgrade)
+	if fetchAddr != "" {
+		log.Printf("[TRACE] ModuleInstaller: %s is locked to %s", key, fetchAddr)
+	} else {
+		var err error
+		fetchAddr, err = fetcher.ResolvePackage(ctx, packageAddr.String())
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to download module",
+				Detail:   fmt.Sprintf("Could not download module %q (%s:%d) source code from %q: %s", req.Name, req.CallRange.Filename, req.CallRange.Start.Line, packageAddr, err),
+				Subject:  req.CallRange.Ptr(),
+			})
+			return nil, diags
+		}
+	}
+	pins[packageAddr.String()] = fetchAddr
+	var pinnedPackage string
+	if fetchAddr != packageAddr.String() {
+		pinnedPackage = fetchAddr
+	}
+
+	err := fetcher.FetchPackage(ctx, instPath, fetchAddr)
 	if err != nil {
 		// go-getter generates a poor error for an invalid relative path, so
 		// we'll detect that case and generate a better one.
@@ -841,9 +959,10 @@ func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *config
 
 	// Note the local location in our manifest.
 	manifest[key] = modsdir.Record{
-		Key:        key,
-		Dir:        modDir,
-		SourceAddr: req.SourceAddr.String(),
+		Key:           key,
+		Dir:           modDir,
+		SourceAddr:    req.SourceAddr.String(),
+		PinnedPackage: pinnedPackage,
 	}
 	log.Printf("[DEBUG] Module installer: %s installed at %s", key, modDir)
 	hooks.Install(key, nil, modDir)
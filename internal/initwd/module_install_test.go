@@ -25,6 +25,9 @@ import (
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getmodules"
+	"github.com/opentofu/opentofu/internal/modsdir"
 	"github.com/opentofu/opentofu/internal/registry"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 
@@ -476,6 +479,93 @@ func TestModuleInstaller_symlink(t *testing.T) {
 	assertResultDeepEqual(t, gotTraces, wantTraces)
 }
 
+func TestModuleInstaller_lockedPackage(t *testing.T) {
+	fixtureDir := filepath.Clean("testdata/oci-locked-module")
+	dir, done := tempChdir(t, fixtureDir)
+	defer done()
+
+	// The dependency locks record that the module's OCI tag is pinned to the
+	// "package-a" directory, as if that was the digest that the tag referred
+	// to, along with a lock for a module that is no longer used.
+	const source = "oci://example.com/modules/vpc:1.0.0"
+	const unused = "oci://example.com/modules/unused:1.0.0"
+	packageA, _, err := getmodules.NormalizePackageAddress(filepath.Join(dir, "package-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	packageB, _, err := getmodules.NormalizePackageAddress(filepath.Join(dir, "package-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locks := depsfile.NewLocks()
+	locks.SetModule(source, packageA)
+	locks.SetModule(unused, packageA)
+
+	modulesDir := filepath.Join(dir, ".terraform/modules")
+	if err := os.MkdirAll(modulesDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	install := func(t *testing.T, upgrade bool) (*configs.Config, tfdiags.Diagnostics) {
+		t.Helper()
+		// Each installation uses a new loader, as each "tofu init" would,
+		// because the parser caches the files it has already read.
+		loader, close := configload.NewLoaderForTests(t)
+		defer close()
+		inst := NewModuleInstaller(modulesDir, loader, nil)
+		inst.SetLocks(locks)
+		return inst.InstallModules(context.Background(), ".", "tests", upgrade, false, nil, configs.RootModuleCallForTesting())
+	}
+	assertInstalled := func(t *testing.T, cfg *configs.Config, pinned, want string) {
+		t.Helper()
+		if got := cfg.Children["vpc"].Module.Variables["v"].Description; got != want {
+			t.Errorf("wrong module installed\ngot:  %s\nwant: %s", got, want)
+		}
+		manifest, err := modsdir.ReadManifestSnapshotForDir(modulesDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := manifest["vpc"].PinnedPackage; got != pinned {
+			t.Errorf("wrong pinned package in manifest %q; want %q", got, pinned)
+		}
+	}
+
+	// Without upgrading, the module is installed from the locked package
+	// without resolving the tag, so no OCI registry access is needed.
+	cfg, diags := install(t, false)
+	if assertNoDiagnostics(t, diags) {
+		return
+	}
+	assertInstalled(t, cfg, packageA, "in package a")
+	if got := locks.Module(source); got == nil || got.Pinned() != packageA {
+		t.Errorf("wrong lock for %s after install: %#v", source, got)
+	}
+	if got := locks.Module(unused); got != nil {
+		t.Errorf("lock for unused module %s was not removed", unused)
+	}
+
+	// If the locked package changes, such as when the lock file is updated
+	// in version control, the module is reinstalled from the new package.
+	locks.SetModule(source, packageB)
+	cfg, diags = install(t, false)
+	if assertNoDiagnostics(t, diags) {
+		return
+	}
+	assertInstalled(t, cfg, packageB, "in package b")
+
+	// Upgrading disregards the lock and resolves the tag again, which fails
+	// here because there is no OCI registry, and leaves the lock unchanged.
+	_, diags = install(t, true)
+	if !diags.HasErrors() {
+		t.Fatal("unexpected success upgrading module")
+	}
+	if got, want := diags.Err().Error(), "OCI registries are not available"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+	if got := locks.Module(source); got == nil || got.Pinned() != packageB {
+		t.Errorf("wrong lock for %s after failed upgrade: %#v", source, got)
+	}
+}
+
 func TestLoaderInstallModules_registry(t *testing.T) {
 	if os.Getenv("TF_ACC") == "" {
 		t.Skip("this test accesses registry.opentofu.org and github.com; set TF_ACC=1 to run it")
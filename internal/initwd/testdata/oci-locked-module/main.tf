@@ -0,0 +1,3 @@
+module "vpc" {
+  source = "oci://example.com/modules/vpc:1.0.0"
+}
@@ -0,0 +1,4 @@
+variable "v" {
+  description = "in package b"
+  default     = ""
+}
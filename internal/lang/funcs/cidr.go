@@ -8,6 +8,9 @@ package funcs
 import (
 	"fmt"
 	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
 
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/opentofu/opentofu/internal/ipaddr"
@@ -270,6 +273,317 @@ var CidrContainsFunc = function.New(&function.Spec{
 	},
 })
 
+// CidrOverlapsFunc constructs a function that checks whether two IP network
+// address prefixes have any addresses in common.
+var CidrOverlapsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "first_prefix",
+			Type: cty.String,
+		},
+		{
+			Name: "second_prefix",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		a, err := parseCIDRRange(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgError(0, err)
+		}
+		b, err := parseCIDRRange(args[1].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgError(1, err)
+		}
+		if a.bits != b.bits {
+			return cty.UnknownVal(cty.Bool), fmt.Errorf("address family mismatch: %s vs. %s", args[0].AsString(), args[1].AsString())
+		}
+
+		return cty.BoolVal(a.start.Cmp(b.end) <= 0 && b.start.Cmp(a.end) <= 0), nil
+	},
+})
+
+// CidrMergeFunc constructs a function that aggregates a list of IP network
+// address prefixes into the smallest list of prefixes covering exactly the
+// same addresses.
+var CidrMergeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "prefixes",
+			Type: cty.List(cty.String),
+		},
+	},
+	Type:         function.StaticReturnType(cty.List(cty.String)),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		ranges, err := parseCIDRRangeList(args[0])
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgError(0, err)
+		}
+
+		var retVals []cty.Value
+		for _, r := range mergeIPRanges(ranges) {
+			retVals = append(retVals, r.prefixes()...)
+		}
+		if len(retVals) == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		return cty.ListVal(retVals), nil
+	},
+})
+
+// CidrSubtractFunc constructs a function that calculates the address space
+// remaining within an IP network address prefix after removing a list of
+// allocated prefixes, as the smallest list of prefixes covering it.
+var CidrSubtractFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "prefix",
+			Type: cty.String,
+		},
+		{
+			Name: "allocated_prefixes",
+			Type: cty.List(cty.String),
+		},
+	},
+	Type:         function.StaticReturnType(cty.List(cty.String)),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		base, err := parseCIDRRange(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgError(0, err)
+		}
+		allocated, err := parseCIDRRangeList(args[1])
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgError(1, err)
+		}
+		for _, r := range allocated {
+			if r.bits != base.bits {
+				return cty.UnknownVal(retType), function.NewArgErrorf(1, "all allocated prefixes must be of the same address family as %s", args[0].AsString())
+			}
+		}
+
+		// Walk through the merged allocations in order, collecting the gaps
+		// between them that fall within the base prefix.
+		var free []ipRange
+		next := new(big.Int).Set(base.start)
+		for _, r := range mergeIPRanges(allocated) {
+			if r.end.Cmp(next) < 0 {
+				continue
+			}
+			if r.start.Cmp(base.end) > 0 {
+				break
+			}
+			if r.start.Cmp(next) > 0 {
+				free = append(free, ipRange{start: next, end: new(big.Int).Sub(r.start, big.NewInt(1)), bits: base.bits})
+			}
+			next = new(big.Int).Add(r.end, big.NewInt(1))
+		}
+		if next.Cmp(base.end) <= 0 {
+			free = append(free, ipRange{start: next, end: base.end, bits: base.bits})
+		}
+
+		var retVals []cty.Value
+		for _, r := range free {
+			retVals = append(retVals, r.prefixes()...)
+		}
+		if len(retVals) == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		return cty.ListVal(retVals), nil
+	},
+})
+
+// CidrSizeFunc constructs a function that returns the number of addresses
+// within an IP network address prefix.
+var CidrSizeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "prefix",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Number),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		r, err := parseCIDRRange(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Number), function.NewArgError(0, err)
+		}
+
+		size := new(big.Int).Sub(r.end, r.start)
+		size.Add(size, big.NewInt(1))
+		return cty.NumberVal(new(big.Float).SetInt(size)), nil
+	},
+})
+
+// IPInRangeFunc constructs a function that checks whether an IP address is
+// within an inclusive range of IP addresses.
+var IPInRangeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "address",
+			Type: cty.String,
+		},
+		{
+			Name: "range_start",
+			Type: cty.String,
+		},
+		{
+			Name: "range_end",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		addrs := make([]*big.Int, len(args))
+		var bits int
+		for i, arg := range args {
+			ip := ipaddr.ParseIP(arg.AsString())
+			if ip == nil {
+				return cty.UnknownVal(cty.Bool), function.NewArgErrorf(i, "invalid IP address: %s", arg.AsString())
+			}
+			ipBits := ipAddrBits(arg.AsString())
+			n := ipToInt(ip, ipBits)
+			if i > 0 && ipBits != bits {
+				return cty.UnknownVal(cty.Bool), fmt.Errorf("address family mismatch: %s vs. %s", args[0].AsString(), arg.AsString())
+			}
+			addrs[i], bits = n, ipBits
+		}
+
+		addr, start, end := addrs[0], addrs[1], addrs[2]
+		if start.Cmp(end) > 0 {
+			return cty.UnknownVal(cty.Bool), function.NewArgErrorf(2, "range end %s is before range start %s", args[2].AsString(), args[1].AsString())
+		}
+
+		return cty.BoolVal(start.Cmp(addr) <= 0 && addr.Cmp(end) <= 0), nil
+	},
+})
+
+// ipRange is an inclusive range of IP addresses of the same family, used to
+// do arithmetic on address prefixes. The addresses are stored as integers,
+// with bits recording whether they are IPv4 (32) or IPv6 (128) addresses.
+type ipRange struct {
+	start, end *big.Int
+	bits       int
+}
+
+// parseCIDRRange parses an IP network address prefix in CIDR notation into
+// the range of addresses it contains.
+func parseCIDRRange(s string) (ipRange, error) {
+	_, network, err := ipaddr.ParseCIDR(s)
+	if err != nil {
+		return ipRange{}, fmt.Errorf("invalid CIDR expression: %w", err)
+	}
+
+	// The family comes from the prefix rather than from the address, so
+	// that IPv4-mapped IPv6 prefixes such as ::ffff:0:0/96 remain IPv6.
+	_, bits := network.Mask.Size()
+	first, last := cidr.AddressRange(network)
+	start, end := ipToInt(first, bits), ipToInt(last, bits)
+	return ipRange{start: start, end: end, bits: bits}, nil
+}
+
+// parseCIDRRangeList parses a list of IP network address prefixes in CIDR
+// notation into the ranges of addresses they contain.
+func parseCIDRRangeList(list cty.Value) ([]ipRange, error) {
+	var ranges []ipRange
+	for it := list.ElementIterator(); it.Next(); {
+		idx, val := it.Element()
+		if val.IsNull() {
+			return nil, fmt.Errorf("element %s is null", idx.AsBigFloat().String())
+		}
+		r, err := parseCIDRRange(val.AsString())
+		if err != nil {
+			return nil, fmt.Errorf("element %s: %w", idx.AsBigFloat().String(), err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// ipAddrBits returns the number of bits in addresses of the family of the
+// given IP address. An IP address parses to the same bytes whether it is
+// written as an IPv4 address or as an IPv4-mapped IPv6 address, so the family
+// comes from how the address is written.
+func ipAddrBits(addr string) int {
+	if strings.Contains(addr, ":") {
+		return 128
+	}
+	return 32
+}
+
+// ipToInt returns the given IP address as an integer in the address family
+// with the given number of bits.
+func ipToInt(ip ipaddr.IP, bits int) *big.Int {
+	if bits == 32 {
+		return new(big.Int).SetBytes(ip.To4())
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+// mergeIPRanges returns the given ranges sorted, with IPv4 ranges first, and
+// with any ranges that overlap or are adjacent combined into one.
+func mergeIPRanges(ranges []ipRange) []ipRange {
+	sorted := make([]ipRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].bits != sorted[j].bits {
+			return sorted[i].bits < sorted[j].bits
+		}
+		return sorted[i].start.Cmp(sorted[j].start) < 0
+	})
+
+	var merged []ipRange
+	for _, r := range sorted {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.bits == r.bits && new(big.Int).Add(last.end, big.NewInt(1)).Cmp(r.start) >= 0 {
+				if r.end.Cmp(last.end) > 0 {
+					last.end = r.end
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// prefixes returns the smallest list of prefixes in CIDR notation that
+// covers exactly the addresses in the range.
+func (r ipRange) prefixes() []cty.Value {
+	var ret []cty.Value
+	start := new(big.Int).Set(r.start)
+	for start.Cmp(r.end) <= 0 {
+		// The largest prefix starting here is limited both by the alignment
+		// of the start address and by the end of the range.
+		size := r.bits
+		if start.Sign() != 0 {
+			size = int(start.TrailingZeroBits())
+		}
+		var block *big.Int
+		for {
+			block = new(big.Int).Lsh(big.NewInt(1), uint(size))
+			last := new(big.Int).Add(start, block)
+			if last.Sub(last, big.NewInt(1)).Cmp(r.end) <= 0 {
+				break
+			}
+			size--
+		}
+
+		// We format the prefix with netip, which keeps IPv4-mapped IPv6
+		// prefixes in IPv6 notation.
+		addr, _ := netip.AddrFromSlice(start.FillBytes(make([]byte, r.bits/8)))
+		ret = append(ret, cty.StringVal(netip.PrefixFrom(addr, r.bits-size).String()))
+		start.Add(start, block)
+	}
+	return ret
+}
+
 // CidrHost calculates a full host IP address within a given IP network address prefix.
 func CidrHost(prefix, hostnum cty.Value) (cty.Value, error) {
 	return CidrHostFunc.Call([]cty.Value{prefix, hostnum})
@@ -298,3 +612,30 @@ func CidrSubnets(prefix cty.Value, newbits ...cty.Value) (cty.Value, error) {
 func CidrContains(prefix, address cty.Value) (cty.Value, error) {
 	return CidrContainsFunc.Call([]cty.Value{prefix, address})
 }
+
+// CidrOverlaps checks whether two IP network address prefixes overlap.
+func CidrOverlaps(a, b cty.Value) (cty.Value, error) {
+	return CidrOverlapsFunc.Call([]cty.Value{a, b})
+}
+
+// CidrMerge aggregates a list of IP network address prefixes into the smallest
+// equivalent list of prefixes.
+func CidrMerge(prefixes cty.Value) (cty.Value, error) {
+	return CidrMergeFunc.Call([]cty.Value{prefixes})
+}
+
+// CidrSubtract calculates the address space remaining within an IP network
+// address prefix after removing the given allocated prefixes.
+func CidrSubtract(prefix, allocated cty.Value) (cty.Value, error) {
+	return CidrSubtractFunc.Call([]cty.Value{prefix, allocated})
+}
+
+// CidrSize returns the number of addresses within an IP network address prefix.
+func CidrSize(prefix cty.Value) (cty.Value, error) {
+	return CidrSizeFunc.Call([]cty.Value{prefix})
+}
+
+// IPInRange checks whether an IP address is within an inclusive range of addresses.
+func IPInRange(address, start, end cty.Value) (cty.Value, error) {
+	return IPInRangeFunc.Call([]cty.Value{address, start, end})
+}
@@ -520,3 +520,441 @@ func TestCidrContains(t *testing.T) {
 		})
 	}
 }
+
+func TestCidrOverlaps(t *testing.T) {
+	tests := []struct {
+		A, B cty.Value
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			cty.StringVal("10.0.0.0/16"),
+			cty.StringVal("10.0.128.0/17"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("10.0.128.0/17"),
+			cty.StringVal("10.0.0.0/16"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.0/24"),
+			cty.StringVal("10.0.1.0/24"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("fd00::/8"),
+			cty.StringVal("fd12:3456::/32"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("fd00::/16"),
+			cty.StringVal("fd01::/16"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.0/8"),
+			cty.StringVal("fd00::/8"),
+			cty.UnknownVal(cty.Bool),
+			true, // address family mismatch
+		},
+		{
+			cty.StringVal("::ffff:0:0/96"),
+			cty.StringVal("::ffff:10.0.0.0/104"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("::ffff:0:0/96"),
+			cty.StringVal("10.0.0.0/8"),
+			cty.UnknownVal(cty.Bool),
+			true, // address family mismatch
+		},
+		{
+			cty.StringVal("10.0.0.0/8"),
+			cty.StringVal("10.0.0.1"),
+			cty.UnknownVal(cty.Bool),
+			true, // not a prefix
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("cidroverlaps(%#v, %#v)", test.A, test.B), func(t *testing.T) {
+			got, err := CidrOverlaps(test.A, test.B)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestCidrMerge(t *testing.T) {
+	tests := []struct {
+		Prefixes cty.Value
+		Want     cty.Value
+		Err      bool
+	}{
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.1.0/24"),
+				cty.StringVal("10.0.0.0/24"),
+				cty.StringVal("10.0.2.0/24"),
+				cty.StringVal("10.0.3.0/24"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/22"),
+			}),
+			false,
+		},
+		{
+			// Overlapping and contained prefixes are absorbed, and ranges
+			// that don't align to a single prefix are split.
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/24"),
+				cty.StringVal("10.0.0.128/25"),
+				cty.StringVal("10.0.1.0/24"),
+				cty.StringVal("10.0.2.0/24"),
+				cty.StringVal("192.168.0.0/16"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/23"),
+				cty.StringVal("10.0.2.0/24"),
+				cty.StringVal("192.168.0.0/16"),
+			}),
+			false,
+		},
+		{
+			// Host bits are ignored, and IPv4 prefixes come first.
+			cty.ListVal([]cty.Value{
+				cty.StringVal("fd00:0:0:1::/64"),
+				cty.StringVal("fd00::/64"),
+				cty.StringVal("10.0.0.7/8"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/8"),
+				cty.StringVal("fd00::/63"),
+			}),
+			false,
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("0.0.0.0/1"),
+				cty.StringVal("128.0.0.0/1"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("0.0.0.0/0"),
+			}),
+			false,
+		},
+		{
+			cty.ListValEmpty(cty.String),
+			cty.ListValEmpty(cty.String),
+			false,
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("::ffff:0:0/96"),
+				cty.StringVal("0.0.0.0/0"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("0.0.0.0/0"),
+				cty.StringVal("::ffff:0.0.0.0/96"),
+			}),
+			false,
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/8"),
+				cty.StringVal("not-a-prefix"),
+			}),
+			cty.UnknownVal(cty.List(cty.String)),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("cidrmerge(%#v)", test.Prefixes), func(t *testing.T) {
+			got, err := CidrMerge(test.Prefixes)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestCidrSubtract(t *testing.T) {
+	tests := []struct {
+		Prefix    cty.Value
+		Allocated cty.Value
+		Want      cty.Value
+		Err       bool
+	}{
+		{
+			cty.StringVal("10.0.0.0/22"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.1.0/24"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/24"),
+				cty.StringVal("10.0.2.0/23"),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.0/24"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/26"),
+				cty.StringVal("10.0.0.192/26"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.64/26"),
+				cty.StringVal("10.0.0.128/26"),
+			}),
+			false,
+		},
+		{
+			// Allocations that extend beyond the prefix only remove the
+			// part within it.
+			cty.StringVal("10.0.0.0/24"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.128/25"),
+				cty.StringVal("10.0.0.0/16"),
+			}),
+			cty.ListValEmpty(cty.String),
+			false,
+		},
+		{
+			cty.StringVal("fd00::/62"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("fd00::/64"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("fd00:0:0:1::/64"),
+				cty.StringVal("fd00:0:0:2::/63"),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.0/24"),
+			cty.ListValEmpty(cty.String),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("10.0.0.0/24"),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.0/24"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("fd00::/64"),
+			}),
+			cty.UnknownVal(cty.List(cty.String)),
+			true, // address family mismatch
+		},
+		{
+			cty.StringVal("::ffff:0:0/96"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("::ffff:0:0/97"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("::ffff:128.0.0.0/97"),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("::ffff:0:0/96"),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("0.0.0.0/1"),
+			}),
+			cty.UnknownVal(cty.List(cty.String)),
+			true, // address family mismatch
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("cidrsubtract(%#v, %#v)", test.Prefix, test.Allocated), func(t *testing.T) {
+			got, err := CidrSubtract(test.Prefix, test.Allocated)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestCidrSize(t *testing.T) {
+	tests := []struct {
+		Prefix cty.Value
+		Want   cty.Value
+		Err    bool
+	}{
+		{
+			cty.StringVal("10.0.0.0/24"),
+			cty.NumberIntVal(256),
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.1/32"),
+			cty.NumberIntVal(1),
+			false,
+		},
+		{
+			cty.StringVal("0.0.0.0/0"),
+			cty.NumberIntVal(4294967296),
+			false,
+		},
+		{
+			cty.StringVal("fd00::/64"),
+			cty.MustParseNumberVal("18446744073709551616"),
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.1"),
+			cty.UnknownVal(cty.Number),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("cidrsize(%#v)", test.Prefix), func(t *testing.T) {
+			got, err := CidrSize(test.Prefix)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.Equals(test.Want).True() {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestIPInRange(t *testing.T) {
+	tests := []struct {
+		Address, Start, End cty.Value
+		Want                cty.Value
+		Err                 bool
+	}{
+		{
+			cty.StringVal("10.0.0.50"),
+			cty.StringVal("10.0.0.10"),
+			cty.StringVal("10.0.0.100"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.100"),
+			cty.StringVal("10.0.0.10"),
+			cty.StringVal("10.0.0.100"),
+			cty.True, // the range is inclusive
+			false,
+		},
+		{
+			cty.StringVal("10.0.1.0"),
+			cty.StringVal("10.0.0.10"),
+			cty.StringVal("10.0.0.100"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("fd00::ff"),
+			cty.StringVal("fd00::1"),
+			cty.StringVal("fd00::1:0"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("fd00::2:0"),
+			cty.StringVal("fd00::1"),
+			cty.StringVal("fd00::1:0"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("10.0.0.50"),
+			cty.StringVal("fd00::1"),
+			cty.StringVal("fd00::1:0"),
+			cty.UnknownVal(cty.Bool),
+			true, // address family mismatch
+		},
+		{
+			cty.StringVal("::ffff:10.0.0.50"),
+			cty.StringVal("10.0.0.10"),
+			cty.StringVal("10.0.0.100"),
+			cty.UnknownVal(cty.Bool),
+			true, // address family mismatch
+		},
+		{
+			cty.StringVal("10.0.0.50"),
+			cty.StringVal("10.0.0.100"),
+			cty.StringVal("10.0.0.10"),
+			cty.UnknownVal(cty.Bool),
+			true, // end before start
+		},
+		{
+			cty.StringVal("10.0.0.0/24"),
+			cty.StringVal("10.0.0.10"),
+			cty.StringVal("10.0.0.100"),
+			cty.UnknownVal(cty.Bool),
+			true, // not an address
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("ipinrange(%#v, %#v, %#v)", test.Address, test.Start, test.End), func(t *testing.T) {
+			got, err := IPInRange(test.Address, test.Start, test.End)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/function"
@@ -177,6 +179,323 @@ var CoalesceFunc = function.New(&function.Spec{
 	},
 })
 
+// DeepMergeFunc constructs a function that takes any number of maps or objects
+// and recursively merges them into a single object, with later arguments
+// taking precedence. Lists are replaced and null values override earlier
+// values, as for merge.
+var DeepMergeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{},
+	VarParam: &function.Parameter{
+		Name:             "maps",
+		Type:             cty.DynamicPseudoType,
+		AllowUnknown:     true,
+		AllowDynamicType: true,
+		AllowNull:        true,
+		AllowMarked:      true,
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		return deepMergeType(args, 0, deepMergeOptions{})
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return deepMerge(args, 0, deepMergeOptions{})
+	},
+})
+
+// DeepMergeWithFunc is like DeepMergeFunc, but takes an object of options as
+// its first argument to choose how lists and null values are merged.
+var DeepMergeWithFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "options",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	VarParam: &function.Parameter{
+		Name:             "maps",
+		Type:             cty.DynamicPseudoType,
+		AllowUnknown:     true,
+		AllowDynamicType: true,
+		AllowNull:        true,
+		AllowMarked:      true,
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		opts, err := parseDeepMergeOptions(args[0])
+		if err != nil {
+			return cty.NilType, function.NewArgError(0, err)
+		}
+		return deepMergeType(args[1:], 1, opts)
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		opts, err := parseDeepMergeOptions(args[0])
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		return deepMerge(args[1:], 1, opts)
+	},
+})
+
+// deepMergeOptions are the options for DeepMergeWithFunc. The zero value is
+// the behavior of DeepMergeFunc.
+type deepMergeOptions struct {
+	// lists is how to merge two lists: "append" or "union". Otherwise the
+	// later list replaces the earlier one.
+	lists string
+
+	// ignoreNulls is true if null values should leave the earlier value
+	// unchanged, rather than replacing it.
+	ignoreNulls bool
+}
+
+func parseDeepMergeOptions(val cty.Value) (deepMergeOptions, error) {
+	var opts deepMergeOptions
+	ty := val.Type()
+	if !ty.IsObjectType() && !ty.IsMapType() {
+		return opts, fmt.Errorf("options must be an object, not %s", ty.FriendlyName())
+	}
+	if !val.IsWhollyKnown() {
+		return opts, errors.New("options must be known before the configuration is applied")
+	}
+
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		if v.IsNull() {
+			continue
+		}
+		v, err := convert.Convert(v, cty.String)
+		if err != nil {
+			return opts, fmt.Errorf("invalid value for %q: %w", k.AsString(), err)
+		}
+		switch name, choice := k.AsString(), v.AsString(); name {
+		case "lists":
+			switch choice {
+			case "replace":
+				opts.lists = ""
+			case "append", "union":
+				opts.lists = choice
+			default:
+				return opts, fmt.Errorf("invalid value %q for %q: must be \"replace\", \"append\" or \"union\"", choice, name)
+			}
+		case "nulls":
+			switch choice {
+			case "replace":
+				opts.ignoreNulls = false
+			case "ignore":
+				opts.ignoreNulls = true
+			default:
+				return opts, fmt.Errorf("invalid value %q for %q: must be \"replace\" or \"ignore\"", choice, name)
+			}
+		default:
+			return opts, fmt.Errorf("unsupported option %q; the supported options are \"lists\" and \"nulls\"", name)
+		}
+	}
+	return opts, nil
+}
+
+// deepMergeType returns the result type of merging the given arguments, which
+// start at argument offset of the function call.
+func deepMergeType(args []cty.Value, offset int, opts deepMergeOptions) (cty.Type, error) {
+	for i, arg := range args {
+		ty := arg.Type()
+		if ty != cty.DynamicPseudoType && !ty.IsObjectType() && !ty.IsMapType() {
+			return cty.NilType, function.NewArgErrorf(i+offset, "argument must be a map or object, not %s", ty.FriendlyName())
+		}
+	}
+	for _, arg := range args {
+		if !arg.IsWhollyKnown() {
+			// Unknown values could contain anything, so we won't know the
+			// final type until they are known.
+			return cty.DynamicPseudoType, nil
+		}
+	}
+	val, err := deepMerge(args, offset, opts)
+	if err != nil {
+		return cty.NilType, err
+	}
+	return val.Type(), nil
+}
+
+// deepMerge merges the given maps or objects in order, which start at argument
+// offset of the function call.
+func deepMerge(args []cty.Value, offset int, opts deepMergeOptions) (cty.Value, error) {
+	result := cty.EmptyObjectVal
+	first := true
+	for i, arg := range args {
+		unmarked, argMarks := arg.Unmark()
+		if unmarked.IsNull() {
+			continue
+		}
+		if !unmarked.IsKnown() {
+			return cty.DynamicVal.WithMarks(argMarks), nil
+		}
+		if first {
+			result, first = arg, false
+			continue
+		}
+
+		merged, err := deepMergeValues(result, arg, nil, opts)
+		if err != nil {
+			return cty.NilVal, function.NewArgError(i+offset, err)
+		}
+		result = merged
+	}
+	return result, nil
+}
+
+// deepMergeValues merges b over a, where path is the sequence of attribute
+// accesses and index steps leading to both values, for error messages.
+func deepMergeValues(a, b cty.Value, path []string, opts deepMergeOptions) (cty.Value, error) {
+	a, aMarks := a.Unmark()
+	b, bMarks := b.Unmark()
+
+	switch {
+	case b.IsNull():
+		if opts.ignoreNulls {
+			return a.WithMarks(aMarks), nil
+		}
+		return b.WithMarks(bMarks), nil
+	case a.IsNull():
+		return b.WithMarks(bMarks), nil
+	}
+
+	aTy, bTy := a.Type(), b.Type()
+	if aTy == cty.DynamicPseudoType || bTy == cty.DynamicPseudoType {
+		// We can't check the types until the values are known.
+		return cty.DynamicVal.WithMarks(aMarks, bMarks), nil
+	}
+
+	aObj, bObj := aTy.IsObjectType() || aTy.IsMapType(), bTy.IsObjectType() || bTy.IsMapType()
+	aList, bList := aTy.IsListType() || aTy.IsTupleType() || aTy.IsSetType(), bTy.IsListType() || bTy.IsTupleType() || bTy.IsSetType()
+	switch {
+	case aObj && bObj:
+		if !a.IsKnown() || !b.IsKnown() {
+			return cty.DynamicVal.WithMarks(aMarks, bMarks), nil
+		}
+		return deepMergeObjects(a, b, path, opts, aMarks, bMarks)
+	case aList && bList:
+		if opts.lists == "" {
+			return b.WithMarks(bMarks), nil
+		}
+		if !a.IsKnown() || !b.IsKnown() {
+			return cty.DynamicVal.WithMarks(aMarks, bMarks), nil
+		}
+		return deepMergeLists(a, b, opts, aMarks, bMarks)
+	case aTy.IsPrimitiveType() && bTy.Equals(aTy):
+		return b.WithMarks(bMarks), nil
+	default:
+		return cty.NilVal, fmt.Errorf("cannot merge %s with %s at %s", bTy.FriendlyName(), aTy.FriendlyName(), deepMergePath(path))
+	}
+}
+
+func deepMergeObjects(a, b cty.Value, path []string, opts deepMergeOptions, aMarks, bMarks cty.ValueMarks) (cty.Value, error) {
+	attrs := make(map[string]cty.Value)
+	for it := a.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		attrs[k.AsString()] = v
+	}
+	for it := b.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		key := k.AsString()
+		existing, ok := attrs[key]
+		if !ok {
+			attrs[key] = v
+			continue
+		}
+		// Keys of sensitive maps are themselves sensitive, so we must not
+		// include them in error messages.
+		segment := "[" + redactIfSensitive(key, aMarks, bMarks) + "]"
+		if hclsyntax.ValidIdentifier(key) && segment == fmt.Sprintf("[%q]", key) {
+			segment = "." + key
+		}
+		merged, err := deepMergeValues(existing, v, append(path, segment), opts)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		attrs[key] = merged
+	}
+
+	// Merging two maps produces a map if all of the elements still have the
+	// same type, so that the result can be used where the inputs could.
+	if a.Type().IsMapType() && b.Type().IsMapType() {
+		if len(attrs) == 0 {
+			return a.WithMarks(aMarks, bMarks), nil
+		}
+		var elemTy cty.Type
+		same := true
+		for _, v := range attrs {
+			if elemTy == cty.NilType {
+				elemTy = v.Type()
+			} else if !v.Type().Equals(elemTy) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return cty.MapVal(attrs).WithMarks(aMarks, bMarks), nil
+		}
+	}
+	return cty.ObjectVal(attrs).WithMarks(aMarks, bMarks), nil
+}
+
+func deepMergeLists(a, b cty.Value, opts deepMergeOptions, aMarks, bMarks cty.ValueMarks) (cty.Value, error) {
+	var elems []cty.Value
+	for _, v := range []cty.Value{a, b} {
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			if opts.lists == "union" {
+				if !elem.IsWhollyKnown() {
+					// We can't tell whether an unknown element duplicates
+					// another one.
+					return cty.DynamicVal.WithMarks(aMarks, bMarks), nil
+				}
+				if deepMergeContains(elems, elem) {
+					continue
+				}
+			}
+			elems = append(elems, elem)
+		}
+	}
+
+	aTy, bTy := a.Type(), b.Type()
+	switch {
+	case aTy.IsListType() && aTy.Equals(bTy):
+		if len(elems) == 0 {
+			return a.WithMarks(aMarks, bMarks), nil
+		}
+		return cty.ListVal(elems).WithMarks(aMarks, bMarks), nil
+	case aTy.IsSetType() && aTy.Equals(bTy):
+		if len(elems) == 0 {
+			return a.WithMarks(aMarks, bMarks), nil
+		}
+		return cty.SetVal(elems).WithMarks(aMarks, bMarks), nil
+	default:
+		return cty.TupleVal(elems).WithMarks(aMarks, bMarks), nil
+	}
+}
+
+// deepMergeContains returns true if elems already has an element equal to
+// elem, ignoring marks.
+func deepMergeContains(elems []cty.Value, elem cty.Value) bool {
+	want, _ := elem.UnmarkDeep()
+	for _, existing := range elems {
+		got, _ := existing.UnmarkDeep()
+		if got.Equals(want).True() {
+			return true
+		}
+	}
+	return false
+}
+
+// deepMergePath returns the location of a value within the merged arguments,
+// for error messages.
+func deepMergePath(path []string) string {
+	if len(path) == 0 {
+		return "the top level"
+	}
+	return strings.TrimPrefix(strings.Join(path, ""), ".")
+}
+
 // IndexFunc constructs a function that finds the element index for a given value in a list.
 var IndexFunc = function.New(&function.Spec{
 	Params: []function.Parameter{
@@ -689,6 +1008,20 @@ func Coalesce(args ...cty.Value) (cty.Value, error) {
 	return CoalesceFunc.Call(args)
 }
 
+// DeepMerge recursively merges maps or objects into a single object.
+func DeepMerge(maps ...cty.Value) (cty.Value, error) {
+	return DeepMergeFunc.Call(maps)
+}
+
+// DeepMergeWith recursively merges maps or objects into a single object, using
+// the given options.
+func DeepMergeWith(options cty.Value, maps ...cty.Value) (cty.Value, error) {
+	args := make([]cty.Value, len(maps)+1)
+	args[0] = options
+	copy(args[1:], maps)
+	return DeepMergeWithFunc.Call(args)
+}
+
 // Index finds the element index for a given value in a list.
 func Index(list, value cty.Value) (cty.Value, error) {
 	return IndexFunc.Call([]cty.Value{list, value})
@@ -454,6 +454,366 @@ func TestCoalesce(t *testing.T) {
 	}
 }
 
+func TestDeepMerge(t *testing.T) {
+	tests := []struct {
+		Values []cty.Value
+		Want   cty.Value
+		Err    bool
+	}{
+		{
+			[]cty.Value{},
+			cty.EmptyObjectVal,
+			false,
+		},
+		{
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("app"),
+					"tags": cty.ObjectVal(map[string]cty.Value{
+						"team": cty.StringVal("a"),
+						"env":  cty.StringVal("dev"),
+					}),
+					"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80)}),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"tags": cty.ObjectVal(map[string]cty.Value{
+						"env": cty.StringVal("prod"),
+					}),
+					"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(443)}),
+				}),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("app"),
+				"tags": cty.ObjectVal(map[string]cty.Value{
+					"team": cty.StringVal("a"),
+					"env":  cty.StringVal("prod"),
+				}),
+				"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(443)}),
+			}),
+			false,
+		},
+		{
+			// Maps stay maps if all of their elements have the same type.
+			[]cty.Value{
+				cty.MapVal(map[string]cty.Value{
+					"a": cty.StringVal("1"),
+				}),
+				cty.MapVal(map[string]cty.Value{
+					"b": cty.StringVal("2"),
+				}),
+			},
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("1"),
+				"b": cty.StringVal("2"),
+			}),
+			false,
+		},
+		{
+			[]cty.Value{
+				cty.MapVal(map[string]cty.Value{
+					"a": cty.MapVal(map[string]cty.Value{"x": cty.StringVal("1")}),
+				}),
+				cty.MapVal(map[string]cty.Value{
+					"a": cty.MapVal(map[string]cty.Value{"y": cty.NumberIntVal(2)}),
+				}),
+			},
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.ObjectVal(map[string]cty.Value{
+					"x": cty.StringVal("1"),
+					"y": cty.NumberIntVal(2),
+				}),
+			}),
+			false,
+		},
+		{
+			// Null arguments are ignored, but null attributes replace.
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.StringVal("1"),
+					"b": cty.StringVal("2"),
+				}),
+				cty.NullVal(cty.DynamicPseudoType),
+				cty.ObjectVal(map[string]cty.Value{
+					"b": cty.NullVal(cty.String),
+				}),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("1"),
+				"b": cty.NullVal(cty.String),
+			}),
+			false,
+		},
+		{
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.StringVal("1"),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.UnknownVal(cty.String),
+				}),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.UnknownVal(cty.String),
+			}),
+			false,
+		},
+		{
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.StringVal("1"),
+				}),
+				cty.UnknownVal(cty.EmptyObject),
+			},
+			cty.DynamicVal,
+			false,
+		},
+		{
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.StringVal("1").Mark(marks.Sensitive),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"b": cty.StringVal("2"),
+				}).Mark("test"),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("1").Mark(marks.Sensitive),
+				"b": cty.StringVal("2"),
+			}).Mark("test"),
+			false,
+		},
+		{
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.StringVal("1"),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.NumberIntVal(1),
+				}),
+			},
+			cty.NilVal,
+			true,
+		},
+		{
+			[]cty.Value{
+				cty.StringVal("a"),
+			},
+			cty.NilVal,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("deepmerge(%#v...)", test.Values), func(t *testing.T) {
+			got, err := DeepMerge(test.Values...)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestDeepMergeWith(t *testing.T) {
+	first := cty.ObjectVal(map[string]cty.Value{
+		"zones": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		"size":  cty.StringVal("small"),
+	})
+	second := cty.ObjectVal(map[string]cty.Value{
+		"zones": cty.ListVal([]cty.Value{cty.StringVal("b"), cty.StringVal("c")}),
+		"size":  cty.NullVal(cty.String),
+	})
+
+	tests := []struct {
+		Options cty.Value
+		Values  []cty.Value
+		Want    cty.Value
+		Err     bool
+	}{
+		{
+			cty.EmptyObjectVal,
+			[]cty.Value{first, second},
+			second,
+			false,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"lists": cty.StringVal("append"),
+			}),
+			[]cty.Value{first, second},
+			cty.ObjectVal(map[string]cty.Value{
+				"zones": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("b"), cty.StringVal("c")}),
+				"size":  cty.NullVal(cty.String),
+			}),
+			false,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"lists": cty.StringVal("union"),
+				"nulls": cty.StringVal("ignore"),
+			}),
+			[]cty.Value{first, second},
+			cty.ObjectVal(map[string]cty.Value{
+				"zones": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+				"size":  cty.StringVal("small"),
+			}),
+			false,
+		},
+		{
+			// Lists of different element types are combined into a tuple.
+			cty.ObjectVal(map[string]cty.Value{
+				"lists": cty.StringVal("append"),
+			}),
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ListVal([]cty.Value{cty.StringVal("x")}),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.TupleVal([]cty.Value{cty.NumberIntVal(1)}),
+				}),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.TupleVal([]cty.Value{cty.StringVal("x"), cty.NumberIntVal(1)}),
+			}),
+			false,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"lists": cty.StringVal("union"),
+			}),
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ListVal([]cty.Value{cty.StringVal("x")}),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ListVal([]cty.Value{cty.UnknownVal(cty.String)}),
+				}),
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.DynamicVal,
+			}),
+			false,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"lists": cty.StringVal("merge"),
+			}),
+			[]cty.Value{first, second},
+			cty.NilVal,
+			true,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"maps": cty.StringVal("replace"),
+			}),
+			[]cty.Value{first, second},
+			cty.NilVal,
+			true,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"lists": cty.UnknownVal(cty.String),
+			}),
+			[]cty.Value{first, second},
+			cty.NilVal,
+			true,
+		},
+		{
+			cty.StringVal("append"),
+			[]cty.Value{first, second},
+			cty.NilVal,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("deepmergewith(%#v, %#v...)", test.Options, test.Values), func(t *testing.T) {
+			got, err := DeepMergeWith(test.Options, test.Values...)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestDeepMerge_error(t *testing.T) {
+	tests := map[string]struct {
+		Values  []cty.Value
+		WantErr string
+	}{
+		"conflicting kinds": {
+			[]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"network": cty.ObjectVal(map[string]cty.Value{
+						"subnets": cty.ObjectVal(map[string]cty.Value{
+							"private/a": cty.StringVal("10.0.0.0/24"),
+						}),
+					}),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"network": cty.ObjectVal(map[string]cty.Value{
+						"subnets": cty.ObjectVal(map[string]cty.Value{
+							"private/a": cty.ListValEmpty(cty.String),
+						}),
+					}),
+				}),
+			},
+			`cannot merge list of string with string at network.subnets["private/a"]`,
+		},
+		"sensitive key": {
+			[]cty.Value{
+				cty.MapVal(map[string]cty.Value{
+					"secret": cty.StringVal("a"),
+				}).Mark(marks.Sensitive),
+				cty.ObjectVal(map[string]cty.Value{
+					"secret": cty.NumberIntVal(1),
+				}),
+			},
+			`cannot merge number with string at [(sensitive value)]`,
+		},
+		"not an object": {
+			[]cty.Value{
+				cty.EmptyObjectVal,
+				cty.ListValEmpty(cty.String),
+			},
+			"argument must be a map or object, not list of string",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := DeepMerge(test.Values...)
+
+			if err == nil {
+				t.Fatal("succeeded; want error")
+			}
+
+			if err.Error() != test.WantErr {
+				t.Errorf("wrong error\ngot:  %#v\nwant: %#v", err.Error(), test.WantErr)
+			}
+		})
+	}
+}
+
 func TestIndex(t *testing.T) {
 	tests := []struct {
 		List  cty.Value
@@ -111,12 +111,31 @@ var DescriptionList = map[string]descriptionEntry{
 			"`hostnum` is a whole number that can be represented as a binary integer with no more than the number of digits remaining in the address after the given prefix.",
 		},
 	},
+	"cidrmerge": {
+		Description: "`cidrmerge` aggregates a list of IP network address prefixes into the smallest list of prefixes that covers exactly the same addresses.",
+		ParamDescription: []string{
+			"`prefixes` is a list of IPv4 or IPv6 address prefixes given in CIDR notation.",
+		},
+	},
 	"cidrnetmask": {
 		Description: "`cidrnetmask` converts an IPv4 address prefix given in CIDR notation into a subnet mask address.",
 		ParamDescription: []string{
 			"`prefix` must be given in CIDR notation, as defined in [RFC 4632 section 3.1](https://tools.ietf.org/html/rfc4632#section-3.1).",
 		},
 	},
+	"cidroverlaps": {
+		Description: "`cidroverlaps` determines whether two IP network address prefixes given in CIDR notation have any addresses in common.",
+		ParamDescription: []string{
+			"`first_prefix` must be given in CIDR notation, as defined in [RFC 4632 section 3.1](https://tools.ietf.org/html/rfc4632#section-3.1).",
+			"`second_prefix` must be given in CIDR notation, and be of the same address family as `first_prefix`.",
+		},
+	},
+	"cidrsize": {
+		Description: "`cidrsize` returns the number of addresses within an IP network address prefix given in CIDR notation.",
+		ParamDescription: []string{
+			"`prefix` must be given in CIDR notation, as defined in [RFC 4632 section 3.1](https://tools.ietf.org/html/rfc4632#section-3.1).",
+		},
+	},
 	"cidrsubnet": {
 		Description: "`cidrsubnet` calculates a subnet address within given IP network address prefix.",
 		ParamDescription: []string{
@@ -131,6 +150,13 @@ var DescriptionList = map[string]descriptionEntry{
 			"",
 		},
 	},
+	"cidrsubtract": {
+		Description: "`cidrsubtract` calculates the address space remaining within an IP network address prefix after removing a list of allocated prefixes, returning the smallest list of prefixes that covers it.",
+		ParamDescription: []string{
+			"`prefix` must be given in CIDR notation, as defined in [RFC 4632 section 3.1](https://tools.ietf.org/html/rfc4632#section-3.1).",
+			"`allocated_prefixes` is a list of address prefixes given in CIDR notation, of the same address family as `prefix`.",
+		},
+	},
 	"coalesce": {
 		Description:      "`coalesce` takes any number of arguments and returns the first one that isn't null or an empty string.",
 		ParamDescription: []string{""},
@@ -157,6 +183,17 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`csvdecode` decodes a string containing CSV-formatted data and produces a list of maps representing that data.",
 		ParamDescription: []string{""},
 	},
+	"deepmerge": {
+		Description:      "`deepmerge` takes an arbitrary number of maps or objects, and returns a single object that recursively merges the nested maps and objects from all arguments.",
+		ParamDescription: []string{""},
+	},
+	"deepmergewith": {
+		Description: "`deepmergewith` is like `deepmerge`, but takes an object of options that choose how lists and null values are merged.",
+		ParamDescription: []string{
+			"An object with the optional attributes `lists`, which is `\"replace\"`, `\"append\"` or `\"union\"`, and `nulls`, which is `\"replace\"` or `\"ignore\"`.",
+			"",
+		},
+	},
 	"dirname": {
 		Description:      "`dirname` takes a string containing a filesystem path and removes the last portion from it.",
 		ParamDescription: []string{""},
@@ -248,6 +285,14 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`issensitive` takes any value and returns `true` if the value is marked as sensitive, and `false` otherwise.",
 		ParamDescription: []string{""},
 	},
+	"ipinrange": {
+		Description: "`ipinrange` determines whether an IP address is within an inclusive range of IP addresses.",
+		ParamDescription: []string{
+			"",
+			"`range_start` is the first address in the range, of the same address family as `address`.",
+			"`range_end` is the last address in the range, of the same address family as `address`.",
+		},
+	},
 	"join": {
 		Description: "`join` produces a string by concatenating together all elements of a given list of strings with the given delimiter.",
 		ParamDescription: []string{
@@ -333,6 +378,13 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`pow` calculates an exponent, by raising its first argument to the power of the second argument.",
 		ParamDescription: []string{"", ""},
 	},
+	"query": {
+		Description: "`query` selects data from a complex value using a [JMESPath](https://jmespath.org/) expression.",
+		ParamDescription: []string{
+			"",
+			"A JMESPath expression, such as `\"items[?enabled].name\"`.",
+		},
+	},
 	"range": {
 		Description:      "`range` generates a list of numbers using a start value, a limit value, and a step value.",
 		ParamDescription: []string{""},
@@ -357,6 +409,25 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`rsadecrypt` decrypts an RSA-encrypted ciphertext, returning the corresponding cleartext.",
 		ParamDescription: []string{"", ""},
 	},
+	"semvercompare": {
+		Description:      "`semvercompare` compares two [semantic versions](https://semver.org/), returning -1 if the first is lower, 0 if they have the same precedence, or 1 if the first is higher.",
+		ParamDescription: []string{"", ""},
+	},
+	"semverconstraint": {
+		Description: "`semverconstraint` returns `true` if a [semantic version](https://semver.org/) meets a version constraint, using the same syntax as the `version` argument for providers.",
+		ParamDescription: []string{
+			"",
+			"One or more comma-separated version constraints, such as `\"~> 1.2, < 2\"`.",
+		},
+	},
+	"semverparse": {
+		Description:      "`semverparse` parses a [semantic version](https://semver.org/) and returns an object with its `major`, `minor`, `patch`, `prerelease` and `build` parts.",
+		ParamDescription: []string{""},
+	},
+	"semversort": {
+		Description:      "`semversort` takes a list of [semantic versions](https://semver.org/) and returns them sorted from lowest to highest precedence.",
+		ParamDescription: []string{""},
+	},
 	"sensitive": {
 		Description:      "`sensitive` takes any value and returns a copy of it marked so that OpenTofu will treat it as sensitive, with the same meaning and behavior as for [sensitive input variables](/language/values/variables#suppressing-values-in-cli-output).",
 		ParamDescription: []string{""},
@@ -475,6 +546,14 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`tomap` converts its argument to a map value.",
 		ParamDescription: []string{""},
 	},
+	"tomldecode": {
+		Description:      "`tomldecode` parses a string as a [TOML](https://toml.io/) document, and produces an object representing its tables and values.",
+		ParamDescription: []string{""},
+	},
+	"tomlencode": {
+		Description:      "`tomlencode` encodes an object or map as a [TOML](https://toml.io/) document, with the keys in lexical order.",
+		ParamDescription: []string{""},
+	},
 	"tonumber": {
 		Description:      "`tonumber` converts its argument to a number value.",
 		ParamDescription: []string{""},
@@ -542,6 +621,14 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`values` takes a map and returns a list containing the values of the elements in that map.",
 		ParamDescription: []string{""},
 	},
+	"xmldecode": {
+		Description:      "`xmldecode` parses a string as an XML document, and produces an object representing its root element, with the attributes `name`, `attributes`, `children` and `text`.",
+		ParamDescription: []string{""},
+	},
+	"xmlencode": {
+		Description:      "`xmlencode` encodes an object representing an element, in the form produced by `xmldecode`, as an XML document.",
+		ParamDescription: []string{""},
+	},
 	"yamldecode": {
 		Description:      "`yamldecode` parses a string as a subset of YAML, and produces a representation of its value.",
 		ParamDescription: []string{""},
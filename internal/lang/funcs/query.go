@@ -0,0 +1,347 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// QueryFunc constructs a function that selects data from a value using a
+// JMESPath expression.
+//
+// Expressions that only select a nested value by name and index, such as
+// a.b[0].c, return the selected value exactly as it is, with its own marks.
+// Marks are not tracked through any other expressions, so if any part of the
+// value is marked then the whole result has the same marks.
+var QueryFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowDynamicType: true,
+			AllowUnknown:     true,
+			AllowNull:        true,
+			AllowMarked:      true,
+		},
+		{
+			Name:         "expression",
+			Type:         cty.String,
+			AllowUnknown: true,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		if !args[1].IsKnown() {
+			return cty.DynamicPseudoType, nil
+		}
+		// We check the expression even if the value is unknown, so that
+		// mistakes are reported as early as possible.
+		expr, err := compileQuery(args[1].AsString())
+		if err != nil {
+			return cty.NilType, function.NewArgError(1, err)
+		}
+		if !args[0].IsWhollyKnown() {
+			return cty.DynamicPseudoType, nil
+		}
+
+		result, err := runQuery(expr, args[1].AsString(), args[0])
+		if err != nil {
+			return cty.NilType, err
+		}
+		return result.Type(), nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		if !args[0].IsWhollyKnown() || !args[1].IsKnown() {
+			_, valMarks := args[0].UnmarkDeep()
+			return cty.UnknownVal(retType).WithMarks(valMarks), nil
+		}
+		expr, err := compileQuery(args[1].AsString())
+		if err != nil {
+			return cty.NilVal, function.NewArgError(1, err)
+		}
+		return runQuery(expr, args[1].AsString(), args[0])
+	},
+})
+
+// compileQuery parses a JMESPath expression, returning errors that include the
+// position of the problem.
+func compileQuery(src string) (*jmespath.JMESPath, error) {
+	expr, err := jmespath.Compile(src)
+	if err != nil {
+		var syntaxErr jmespath.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			msg := strings.TrimPrefix(syntaxErr.Error(), "SyntaxError: ")
+			return nil, fmt.Errorf("invalid query expression at column %d: %s", syntaxErr.Offset+1, msg)
+		}
+		return nil, fmt.Errorf("invalid query expression: %w", err)
+	}
+	return expr, nil
+}
+
+// runQuery evaluates a compiled JMESPath expression, whose source is src,
+// against a wholly-known value.
+func runQuery(expr *jmespath.JMESPath, src string, val cty.Value) (cty.Value, error) {
+	if path, ok := parseQueryPath(src); ok {
+		if result, ok := selectQueryPath(val, path); ok {
+			return result, nil
+		}
+	}
+
+	unmarked, valMarks := val.UnmarkDeep()
+	raw, err := queryDataFromCty(unmarked)
+	if err != nil {
+		return cty.NilVal, function.NewArgError(0, err)
+	}
+	result, err := expr.Search(raw)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to evaluate query: %s", queryErrorMessage(err))
+	}
+	ret, err := queryDataToCty(result)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ret.WithMarks(valMarks), nil
+}
+
+// parseQueryPath returns the path that a JMESPath expression selects, if the
+// expression consists only of names and array indices, such as a.b[0]."c-d".
+// The expression must already be known to be valid.
+func parseQueryPath(src string) (cty.Path, bool) {
+	var path cty.Path
+	rest := strings.TrimSpace(src)
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, false
+			}
+			// Anything other than a number is a projection, a slice or a
+			// filter, which may combine several values.
+			idx, err := strconv.ParseInt(strings.TrimSpace(rest[1:end]), 10, 64)
+			if err != nil {
+				return nil, false
+			}
+			path = append(path, cty.IndexStep{Key: cty.NumberIntVal(idx)})
+			rest = rest[end+1:]
+		case len(path) > 0 && rest[0] != '.':
+			return nil, false
+		default:
+			if len(path) > 0 {
+				rest = strings.TrimSpace(rest[1:])
+			}
+			name, remain, ok := cutQueryIdentifier(rest)
+			if !ok {
+				return nil, false
+			}
+			path = append(path, cty.GetAttrStep{Name: name})
+			rest = remain
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return path, len(path) > 0
+}
+
+// cutQueryIdentifier returns the JMESPath identifier at the start of src and
+// the remainder of src after it.
+func cutQueryIdentifier(src string) (string, string, bool) {
+	if strings.HasPrefix(src, `"`) {
+		for i := 1; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case '"':
+				var name string
+				if err := json.Unmarshal([]byte(src[:i+1]), &name); err != nil {
+					return "", "", false
+				}
+				return name, src[i+1:], true
+			}
+		}
+		return "", "", false
+	}
+
+	end := strings.IndexFunc(src, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end < 0 {
+		end = len(src)
+	}
+	if end == 0 || src[0] >= '0' && src[0] <= '9' {
+		return "", "", false
+	}
+	return src[:end], src[end:], true
+}
+
+// selectQueryPath returns the value at the given path within val with the
+// same semantics as JMESPath, where a name or index that doesn't exist
+// selects null. The result has the marks of the selected value and of the
+// values that contain it. It returns false if the path indexes a set, whose
+// elements have no index.
+func selectQueryPath(val cty.Value, path cty.Path) (cty.Value, bool) {
+	var pathMarks []cty.ValueMarks
+	for _, step := range path {
+		unmarked, stepMarks := val.Unmark()
+		pathMarks = append(pathMarks, stepMarks)
+		missing := cty.NullVal(cty.DynamicPseudoType).WithMarks(pathMarks...)
+		if unmarked.IsNull() {
+			return missing, true
+		}
+		ty := unmarked.Type()
+
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			switch {
+			case ty.IsObjectType() && ty.HasAttribute(step.Name):
+				val = unmarked.GetAttr(step.Name)
+			case ty.IsMapType() && unmarked.HasIndex(cty.StringVal(step.Name)).True():
+				val = unmarked.Index(cty.StringVal(step.Name))
+			default:
+				return missing, true
+			}
+		case cty.IndexStep:
+			switch {
+			case ty.IsListType() || ty.IsTupleType():
+				idx, _ := step.Key.AsBigFloat().Int64()
+				length := int64(unmarked.LengthInt())
+				if idx < 0 {
+					idx += length
+				}
+				if idx < 0 || idx >= length {
+					return missing, true
+				}
+				val = unmarked.Index(cty.NumberIntVal(idx))
+			case ty.IsSetType():
+				return cty.NilVal, false
+			default:
+				return missing, true
+			}
+		}
+	}
+	return val.WithMarks(pathMarks...), true
+}
+
+// queryTypeErrorPattern matches the error the JMESPath implementation returns
+// when a function argument has the wrong type.
+var queryTypeErrorPattern = regexp.MustCompile(`^Invalid type for: .*, expected: .*\{(.*)\}$`)
+
+// queryErrorMessage returns the message for an error from evaluating a JMESPath
+// expression. Type errors include the whole argument, which may be large and
+// which has had its marks removed, so we describe only the expected types.
+func queryErrorMessage(err error) string {
+	msg := err.Error()
+	if match := queryTypeErrorPattern.FindStringSubmatch(msg); match != nil {
+		return fmt.Sprintf("invalid argument type for function, expected %s", strings.ReplaceAll(match[1], `"`, ""))
+	}
+	return msg
+}
+
+// queryDataFromCty converts a value into the JSON-like data that the JMESPath
+// implementation works with.
+func queryDataFromCty(val cty.Value) (interface{}, error) {
+	if val.IsNull() {
+		return nil, nil
+	}
+	ty := val.Type()
+
+	switch {
+	case ty.IsObjectType() || ty.IsMapType():
+		ret := make(map[string]interface{}, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			raw, err := queryDataFromCty(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[k.AsString()] = raw
+		}
+		return ret, nil
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		ret := make([]interface{}, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			raw, err := queryDataFromCty(v)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, raw)
+		}
+		return ret, nil
+	case ty == cty.String:
+		return val.AsString(), nil
+	case ty == cty.Bool:
+		return val.True(), nil
+	case ty == cty.Number:
+		// JMESPath numbers are 64-bit floating point numbers, so we refuse
+		// to lose the precision of numbers such as large IDs.
+		f, _ := val.AsBigFloat().Float64()
+		if !queryNumberVal(f).Equals(val).True() {
+			return nil, fmt.Errorf("cannot query the number %s, because it cannot be represented exactly in a query expression", val.AsBigFloat().Text('f', -1))
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("cannot query a value of type %s", ty.FriendlyName())
+	}
+}
+
+// queryDataToCty converts the result of a JMESPath expression back into a
+// value. Objects become objects and arrays become tuples, as for jsondecode.
+func queryDataToCty(raw interface{}) (cty.Value, error) {
+	switch raw := raw.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case map[string]interface{}:
+		attrs := make(map[string]cty.Value, len(raw))
+		for k, v := range raw {
+			val, err := queryDataToCty(v)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = val
+		}
+		return cty.ObjectVal(attrs), nil
+	case []interface{}:
+		vals := make([]cty.Value, len(raw))
+		for i, v := range raw {
+			val, err := queryDataToCty(v)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[i] = val
+		}
+		return cty.TupleVal(vals), nil
+	case string:
+		return cty.StringVal(raw), nil
+	case bool:
+		return cty.BoolVal(raw), nil
+	case float64:
+		return queryNumberVal(raw), nil
+	default:
+		return cty.NilVal, fmt.Errorf("query returned an unsupported value of type %T", raw)
+	}
+}
+
+// queryNumberVal returns the number with the shortest decimal representation
+// that rounds to f, so that 0.1 in a query result equals 0.1 in configuration.
+func queryNumberVal(f float64) cty.Value {
+	val, err := cty.ParseNumberVal(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		// Query results are always finite, so this can't happen.
+		return cty.NumberFloatVal(f)
+	}
+	return val
+}
+
+// Query selects data from a value using a JMESPath expression.
+func Query(val, expr cty.Value) (cty.Value, error) {
+	return QueryFunc.Call([]cty.Value{val, expr})
+}
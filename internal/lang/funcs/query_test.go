@@ -0,0 +1,228 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestQuery(t *testing.T) {
+	instances := cty.ObjectVal(map[string]cty.Value{
+		"reservations": cty.TupleVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"instances": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"id":    cty.StringVal("i-1"),
+						"state": cty.StringVal("running"),
+						"cpus":  cty.NumberIntVal(2),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"id":    cty.StringVal("i-2"),
+						"state": cty.StringVal("stopped"),
+						"cpus":  cty.NumberIntVal(4),
+					}),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"instances": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"id":    cty.StringVal("i-3"),
+						"state": cty.StringVal("running"),
+						"cpus":  cty.NumberIntVal(8),
+					}),
+				}),
+			}),
+		}),
+	})
+
+	tests := []struct {
+		Value cty.Value
+		Expr  cty.Value
+		Want  cty.Value
+		Err   string
+	}{
+		{
+			instances,
+			cty.StringVal("reservations[].instances[?state == 'running'].id[]"),
+			cty.TupleVal([]cty.Value{cty.StringVal("i-1"), cty.StringVal("i-3")}),
+			``,
+		},
+		{
+			instances,
+			cty.StringVal("sum(reservations[].instances[].cpus[])"),
+			cty.NumberIntVal(14),
+			``,
+		},
+		{
+			instances,
+			cty.StringVal("reservations[0].instances[1]"),
+			cty.ObjectVal(map[string]cty.Value{
+				"id":    cty.StringVal("i-2"),
+				"state": cty.StringVal("stopped"),
+				"cpus":  cty.NumberIntVal(4),
+			}),
+			``,
+		},
+		{
+			instances,
+			cty.StringVal("reservations[].instances[].{name: id, big: cpus > `4`}"),
+			cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("i-1"), "big": cty.False}),
+				cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("i-2"), "big": cty.False}),
+				cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("i-3"), "big": cty.True}),
+			}),
+			``,
+		},
+		{
+			// Missing attributes produce null rather than an error.
+			instances,
+			cty.StringVal("reservations[0].missing"),
+			cty.NullVal(cty.DynamicPseudoType),
+			``,
+		},
+		{
+			// Selecting a value keeps only its own marks.
+			cty.MapVal(map[string]cty.Value{
+				"password": cty.StringVal("hunter2").Mark(marks.Sensitive),
+				"username": cty.StringVal("admin"),
+			}),
+			cty.StringVal("username"),
+			cty.StringVal("admin"),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("x").Mark(marks.Sensitive),
+				"b": cty.NumberIntVal(1),
+			}),
+			cty.StringVal("a"),
+			cty.StringVal("x").Mark(marks.Sensitive),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"creds": cty.ObjectVal(map[string]cty.Value{
+					"users": cty.ListVal([]cty.Value{cty.StringVal("admin"), cty.StringVal("guest")}),
+				}).Mark(marks.Sensitive),
+			}),
+			cty.StringVal(`creds."users"[-1]`),
+			cty.StringVal("guest").Mark(marks.Sensitive),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("x").Mark(marks.Sensitive),
+				"b": cty.NumberIntVal(1),
+			}),
+			cty.StringVal("b"),
+			cty.NumberIntVal(1),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("x").Mark(marks.Sensitive),
+				"b": cty.NumberIntVal(1),
+			}),
+			cty.StringVal("b[0].c"),
+			cty.NullVal(cty.DynamicPseudoType),
+			``,
+		},
+		{
+			// Other expressions have the marks of the whole value.
+			cty.MapVal(map[string]cty.Value{
+				"password": cty.StringVal("hunter2").Mark(marks.Sensitive),
+				"username": cty.StringVal("admin"),
+			}),
+			cty.StringVal("[username]"),
+			cty.TupleVal([]cty.Value{cty.StringVal("admin")}).Mark(marks.Sensitive),
+			``,
+		},
+		{
+			// Selected numbers keep their precision.
+			cty.ObjectVal(map[string]cty.Value{
+				"id": cty.MustParseNumberVal("12345678901234567891"),
+			}),
+			cty.StringVal("id"),
+			cty.MustParseNumberVal("12345678901234567891"),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"prices": cty.TupleVal([]cty.Value{cty.MustParseNumberVal("0.1"), cty.MustParseNumberVal("0.2")}),
+			}),
+			cty.StringVal("max(prices)"),
+			cty.MustParseNumberVal("0.2"),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"id": cty.MustParseNumberVal("12345678901234567891"),
+			}),
+			cty.StringVal("[id]"),
+			cty.NilVal,
+			`cannot query the number 12345678901234567891, because it cannot be represented exactly in a query expression`,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"id":   cty.UnknownVal(cty.String),
+				"name": cty.StringVal("web"),
+			}),
+			cty.StringVal("name"),
+			cty.DynamicVal,
+			``,
+		},
+		{
+			instances,
+			cty.UnknownVal(cty.String),
+			cty.DynamicVal,
+			``,
+		},
+		{
+			cty.UnknownVal(cty.EmptyObject),
+			cty.StringVal("reservations[?"),
+			cty.NilVal,
+			`invalid query expression at column 15: Incomplete expression`,
+		},
+		{
+			instances,
+			cty.StringVal("reservations | foo(@)"),
+			cty.NilVal,
+			`failed to evaluate query: unknown function: foo`,
+		},
+		{
+			instances,
+			cty.StringVal("sum(reservations)"),
+			cty.NilVal,
+			`failed to evaluate query: invalid argument type for function, expected array[number]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("query(%#v, %#v)", test.Value, test.Expr), func(t *testing.T) {
+			got, err := Query(test.Value, test.Expr)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			gotVal, gotMarks := got.UnmarkDeep()
+			wantVal, wantMarks := test.Want.UnmarkDeep()
+			if !got.RawEquals(test.Want) && !(gotVal.IsKnown() && gotVal.Equals(wantVal).True() && gotMarks.Equal(wantMarks)) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
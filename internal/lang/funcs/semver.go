@@ -0,0 +1,197 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/apparentlymart/go-versions/versions/constraints"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// semverObjectType is the type of the object returned by semverparse.
+var semverObjectType = cty.Object(map[string]cty.Type{
+	"version":    cty.String,
+	"major":      cty.Number,
+	"minor":      cty.Number,
+	"patch":      cty.Number,
+	"prerelease": cty.String,
+	"build":      cty.String,
+})
+
+// SemverParseFunc constructs a function that parses a semantic version string
+// into its components.
+var SemverParseFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "version",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(semverObjectType),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		v, err := parseSemver(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgError(0, err)
+		}
+
+		return cty.ObjectVal(map[string]cty.Value{
+			"version":    cty.StringVal(v.String()),
+			"major":      cty.NumberUIntVal(v.Major),
+			"minor":      cty.NumberUIntVal(v.Minor),
+			"patch":      cty.NumberUIntVal(v.Patch),
+			"prerelease": cty.StringVal(string(v.Prerelease)),
+			"build":      cty.StringVal(string(v.Metadata)),
+		}), nil
+	},
+})
+
+// SemverCompareFunc constructs a function that compares two semantic versions,
+// returning -1, 0 or 1.
+var SemverCompareFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "a",
+			Type: cty.String,
+		},
+		{
+			Name: "b",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Number),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		a, err := parseSemver(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Number), function.NewArgError(0, err)
+		}
+		b, err := parseSemver(args[1].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Number), function.NewArgError(1, err)
+		}
+
+		switch {
+		case a.LessThan(b):
+			return cty.NumberIntVal(-1), nil
+		case a.GreaterThan(b):
+			return cty.NumberIntVal(1), nil
+		default:
+			return cty.NumberIntVal(0), nil
+		}
+	},
+})
+
+// SemverConstraintFunc constructs a function that tests whether a semantic
+// version meets a version constraint string, using the same syntax as the
+// version constraints for providers.
+var SemverConstraintFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "version",
+			Type: cty.String,
+		},
+		{
+			Name: "constraint",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		v, err := parseSemver(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgError(0, err)
+		}
+
+		spec, err := constraints.ParseRubyStyleMulti(args[1].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgErrorf(1, "invalid version constraint: %s", err)
+		}
+
+		return cty.BoolVal(versions.MeetingConstraints(spec).Has(v)), nil
+	},
+})
+
+// SemverSortFunc constructs a function that sorts a list of semantic version
+// strings in ascending order of precedence.
+var SemverSortFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "versions",
+			Type: cty.List(cty.String),
+		},
+	},
+	Type:         function.StaticReturnType(cty.List(cty.String)),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		list := args[0]
+		if list.LengthInt() == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+
+		type entry struct {
+			raw     cty.Value
+			version versions.Version
+		}
+		entries := make([]entry, 0, list.LengthInt())
+		for it := list.ElementIterator(); it.Next(); {
+			idx, val := it.Element()
+			if val.IsNull() {
+				return cty.UnknownVal(retType), function.NewArgErrorf(0, "element %s is null", idx.AsBigFloat().String())
+			}
+			v, err := parseSemver(val.AsString())
+			if err != nil {
+				return cty.UnknownVal(retType), function.NewArgErrorf(0, "element %s: %s", idx.AsBigFloat().String(), err)
+			}
+			entries = append(entries, entry{raw: val, version: v})
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].version.LessThan(entries[j].version)
+		})
+
+		sorted := make([]cty.Value, len(entries))
+		for i, entry := range entries {
+			sorted[i] = entry.raw
+		}
+		return cty.ListVal(sorted), nil
+	},
+})
+
+// parseSemver parses a semantic version, allowing a "v" prefix as is common
+// in version control tags and image names. Missing minor and patch numbers
+// are taken as zero.
+func parseSemver(str string) (versions.Version, error) {
+	v, err := versions.ParseVersion(strings.TrimPrefix(str, "v"))
+	if err != nil {
+		return versions.Unspecified, fmt.Errorf("invalid semantic version %q: %w", str, err)
+	}
+	return v, nil
+}
+
+// SemverParse parses a semantic version into its components.
+func SemverParse(version cty.Value) (cty.Value, error) {
+	return SemverParseFunc.Call([]cty.Value{version})
+}
+
+// SemverCompare compares two semantic versions.
+func SemverCompare(a, b cty.Value) (cty.Value, error) {
+	return SemverCompareFunc.Call([]cty.Value{a, b})
+}
+
+// SemverConstraint tests whether a semantic version meets a constraint.
+func SemverConstraint(version, constraint cty.Value) (cty.Value, error) {
+	return SemverConstraintFunc.Call([]cty.Value{version, constraint})
+}
+
+// SemverSort sorts a list of semantic versions in ascending order.
+func SemverSort(list cty.Value) (cty.Value, error) {
+	return SemverSortFunc.Call([]cty.Value{list})
+}
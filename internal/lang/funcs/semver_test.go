@@ -0,0 +1,329 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSemverParse(t *testing.T) {
+	tests := []struct {
+		Version cty.Value
+		Want    cty.Value
+		Err     bool
+	}{
+		{
+			cty.StringVal("1.2.3"),
+			cty.ObjectVal(map[string]cty.Value{
+				"version":    cty.StringVal("1.2.3"),
+				"major":      cty.NumberIntVal(1),
+				"minor":      cty.NumberIntVal(2),
+				"patch":      cty.NumberIntVal(3),
+				"prerelease": cty.StringVal(""),
+				"build":      cty.StringVal(""),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("v2.0.0-rc.1+build.5"),
+			cty.ObjectVal(map[string]cty.Value{
+				"version":    cty.StringVal("2.0.0-rc.1+build.5"),
+				"major":      cty.NumberIntVal(2),
+				"minor":      cty.NumberIntVal(0),
+				"patch":      cty.NumberIntVal(0),
+				"prerelease": cty.StringVal("rc.1"),
+				"build":      cty.StringVal("build.5"),
+			}),
+			false,
+		},
+		{
+			// Missing parts are taken as zero.
+			cty.StringVal("1.4"),
+			cty.ObjectVal(map[string]cty.Value{
+				"version":    cty.StringVal("1.4.0"),
+				"major":      cty.NumberIntVal(1),
+				"minor":      cty.NumberIntVal(4),
+				"patch":      cty.NumberIntVal(0),
+				"prerelease": cty.StringVal(""),
+				"build":      cty.StringVal(""),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("not-a-version"),
+			cty.UnknownVal(semverObjectType),
+			true,
+		},
+		{
+			cty.StringVal(">= 1.0.0"),
+			cty.UnknownVal(semverObjectType),
+			true,
+		},
+		{
+			cty.StringVal(""),
+			cty.UnknownVal(semverObjectType),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("semverparse(%#v)", test.Version), func(t *testing.T) {
+			got, err := SemverParse(test.Version)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		A, B cty.Value
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			cty.StringVal("1.2.3"),
+			cty.StringVal("1.2.3"),
+			cty.NumberIntVal(0),
+			false,
+		},
+		{
+			cty.StringVal("1.2.3"),
+			cty.StringVal("1.10.0"),
+			cty.NumberIntVal(-1),
+			false,
+		},
+		{
+			cty.StringVal("v2.0.0"),
+			cty.StringVal("1.99.99"),
+			cty.NumberIntVal(1),
+			false,
+		},
+		{
+			// Pre-releases come before the release.
+			cty.StringVal("1.0.0-beta.2"),
+			cty.StringVal("1.0.0"),
+			cty.NumberIntVal(-1),
+			false,
+		},
+		{
+			cty.StringVal("1.0.0-beta.2"),
+			cty.StringVal("1.0.0-beta.10"),
+			cty.NumberIntVal(-1),
+			false,
+		},
+		{
+			// Build metadata doesn't affect precedence.
+			cty.StringVal("1.0.0+a"),
+			cty.StringVal("1.0.0+b"),
+			cty.NumberIntVal(0),
+			false,
+		},
+		{
+			cty.StringVal("1.0.0"),
+			cty.StringVal("latest"),
+			cty.UnknownVal(cty.Number),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("semvercompare(%#v, %#v)", test.A, test.B), func(t *testing.T) {
+			got, err := SemverCompare(test.A, test.B)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSemverConstraint(t *testing.T) {
+	tests := []struct {
+		Version    cty.Value
+		Constraint cty.Value
+		Want       cty.Value
+		Err        bool
+	}{
+		{
+			cty.StringVal("1.4.2"),
+			cty.StringVal("~> 1.2, < 2"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("2.0.0"),
+			cty.StringVal("~> 1.2, < 2"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("1.2.9"),
+			cty.StringVal("~> 1.2.0"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("1.3.0"),
+			cty.StringVal("~> 1.2.0"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("v1.0.0"),
+			cty.StringVal("1.0.0"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("1.0.0"),
+			cty.StringVal("!= 1.0.0"),
+			cty.False,
+			false,
+		},
+		{
+			// Pre-releases only match constraints that name them exactly.
+			cty.StringVal("1.5.0-beta.1"),
+			cty.StringVal(">= 1.0.0"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("1.5.0-beta.1"),
+			cty.StringVal("1.5.0-beta.1"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("1.0.0"),
+			cty.StringVal("~> 1.2, nope"),
+			cty.UnknownVal(cty.Bool),
+			true,
+		},
+		{
+			cty.StringVal("nope"),
+			cty.StringVal(">= 1.0.0"),
+			cty.UnknownVal(cty.Bool),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("semverconstraint(%#v, %#v)", test.Version, test.Constraint), func(t *testing.T) {
+			got, err := SemverConstraint(test.Version, test.Constraint)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSemverSort(t *testing.T) {
+	tests := []struct {
+		List cty.Value
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("1.10.0"),
+				cty.StringVal("v1.2.0"),
+				cty.StringVal("1.2.0-rc.1"),
+				cty.StringVal("0.9"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("0.9"),
+				cty.StringVal("1.2.0-rc.1"),
+				cty.StringVal("v1.2.0"),
+				cty.StringVal("1.10.0"),
+			}),
+			false,
+		},
+		{
+			// Versions with the same precedence keep their order.
+			cty.ListVal([]cty.Value{
+				cty.StringVal("1.0.0+b"),
+				cty.StringVal("1.0.0+a"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.StringVal("1.0.0+b"),
+				cty.StringVal("1.0.0+a"),
+			}),
+			false,
+		},
+		{
+			cty.ListValEmpty(cty.String),
+			cty.ListValEmpty(cty.String),
+			false,
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("1.0.0"),
+				cty.StringVal("latest"),
+			}),
+			cty.UnknownVal(cty.List(cty.String)),
+			true,
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.StringVal("1.0.0"),
+				cty.NullVal(cty.String),
+			}),
+			cty.UnknownVal(cty.List(cty.String)),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("semversort(%#v)", test.List), func(t *testing.T) {
+			got, err := SemverSort(test.List)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
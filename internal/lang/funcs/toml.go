@@ -0,0 +1,252 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// TOMLDecodeFunc constructs a function that parses a TOML document into a
+// value. Tables become objects and arrays become tuples, while dates and times
+// become strings as they do for yamldecode.
+var TOMLDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "src",
+			Type: cty.String,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		if !args[0].IsKnown() {
+			// A TOML document is always a table, but we can't know its
+			// attributes until the whole document is known.
+			return cty.DynamicPseudoType, nil
+		}
+		val, err := decodeTOML(args[0].AsString())
+		if err != nil {
+			return cty.NilType, function.NewArgError(0, err)
+		}
+		return val.Type(), nil
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val, err := decodeTOML(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		return val, nil
+	},
+})
+
+// TOMLEncodeFunc constructs a function that renders an object or map as a
+// TOML document, with its keys in lexical order.
+var TOMLEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val := args[0]
+		ty := val.Type()
+		if ty != cty.DynamicPseudoType && !ty.IsObjectType() && !ty.IsMapType() {
+			return cty.NilVal, function.NewArgErrorf(0, "a TOML document must be an object or a map, not %s", ty.FriendlyName())
+		}
+		if !val.IsWhollyKnown() {
+			return cty.UnknownVal(retType), nil
+		}
+
+		doc, err := tomlFromCty(val, nil)
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+
+		var buf bytes.Buffer
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = ""
+		if err := enc.Encode(doc); err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		return cty.StringVal(buf.String()), nil
+	},
+})
+
+// decodeTOML parses src as a TOML document, returning errors that include the
+// position of the problem.
+func decodeTOML(src string) (cty.Value, error) {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(src, &doc); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			// The library doesn't always record the line of errors at the
+			// end of the document, so we count the lines ourselves.
+			start := min(parseErr.Position.Start, len(src))
+			line := strings.Count(src[:start], "\n") + 1
+			column := start - strings.LastIndexByte(src[:start], '\n')
+			msg := parseErr.Message
+			if msg == "" {
+				// Some errors only have a message in the full error string,
+				// after the library's own description of the position.
+				prefix := fmt.Sprintf("toml: line %d", parseErr.Position.Line)
+				if parseErr.LastKey != "" {
+					prefix += fmt.Sprintf(" (last key %q)", parseErr.LastKey)
+				}
+				msg = strings.TrimPrefix(parseErr.Error(), prefix+": ")
+			}
+			return cty.NilVal, fmt.Errorf("invalid TOML at line %d, column %d: %s", line, column, msg)
+		}
+		return cty.NilVal, fmt.Errorf("invalid TOML: %w", err)
+	}
+	return tomlToCty(doc, nil)
+}
+
+// tomlToCty converts a value decoded by the TOML library into the equivalent
+// cty value. The path is the sequence of keys and indices leading to the
+// value, for error messages.
+func tomlToCty(raw interface{}, path []string) (cty.Value, error) {
+	switch raw := raw.(type) {
+	case map[string]interface{}:
+		attrs := make(map[string]cty.Value, len(raw))
+		for k, v := range raw {
+			val, err := tomlToCty(v, append(path, k))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = val
+		}
+		return cty.ObjectVal(attrs), nil
+	case []map[string]interface{}:
+		vals := make([]cty.Value, len(raw))
+		for i, v := range raw {
+			val, err := tomlToCty(v, append(path, fmt.Sprint(i)))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[i] = val
+		}
+		return cty.TupleVal(vals), nil
+	case []interface{}:
+		vals := make([]cty.Value, len(raw))
+		for i, v := range raw {
+			val, err := tomlToCty(v, append(path, fmt.Sprint(i)))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[i] = val
+		}
+		return cty.TupleVal(vals), nil
+	case string:
+		return cty.StringVal(raw), nil
+	case bool:
+		return cty.BoolVal(raw), nil
+	case int64:
+		return cty.NumberIntVal(raw), nil
+	case float64:
+		if math.IsInf(raw, 0) || math.IsNaN(raw) {
+			return cty.NilVal, fmt.Errorf("cannot decode %v at %s, because numbers must be finite", raw, tomlPath(path))
+		}
+		return cty.NumberFloatVal(raw), nil
+	case time.Time:
+		// cty has no timestamp type, so as with yamldecode we use strings in
+		// the formats the timestamp functions accept. TOML also allows dates
+		// and times without an offset, which keep their own formats.
+		switch raw.Location().String() {
+		case "datetime-local":
+			return cty.StringVal(raw.Format("2006-01-02T15:04:05.999999999")), nil
+		case "date-local":
+			return cty.StringVal(raw.Format("2006-01-02")), nil
+		case "time-local":
+			return cty.StringVal(raw.Format("15:04:05.999999999")), nil
+		default:
+			return cty.StringVal(raw.Format(time.RFC3339Nano)), nil
+		}
+	default:
+		return cty.NilVal, fmt.Errorf("unsupported TOML value at %s", tomlPath(path))
+	}
+}
+
+// tomlFromCty converts a cty value into the equivalent value for the TOML
+// library to encode. TOML has no null, so null values are an error.
+func tomlFromCty(val cty.Value, path []string) (interface{}, error) {
+	if val.IsNull() {
+		return nil, fmt.Errorf("cannot encode null at %s, because TOML has no null value", tomlPath(path))
+	}
+	ty := val.Type()
+
+	switch {
+	case ty.IsObjectType() || ty.IsMapType():
+		ret := make(map[string]interface{}, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			raw, err := tomlFromCty(v, append(path, k.AsString()))
+			if err != nil {
+				return nil, err
+			}
+			ret[k.AsString()] = raw
+		}
+		return ret, nil
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		ret := make([]interface{}, 0, val.LengthInt())
+		i := 0
+		for it := val.ElementIterator(); it.Next(); i++ {
+			_, v := it.Element()
+			raw, err := tomlFromCty(v, append(path, fmt.Sprint(i)))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, raw)
+		}
+		return ret, nil
+	case ty == cty.String:
+		return val.AsString(), nil
+	case ty == cty.Bool:
+		return val.True(), nil
+	case ty == cty.Number:
+		bf := val.AsBigFloat()
+		if bf.IsInt() {
+			if i, acc := bf.Int64(); acc == big.Exact {
+				return i, nil
+			}
+			return nil, fmt.Errorf("cannot encode %s at %s, because it is too large for a TOML integer", bf.Text('f', -1), tomlPath(path))
+		}
+		f, _ := bf.Float64()
+		return f, nil
+	default:
+		return nil, fmt.Errorf("cannot encode %s at %s", ty.FriendlyName(), tomlPath(path))
+	}
+}
+
+// tomlPath returns a description of the location of a value in a TOML
+// document for error messages.
+func tomlPath(path []string) string {
+	if len(path) == 0 {
+		return "the top level"
+	}
+	return fmt.Sprintf("%q", strings.Join(path, "."))
+}
+
+// TOMLDecode parses a TOML document into a value.
+func TOMLDecode(src cty.Value) (cty.Value, error) {
+	return TOMLDecodeFunc.Call([]cty.Value{src})
+}
+
+// TOMLEncode renders an object or map as a TOML document.
+func TOMLEncode(val cty.Value) (cty.Value, error) {
+	return TOMLEncodeFunc.Call([]cty.Value{val})
+}
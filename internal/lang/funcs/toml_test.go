@@ -0,0 +1,235 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestTOMLDecode(t *testing.T) {
+	tests := []struct {
+		Src  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.StringVal(`
+title = "example"
+enabled = true
+ratio = 0.5
+ports = [80, 443]
+
+[server]
+host = "localhost"
+port = 8080
+
+[[backends]]
+name = "a"
+
+[[backends]]
+name = "b"
+weight = 2
+`),
+			cty.ObjectVal(map[string]cty.Value{
+				"title":   cty.StringVal("example"),
+				"enabled": cty.True,
+				"ratio":   cty.NumberFloatVal(0.5),
+				"ports":   cty.TupleVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+				"server": cty.ObjectVal(map[string]cty.Value{
+					"host": cty.StringVal("localhost"),
+					"port": cty.NumberIntVal(8080),
+				}),
+				"backends": cty.TupleVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("a"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"name":   cty.StringVal("b"),
+						"weight": cty.NumberIntVal(2),
+					}),
+				}),
+			}),
+			``,
+		},
+		{
+			// Dates and times become strings, keeping any offset.
+			cty.StringVal(`
+offset = 1979-05-27T07:32:00-07:00
+utc = 1979-05-27T07:32:00.5Z
+local_datetime = 1979-05-27T07:32:00
+local_date = 1979-05-27
+local_time = 07:32:00
+`),
+			cty.ObjectVal(map[string]cty.Value{
+				"offset":         cty.StringVal("1979-05-27T07:32:00-07:00"),
+				"utc":            cty.StringVal("1979-05-27T07:32:00.5Z"),
+				"local_datetime": cty.StringVal("1979-05-27T07:32:00"),
+				"local_date":     cty.StringVal("1979-05-27"),
+				"local_time":     cty.StringVal("07:32:00"),
+			}),
+			``,
+		},
+		{
+			cty.StringVal(``),
+			cty.EmptyObjectVal,
+			``,
+		},
+		{
+			cty.StringVal("a = 1\na = 2\n"),
+			cty.NilVal,
+			`invalid TOML at line 2, column 1: Key 'a' has already been defined.`,
+		},
+		{
+			cty.StringVal("a = "),
+			cty.NilVal,
+			`invalid TOML at line 1, column 4: unexpected EOF; expected value`,
+		},
+		{
+			cty.StringVal("[limits]\nmax = inf\n"),
+			cty.NilVal,
+			`cannot decode +Inf at "limits.max", because numbers must be finite`,
+		},
+		{
+			cty.UnknownVal(cty.String),
+			cty.DynamicVal,
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("tomldecode(%#v)", test.Src), func(t *testing.T) {
+			got, err := TOMLDecode(test.Src)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestTOMLEncode(t *testing.T) {
+	tests := []struct {
+		Val  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"title": cty.StringVal("example"),
+				"ratio": cty.NumberFloatVal(0.5),
+				"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443)}),
+				"server": cty.ObjectVal(map[string]cty.Value{
+					"port": cty.NumberIntVal(8080),
+					"host": cty.StringVal("localhost"),
+				}),
+				"enabled": cty.True,
+			}),
+			cty.StringVal(`enabled = true
+ports = [80, 443]
+ratio = 0.5
+title = "example"
+
+[server]
+host = "localhost"
+port = 8080
+`),
+			``,
+		},
+		{
+			cty.MapVal(map[string]cty.Value{
+				"b": cty.StringVal("2"),
+				"a": cty.StringVal("1"),
+			}),
+			cty.StringVal("a = \"1\"\nb = \"2\"\n"),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"password": cty.StringVal("hunter2").Mark(marks.Sensitive),
+			}),
+			cty.StringVal("password = \"hunter2\"\n").Mark(marks.Sensitive),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.UnknownVal(cty.String),
+			}),
+			cty.UnknownVal(cty.String).RefineNotNull(),
+			``,
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			cty.NilVal,
+			`a TOML document must be an object or a map, not list of string`,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"items": cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NullVal(cty.String)}),
+			}),
+			cty.NilVal,
+			`cannot encode null at "items.1", because TOML has no null value`,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.NullVal(cty.String),
+			}),
+			cty.NilVal,
+			`cannot encode null at "a", because TOML has no null value`,
+		},
+		{
+			cty.MapVal(map[string]cty.Value{
+				"server": cty.ObjectVal(map[string]cty.Value{
+					"host": cty.NullVal(cty.String),
+				}),
+			}),
+			cty.NilVal,
+			`cannot encode null at "server.host", because TOML has no null value`,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"big": cty.MustParseNumberVal("100000000000000000000"),
+			}),
+			cty.NilVal,
+			`cannot encode 100000000000000000000 at "big", because it is too large for a TOML integer`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("tomlencode(%#v)", test.Val), func(t *testing.T) {
+			got, err := TOMLEncode(test.Val)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
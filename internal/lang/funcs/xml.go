@@ -0,0 +1,312 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// XMLDecodeFunc constructs a function that parses an XML document into a
+// value. Each element becomes an object with the attributes "name",
+// "attributes", "children" and "text", so that every document has the same
+// shape regardless of its schema.
+var XMLDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "src",
+			Type: cty.String,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		src := args[0]
+		if !src.IsKnown() {
+			// The type of the children depends on the whole document, but we
+			// can still reject a prefix that can't begin an XML document.
+			if prefix := strings.TrimSpace(src.Range().StringPrefix()); prefix != "" {
+				if r, _ := utf8.DecodeRuneInString(prefix); r != '<' && r != '\uFEFF' {
+					return cty.NilType, function.NewArgErrorf(0, "an XML document cannot begin with the character %q", r)
+				}
+			}
+			return cty.DynamicPseudoType, nil
+		}
+		val, err := decodeXML(src.AsString())
+		if err != nil {
+			return cty.NilType, function.NewArgError(0, err)
+		}
+		return val.Type(), nil
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val, err := decodeXML(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		return val, nil
+	},
+})
+
+// XMLEncodeFunc constructs a function that renders an element object, in the
+// form returned by XMLDecodeFunc, as an XML document. Attributes are written
+// in lexical order.
+var XMLEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val := args[0]
+		ty := val.Type()
+		if ty != cty.DynamicPseudoType && !ty.IsObjectType() && !ty.IsMapType() {
+			return cty.NilVal, function.NewArgErrorf(0, "an XML element must be an object, not %s", ty.FriendlyName())
+		}
+		if !val.IsWhollyKnown() {
+			// Every document we produce starts with the same declaration.
+			return cty.UnknownVal(retType).Refine().StringPrefixFull(xml.Header).NewValue(), nil
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		enc := xml.NewEncoder(&buf)
+		enc.Indent("", "  ")
+		if err := encodeXMLElement(enc, val, "the root element"); err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		if err := enc.Flush(); err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+		buf.WriteByte('\n')
+		return cty.StringVal(buf.String()), nil
+	},
+})
+
+// xmlElement is an element being built while decoding an XML document.
+type xmlElement struct {
+	name     string
+	attrs    map[string]cty.Value
+	children []cty.Value
+	text     strings.Builder
+}
+
+func (e *xmlElement) value() cty.Value {
+	attrs := cty.MapValEmpty(cty.String)
+	if len(e.attrs) > 0 {
+		attrs = cty.MapVal(e.attrs)
+	}
+	children := cty.EmptyTupleVal
+	if len(e.children) > 0 {
+		children = cty.TupleVal(e.children)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":       cty.StringVal(e.name),
+		"attributes": attrs,
+		"children":   children,
+		"text":       cty.StringVal(strings.TrimSpace(e.text.String())),
+	})
+}
+
+// decodeXML parses src as an XML document with a single root element,
+// returning errors that include the position of the problem. Comments,
+// processing instructions and directives are ignored, and namespace prefixes
+// are kept as part of the element and attribute names.
+func decodeXML(src string) (cty.Value, error) {
+	dec := xml.NewDecoder(strings.NewReader(src))
+
+	errorf := func(format string, args ...interface{}) error {
+		line, column := dec.InputPos()
+		return fmt.Errorf("invalid XML at line %d, column %d: %s", line, column, fmt.Sprintf(format, args...))
+	}
+
+	var stack []*xmlElement
+	var root cty.Value
+	var haveRoot bool
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return cty.NilVal, errorf("%s", syntaxErr.Msg)
+			}
+			return cty.NilVal, errorf("%s", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && haveRoot {
+				return cty.NilVal, errorf("only a single root element is allowed, but found <%s> after it", xmlName(tok.Name))
+			}
+			elem := &xmlElement{name: xmlName(tok.Name)}
+			for _, attr := range tok.Attr {
+				if elem.attrs == nil {
+					elem.attrs = make(map[string]cty.Value)
+				}
+				name := xmlName(attr.Name)
+				if _, exists := elem.attrs[name]; exists {
+					return cty.NilVal, errorf("duplicate attribute %q on <%s>", name, elem.name)
+				}
+				elem.attrs[name] = cty.StringVal(attr.Value)
+			}
+			stack = append(stack, elem)
+		case xml.EndElement:
+			name := xmlName(tok.Name)
+			if len(stack) == 0 {
+				return cty.NilVal, errorf("unexpected </%s>", name)
+			}
+			elem := stack[len(stack)-1]
+			if elem.name != name {
+				return cty.NilVal, errorf("element <%s> closed by </%s>", elem.name, name)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				root, haveRoot = elem.value(), true
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, elem.value())
+			}
+		case xml.CharData:
+			if len(stack) == 0 {
+				if len(bytes.TrimSpace(tok)) > 0 {
+					return cty.NilVal, errorf("unexpected text outside of the root element")
+				}
+				continue
+			}
+			stack[len(stack)-1].text.Write(tok)
+		}
+	}
+
+	if len(stack) > 0 {
+		return cty.NilVal, errorf("element <%s> is not closed", stack[len(stack)-1].name)
+	}
+	if !haveRoot {
+		return cty.NilVal, errorf("the document has no root element")
+	}
+	return root, nil
+}
+
+// encodeXMLElement writes an element object, and recursively its children, to
+// enc. The path describes the element for error messages.
+func encodeXMLElement(enc *xml.Encoder, val cty.Value, path string) error {
+	if val.IsNull() {
+		return fmt.Errorf("%s is null", path)
+	}
+	ty := val.Type()
+	if !ty.IsObjectType() && !ty.IsMapType() {
+		return fmt.Errorf("%s must be an object, not %s", path, ty.FriendlyName())
+	}
+
+	name := cty.NullVal(cty.String)
+	attrs := cty.NullVal(cty.Map(cty.String))
+	children := cty.NullVal(cty.EmptyTuple)
+	text := cty.NullVal(cty.String)
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		switch k.AsString() {
+		case "name":
+			name = v
+		case "attributes":
+			attrs = v
+		case "children":
+			children = v
+		case "text":
+			text = v
+		default:
+			return fmt.Errorf("%s has unsupported attribute %q; elements may only have the attributes \"name\", \"attributes\", \"children\" and \"text\"", path, k.AsString())
+		}
+	}
+
+	if name.IsNull() {
+		return fmt.Errorf("%s must have a name", path)
+	}
+	name, err := convert.Convert(name, cty.String)
+	if err != nil {
+		return fmt.Errorf("invalid name for %s: %w", path, err)
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name.AsString()}}
+	path = fmt.Sprintf("element <%s>", start.Name.Local)
+
+	if !attrs.IsNull() {
+		attrs, err = convert.Convert(attrs, cty.Map(cty.String))
+		if err != nil {
+			return fmt.Errorf("invalid attributes for %s: %w", path, err)
+		}
+		for it := attrs.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			if v.IsNull() {
+				continue
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: k.AsString()}, Value: v.AsString()})
+		}
+		sort.Slice(start.Attr, func(i, j int) bool {
+			return start.Attr[i].Name.Local < start.Attr[j].Name.Local
+		})
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return fmt.Errorf("cannot encode %s: %w", path, err)
+	}
+	if !text.IsNull() {
+		text, err = convert.Convert(text, cty.String)
+		if err != nil {
+			return fmt.Errorf("invalid text for %s: %w", path, err)
+		}
+		if err := enc.EncodeToken(xml.CharData(text.AsString())); err != nil {
+			return fmt.Errorf("cannot encode %s: %w", path, err)
+		}
+	}
+	if !children.IsNull() {
+		childrenTy := children.Type()
+		if !childrenTy.IsListType() && !childrenTy.IsTupleType() {
+			return fmt.Errorf("the children of %s must be a list, not %s", path, childrenTy.FriendlyName())
+		}
+		i := 0
+		for it := children.ElementIterator(); it.Next(); i++ {
+			_, child := it.Element()
+			if err := encodeXMLElement(enc, child, fmt.Sprintf("child %d of %s", i, path)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.EncodeToken(start.End()); err != nil {
+		return fmt.Errorf("cannot encode %s: %w", path, err)
+	}
+	return nil
+}
+
+// xmlName returns the name of an element or attribute, including its
+// namespace prefix if it has one.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// XMLDecode parses an XML document into a value.
+func XMLDecode(src cty.Value) (cty.Value, error) {
+	return XMLDecodeFunc.Call([]cty.Value{src})
+}
+
+// XMLEncode renders an element object as an XML document.
+func XMLEncode(val cty.Value) (cty.Value, error) {
+	return XMLEncodeFunc.Call([]cty.Value{val})
+}
@@ -0,0 +1,229 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// xmlElementVal returns the value xmldecode produces for an element.
+func xmlElementVal(name string, attrs map[string]string, text string, children ...cty.Value) cty.Value {
+	attrsVal := cty.MapValEmpty(cty.String)
+	if len(attrs) > 0 {
+		vals := make(map[string]cty.Value, len(attrs))
+		for k, v := range attrs {
+			vals[k] = cty.StringVal(v)
+		}
+		attrsVal = cty.MapVal(vals)
+	}
+	childrenVal := cty.EmptyTupleVal
+	if len(children) > 0 {
+		childrenVal = cty.TupleVal(children)
+	}
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":       cty.StringVal(name),
+		"attributes": attrsVal,
+		"children":   childrenVal,
+		"text":       cty.StringVal(text),
+	})
+}
+
+func TestXMLDecode(t *testing.T) {
+	tests := []struct {
+		Src  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.StringVal(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- deployment descriptor -->
+<web-app version="3.1">
+  <display-name>Example &amp; Co</display-name>
+  <servlet>
+    <servlet-name>main</servlet-name>
+    <load-on-startup>1</load-on-startup>
+  </servlet>
+  <empty/>
+</web-app>
+`),
+			xmlElementVal("web-app", map[string]string{"version": "3.1"}, "",
+				xmlElementVal("display-name", nil, "Example & Co"),
+				xmlElementVal("servlet", nil, "",
+					xmlElementVal("servlet-name", nil, "main"),
+					xmlElementVal("load-on-startup", nil, "1"),
+				),
+				xmlElementVal("empty", nil, ""),
+			),
+			``,
+		},
+		{
+			// Namespace prefixes are kept as part of the names.
+			cty.StringVal(`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><![CDATA[<raw>]]></soap:Body></soap:Envelope>`),
+			xmlElementVal("soap:Envelope", map[string]string{"xmlns:soap": "http://www.w3.org/2003/05/soap-envelope"}, "",
+				xmlElementVal("soap:Body", nil, "<raw>"),
+			),
+			``,
+		},
+		{
+			cty.StringVal("<a>\n  <b>\n</a>"),
+			cty.NilVal,
+			`invalid XML at line 3, column 5: element <b> closed by </a>`,
+		},
+		{
+			cty.StringVal("<a>"),
+			cty.NilVal,
+			`invalid XML at line 1, column 4: element <a> is not closed`,
+		},
+		{
+			cty.StringVal("<a/>\n<b/>"),
+			cty.NilVal,
+			`invalid XML at line 2, column 5: only a single root element is allowed, but found <b> after it`,
+		},
+		{
+			cty.StringVal(`<a x="1" x="2"/>`),
+			cty.NilVal,
+			`invalid XML at line 1, column 17: duplicate attribute "x" on <a>`,
+		},
+		{
+			cty.StringVal(`<a>&nbsp;</a>`),
+			cty.NilVal,
+			`invalid XML at line 1, column 10: invalid character entity &nbsp;`,
+		},
+		{
+			cty.StringVal(``),
+			cty.NilVal,
+			`invalid XML at line 1, column 1: the document has no root element`,
+		},
+		{
+			cty.UnknownVal(cty.String),
+			cty.DynamicVal,
+			``,
+		},
+		{
+			cty.UnknownVal(cty.String).Refine().StringPrefix("{").NewValue(),
+			cty.NilVal,
+			`an XML document cannot begin with the character '{'`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("xmldecode(%#v)", test.Src), func(t *testing.T) {
+			got, err := XMLDecode(test.Src)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestXMLEncode(t *testing.T) {
+	tests := []struct {
+		Val  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			xmlElementVal("web-app", map[string]string{"version": "3.1", "id": "app"}, "",
+				xmlElementVal("display-name", nil, "Example & Co"),
+				xmlElementVal("servlet", nil, "",
+					xmlElementVal("servlet-name", nil, "main"),
+				),
+			),
+			cty.StringVal(`<?xml version="1.0" encoding="UTF-8"?>
+<web-app id="app" version="3.1">
+  <display-name>Example &amp; Co</display-name>
+  <servlet>
+    <servlet-name>main</servlet-name>
+  </servlet>
+</web-app>
+`),
+			``,
+		},
+		{
+			// Only the name is required, and attribute values are converted
+			// to strings.
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("server"),
+				"attributes": cty.ObjectVal(map[string]cty.Value{
+					"port":    cty.NumberIntVal(8080),
+					"enabled": cty.True,
+				}),
+			}),
+			cty.StringVal(`<?xml version="1.0" encoding="UTF-8"?>
+<server enabled="true" port="8080"></server>
+`),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.UnknownVal(cty.String),
+			}),
+			cty.UnknownVal(cty.String).Refine().NotNull().StringPrefixFull("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n").NewValue(),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"text": cty.StringVal("hello"),
+			}),
+			cty.NilVal,
+			`the root element must have a name`,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("a"),
+				"children": cty.TupleVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name":  cty.StringVal("b"),
+						"value": cty.StringVal("oops"),
+					}),
+				}),
+			}),
+			cty.NilVal,
+			`child 0 of element <a> has unsupported attribute "value"; elements may only have the attributes "name", "attributes", "children" and "text"`,
+		},
+		{
+			cty.StringVal("<a/>"),
+			cty.NilVal,
+			`an XML element must be an object, not string`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("xmlencode(%#v)", test.Val), func(t *testing.T) {
+			got, err := XMLEncode(test.Val)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
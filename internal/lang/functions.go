@@ -133,15 +133,21 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"chomp":            stdlib.ChompFunc,
 		"cidrcontains":     funcs.CidrContainsFunc,
 		"cidrhost":         funcs.CidrHostFunc,
+		"cidrmerge":        funcs.CidrMergeFunc,
 		"cidrnetmask":      funcs.CidrNetmaskFunc,
+		"cidroverlaps":     funcs.CidrOverlapsFunc,
+		"cidrsize":         funcs.CidrSizeFunc,
 		"cidrsubnet":       funcs.CidrSubnetFunc,
 		"cidrsubnets":      funcs.CidrSubnetsFunc,
+		"cidrsubtract":     funcs.CidrSubtractFunc,
 		"coalesce":         funcs.CoalesceFunc,
 		"coalescelist":     stdlib.CoalesceListFunc,
 		"compact":          stdlib.CompactFunc,
 		"concat":           stdlib.ConcatFunc,
 		"contains":         stdlib.ContainsFunc,
 		"csvdecode":        stdlib.CSVDecodeFunc,
+		"deepmerge":        funcs.DeepMergeFunc,
+		"deepmergewith":    funcs.DeepMergeWithFunc,
 		"dirname":          funcs.DirnameFunc,
 		"distinct":         stdlib.DistinctFunc,
 		"element":          stdlib.ElementFunc,
@@ -164,6 +170,7 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"formatlist":       stdlib.FormatListFunc,
 		"indent":           stdlib.IndentFunc,
 		"index":            funcs.IndexFunc, // stdlib.IndexFunc is not compatible
+		"ipinrange":        funcs.IPInRangeFunc,
 		"join":             stdlib.JoinFunc,
 		"jsondecode":       stdlib.JSONDecodeFunc,
 		"jsonencode":       stdlib.JSONEncodeFunc,
@@ -183,12 +190,17 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"parseint":         stdlib.ParseIntFunc,
 		"pathexpand":       funcs.PathExpandFunc,
 		"pow":              stdlib.PowFunc,
+		"query":            funcs.QueryFunc,
 		"range":            stdlib.RangeFunc,
 		"regex":            stdlib.RegexFunc,
 		"regexall":         stdlib.RegexAllFunc,
 		"replace":          funcs.ReplaceFunc,
 		"reverse":          stdlib.ReverseListFunc,
 		"rsadecrypt":       funcs.RsaDecryptFunc,
+		"semvercompare":    funcs.SemverCompareFunc,
+		"semverconstraint": funcs.SemverConstraintFunc,
+		"semverparse":      funcs.SemverParseFunc,
+		"semversort":       funcs.SemverSortFunc,
 		"sensitive":        funcs.SensitiveFunc,
 		"nonsensitive":     funcs.NonsensitiveFunc,
 		"issensitive":      funcs.IsSensitiveFunc,
@@ -220,6 +232,8 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"toset":            funcs.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
 		"tolist":           funcs.MakeToFunc(cty.List(cty.DynamicPseudoType)),
 		"tomap":            funcs.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tomldecode":       funcs.TOMLDecodeFunc,
+		"tomlencode":       funcs.TOMLEncodeFunc,
 		"transpose":        funcs.TransposeFunc,
 		"trim":             stdlib.TrimFunc,
 		"trimprefix":       stdlib.TrimPrefixFunc,
@@ -232,6 +246,8 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"uuid":             funcs.UUIDFunc,
 		"uuidv5":           funcs.UUIDV5Func,
 		"values":           stdlib.ValuesFunc,
+		"xmldecode":        funcs.XMLDecodeFunc,
+		"xmlencode":        funcs.XMLEncodeFunc,
 		"yamldecode":       ctyyaml.YAMLDecodeFunc,
 		"yamlencode":       ctyyaml.YAMLEncodeFunc,
 		"zipmap":           stdlib.ZipmapFunc,
@@ -206,6 +206,15 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"cidrmerge": {
+			{
+				`cidrmerge(["10.0.1.0/24", "10.0.0.0/24"])`,
+				cty.ListVal([]cty.Value{
+					cty.StringVal("10.0.0.0/23"),
+				}),
+			},
+		},
+
 		"cidrnetmask": {
 			{
 				`cidrnetmask("192.168.1.0/24")`,
@@ -213,6 +222,20 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"cidroverlaps": {
+			{
+				`cidroverlaps("10.0.0.0/16", "10.0.128.0/17")`,
+				cty.True,
+			},
+		},
+
+		"cidrsize": {
+			{
+				`cidrsize("192.168.1.0/24")`,
+				cty.NumberIntVal(256),
+			},
+		},
+
 		"cidrsubnet": {
 			{
 				`cidrsubnet("192.168.2.0/20", 4, 6)`,
@@ -232,6 +255,16 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"cidrsubtract": {
+			{
+				`cidrsubtract("10.0.0.0/22", ["10.0.1.0/24"])`,
+				cty.ListVal([]cty.Value{
+					cty.StringVal("10.0.0.0/24"),
+					cty.StringVal("10.0.2.0/23"),
+				}),
+			},
+		},
+
 		"coalesce": {
 			{
 				`coalesce("first", "second", "third")`,
@@ -313,6 +346,29 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"deepmerge": {
+			{
+				`deepmerge({a = {b = 1, c = 2}}, {a = {c = 3}})`,
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ObjectVal(map[string]cty.Value{
+						"b": cty.NumberIntVal(1),
+						"c": cty.NumberIntVal(3),
+					}),
+				}),
+			},
+		},
+
+		"deepmergewith": {
+			{
+				`deepmergewith({lists = "union"}, {a = ["x", "y"]}, {a = ["y", "z"]})`,
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.TupleVal([]cty.Value{
+						cty.StringVal("x"), cty.StringVal("y"), cty.StringVal("z"),
+					}),
+				}),
+			},
+		},
+
 		"dirname": {
 			{
 				`dirname("testdata/hello.txt")`,
@@ -541,6 +597,13 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"ipinrange": {
+			{
+				`ipinrange("10.0.0.50", "10.0.0.10", "10.0.0.100")`,
+				cty.True,
+			},
+		},
+
 		"join": {
 			{
 				`join(" ", ["Hello", "World"])`,
@@ -716,6 +779,13 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"query": {
+			{
+				`query({items = [{name = "a", enabled = true}, {name = "b", enabled = false}]}, "items[?enabled].name")`,
+				cty.TupleVal([]cty.Value{cty.StringVal("a")}),
+			},
+		},
+
 		"range": {
 			{
 				`range(3)`,
@@ -782,6 +852,38 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"semvercompare": {
+			{
+				`semvercompare("1.2.0", "1.10.0")`,
+				cty.NumberIntVal(-1),
+			},
+		},
+
+		"semverconstraint": {
+			{
+				`semverconstraint("1.4.2", "~> 1.2, < 2")`,
+				cty.True,
+			},
+		},
+
+		"semverparse": {
+			{
+				`semverparse("v1.2.3-rc.1").prerelease`,
+				cty.StringVal("rc.1"),
+			},
+		},
+
+		"semversort": {
+			{
+				`semversort(["1.10.0", "1.2.0", "1.2.0-rc.1"])`,
+				cty.ListVal([]cty.Value{
+					cty.StringVal("1.2.0-rc.1"),
+					cty.StringVal("1.2.0"),
+					cty.StringVal("1.10.0"),
+				}),
+			},
+		},
+
 		"sensitive": {
 			{
 				`sensitive(1)`,
@@ -1045,6 +1147,20 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"tomldecode": {
+			{
+				`tomldecode("[server]\nport = 8080\n").server.port`,
+				cty.NumberIntVal(8080),
+			},
+		},
+
+		"tomlencode": {
+			{
+				`tomlencode({b = 2, a = "x"})`,
+				cty.StringVal("a = \"x\"\nb = 2\n"),
+			},
+		},
+
 		"tonumber": {
 			{
 				`tonumber("42")`,
@@ -1184,6 +1300,20 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"xmldecode": {
+			{
+				`xmldecode("<server port=\"8080\"><name>web</name></server>").children[0].text`,
+				cty.StringVal("web"),
+			},
+		},
+
+		"xmlencode": {
+			{
+				`xmlencode({name = "server", attributes = {port = 8080}})`,
+				cty.StringVal("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<server port=\"8080\"></server>\n"),
+			},
+		},
+
 		"yamldecode": {
 			{
 				`yamldecode("true")`,
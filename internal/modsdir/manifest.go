@@ -47,6 +47,14 @@ type Record struct {
 
 	// Dir is the path to the local directory where the module is installed.
 	Dir string `json:"Dir"`
+
+	// PinnedPackage is the address of exactly the package that was installed,
+	// if that differs from the package in SourceAddr. This is the case for an
+	// "oci:" source address that refers to a tag, which is pinned to the
+	// digest that the tag referred to at installation time. The dependency
+	// lock file records the same selection, and the module is reinstalled
+	// if the two no longer match.
+	PinnedPackage string `json:"PinnedPackage,omitempty"`
 }
 
 // Manifest is a map used to keep track of the filesystem locations
@@ -0,0 +1,444 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduletest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// CoverageFormatVersion is the version of the JSON coverage report.
+const CoverageFormatVersion = "1.0"
+
+// CoverageKind describes the kind of object a CoverageItem represents.
+type CoverageKind string
+
+const (
+	CoverageResource CoverageKind = "resource"
+	CoverageData     CoverageKind = "data"
+	CoverageOutput   CoverageKind = "output"
+	CoverageVariable CoverageKind = "variable"
+	CoverageCheck    CoverageKind = "check"
+)
+
+// CoverageKinds lists the kinds of objects in the order they should be
+// presented to users.
+var CoverageKinds = []CoverageKind{
+	CoverageResource,
+	CoverageData,
+	CoverageOutput,
+	CoverageVariable,
+	CoverageCheck,
+}
+
+// CoverageStatus records the furthest a run block took an object. The
+// statuses are ordered, so a higher status also implies the lower ones.
+type CoverageStatus int
+
+const (
+	NotCovered CoverageStatus = iota
+	Evaluated
+	Planned
+	Applied
+)
+
+func (status CoverageStatus) String() string {
+	switch status {
+	case NotCovered:
+		return "not_covered"
+	case Evaluated:
+		return "evaluated"
+	case Planned:
+		return "planned"
+	case Applied:
+		return "applied"
+	default:
+		panic(fmt.Sprintf("unrecognized coverage status: %d", status))
+	}
+}
+
+// CoverageItem is a single object within the configuration under test that
+// run blocks can exercise.
+type CoverageItem struct {
+	Addr addrs.ConfigCheckable
+	Kind CoverageKind
+
+	// Range covers the whole block declaring the object.
+	Range hcl.Range
+
+	// HasChecks is true if the object declares any conditions, validations
+	// or assertions.
+	HasChecks bool
+
+	// Status is the furthest any run block took this object.
+	Status CoverageStatus
+
+	// Runs is the number of run blocks that exercised this object.
+	Runs int
+}
+
+// Coverage records which objects in a configuration were exercised by the
+// run blocks of a test suite.
+//
+// Resources are covered when they are planned or applied. Output values,
+// input variables and check blocks that declare conditions are covered when
+// their conditions are evaluated, and root module output values are also
+// covered when they are planned or applied. Output values in child modules
+// without conditions are not included, as there is no record of whether they
+// were evaluated.
+//
+// It is safe to record runs from multiple goroutines.
+type Coverage struct {
+	Items map[string]*CoverageItem
+
+	mu sync.Mutex
+}
+
+// NewCoverage returns a Coverage with an entry for every object in config
+// and its child modules, none of which are covered yet.
+//
+// The sources are the parsed configuration files, which are used to find the
+// full range of the block declaring each object.
+func NewCoverage(config *configs.Config, sources map[string]*hcl.File) *Coverage {
+	coverage := &Coverage{
+		Items: make(map[string]*CoverageItem),
+	}
+
+	blocks := make(map[string]map[int]hcl.Range)
+	blockRange := func(decl hcl.Range) hcl.Range {
+		ranges, ok := blocks[decl.Filename]
+		if !ok {
+			ranges = make(map[int]hcl.Range)
+			if file, ok := sources[decl.Filename]; ok {
+				if body, ok := file.Body.(*hclsyntax.Body); ok {
+					for _, block := range body.Blocks {
+						ranges[block.DefRange().Start.Byte] = block.Range()
+					}
+				}
+			}
+			blocks[decl.Filename] = ranges
+		}
+		if rng, ok := ranges[decl.Start.Byte]; ok {
+			return rng
+		}
+		return decl
+	}
+
+	checkable := checks.NewState(config)
+	add := func(addr addrs.ConfigCheckable, kind CoverageKind, decl hcl.Range) {
+		coverage.Items[addr.String()] = &CoverageItem{
+			Addr:      addr,
+			Kind:      kind,
+			Range:     blockRange(decl),
+			HasChecks: checkable.ConfigHasChecks(addr),
+		}
+	}
+
+	config.DeepEach(func(c *configs.Config) {
+		for _, rc := range c.Module.ManagedResources {
+			add(rc.Addr().InModule(c.Path), CoverageResource, rc.DeclRange)
+		}
+		for _, rc := range c.Module.DataResources {
+			add(rc.Addr().InModule(c.Path), CoverageData, rc.DeclRange)
+		}
+		for _, oc := range c.Module.Outputs {
+			if !c.Path.IsRoot() && len(oc.Preconditions) == 0 {
+				continue
+			}
+			add(oc.Addr().InModule(c.Path), CoverageOutput, oc.DeclRange)
+		}
+		for _, vc := range c.Module.Variables {
+			if len(vc.Validations) == 0 {
+				continue
+			}
+			add(vc.Addr().InModule(c.Path), CoverageVariable, vc.DeclRange)
+		}
+		for _, cc := range c.Module.Checks {
+			add(cc.Addr().InModule(c.Path), CoverageCheck, cc.DeclRange)
+		}
+	})
+
+	return coverage
+}
+
+// Record updates the coverage with the plan and, for run blocks that apply,
+// the updated state produced by a single run block. Either may be nil.
+//
+// Only objects the run block actually exercised are recorded: resources and
+// output values are planned when the plan changes them, and applied when the
+// updated state differs from the prior state of the plan. Objects that the
+// plan leaves unchanged, such as resources created by an earlier run block,
+// are not counted for this run block.
+func (c *Coverage) Record(plan *plans.Plan, state *states.State) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	covered := make(map[*CoverageItem]CoverageStatus)
+	mark := func(addr addrs.ConfigCheckable, status CoverageStatus) {
+		item, ok := c.Items[addr.String()]
+		if !ok {
+			return
+		}
+		if status > covered[item] {
+			covered[item] = status
+		}
+	}
+	markChecks := func(results *states.CheckResults) {
+		if results == nil {
+			return
+		}
+		for _, elem := range results.ConfigResults.Elems {
+			if elem.Value.Status != checks.StatusUnknown {
+				mark(elem.Key, Evaluated)
+			}
+		}
+	}
+
+	if plan != nil {
+		prior := plan.PriorState
+		if prior == nil {
+			prior = states.NewState()
+		}
+
+		if plan.Changes != nil {
+			for _, change := range plan.Changes.Resources {
+				if change.Action == plans.NoOp {
+					continue
+				}
+				status := Planned
+				if state != nil && resourceInstanceChanged(prior, state, change.Addr) {
+					status = Applied
+				}
+				mark(change.Addr.ContainingResource().Config(), status)
+			}
+			for _, change := range plan.Changes.Outputs {
+				if change.Action == plans.NoOp {
+					continue
+				}
+				status := Planned
+				if state != nil && change.Addr.Module.IsRoot() && outputValueChanged(prior, state, change.Addr) {
+					status = Applied
+				}
+				mark(change.Addr.ConfigOutputValue(), status)
+			}
+		}
+		if plan.PlannedState != nil {
+			// Data sources read during planning don't have a change, but are
+			// in the planned state.
+			for _, module := range plan.PlannedState.Modules {
+				for _, resource := range module.Resources {
+					if resource.Addr.Resource.Mode == addrs.DataResourceMode {
+						mark(resource.Addr.Config(), Planned)
+					}
+				}
+			}
+		}
+		markChecks(plan.Checks)
+	}
+
+	if state != nil {
+		markChecks(state.CheckResults)
+	}
+
+	for item, status := range covered {
+		item.Runs++
+		if status > item.Status {
+			item.Status = status
+		}
+	}
+}
+
+// resourceInstanceChanged returns true if the current object of the given
+// resource instance differs between the prior and the updated state.
+func resourceInstanceChanged(prior, updated *states.State, addr addrs.AbsResourceInstance) bool {
+	current := func(state *states.State) *states.ResourceInstanceObjectSrc {
+		if instance := state.ResourceInstance(addr); instance != nil {
+			return instance.Current
+		}
+		return nil
+	}
+	before, after := current(prior), current(updated)
+	if before == nil || after == nil {
+		return before != after
+	}
+	return before.Status != after.Status || !bytes.Equal(before.AttrsJSON, after.AttrsJSON)
+}
+
+// outputValueChanged returns true if the given output value differs between
+// the prior and the updated state.
+func outputValueChanged(prior, updated *states.State, addr addrs.AbsOutputValue) bool {
+	before, after := prior.OutputValue(addr), updated.OutputValue(addr)
+	if before == nil || after == nil {
+		return before != after
+	}
+	return before.Sensitive != after.Sensitive || !before.Value.RawEquals(after.Value)
+}
+
+// Sorted returns all the items, ordered by file name and then line.
+func (c *Coverage) Sorted() []*CoverageItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make([]*CoverageItem, 0, len(c.Items))
+	for _, item := range c.Items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Range.Filename != items[j].Range.Filename {
+			return items[i].Range.Filename < items[j].Range.Filename
+		}
+		if items[i].Range.Start.Line != items[j].Range.Start.Line {
+			return items[i].Range.Start.Line < items[j].Range.Start.Line
+		}
+		return items[i].Addr.String() < items[j].Addr.String()
+	})
+	return items
+}
+
+// CoverageSummary counts the covered objects of a single kind, or of all
+// kinds.
+type CoverageSummary struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Summary returns the totals for each kind of object, and for all objects
+// under the empty kind.
+func (c *Coverage) Summary() map[CoverageKind]CoverageSummary {
+	summary := make(map[CoverageKind]CoverageSummary)
+	for _, item := range c.Sorted() {
+		for _, kind := range []CoverageKind{item.Kind, ""} {
+			s := summary[kind]
+			s.Total++
+			if item.Status > NotCovered {
+				s.Covered++
+			}
+			summary[kind] = s
+		}
+	}
+	return summary
+}
+
+type jsonCoverage struct {
+	FormatVersion string                           `json:"format_version"`
+	Summary       CoverageSummary                  `json:"summary"`
+	Kinds         map[CoverageKind]CoverageSummary `json:"kinds"`
+	Files         []jsonCoverageFile               `json:"files"`
+}
+
+type jsonCoverageFile struct {
+	Filename string               `json:"filename"`
+	Objects  []jsonCoverageObject `json:"objects"`
+}
+
+type jsonCoverageObject struct {
+	Address   string       `json:"address"`
+	Kind      CoverageKind `json:"kind"`
+	StartLine int          `json:"start_line"`
+	EndLine   int          `json:"end_line"`
+	HasChecks bool         `json:"has_checks"`
+	Status    string       `json:"status"`
+	Runs      int          `json:"runs"`
+}
+
+// JSON renders the coverage as a JSON report, grouped by file.
+func (c *Coverage) JSON() ([]byte, error) {
+	summary := c.Summary()
+	report := jsonCoverage{
+		FormatVersion: CoverageFormatVersion,
+		Summary:       summary[""],
+		Files:         []jsonCoverageFile{},
+		Kinds:         make(map[CoverageKind]CoverageSummary),
+	}
+	for _, kind := range CoverageKinds {
+		report.Kinds[kind] = summary[kind]
+	}
+
+	for _, item := range c.Sorted() {
+		if len(report.Files) == 0 || report.Files[len(report.Files)-1].Filename != item.Range.Filename {
+			report.Files = append(report.Files, jsonCoverageFile{Filename: item.Range.Filename})
+		}
+		file := &report.Files[len(report.Files)-1]
+		file.Objects = append(file.Objects, jsonCoverageObject{
+			Address:   item.Addr.String(),
+			Kind:      item.Kind,
+			StartLine: item.Range.Start.Line,
+			EndLine:   item.Range.End.Line,
+			HasChecks: item.HasChecks,
+			Status:    item.Status.String(),
+			Runs:      item.Runs,
+		})
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// LCOV renders the coverage in the LCOV tracefile format. Each object is
+// reported as a function, and every line of the block declaring it as hit
+// by the number of run blocks that exercised the object.
+func (c *Coverage) LCOV() []byte {
+	var buf bytes.Buffer
+	items := c.Sorted()
+
+	for start := 0; start < len(items); {
+		filename := items[start].Range.Filename
+		end := start
+		for end < len(items) && items[end].Range.Filename == filename {
+			end++
+		}
+		file := items[start:end]
+		start = end
+
+		fmt.Fprintf(&buf, "TN:\nSF:%s\n", filename)
+
+		hit := 0
+		for _, item := range file {
+			fmt.Fprintf(&buf, "FN:%d,%s\n", item.Range.Start.Line, item.Addr)
+		}
+		for _, item := range file {
+			fmt.Fprintf(&buf, "FNDA:%d,%s\n", item.Runs, item.Addr)
+			if item.Runs > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(&buf, "FNF:%d\nFNH:%d\n", len(file), hit)
+
+		// Blocks can't overlap within a file, except for check blocks
+		// containing data sources which we only include once.
+		lines := make(map[int]int)
+		var order []int
+		for _, item := range file {
+			for line := item.Range.Start.Line; line <= item.Range.End.Line; line++ {
+				if _, ok := lines[line]; !ok {
+					order = append(order, line)
+				}
+				lines[line] = max(lines[line], item.Runs)
+			}
+		}
+		sort.Ints(order)
+
+		linesHit := 0
+		for _, line := range order {
+			fmt.Fprintf(&buf, "DA:%d,%d\n", line, lines[line])
+			if lines[line] > 0 {
+				linesHit++
+			}
+		}
+		fmt.Fprintf(&buf, "LF:%d\nLH:%d\nend_of_record\n", len(order), linesHit)
+	}
+
+	return buf.Bytes()
+}
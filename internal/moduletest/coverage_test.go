@@ -0,0 +1,260 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduletest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestCoverage_Record(t *testing.T) {
+	resource := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "foo"}
+	unused := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "unused"}
+	variable := addrs.InputVariable{Name: "input"}.InModule(addrs.RootModule)
+	check := addrs.Check{Name: "healthy"}.InModule(addrs.RootModule)
+
+	item := func(addr addrs.ConfigCheckable, kind CoverageKind, line int) *CoverageItem {
+		return &CoverageItem{
+			Addr: addr,
+			Kind: kind,
+			Range: hcl.Range{
+				Filename: "main.tf",
+				Start:    hcl.Pos{Line: line},
+				End:      hcl.Pos{Line: line + 1},
+			},
+		}
+	}
+	coverage := &Coverage{
+		Items: map[string]*CoverageItem{
+			"test_resource.foo":    item(resource.InModule(addrs.RootModule), CoverageResource, 5),
+			"test_resource.unused": item(unused.InModule(addrs.RootModule), CoverageResource, 8),
+			"var.input":            item(variable, CoverageVariable, 1),
+			"check.healthy":        item(check, CoverageCheck, 11),
+		},
+	}
+
+	checkResults := func(status checks.Status) *states.CheckResults {
+		results := &states.CheckResults{
+			ConfigResults: addrs.MakeMap[addrs.ConfigCheckable, *states.CheckResultAggregate](),
+		}
+		results.ConfigResults.Put(variable, &states.CheckResultAggregate{Status: checks.StatusPass})
+		results.ConfigResults.Put(check, &states.CheckResultAggregate{Status: status})
+		return results
+	}
+
+	// A plan that creates the resource, and evaluates the variable validation
+	// but not the check block.
+	changes := plans.NewChanges()
+	changes.SyncWrapper().AppendResourceInstanceChange(&plans.ResourceInstanceChangeSrc{
+		Addr: resource.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+		ChangeSrc: plans.ChangeSrc{
+			Action: plans.Create,
+		},
+	})
+	coverage.Record(&plans.Plan{
+		Changes: changes,
+		Checks:  checkResults(checks.StatusUnknown),
+	}, nil)
+
+	// Then apply the resource, which evaluates the check block.
+	state := states.BuildState(func(state *states.SyncState) {
+		state.SetResourceInstanceCurrent(
+			resource.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{Status: states.ObjectReady},
+			addrs.AbsProviderConfig{
+				Module:   addrs.RootModule,
+				Provider: addrs.NewDefaultProvider("test"),
+			}, addrs.NoKey)
+	})
+	state.CheckResults = checkResults(checks.StatusFail)
+	coverage.Record(&plans.Plan{Changes: changes}, state)
+
+	type result struct {
+		Status CoverageStatus
+		Runs   int
+	}
+	got := make(map[string]result)
+	for key, item := range coverage.Items {
+		got[key] = result{item.Status, item.Runs}
+	}
+	want := map[string]result{
+		"test_resource.foo":    {Applied, 2},
+		"test_resource.unused": {NotCovered, 0},
+		"var.input":            {Evaluated, 2},
+		"check.healthy":        {Evaluated, 1},
+	}
+	if diff := cmp.Diff(want, got); len(diff) > 0 {
+		t.Errorf("unexpected coverage:\n%s", diff)
+	}
+
+	summary := coverage.Summary()
+	if got, want := summary[""], (CoverageSummary{Covered: 3, Total: 4}); got != want {
+		t.Errorf("expected summary %v but got %v", want, got)
+	}
+	if got, want := summary[CoverageResource], (CoverageSummary{Covered: 1, Total: 2}); got != want {
+		t.Errorf("expected resource summary %v but got %v", want, got)
+	}
+
+	src, err := coverage.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "format_version": "1.0",
+  "summary": {
+    "covered": 3,
+    "total": 4
+  },
+  "kinds": {
+    "check": {
+      "covered": 1,
+      "total": 1
+    },
+    "data": {
+      "covered": 0,
+      "total": 0
+    },
+    "output": {
+      "covered": 0,
+      "total": 0
+    },
+    "resource": {
+      "covered": 1,
+      "total": 2
+    },
+    "variable": {
+      "covered": 1,
+      "total": 1
+    }
+  },
+  "files": [
+    {
+      "filename": "main.tf",
+      "objects": [
+        {
+          "address": "var.input",
+          "kind": "variable",
+          "start_line": 1,
+          "end_line": 2,
+          "has_checks": false,
+          "status": "evaluated",
+          "runs": 2
+        },
+        {
+          "address": "test_resource.foo",
+          "kind": "resource",
+          "start_line": 5,
+          "end_line": 6,
+          "has_checks": false,
+          "status": "applied",
+          "runs": 2
+        },
+        {
+          "address": "test_resource.unused",
+          "kind": "resource",
+          "start_line": 8,
+          "end_line": 9,
+          "has_checks": false,
+          "status": "not_covered",
+          "runs": 0
+        },
+        {
+          "address": "check.healthy",
+          "kind": "check",
+          "start_line": 11,
+          "end_line": 12,
+          "has_checks": false,
+          "status": "evaluated",
+          "runs": 1
+        }
+      ]
+    }
+  ]
+}`
+	if diff := cmp.Diff(expected, string(src)); len(diff) > 0 {
+		t.Errorf("unexpected JSON report:\n%s", diff)
+	}
+}
+
+func TestCoverage_RecordUnchanged(t *testing.T) {
+	foo := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "foo"}
+	bar := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_resource", Name: "bar"}
+	output := addrs.OutputValue{Name: "value"}.InModule(addrs.RootModule)
+
+	coverage := &Coverage{
+		Items: map[string]*CoverageItem{
+			"test_resource.foo": {Addr: foo.InModule(addrs.RootModule), Kind: CoverageResource},
+			"test_resource.bar": {Addr: bar.InModule(addrs.RootModule), Kind: CoverageResource},
+			"output.value":      {Addr: output, Kind: CoverageOutput},
+		},
+	}
+
+	provider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+	setResource := func(state *states.SyncState, addr addrs.Resource, value string) {
+		state.SetResourceInstanceCurrent(
+			addr.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(fmt.Sprintf(`{"value":%q}`, value)),
+			},
+			provider, addrs.NoKey)
+	}
+	planFor := func(prior *states.State, actions map[addrs.Resource]plans.Action, output plans.Action) *plans.Plan {
+		changes := plans.NewChanges()
+		for addr, action := range actions {
+			changes.SyncWrapper().AppendResourceInstanceChange(&plans.ResourceInstanceChangeSrc{
+				Addr:      addr.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				ChangeSrc: plans.ChangeSrc{Action: action},
+			})
+		}
+		changes.Outputs = append(changes.Outputs, &plans.OutputChangeSrc{
+			Addr:      addrs.OutputValue{Name: "value"}.Absolute(addrs.RootModuleInstance),
+			ChangeSrc: plans.ChangeSrc{Action: output},
+		})
+		return &plans.Plan{Changes: changes, PriorState: prior}
+	}
+
+	// The first run block applies foo and the output value.
+	first := states.BuildState(func(state *states.SyncState) {
+		setResource(state, foo, "foo")
+		state.SetOutputValue(addrs.OutputValue{Name: "value"}.Absolute(addrs.RootModuleInstance), cty.StringVal("foo"), false)
+	})
+	coverage.Record(planFor(states.NewState(), map[addrs.Resource]plans.Action{foo: plans.Create}, plans.Create), first)
+
+	// The second run block starts from a state that also contains bar. It
+	// leaves foo and the output value unchanged, and plans an update to bar
+	// that fails to apply.
+	second := first.DeepCopy()
+	setResource(second.SyncWrapper(), bar, "bar")
+	coverage.Record(planFor(second, map[addrs.Resource]plans.Action{foo: plans.NoOp, bar: plans.Update}, plans.NoOp), second.DeepCopy())
+
+	type result struct {
+		Status CoverageStatus
+		Runs   int
+	}
+	got := make(map[string]result)
+	for key, item := range coverage.Items {
+		got[key] = result{item.Status, item.Runs}
+	}
+	want := map[string]result{
+		"test_resource.foo": {Applied, 1},
+		"test_resource.bar": {Planned, 1},
+		"output.value":      {Applied, 1},
+	}
+	if diff := cmp.Diff(want, got); len(diff) > 0 {
+		t.Errorf("unexpected coverage:\n%s", diff)
+	}
+}
@@ -6,6 +6,8 @@
 package moduletest
 
 import (
+	"time"
+
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -18,5 +20,9 @@ type File struct {
 
 	Runs []*Run
 
+	// Duration is the time taken to execute the whole file, including the
+	// cleanup of any infrastructure created by its run blocks.
+	Duration time.Duration
+
 	Diagnostics tfdiags.Diagnostics
 }
@@ -7,12 +7,16 @@ package moduletest
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/instances"
+	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
@@ -28,6 +32,16 @@ type Run struct {
 	Index  int
 	Status Status
 
+	// Key identifies this run among the runs expanded from a run block that
+	// sets for_each, and is addrs.NoKey otherwise. Repetition holds the
+	// each.key and each.value for the run.
+	Key        addrs.InstanceKey
+	Repetition instances.RepetitionData
+
+	// Duration is the time taken to execute the run block. It is zero for
+	// run blocks that were skipped.
+	Duration time.Duration
+
 	Diagnostics tfdiags.Diagnostics
 }
 
@@ -44,6 +58,121 @@ type Verbose struct {
 	Provisioners map[string]*configschema.Block
 }
 
+// ExpandRuns returns the runs for the run blocks within the given test file,
+// in order. Run blocks that set for_each expand into one run for each element
+// of the collection, named after the run block and the element's key.
+func ExpandRuns(file *configs.TestFile) ([]*Run, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var runs []*Run
+
+	for _, config := range file.Runs {
+		if config.ForEach == nil {
+			runs = append(runs, &Run{
+				Config: config,
+				Index:  len(runs),
+				Name:   config.Name,
+				Key:    addrs.NoKey,
+			})
+			continue
+		}
+
+		repetitions, repDiags := evaluateRunForEach(config.ForEach)
+		diags = diags.Append(repDiags)
+		if repDiags.HasErrors() {
+			continue
+		}
+
+		for _, repetition := range repetitions {
+			runs = append(runs, &Run{
+				Config:     config,
+				Index:      len(runs),
+				Name:       config.Name + repetition.key.String(),
+				Key:        repetition.key,
+				Repetition: repetition.data,
+			})
+		}
+	}
+
+	return runs, diags
+}
+
+type runRepetition struct {
+	key  addrs.InstanceKey
+	data instances.RepetitionData
+}
+
+// evaluateRunForEach evaluates the for_each expression of a run block. Lists
+// and tuples are keyed by index, maps and objects by key, and sets of strings
+// by the strings themselves, in the same way as resources.
+func evaluateRunForEach(expr hcl.Expression) ([]runRepetition, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	scope := &lang.Scope{BaseDir: ".", PureOnly: true}
+	val, hclDiags := expr.Value(&hcl.EvalContext{Functions: scope.Functions()})
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	invalid := func(detail string) tfdiags.Diagnostics {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid for_each argument",
+			Detail:   detail,
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+
+	switch {
+	case val.IsNull():
+		return nil, invalid("The for_each argument of a run block must not be null.")
+	case !val.IsWhollyKnown():
+		return nil, invalid("The for_each argument of a run block must be known when the test file is loaded, so it can only refer to literal values and functions.")
+	case val.ContainsMarked():
+		return nil, invalid("The for_each argument of a run block must not be sensitive, because its keys are used to name the runs.")
+	}
+
+	var repetitions []runRepetition
+	ty := val.Type()
+	switch {
+	case ty.IsListType() || ty.IsTupleType():
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			index, _ := k.AsBigFloat().Int64()
+			repetitions = append(repetitions, runRepetition{
+				key:  addrs.IntKey(index),
+				data: instances.RepetitionData{EachKey: k, EachValue: v},
+			})
+		}
+	case ty.IsMapType() || ty.IsObjectType():
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			repetitions = append(repetitions, runRepetition{
+				key:  addrs.StringKey(k.AsString()),
+				data: instances.RepetitionData{EachKey: k, EachValue: v},
+			})
+		}
+	case ty.IsSetType():
+		if !ty.ElementType().Equals(cty.String) {
+			return nil, invalid(fmt.Sprintf("The for_each argument of a run block can only be a set of strings, but this is a set of %s.", ty.ElementType().FriendlyName()))
+		}
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if v.IsNull() {
+				return nil, invalid("The for_each argument of a run block must not contain null values.")
+			}
+			repetitions = append(repetitions, runRepetition{
+				key:  addrs.StringKey(v.AsString()),
+				data: instances.RepetitionData{EachKey: v, EachValue: v},
+			})
+		}
+	default:
+		return nil, invalid(fmt.Sprintf("The for_each argument of a run block must be a list, map or set of strings, but this is %s.", ty.FriendlyName()))
+	}
+
+	return repetitions, diags
+}
+
 func (run *Run) GetTargets() ([]addrs.Targetable, tfdiags.Diagnostics) {
 	var diagnostics tfdiags.Diagnostics
 	var targets []addrs.Targetable
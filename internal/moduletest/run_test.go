@@ -797,6 +797,88 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 	}
 }
 
+func TestExpandRuns(t *testing.T) {
+	tcs := map[string]struct {
+		forEach string
+		want    []string
+		err     string
+	}{
+		"no for_each": {
+			want: []string{"test"},
+		},
+		"list": {
+			forEach: `["a", "b"]`,
+			want:    []string{"test[0]", "test[1]"},
+		},
+		"map": {
+			forEach: `{ b = 1, a = 2 }`,
+			want:    []string{`test["a"]`, `test["b"]`},
+		},
+		"set": {
+			forEach: `toset(["y", "x", "y"])`,
+			want:    []string{`test["x"]`, `test["y"]`},
+		},
+		"empty": {
+			forEach: `[]`,
+		},
+		"string": {
+			forEach: `"a"`,
+			err:     "The for_each argument of a run block must be a list, map or set of strings, but this is string.",
+		},
+		"null": {
+			forEach: `null`,
+			err:     "The for_each argument of a run block must not be null.",
+		},
+		"set of numbers": {
+			forEach: `toset([1, 2])`,
+			err:     "The for_each argument of a run block can only be a set of strings, but this is a set of number.",
+		},
+		"variable": {
+			forEach: `var.cases`,
+			err:     "Variables not allowed",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			config := &configs.TestRun{Name: "test"}
+			if tc.forEach != "" {
+				expr, diags := hclsyntax.ParseExpression([]byte(tc.forEach), "main.tftest.hcl", hcl.Pos{Line: 1, Column: 1})
+				if diags.HasErrors() {
+					t.Fatal(diags.Error())
+				}
+				config.ForEach = expr
+			}
+
+			runs, diags := ExpandRuns(&configs.TestFile{Runs: []*configs.TestRun{config}})
+			if tc.err != "" {
+				if !diags.HasErrors() {
+					t.Fatalf("expected an error but got none")
+				}
+				desc := diags[0].Description()
+				if desc.Summary != tc.err && desc.Detail != tc.err {
+					t.Fatalf("unexpected error: %s: %s", desc.Summary, desc.Detail)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+
+			var got []string
+			for ix, run := range runs {
+				if run.Index != ix {
+					t.Errorf("run %s has index %d, want %d", run.Name, run.Index, ix)
+				}
+				got = append(got, run.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); len(diff) > 0 {
+				t.Errorf("unexpected runs:\n%s", diff)
+			}
+		})
+	}
+}
+
 func createDiagnostics(populate func(diags tfdiags.Diagnostics) tfdiags.Diagnostics) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 	diags = populate(diags)
@@ -0,0 +1,66 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package ociregistry contains the parts of the client for OCI Distribution
+// registries that are shared by provider installation and module
+// installation.
+package ociregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orascontent "oras.land/oras-go/v2/content"
+	orasregistry "oras.land/oras-go/v2/registry"
+	orasremote "oras.land/oras-go/v2/registry/remote"
+)
+
+// ManifestSizeLimit is the largest manifest that we'll fetch from an OCI
+// registry. Manifests of providers and modules are small, so anything larger
+// is probably not an OpenTofu artifact at all.
+const ManifestSizeLimit = 4 * 1024 * 1024
+
+// RepositoryStore is the subset of the operations of an OCI Distribution
+// repository that OpenTofu needs to install providers and modules. The
+// repository implementation in the ORAS library's "remote" package
+// implements it.
+type RepositoryStore interface {
+	orasregistry.TagLister
+	orascontent.Resolver
+	orascontent.Fetcher
+}
+
+// RepositoryPushStore is a RepositoryStore that can also publish content,
+// which "tofu providers mirror" needs to publish providers to an OCI
+// repository. The repository implementation in the ORAS library's "remote"
+// package implements it.
+type RepositoryPushStore interface {
+	RepositoryStore
+	orascontent.Storage
+	orascontent.Tagger
+}
+
+var _ RepositoryPushStore = (*orasremote.Repository)(nil)
+
+// RepositoryStoreFunc returns a RepositoryStore for the repository with the
+// given name in the registry with the given domain, which is responsible for
+// any authentication that the registry requires.
+type RepositoryStoreFunc func(ctx context.Context, registryDomain, repositoryName string) (RepositoryStore, error)
+
+// FetchManifest fetches the manifest with the given descriptor, verifies it
+// against the descriptor and decodes it into target.
+func FetchManifest(ctx context.Context, store orascontent.Fetcher, desc ocispec.Descriptor, target any) error {
+	if desc.Size > ManifestSizeLimit {
+		return fmt.Errorf("manifest is too large (%d bytes)", desc.Size)
+	}
+	raw, err := orascontent.FetchAll(ctx, store, desc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package ocitest contains an in-process OCI Distribution registry for
+// testing the installation of providers and modules from OCI registries.
+package ocitest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orascontent "oras.land/oras-go/v2/content"
+)
+
+// Registry is a minimal in-process implementation of the parts of the OCI
+// Distribution API that OpenTofu uses, with all repositories sharing the
+// same content. It's an http.Handler, intended to be used with
+// httptest.NewServer.
+//
+// Content can be added either directly with Push and Tag, or by clients
+// through the API, which creates any repository that doesn't exist yet.
+type Registry struct {
+	// Username and Password, if set, are the basic authentication
+	// credentials that every request must have.
+	Username, Password string
+
+	mu    sync.Mutex
+	blobs map[string][]byte
+	descs map[string]ocispec.Descriptor
+	tags  map[string]map[string]ocispec.Descriptor
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		blobs: make(map[string][]byte),
+		descs: make(map[string]ocispec.Descriptor),
+		tags:  make(map[string]map[string]ocispec.Descriptor),
+	}
+}
+
+// Push adds a blob or manifest with the given media type and content, and
+// returns its descriptor.
+func (r *Registry) Push(mediaType string, content []byte) ocispec.Descriptor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	desc := orascontent.NewDescriptorFromBytes(mediaType, content)
+	r.blobs[desc.Digest.String()] = content
+	r.descs[desc.Digest.String()] = desc
+	return desc
+}
+
+// PushJSON adds the JSON encoding of the given manifest with the given media
+// type, and returns its descriptor.
+func (r *Registry) PushJSON(mediaType string, manifest any) ocispec.Descriptor {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		panic(err)
+	}
+	return r.Push(mediaType, content)
+}
+
+// Tag makes the given tag of the given repository refer to the given
+// manifest, creating the repository if it doesn't exist yet.
+func (r *Registry) Tag(repository, tag string, desc ocispec.Descriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tags[repository] == nil {
+		r.tags[repository] = make(map[string]ocispec.Descriptor)
+	}
+	r.tags[repository][tag] = desc
+}
+
+func (r *Registry) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if r.Username != "" || r.Password != "" {
+		if user, password, ok := req.BasicAuth(); !ok || user != r.Username || password != r.Password {
+			resp.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			resp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	var repository, kind, ref string
+	for _, k := range []string{"/tags/list", "/blobs/uploads/", "/manifests/", "/blobs/"} {
+		if i := strings.LastIndex(path, k); i >= 0 {
+			repository, kind, ref = path[:i], k, path[i+len(k):]
+			break
+		}
+	}
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		r.receive(resp, req, repository, kind, ref)
+		return
+	}
+	tags, ok := r.tags[repository]
+	if !ok {
+		resp.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var desc ocispec.Descriptor
+	switch kind {
+	case "/tags/list":
+		list := struct {
+			Name string   `json:"name"`
+			Tags []string `json:"tags"`
+		}{Name: repository}
+		for tag := range tags {
+			list.Tags = append(list.Tags, tag)
+		}
+		resp.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(resp).Encode(list)
+		return
+	case "/manifests/":
+		if desc, ok = tags[ref]; !ok {
+			desc, ok = r.descs[ref]
+		}
+	case "/blobs/":
+		desc, ok = r.descs[ref]
+	}
+	if !ok {
+		resp.WriteHeader(http.StatusNotFound)
+		return
+	}
+	resp.Header().Set("Content-Type", desc.MediaType)
+	resp.Header().Set("Docker-Content-Digest", desc.Digest.String())
+	resp.Header().Set("Content-Length", strconv.FormatInt(desc.Size, 10))
+	if req.Method == http.MethodHead {
+		return
+	}
+	_, _ = resp.Write(r.blobs[desc.Digest.String()])
+}
+
+// receive handles the requests that push blobs and manifests. Blobs must be
+// uploaded in a single request, as the ORAS library does.
+func (r *Registry) receive(resp http.ResponseWriter, req *http.Request, repository, kind, ref string) {
+	switch {
+	case kind == "/blobs/uploads/" && req.Method == http.MethodPost:
+		resp.Header().Set("Location", "/v2/"+repository+"/blobs/uploads/upload")
+		resp.WriteHeader(http.StatusAccepted)
+	case kind == "/blobs/uploads/" && req.Method == http.MethodPut:
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		desc := orascontent.NewDescriptorFromBytes("application/octet-stream", content)
+		if desc.Digest.String() != req.URL.Query().Get("digest") {
+			resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.store(repository, desc, content)
+		resp.Header().Set("Docker-Content-Digest", desc.Digest.String())
+		resp.WriteHeader(http.StatusCreated)
+	case kind == "/manifests/" && req.Method == http.MethodPut:
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		desc := orascontent.NewDescriptorFromBytes(req.Header.Get("Content-Type"), content)
+		r.store(repository, desc, content)
+		if desc.Digest.String() != ref {
+			r.tags[repository][ref] = desc
+		}
+		resp.Header().Set("Docker-Content-Digest", desc.Digest.String())
+		resp.WriteHeader(http.StatusCreated)
+	default:
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// store adds content received through the API, creating the repository if
+// it doesn't exist yet. Blobs are stored with a generic media type, which
+// the client replaces with the one from the manifest that refers to them.
+func (r *Registry) store(repository string, desc ocispec.Descriptor, content []byte) {
+	if r.tags[repository] == nil {
+		r.tags[repository] = make(map[string]ocispec.Descriptor)
+	}
+	if _, ok := r.descs[desc.Digest.String()]; ok {
+		return
+	}
+	r.blobs[desc.Digest.String()] = content
+	r.descs[desc.Digest.String()] = desc
+}
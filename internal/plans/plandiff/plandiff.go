@@ -0,0 +1,383 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package plandiff compares the changes proposed by two plans, such as a plan
+// that was approved in review and a plan that was created again later, to
+// find what changed between them.
+package plandiff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// Kind describes how an item differs between two plans.
+type Kind string
+
+const (
+	// Added means that only the new plan has a change for the item.
+	Added Kind = "added"
+
+	// Removed means that only the old plan has a change for the item.
+	Removed Kind = "removed"
+
+	// Changed means that both plans have a change for the item, but the
+	// changes are different.
+	Changed Kind = "changed"
+)
+
+// Diff is the difference between the changes proposed by two plans.
+type Diff struct {
+	Resources []*ResourceDiff
+	Outputs   []*OutputDiff
+}
+
+// Empty returns true if the two plans propose the same changes.
+func (d *Diff) Empty() bool {
+	return len(d.Resources) == 0 && len(d.Outputs) == 0
+}
+
+// ResourceDiff is the difference between the changes that two plans propose
+// for a single resource instance object.
+type ResourceDiff struct {
+	Addr       addrs.AbsResourceInstance
+	DeposedKey states.DeposedKey
+	Kind       Kind
+
+	// OldAction and NewAction are the actions that each plan proposes. The
+	// action of a plan that has no change for the object is plans.NoOp.
+	OldAction plans.Action
+	NewAction plans.Action
+
+	// Attributes are the differences between the planned new values of the
+	// object, if both plans propose an object.
+	Attributes []*AttributeDiff
+}
+
+// AttributeDiff is a difference between the planned values of an attribute,
+// or of a nested value within one.
+type AttributeDiff struct {
+	Path cty.Path
+
+	// Old and New are the planned values, which may be unknown or marked as
+	// sensitive. A value is cty.NilVal if it doesn't exist in that plan.
+	Old cty.Value
+	New cty.Value
+}
+
+// OutputDiff is the difference between the changes that two plans propose for
+// a root module output value.
+type OutputDiff struct {
+	Addr addrs.AbsOutputValue
+	Kind Kind
+
+	OldAction plans.Action
+	NewAction plans.Action
+
+	// Old and New are the planned values, which are cty.NilVal if that plan
+	// has no change for the output value.
+	Old cty.Value
+	New cty.Value
+}
+
+// Compare returns the differences between the changes of an old and a new
+// plan. The schemas of each plan are used to decode its resource changes.
+func Compare(oldChanges *plans.Changes, oldSchemas *tofu.Schemas, newChanges *plans.Changes, newSchemas *tofu.Schemas) (*Diff, error) {
+	oldResources, err := decodeResources(oldChanges, oldSchemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode old plan: %w", err)
+	}
+	newResources, err := decodeResources(newChanges, newSchemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode new plan: %w", err)
+	}
+	oldOutputs, err := decodeOutputs(oldChanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode old plan: %w", err)
+	}
+	newOutputs, err := decodeOutputs(newChanges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode new plan: %w", err)
+	}
+
+	diff := &Diff{}
+	for _, key := range sortedKeys(oldResources, newResources) {
+		oldChange, newChange := oldResources[key], newResources[key]
+		if rd := compareResources(oldChange, newChange); rd != nil {
+			diff.Resources = append(diff.Resources, rd)
+		}
+	}
+	for _, key := range sortedKeys(oldOutputs, newOutputs) {
+		oldChange, newChange := oldOutputs[key], newOutputs[key]
+		if od := compareOutputs(oldChange, newChange); od != nil {
+			diff.Outputs = append(diff.Outputs, od)
+		}
+	}
+	return diff, nil
+}
+
+func decodeResources(changes *plans.Changes, schemas *tofu.Schemas) (map[string]*plans.ResourceInstanceChange, error) {
+	ret := make(map[string]*plans.ResourceInstanceChange)
+	if changes == nil {
+		return ret, nil
+	}
+	for _, rcs := range changes.Resources {
+		if rcs.Addr.Resource.Resource.Mode == addrs.DataResourceMode && rcs.Action == plans.Delete {
+			// Data sources are removed from the state without any effect, so
+			// as in the plan output we ignore them.
+			continue
+		}
+		schema, _ := schemas.ResourceTypeConfig(
+			rcs.ProviderAddr.Provider,
+			rcs.Addr.Resource.Resource.Mode,
+			rcs.Addr.Resource.Resource.Type,
+		)
+		if schema == nil {
+			return nil, fmt.Errorf("no schema found for %s (in provider %s)", rcs.Addr, rcs.ProviderAddr.Provider)
+		}
+		change, err := rcs.Decode(schema.ImpliedType())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode change for %s: %w", rcs.Addr, err)
+		}
+		ret[resourceKey(rcs.Addr, rcs.DeposedKey)] = change
+	}
+	return ret, nil
+}
+
+func decodeOutputs(changes *plans.Changes) (map[string]*plans.OutputChange, error) {
+	ret := make(map[string]*plans.OutputChange)
+	if changes == nil {
+		return ret, nil
+	}
+	for _, ocs := range changes.Outputs {
+		if !ocs.Addr.Module.IsRoot() {
+			continue
+		}
+		change, err := ocs.Decode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode change for %s: %w", ocs.Addr, err)
+		}
+		ret[ocs.Addr.String()] = change
+	}
+	return ret, nil
+}
+
+func resourceKey(addr addrs.AbsResourceInstance, deposed states.DeposedKey) string {
+	if deposed == states.NotDeposed {
+		return addr.String()
+	}
+	return addr.String() + " (deposed object " + string(deposed) + ")"
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, exists := a[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func compareResources(oldChange, newChange *plans.ResourceInstanceChange) *ResourceDiff {
+	var rd ResourceDiff
+	var oldAfter, newAfter cty.Value
+	if oldChange != nil {
+		rd.Addr, rd.DeposedKey = oldChange.Addr, oldChange.DeposedKey
+		rd.OldAction = oldChange.Action
+		oldAfter = oldChange.After
+	}
+	if newChange != nil {
+		rd.Addr, rd.DeposedKey = newChange.Addr, newChange.DeposedKey
+		rd.NewAction = newChange.Action
+		newAfter = newChange.After
+	}
+
+	switch {
+	case rd.OldAction == plans.NoOp && rd.NewAction == plans.NoOp:
+		// Neither plan proposes to do anything with this object, even if one
+		// of them recorded a no-op change for it.
+		return nil
+	case rd.OldAction == plans.NoOp:
+		rd.Kind = Added
+	case rd.NewAction == plans.NoOp:
+		rd.Kind = Removed
+	default:
+		rd.Kind = Changed
+		if !oldAfter.IsNull() && !newAfter.IsNull() {
+			rd.Attributes = compareValues(nil, oldAfter, newAfter, false)
+		}
+		if rd.OldAction == rd.NewAction && len(rd.Attributes) == 0 {
+			return nil
+		}
+	}
+	return &rd
+}
+
+func compareOutputs(oldChange, newChange *plans.OutputChange) *OutputDiff {
+	od := OutputDiff{
+		Old: cty.NilVal,
+		New: cty.NilVal,
+	}
+	if oldChange != nil {
+		od.Addr = oldChange.Addr
+		od.OldAction = oldChange.Action
+		od.Old = outputValue(oldChange)
+	}
+	if newChange != nil {
+		od.Addr = newChange.Addr
+		od.NewAction = newChange.Action
+		od.New = outputValue(newChange)
+	}
+
+	switch {
+	case od.OldAction == plans.NoOp && od.NewAction == plans.NoOp:
+		return nil
+	case od.OldAction == plans.NoOp:
+		od.Kind = Added
+	case od.NewAction == plans.NoOp:
+		od.Kind = Removed
+	default:
+		od.Kind = Changed
+		if od.OldAction == od.NewAction && valuesEqual(od.Old, od.New) {
+			return nil
+		}
+	}
+	return &od
+}
+
+func outputValue(change *plans.OutputChange) cty.Value {
+	if change.Sensitive {
+		return change.After.Mark(marks.Sensitive)
+	}
+	return change.After
+}
+
+// compareValues returns the differences between two values, descending into
+// objects, maps, lists and tuples so that each difference is as specific as
+// possible. Sets are compared as a whole, because their elements have no
+// identity that could be used to match them.
+//
+// The sensitive argument is true if an ancestor of the values is sensitive,
+// in which case the whole difference is reported as sensitive.
+func compareValues(path cty.Path, oldVal, newVal cty.Value, sensitive bool) []*AttributeDiff {
+	oldUnmarked, oldMarks := oldVal.Unmark()
+	newUnmarked, newMarks := newVal.Unmark()
+	sensitive = sensitive || marks.Has(oldVal, marks.Sensitive) || marks.Has(newVal, marks.Sensitive)
+
+	whole := func() []*AttributeDiff {
+		if valuesEqual(oldVal, newVal) {
+			return nil
+		}
+		oldRet, newRet := oldVal, newVal
+		if sensitive {
+			oldRet, newRet = oldRet.Mark(marks.Sensitive), newRet.Mark(marks.Sensitive)
+		}
+		return []*AttributeDiff{{
+			Path: copyPath(path),
+			Old:  oldRet,
+			New:  newRet,
+		}}
+	}
+
+	oldTy, newTy := oldUnmarked.Type(), newUnmarked.Type()
+	if !oldUnmarked.IsKnown() || !newUnmarked.IsKnown() || oldUnmarked.IsNull() || newUnmarked.IsNull() {
+		return whole()
+	}
+
+	var ret []*AttributeDiff
+	switch {
+	case (oldTy.IsObjectType() || oldTy.IsMapType()) && (newTy.IsObjectType() || newTy.IsMapType()):
+		oldElems := elementMap(oldUnmarked)
+		newElems := elementMap(newUnmarked)
+		for _, key := range sortedKeys(oldElems, newElems) {
+			var step cty.PathStep = cty.GetAttrStep{Name: key}
+			if oldTy.IsMapType() || newTy.IsMapType() {
+				step = cty.IndexStep{Key: cty.StringVal(key)}
+			}
+			childPath := append(path, step)
+			oldElem, oldOk := oldElems[key]
+			newElem, newOk := newElems[key]
+			switch {
+			case oldOk && newOk:
+				ret = append(ret, compareValues(childPath, oldElem.WithMarks(oldMarks), newElem.WithMarks(newMarks), sensitive)...)
+			case oldOk:
+				ret = append(ret, missingValue(childPath, oldElem.WithMarks(oldMarks), true, sensitive))
+			default:
+				ret = append(ret, missingValue(childPath, newElem.WithMarks(newMarks), false, sensitive))
+			}
+		}
+	case (oldTy.IsListType() || oldTy.IsTupleType()) && (newTy.IsListType() || newTy.IsTupleType()):
+		oldElems := oldUnmarked.AsValueSlice()
+		newElems := newUnmarked.AsValueSlice()
+		for i := 0; i < len(oldElems) || i < len(newElems); i++ {
+			childPath := append(path, cty.IndexStep{Key: cty.NumberIntVal(int64(i))})
+			switch {
+			case i < len(oldElems) && i < len(newElems):
+				ret = append(ret, compareValues(childPath, oldElems[i].WithMarks(oldMarks), newElems[i].WithMarks(newMarks), sensitive)...)
+			case i < len(oldElems):
+				ret = append(ret, missingValue(childPath, oldElems[i].WithMarks(oldMarks), true, sensitive))
+			default:
+				ret = append(ret, missingValue(childPath, newElems[i].WithMarks(newMarks), false, sensitive))
+			}
+		}
+	default:
+		return whole()
+	}
+	return ret
+}
+
+// missingValue returns the difference for a value that exists in only one of
+// the plans.
+func missingValue(path cty.Path, val cty.Value, old, sensitive bool) *AttributeDiff {
+	if sensitive {
+		val = val.Mark(marks.Sensitive)
+	}
+	ret := &AttributeDiff{
+		Path: copyPath(path),
+		Old:  cty.NilVal,
+		New:  cty.NilVal,
+	}
+	if old {
+		ret.Old = val
+	} else {
+		ret.New = val
+	}
+	return ret
+}
+
+func elementMap(val cty.Value) map[string]cty.Value {
+	ret := make(map[string]cty.Value)
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		ret[k.AsString()] = v
+	}
+	return ret
+}
+
+// valuesEqual returns true if two values are identical, including their
+// marks. Unknown values are only equal to unknown values of the same type.
+func valuesEqual(a, b cty.Value) bool {
+	if a == cty.NilVal || b == cty.NilVal {
+		return a == cty.NilVal && b == cty.NilVal
+	}
+	return a.RawEquals(b)
+}
+
+// copyPath returns a copy of path, so that it isn't changed by later appends
+// to the slice it shares an array with.
+func copyPath(path cty.Path) cty.Path {
+	return append(cty.Path(nil), path...)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package plandiff
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestCompare(t *testing.T) {
+	schemas := testSchemas()
+
+	oldChanges := plans.NewChanges()
+	newChanges := plans.NewChanges()
+
+	// Unchanged in both plans.
+	addResource(t, oldChanges, "same", plans.Create, cty.StringVal("a"), nil)
+	addResource(t, newChanges, "same", plans.Create, cty.StringVal("a"), nil)
+	// Only the old plan destroys it.
+	addResource(t, oldChanges, "gone", plans.Delete, cty.StringVal("a"), nil)
+	addResource(t, newChanges, "gone", plans.NoOp, cty.StringVal("a"), nil)
+	// Only the new plan creates it.
+	addResource(t, newChanges, "new", plans.Create, cty.StringVal("a"), nil)
+	// Both update it, but to different values.
+	addResource(t, oldChanges, "values", plans.Update, cty.StringVal("a"), map[string]cty.Value{"k": cty.StringVal("1"), "old": cty.StringVal("x")})
+	addResource(t, newChanges, "values", plans.Update, cty.StringVal("b"), map[string]cty.Value{"k": cty.StringVal("1"), "new": cty.StringVal("y")})
+	// The action differs.
+	addResource(t, oldChanges, "action", plans.Update, cty.StringVal("a"), nil)
+	addResource(t, newChanges, "action", plans.DeleteThenCreate, cty.StringVal("a"), nil)
+	// The value is sensitive.
+	addResource(t, oldChanges, "secret", plans.Update, cty.StringVal("a").Mark(marks.Sensitive), nil)
+	addResource(t, newChanges, "secret", plans.Update, cty.StringVal("b").Mark(marks.Sensitive), nil)
+
+	addOutput(t, oldChanges, "same", plans.Create, cty.StringVal("a"), false)
+	addOutput(t, newChanges, "same", plans.Create, cty.StringVal("a"), false)
+	addOutput(t, oldChanges, "changed", plans.Create, cty.StringVal("a"), true)
+	addOutput(t, newChanges, "changed", plans.Create, cty.StringVal("b"), true)
+
+	diff, err := Compare(oldChanges, schemas, newChanges, schemas)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	type wantAttr struct {
+		path     string
+		old, new cty.Value
+	}
+	want := []struct {
+		addr      string
+		kind      Kind
+		oldAction plans.Action
+		newAction plans.Action
+		attrs     []wantAttr
+	}{
+		{"test_thing.action", Changed, plans.Update, plans.DeleteThenCreate, nil},
+		{"test_thing.gone", Removed, plans.Delete, plans.NoOp, nil},
+		{"test_thing.new", Added, plans.NoOp, plans.Create, nil},
+		{"test_thing.secret", Changed, plans.Update, plans.Update, []wantAttr{
+			{".value", cty.StringVal("a").Mark(marks.Sensitive), cty.StringVal("b").Mark(marks.Sensitive)},
+		}},
+		{"test_thing.values", Changed, plans.Update, plans.Update, []wantAttr{
+			{`.tags["new"]`, cty.NilVal, cty.StringVal("y")},
+			{`.tags["old"]`, cty.StringVal("x"), cty.NilVal},
+			{".value", cty.StringVal("a"), cty.StringVal("b")},
+		}},
+	}
+
+	if len(diff.Resources) != len(want) {
+		for _, rd := range diff.Resources {
+			t.Logf("got %s (%s)", rd.Addr, rd.Kind)
+		}
+		t.Fatalf("got %d resource differences, want %d", len(diff.Resources), len(want))
+	}
+	for i, w := range want {
+		got := diff.Resources[i]
+		if got.Addr.String() != w.addr || got.Kind != w.kind || got.OldAction != w.oldAction || got.NewAction != w.newAction {
+			t.Errorf("resource %d is %s (%s, %s to %s), want %s (%s, %s to %s)", i,
+				got.Addr, got.Kind, got.OldAction, got.NewAction,
+				w.addr, w.kind, w.oldAction, w.newAction)
+			continue
+		}
+		if len(got.Attributes) != len(w.attrs) {
+			t.Errorf("%s has %d attribute differences, want %d", w.addr, len(got.Attributes), len(w.attrs))
+			continue
+		}
+		for j, wa := range w.attrs {
+			ga := got.Attributes[j]
+			if path := tfdiags.FormatCtyPath(ga.Path); path != wa.path {
+				t.Errorf("%s attribute %d has path %s, want %s", w.addr, j, path, wa.path)
+			}
+			if !valuesEqual(ga.Old, wa.old) || !valuesEqual(ga.New, wa.new) {
+				t.Errorf("%s%s changed from %#v to %#v, want %#v to %#v", w.addr, wa.path, ga.Old, ga.New, wa.old, wa.new)
+			}
+		}
+	}
+
+	if len(diff.Outputs) != 1 {
+		t.Fatalf("got %d output differences, want 1", len(diff.Outputs))
+	}
+	od := diff.Outputs[0]
+	if od.Addr.OutputValue.Name != "changed" || od.Kind != Changed {
+		t.Errorf("got output %s (%s), want output.changed (changed)", od.Addr, od.Kind)
+	}
+	if !marks.Has(od.Old, marks.Sensitive) || !marks.Has(od.New, marks.Sensitive) {
+		t.Errorf("sensitive output values are not marked: %#v, %#v", od.Old, od.New)
+	}
+}
+
+func TestCompare_same(t *testing.T) {
+	schemas := testSchemas()
+	changes := plans.NewChanges()
+	addResource(t, changes, "a", plans.Create, cty.UnknownVal(cty.String), nil)
+	addOutput(t, changes, "o", plans.Create, cty.UnknownVal(cty.String), false)
+
+	diff, err := Compare(changes, schemas, changes, schemas)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("expected no differences, got %d resource and %d output differences", len(diff.Resources), len(diff.Outputs))
+	}
+}
+
+func TestCompare_noSchema(t *testing.T) {
+	changes := plans.NewChanges()
+	addResource(t, changes, "a", plans.Create, cty.StringVal("a"), nil)
+
+	_, err := Compare(changes, testSchemas(), changes, &tofu.Schemas{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got, want := err.Error(), "failed to decode new plan: no schema found for test_thing.a (in provider registry.opentofu.org/hashicorp/test)"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+var testThingType = cty.Object(map[string]cty.Type{
+	"value": cty.String,
+	"tags":  cty.Map(cty.String),
+})
+
+func testSchemas() *tofu.Schemas {
+	return &tofu.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			addrs.NewDefaultProvider("test"): {
+				ResourceTypes: map[string]providers.Schema{
+					"test_thing": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"value": {Type: cty.String, Optional: true},
+								"tags":  {Type: cty.Map(cty.String), Optional: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func addResource(t *testing.T, changes *plans.Changes, name string, action plans.Action, value cty.Value, tags map[string]cty.Value) {
+	t.Helper()
+
+	tagsVal := cty.NullVal(cty.Map(cty.String))
+	if tags != nil {
+		tagsVal = cty.MapVal(tags)
+	}
+	after := cty.ObjectVal(map[string]cty.Value{
+		"value": value,
+		"tags":  tagsVal,
+	})
+	before := cty.NullVal(testThingType)
+	if action != plans.Create {
+		before = after
+	}
+	if action == plans.Delete {
+		after = cty.NullVal(testThingType)
+	}
+
+	change := &plans.ResourceInstanceChange{
+		Addr: addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_thing",
+			Name: name,
+		}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+		ProviderAddr: addrs.AbsProviderConfig{
+			Provider: addrs.NewDefaultProvider("test"),
+			Module:   addrs.RootModule,
+		},
+		Change: plans.Change{
+			Action: action,
+			Before: before,
+			After:  after,
+		},
+	}
+	change.PrevRunAddr = change.Addr
+	src, err := change.Encode(testThingType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes.SyncWrapper().AppendResourceInstanceChange(src)
+}
+
+func addOutput(t *testing.T, changes *plans.Changes, name string, action plans.Action, value cty.Value, sensitive bool) {
+	t.Helper()
+
+	change := &plans.OutputChange{
+		Addr:      addrs.OutputValue{Name: name}.Absolute(addrs.RootModuleInstance),
+		Sensitive: sensitive,
+		Change: plans.Change{
+			Action: action,
+			Before: cty.NullVal(cty.DynamicPseudoType),
+			After:  value,
+		},
+	}
+	src, err := change.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes.Outputs = append(changes.Outputs, src)
+}
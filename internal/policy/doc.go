@@ -0,0 +1,24 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package policy contains a policy engine that runs locally, evaluating
+// policies written in HCL against the JSON representation of a plan and its
+// prior state before the plan can be applied.
+//
+// Policies are declared in files with the suffix ".tfpolicy.hcl":
+//
+//	policy "require_tags" {
+//	  description       = "All resources must have an owner tag."
+//	  enforcement_level = "mandatory"
+//
+//	  condition = alltrue([
+//	    for rc in plan.resource_changes : can(rc.change.after.tags.owner)
+//	  ])
+//	  error_message = "Every resource must have an owner tag."
+//	}
+//
+// The condition and error message can use the OpenTofu built-in functions and
+// two variables: plan, which is the plan in the same format as the output of
+// "tofu show -json" for a plan file, and state, which is the prior state in
+// the same format as the output of "tofu show -json" for a state file.
+package policy
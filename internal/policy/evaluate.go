@@ -0,0 +1,194 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// Input is the data that policies are evaluated against.
+type Input struct {
+	// Plan is the JSON representation of the plan, as produced by
+	// "tofu show -json" for a plan file.
+	Plan cty.Value
+
+	// State is the JSON representation of the prior state, as produced by
+	// "tofu show -json" for a state file.
+	State cty.Value
+
+	// PlanTimestamp is the time at which the plan was created, which is
+	// returned by the plantimestamp function.
+	PlanTimestamp time.Time
+}
+
+// NewInput returns the input for evaluating policies against a plan.
+func NewInput(config *configs.Config, plan *plans.Plan, schemas *tofu.Schemas) (*Input, error) {
+	stateFile := &statefile.File{State: plan.PriorState}
+
+	planJSON, err := jsonplan.Marshal(config, plan, stateFile, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	stateJSON, err := jsonstate.Marshal(stateFile, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	planVal, err := jsonToValue(planJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode plan: %w", err)
+	}
+	stateVal, err := jsonToValue(stateJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+	return &Input{
+		Plan:          planVal,
+		State:         stateVal,
+		PlanTimestamp: plan.Timestamp,
+	}, nil
+}
+
+func jsonToValue(src []byte) (cty.Value, error) {
+	ty, err := ctyjson.ImpliedType(src)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(src, ty)
+}
+
+// Outcome is the result of evaluating a single policy.
+type Outcome struct {
+	Policy *Policy
+	Passed bool
+
+	// Message is the error message of the policy if it failed.
+	Message string
+}
+
+// Results is the outcome of evaluating each policy of a set, in the order in
+// which the policies are declared.
+type Results struct {
+	Outcomes []*Outcome
+}
+
+// Failed returns the number of failed policies with the given enforcement
+// level.
+func (r *Results) Failed(level EnforcementLevel) int {
+	var count int
+	for _, outcome := range r.Outcomes {
+		if !outcome.Passed && outcome.Policy.EnforcementLevel == level {
+			count++
+		}
+	}
+	return count
+}
+
+// Passed returns the number of policies that passed.
+func (r *Results) Passed() int {
+	var count int
+	for _, outcome := range r.Outcomes {
+		if outcome.Passed {
+			count++
+		}
+	}
+	return count
+}
+
+// Evaluate evaluates each policy of the set against the input. The baseDir is
+// used to resolve relative paths in functions such as file.
+//
+// Evaluate returns error diagnostics for policies that cannot be evaluated,
+// but not for policies that fail: callers decide what to do about failures
+// based on the enforcement level of each policy.
+func (s *Set) Evaluate(input *Input, baseDir string) (*Results, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	scope := &lang.Scope{
+		BaseDir:       baseDir,
+		PlanTimestamp: input.PlanTimestamp,
+	}
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"plan":  input.Plan,
+			"state": input.State,
+		},
+		Functions: scope.Functions(),
+	}
+
+	results := &Results{}
+	for _, policy := range s.Policies {
+		outcome, moreDiags := evaluatePolicy(policy, ctx)
+		diags = diags.Append(moreDiags)
+		if outcome != nil {
+			results.Outcomes = append(results.Outcomes, outcome)
+		}
+	}
+	return results, diags
+}
+
+func evaluatePolicy(policy *Policy, ctx *hcl.EvalContext) (*Outcome, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	val, hclDiags := policy.Condition.Value(ctx)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	switch {
+	case val.IsNull():
+		diags = diags.Append(invalidResultDiag(policy.Condition, policy.Name, "condition", "The condition value is null. Policy conditions must either be true or false."))
+		return nil, diags
+	case !val.IsWhollyKnown():
+		diags = diags.Append(invalidResultDiag(policy.Condition, policy.Name, "condition", "The condition value is unknown. Policy conditions must either be true or false."))
+		return nil, diags
+	}
+	val, _ = val.Unmark()
+	val, err := convert.Convert(val, cty.Bool)
+	if err != nil {
+		diags = diags.Append(invalidResultDiag(policy.Condition, policy.Name, "condition", fmt.Sprintf("Invalid condition result value: %s.", tfdiags.FormatError(err))))
+		return nil, diags
+	}
+	if val.True() {
+		return &Outcome{Policy: policy, Passed: true}, diags
+	}
+
+	msgVal, hclDiags := policy.ErrorMessage.Value(ctx)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	msgVal, err = convert.Convert(msgVal, cty.String)
+	if err != nil || msgVal.IsNull() || !msgVal.IsWhollyKnown() {
+		diags = diags.Append(invalidResultDiag(policy.ErrorMessage, policy.Name, "error message", "The error message must be a known string."))
+		return nil, diags
+	}
+	msgVal, _ = msgVal.Unmark()
+	return &Outcome{Policy: policy, Message: msgVal.AsString()}, diags
+}
+
+func invalidResultDiag(expr hcl.Expression, name, what, detail string) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity:   hcl.DiagError,
+		Summary:    fmt.Sprintf("Invalid policy %s", what),
+		Detail:     fmt.Sprintf("Failed to evaluate the %s of policy %q: %s", what, name, detail),
+		Subject:    expr.Range().Ptr(),
+		Expression: expr,
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// FileSuffix is the suffix of the files that are loaded from a policy
+// directory.
+const FileSuffix = ".tfpolicy.hcl"
+
+// EnforcementLevel decides what happens when a policy fails.
+type EnforcementLevel string
+
+const (
+	// Advisory policies report their failures, but do not prevent the plan
+	// from being applied.
+	Advisory EnforcementLevel = "advisory"
+
+	// Mandatory policies prevent the plan from being applied when they fail.
+	Mandatory EnforcementLevel = "mandatory"
+)
+
+// Policy is a single policy block.
+type Policy struct {
+	Name             string
+	Description      string
+	EnforcementLevel EnforcementLevel
+
+	// Condition must evaluate to true for the policy to pass, and
+	// ErrorMessage explains why it failed otherwise.
+	Condition    hcl.Expression
+	ErrorMessage hcl.Expression
+
+	DeclRange hcl.Range
+}
+
+// Set is the policies loaded from one or more files, in the order in which
+// they are declared.
+type Set struct {
+	Policies []*Policy
+}
+
+// Empty returns true if the set has no policies.
+func (s *Set) Empty() bool {
+	return s == nil || len(s.Policies) == 0
+}
+
+// FileLoader reads and parses HCL files, such as the parser of the
+// configuration loader, which also keeps the sources for diagnostic snippets.
+type FileLoader interface {
+	LoadHCLFile(path string) (hcl.Body, hcl.Diagnostics)
+}
+
+// LoadPaths loads the policies in the given paths. A path may be a single
+// policy file, or a directory in which case all the files in it that have the
+// suffix FileSuffix are loaded.
+func LoadPaths(loader FileLoader, paths []string) (*Set, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var filenames []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read policies",
+				Detail:   fmt.Sprintf("Couldn't read the policy path %s: %s.", path, err),
+			})
+			continue
+		}
+		if !info.IsDir() {
+			filenames = append(filenames, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read policies",
+				Detail:   fmt.Sprintf("Couldn't read the policy directory %s: %s.", path, err),
+			})
+			continue
+		}
+		var found bool
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), FileSuffix) {
+				continue
+			}
+			filenames = append(filenames, filepath.Join(path, entry.Name()))
+			found = true
+		}
+		if !found {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "No policy files",
+				Detail:   fmt.Sprintf("The policy directory %s doesn't contain any files with the suffix %q.", path, FileSuffix),
+			})
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	set := &Set{}
+	for _, filename := range filenames {
+		body, fileDiags := loader.LoadHCLFile(filename)
+		diags = append(diags, fileDiags...)
+		if fileDiags.HasErrors() {
+			continue
+		}
+		policies, moreDiags := decodeFile(body)
+		diags = append(diags, moreDiags...)
+		set.Policies = append(set.Policies, policies...)
+	}
+	diags = append(diags, set.checkNames()...)
+	return set, diags
+}
+
+// ParseFile parses the source of a single policy file.
+func ParseFile(filename string, src []byte) (*Set, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	policies, moreDiags := decodeFile(file.Body)
+	diags = append(diags, moreDiags...)
+	set := &Set{Policies: policies}
+	diags = append(diags, set.checkNames()...)
+	return set, diags
+}
+
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "policy", LabelNames: []string{"name"}},
+	},
+}
+
+var policySchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "description"},
+		{Name: "enforcement_level"},
+		{Name: "condition", Required: true},
+		{Name: "error_message", Required: true},
+	},
+}
+
+func decodeFile(body hcl.Body) ([]*Policy, hcl.Diagnostics) {
+	content, diags := body.Content(fileSchema)
+
+	var policies []*Policy
+	for _, block := range content.Blocks {
+		policy, moreDiags := decodePolicyBlock(block)
+		diags = append(diags, moreDiags...)
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, diags
+}
+
+func decodePolicyBlock(block *hcl.Block) (*Policy, hcl.Diagnostics) {
+	policy := &Policy{
+		Name:             block.Labels[0],
+		EnforcementLevel: Mandatory,
+		DeclRange:        block.DefRange,
+	}
+
+	var diags hcl.Diagnostics
+	if !hclsyntax.ValidIdentifier(policy.Name) {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid policy name",
+			Detail:   "A name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.",
+			Subject:  &block.LabelRanges[0],
+		})
+	}
+
+	content, moreDiags := block.Body.Content(policySchema)
+	diags = append(diags, moreDiags...)
+
+	if attr, exists := content.Attributes["description"]; exists {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &policy.Description)...)
+	}
+	if attr, exists := content.Attributes["enforcement_level"]; exists {
+		var level string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &level)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			switch EnforcementLevel(level) {
+			case Advisory, Mandatory:
+				policy.EnforcementLevel = EnforcementLevel(level)
+			default:
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid enforcement level",
+					Detail:   fmt.Sprintf("The enforcement level must be %q or %q.", Advisory, Mandatory),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+	if attr, exists := content.Attributes["condition"]; exists {
+		policy.Condition = attr.Expr
+	}
+	if attr, exists := content.Attributes["error_message"]; exists {
+		policy.ErrorMessage = attr.Expr
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return policy, diags
+}
+
+// checkNames returns errors for policies that have the same name, which would
+// make their results ambiguous.
+func (s *Set) checkNames() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	seen := make(map[string]*Policy, len(s.Policies))
+	for _, policy := range s.Policies {
+		if prev, exists := seen[policy.Name]; exists {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate policy",
+				Detail:   fmt.Sprintf("A policy named %q was already declared at %s. Policy names must be unique.", policy.Name, prev.DeclRange),
+				Subject:  policy.DeclRange.Ptr(),
+			})
+			continue
+		}
+		seen[policy.Name] = policy
+	}
+	return diags
+}
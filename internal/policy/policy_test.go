@@ -0,0 +1,227 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type testLoader struct {
+	parser *hclparse.Parser
+}
+
+func (l testLoader) LoadHCLFile(path string) (hcl.Body, hcl.Diagnostics) {
+	file, diags := l.parser.ParseHCLFile(path)
+	if file == nil {
+		return nil, diags
+	}
+	return file.Body, diags
+}
+
+func TestLoadPaths(t *testing.T) {
+	set, diags := LoadPaths(testLoader{hclparse.NewParser()}, []string{"testdata/policies"})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	// Files are loaded in lexical order.
+	var names []string
+	for _, policy := range set.Policies {
+		names = append(names, policy.Name+":"+string(policy.EnforcementLevel))
+	}
+	if got, want := strings.Join(names, ","), "count:advisory,tags:mandatory"; got != want {
+		t.Errorf("wrong policies\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := set.Policies[1].Description, "Every resource must have an owner tag."; got != want {
+		t.Errorf("wrong description\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestLoadPaths_errors(t *testing.T) {
+	tcs := map[string]struct {
+		paths []string
+		want  string
+	}{
+		"missing": {
+			[]string{"testdata/missing"},
+			"Couldn't read the policy path testdata/missing",
+		},
+		"no policy files": {
+			[]string{"testdata"},
+			`The policy directory testdata doesn't contain any files with the suffix ".tfpolicy.hcl".`,
+		},
+		"duplicate": {
+			[]string{"testdata/policies", "testdata/policies/tags.tfpolicy.hcl"},
+			`A policy named "tags" was already declared`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, diags := LoadPaths(testLoader{hclparse.NewParser()}, tc.paths)
+			if !diags.HasErrors() {
+				t.Fatal("expected errors")
+			}
+			if got := diags.Error(); !strings.Contains(got, tc.want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseFile_errors(t *testing.T) {
+	tcs := map[string]struct {
+		src  string
+		want string
+	}{
+		"enforcement level": {
+			`policy "a" {
+  enforcement_level = "soft"
+  condition         = true
+  error_message     = "a"
+}`,
+			`The enforcement level must be "advisory" or "mandatory".`,
+		},
+		"missing condition": {
+			`policy "a" {
+  error_message = "a"
+}`,
+			`The argument "condition" is required`,
+		},
+		"invalid name": {
+			`policy "a b" {
+  condition     = true
+  error_message = "a"
+}`,
+			"A name must start with a letter or underscore",
+		},
+		"unsupported block": {
+			`rule "a" {}`,
+			`Blocks of type "rule" are not expected here.`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseFile("test.tfpolicy.hcl", []byte(tc.src))
+			if !diags.HasErrors() {
+				t.Fatal("expected errors")
+			}
+			if got := diags.Error(); !strings.Contains(got, tc.want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	set, diags := LoadPaths(testLoader{hclparse.NewParser()}, []string{"testdata/policies"})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	resourceChange := func(tags cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"change": cty.ObjectVal(map[string]cty.Value{
+				"after": cty.ObjectVal(map[string]cty.Value{
+					"tags": tags,
+				}),
+			}),
+		})
+	}
+	input := &Input{
+		Plan: cty.ObjectVal(map[string]cty.Value{
+			"resource_changes": cty.TupleVal([]cty.Value{
+				resourceChange(cty.ObjectVal(map[string]cty.Value{"owner": cty.StringVal("me")})),
+				resourceChange(cty.EmptyObjectVal),
+			}),
+		}),
+		State: cty.EmptyObjectVal,
+	}
+
+	results, evalDiags := set.Evaluate(input, ".")
+	if evalDiags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", evalDiags.Err())
+	}
+	if got, want := len(results.Outcomes), 2; got != want {
+		t.Fatalf("got %d outcomes, want %d", got, want)
+	}
+	if got, want := results.Outcomes[0].Message, "This plan changes 2 resources."; got != want {
+		t.Errorf("wrong message\ngot:  %s\nwant: %s", got, want)
+	}
+	if results.Passed() != 0 || results.Failed(Advisory) != 1 || results.Failed(Mandatory) != 1 {
+		t.Errorf("wrong counts: %d passed, %d advisory failed, %d mandatory failed", results.Passed(), results.Failed(Advisory), results.Failed(Mandatory))
+	}
+
+	// With a single tagged resource, every policy passes.
+	input.Plan = cty.ObjectVal(map[string]cty.Value{
+		"resource_changes": cty.TupleVal([]cty.Value{
+			resourceChange(cty.ObjectVal(map[string]cty.Value{"owner": cty.StringVal("me")})),
+		}),
+	})
+	results, evalDiags = set.Evaluate(input, ".")
+	if evalDiags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", evalDiags.Err())
+	}
+	if got, want := results.Passed(), 2; got != want {
+		t.Errorf("%d policies passed, want %d", got, want)
+	}
+}
+
+func TestEvaluate_errors(t *testing.T) {
+	tcs := map[string]struct {
+		src  string
+		want string
+	}{
+		"not a bool": {
+			`policy "a" {
+  condition     = "yes please"
+  error_message = "a"
+}`,
+			"Invalid condition result value: a bool is required.",
+		},
+		"null": {
+			`policy "a" {
+  condition     = null
+  error_message = "a"
+}`,
+			"The condition value is null.",
+		},
+		"unknown variable": {
+			`policy "a" {
+  condition     = var.a
+  error_message = "a"
+}`,
+			`There is no variable named "var".`,
+		},
+		"error message": {
+			`policy "a" {
+  condition     = false
+  error_message = ["a"]
+}`,
+			"The error message must be a known string.",
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			set, diags := ParseFile("test.tfpolicy.hcl", []byte(tc.src))
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+			results, evalDiags := set.Evaluate(&Input{Plan: cty.EmptyObjectVal, State: cty.EmptyObjectVal}, ".")
+			if !evalDiags.HasErrors() {
+				t.Fatal("expected errors")
+			}
+			if len(results.Outcomes) != 0 {
+				t.Errorf("unexpected outcomes for a policy that failed to evaluate")
+			}
+			if got := evalDiags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
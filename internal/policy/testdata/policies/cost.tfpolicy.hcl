@@ -0,0 +1,5 @@
+policy "count" {
+  enforcement_level = "advisory"
+  condition         = length(plan.resource_changes) <= 1
+  error_message     = "This plan changes ${length(plan.resource_changes)} resources."
+}
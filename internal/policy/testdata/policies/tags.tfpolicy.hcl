@@ -0,0 +1,7 @@
+policy "tags" {
+  description = "Every resource must have an owner tag."
+  condition = alltrue([
+    for rc in plan.resource_changes : can(rc.change.after.tags.owner)
+  ])
+  error_message = "Every resource must have an owner tag."
+}
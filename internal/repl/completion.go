@@ -0,0 +1,126 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package repl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/configs"
+)
+
+// Complete returns the completions for the word that ends the given line,
+// along with the length of that word. Each completion is a whole word that
+// can replace it, such as "var.region" for the word "var.re".
+//
+// Completions are the console commands, the addresses of the session, the
+// functions of its scope and the attributes of the values that the
+// expression before the last dot refers to.
+func (s *Session) Complete(line string) ([]string, int) {
+	word := line[strings.LastIndexFunc(line, func(r rune) bool { return !isWordRune(r) })+1:]
+
+	var candidates []string
+	switch {
+	case strings.HasPrefix(line, ":") && !strings.Contains(line, " "):
+		for name := range consoleCommands {
+			candidates = append(candidates, name)
+		}
+	case word == "":
+		return nil, 0
+	default:
+		candidates = append(candidates, s.Addresses...)
+		if s.Scope != nil {
+			for name := range s.Scope.Functions() {
+				candidates = append(candidates, name+"(")
+			}
+		}
+		if dot := strings.LastIndexByte(word, '.'); dot > 0 {
+			candidates = append(candidates, s.attributeNames(word[:dot])...)
+		}
+	}
+
+	var ret []string
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) && candidate != word && !seen[candidate] {
+			seen[candidate] = true
+			ret = append(ret, candidate)
+		}
+	}
+	sort.Strings(ret)
+	return ret, len(word)
+}
+
+// attributeNames returns the names of the attributes of the value that the
+// given traversal refers to, prefixed by the traversal, or nothing if it
+// can't be evaluated.
+func (s *Session) attributeNames(base string) []string {
+	if s.Scope == nil {
+		return nil
+	}
+	expr, diags := hclsyntax.ParseExpression([]byte(base), "<console-input>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil
+	}
+	if _, ok := expr.(*hclsyntax.ScopeTraversalExpr); !ok {
+		return nil
+	}
+	val, valDiags := s.Scope.EvalExpr(expr, cty.DynamicPseudoType)
+	if valDiags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return nil
+	}
+	val, _ = val.UnmarkDeep()
+
+	var ret []string
+	ty := val.Type()
+	switch {
+	case ty.IsObjectType():
+		for name := range ty.AttributeTypes() {
+			ret = append(ret, base+"."+name)
+		}
+	case ty.IsMapType():
+		for it := val.ElementIterator(); it.Next(); {
+			key, _ := it.Element()
+			ret = append(ret, base+"."+key.AsString())
+		}
+	}
+	return ret
+}
+
+// isWordRune returns true if r can be part of a word that Complete
+// completes.
+func isWordRune(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || r == ':' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// ModuleAddresses returns the addresses that expressions in the given module
+// can refer to, for use as Session.Addresses.
+func ModuleAddresses(mod *configs.Module) []string {
+	ret := []string{"path.module", "path.root", "path.cwd", "terraform.workspace", "tofu.workspace"}
+	if mod == nil {
+		return ret
+	}
+	for name := range mod.Variables {
+		ret = append(ret, "var."+name)
+	}
+	for name := range mod.Locals {
+		ret = append(ret, "local."+name)
+	}
+	for _, r := range mod.ManagedResources {
+		ret = append(ret, r.Addr().String())
+	}
+	for _, r := range mod.DataResources {
+		ret = append(ret, r.Addr().String())
+	}
+	for name := range mod.ModuleCalls {
+		ret = append(ret, "module."+name)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -24,6 +24,11 @@ import (
 type Session struct {
 	// Scope is the evaluation scope where expressions will be evaluated.
 	Scope *lang.Scope
+
+	// Addresses are the addresses that expressions can refer to, such as
+	// "var.region" or "aws_instance.web", which Complete offers along with
+	// the function names of the scope. See ModuleAddresses.
+	Addresses []string
 }
 
 // Handle handles a single line of input from the REPL.
@@ -41,6 +46,9 @@ func (s *Session) Handle(line string) (string, bool, tfdiags.Diagnostics) {
 	case strings.TrimSpace(line) == "help":
 		ret := s.handleHelp()
 		return ret, false, nil
+	case strings.HasPrefix(strings.TrimSpace(line), ":"):
+		ret, diags := s.handleCommand(strings.TrimSpace(line))
+		return ret, false, diags
 	default:
 		ret, diags := s.handleEval(line)
 		return ret, false, diags
@@ -48,18 +56,8 @@ func (s *Session) Handle(line string) (string, bool, tfdiags.Diagnostics) {
 }
 
 func (s *Session) handleEval(line string) (string, tfdiags.Diagnostics) {
-	var diags tfdiags.Diagnostics
-
-	// Parse the given line as an expression
-	expr, parseDiags := hclsyntax.ParseExpression([]byte(line), "<console-input>", hcl.Pos{Line: 1, Column: 1})
-	diags = diags.Append(parseDiags)
-	if parseDiags.HasErrors() {
-		return "", diags
-	}
-
-	val, valDiags := s.Scope.EvalExpr(expr, cty.DynamicPseudoType)
-	diags = diags.Append(valDiags)
-	if valDiags.HasErrors() {
+	val, diags := s.eval(line)
+	if diags.HasErrors() {
 		return "", diags
 	}
 
@@ -93,6 +91,70 @@ func (s *Session) handleEval(line string) (string, tfdiags.Diagnostics) {
 	return FormatValue(val, 0), diags
 }
 
+// eval parses and evaluates the given line as an expression.
+func (s *Session) eval(line string) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	// Parse the given line as an expression. The closing marker of a heredoc
+	// must be followed by a newline, which the console doesn't keep.
+	expr, parseDiags := hclsyntax.ParseExpression([]byte(line+"\n"), "<console-input>", hcl.Pos{Line: 1, Column: 1})
+	diags = diags.Append(parseDiags)
+	if parseDiags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+
+	val, valDiags := s.Scope.EvalExpr(expr, cty.DynamicPseudoType)
+	diags = diags.Append(valDiags)
+	return val, diags
+}
+
+// consoleCommands are the commands that the console accepts in addition to
+// expressions, with their descriptions.
+var consoleCommands = map[string]string{
+	":type":      "shows the type of the value of an expression",
+	":sensitive": "shows the value of an expression, including its sensitive values",
+	":help":      "shows this help",
+}
+
+// handleCommand handles a line that starts with a colon, which is a command
+// to the console rather than an expression.
+func (s *Session) handleCommand(line string) (string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ":help":
+		return s.handleHelp(), diags
+	case ":type", ":sensitive":
+		if arg == "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Missing expression",
+				fmt.Sprintf("The %s command requires an expression, such as \"%s var.region\".", name, name),
+			))
+			return "", diags
+		}
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unknown console command",
+			fmt.Sprintf("The console has no command %q. Type \":help\" to list the available commands.", name),
+		))
+		return "", diags
+	}
+
+	val, diags := s.eval(arg)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	val, _ = val.UnmarkDeep()
+	if name == ":type" {
+		return typeString(val.Type()), diags
+	}
+	return FormatValue(val, 0), diags
+}
+
 func (s *Session) handleHelp() string {
 	text := `
 The OpenTofu console allows you to experiment with OpenTofu interpolations.
@@ -102,11 +164,26 @@ to the ID of "aws_instance.foo" if it exists in your state.
 
 Type in the interpolation to test and hit <enter> to see the result.
 
+Expressions can span multiple lines. Press <tab> to complete the names of
+variables, locals, resources, modules and functions.
+
+The console also accepts the following commands:
+%s
 To exit the console, type "exit" and hit <enter>, or use Control-C or
 Control-D.
 `
 
-	return strings.TrimSpace(text)
+	names := make([]string, 0, len(consoleCommands))
+	for name := range consoleCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var commands strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&commands, "  %-12s %s\n", name, consoleCommands[name])
+	}
+
+	return strings.TrimSpace(fmt.Sprintf(text, commands.String()))
 }
 
 // typeString returns a string representation of a given type that is
@@ -263,54 +263,106 @@ func TestSession_stateless(t *testing.T) {
 	})
 }
 
-func testSession(t *testing.T, test testSessionTest) {
-	t.Helper()
+func TestSession_commands(t *testing.T) {
+	t.Run("type", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			Inputs: []testSessionInput{
+				{
+					Input:  `:type {a = 1, b = ["x"]}`,
+					Output: "object({\n    a: number,\n    b: tuple([\n        string,\n    ]),\n})",
+				},
+				{
+					Input:  `:type sensitive("x")`,
+					Output: "string",
+				},
+			},
+		})
+	})
 
-	p := &tofu.MockProvider{}
-	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
-		ResourceTypes: map[string]providers.Schema{
-			"test_instance": {
-				Block: &configschema.Block{
-					Attributes: map[string]*configschema.Attribute{
-						"id": {Type: cty.String, Computed: true},
-					},
+	t.Run("sensitive", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			Inputs: []testSessionInput{
+				{
+					Input:  `sensitive("secret")`,
+					Output: "(sensitive value)",
+				},
+				{
+					Input:  `:sensitive sensitive("secret")`,
+					Output: `"secret"`,
 				},
 			},
-		},
-	}
+		})
+	})
 
-	config, _, cleanup, configDiags := initwd.LoadConfigForTests(t, "testdata/config-fixture", "tests")
-	defer cleanup()
-	if configDiags.HasErrors() {
-		t.Fatalf("unexpected problems loading config: %s", configDiags.Err())
-	}
+	t.Run("help", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			Inputs: []testSessionInput{
+				{
+					Input:          ":help",
+					OutputContains: ":sensitive",
+				},
+			},
+		})
+	})
 
-	// Build the TF context
-	ctx, diags := tofu.NewContext(&tofu.ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): providers.FactoryFixed(p),
-		},
+	t.Run("missing expression", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			Inputs: []testSessionInput{
+				{
+					Input:         ":type",
+					Error:         true,
+					ErrorContains: "The :type command requires an expression",
+				},
+			},
+		})
 	})
-	if diags.HasErrors() {
-		t.Fatalf("failed to create context: %s", diags.Err())
-	}
 
-	state := test.State
-	if state == nil {
-		state = states.NewState()
+	t.Run("unknown command", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			Inputs: []testSessionInput{
+				{
+					Input:         ":foo 1",
+					Error:         true,
+					ErrorContains: `The console has no command ":foo"`,
+				},
+			},
+		})
+	})
+}
+
+func TestSession_Complete(t *testing.T) {
+	s := testNewSession(t, nil)
+
+	tests := []struct {
+		line   string
+		want   []string
+		length int
+	}{
+		{"", nil, 0},
+		{":", []string{":help", ":sensitive", ":type"}, 1},
+		{":t", []string{":type"}, 2},
+		{":type test_", []string{"test_instance.foo"}, 5},
+		{"upper(mod", []string{"module.module"}, 3},
+		{"uppe", []string{"upper("}, 4},
+		{"path.r", []string{"path.root"}, 6},
+		{"module.module.", nil, 14},
+		{"[1, 2] + ", nil, 0},
 	}
-	scope, diags := ctx.Eval(context.Background(), config, state, addrs.RootModuleInstance, &tofu.EvalOpts{})
-	if diags.HasErrors() {
-		t.Fatalf("failed to create scope: %s", diags.Err())
+	for _, test := range tests {
+		got, length := s.Complete(test.line)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("wrong completions for %q\n%s", test.line, diff)
+		}
+		if length != test.length {
+			t.Errorf("wrong length for %q: got %d, want %d", test.line, length, test.length)
+		}
 	}
+}
 
-	// Ensure that any console-only functions are available
-	scope.ConsoleMode = true
+func testSession(t *testing.T, test testSessionTest) {
+	t.Helper()
 
-	// Build the session
-	s := &Session{
-		Scope: scope,
-	}
+	s := testNewSession(t, test.State)
 
 	// Test the inputs. We purposely don't use subtests here because
 	// the inputs don't represent subtests, but a sequence of stateful
@@ -350,6 +402,58 @@ func testSession(t *testing.T, test testSessionTest) {
 	}
 }
 
+// testNewSession returns a session for the configuration in
+// testdata/config-fixture and the given state, which may be nil.
+func testNewSession(t *testing.T, state *states.State) *Session {
+	t.Helper()
+
+	p := &tofu.MockProvider{}
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id": {Type: cty.String, Computed: true},
+					},
+				},
+			},
+		},
+	}
+
+	config, _, cleanup, configDiags := initwd.LoadConfigForTests(t, "testdata/config-fixture", "tests")
+	t.Cleanup(cleanup)
+	if configDiags.HasErrors() {
+		t.Fatalf("unexpected problems loading config: %s", configDiags.Err())
+	}
+
+	// Build the TF context
+	ctx, diags := tofu.NewContext(&tofu.ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): providers.FactoryFixed(p),
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("failed to create context: %s", diags.Err())
+	}
+
+	if state == nil {
+		state = states.NewState()
+	}
+	scope, diags := ctx.Eval(context.Background(), config, state, addrs.RootModuleInstance, &tofu.EvalOpts{})
+	if diags.HasErrors() {
+		t.Fatalf("failed to create scope: %s", diags.Err())
+	}
+
+	// Ensure that any console-only functions are available
+	scope.ConsoleMode = true
+
+	// Build the session
+	return &Session{
+		Scope:     scope,
+		Addresses: ModuleAddresses(config.Module),
+	}
+}
+
 type testSessionTest struct {
 	State  *states.State // State to use
 	Module string        // Module name in testdata to load
@@ -6,6 +6,9 @@
 package remote
 
 import (
+	"errors"
+	"time"
+
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
 
@@ -41,6 +44,49 @@ type OptionalClientLocker interface {
 	IsLockingEnabled() bool
 }
 
+// ClientVersioner is an optional interface that allows a remote state
+// backend to expose the previously-persisted snapshots of a state, so that
+// they can be inspected and restored by the user.
+type ClientVersioner interface {
+	Client
+
+	// PutVersion stores the given state data in the same way as Put, and
+	// retains it as a new version if the backend is configured to. The
+	// serial and lineage of the version are passed separately, because the
+	// data might be encrypted.
+	PutVersion(data []byte, serial uint64, lineage string) error
+
+	// Versions returns all of the retained versions of the state, ordered
+	// from the most recent to the oldest.
+	Versions() ([]*StateVersion, error)
+
+	// GetVersion returns the payload of the version with the given ID, or
+	// nil if no such version is retained.
+	GetVersion(id string) (*Payload, error)
+}
+
+// ErrHistoryDisabled is returned by the methods of ClientVersioner when the
+// backend is configured not to retain the versions of a state.
+var ErrHistoryDisabled = errors.New("state history is not enabled; set enable_history to true in the backend configuration to retain state versions")
+
+// StateVersion describes a single retained version of a remote state.
+type StateVersion struct {
+	// ID is the client-specific identifier of the version, as accepted by
+	// ClientVersioner.GetVersion.
+	ID string
+
+	// Serial and Lineage are the snapshot metadata of the stored state.
+	Serial  uint64
+	Lineage string
+
+	// Created is the time at which the version was persisted.
+	Created time.Time
+
+	// LockInfo is the lock that was held when the version was persisted,
+	// or nil if the state was not locked at that time.
+	LockInfo *statemgr.LockInfo
+}
+
 // Payload is the return value from the remote state storage.
 type Payload struct {
 	MD5  []byte
@@ -227,7 +227,11 @@ func (s *State) PersistState(schemas *tofu.Schemas) error {
 		return err
 	}
 
-	err = s.Client.Put(buf.Bytes())
+	if versioner, ok := s.Client.(ClientVersioner); ok {
+		err = versioner.PutVersion(buf.Bytes(), f.Serial, f.Lineage)
+	} else {
+		err = s.Client.Put(buf.Bytes())
+	}
 	if err != nil {
 		return err
 	}
@@ -9,7 +9,10 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
@@ -106,3 +109,119 @@ func TestRemoteLocks(t *testing.T, a, b Client) {
 
 	// TODO: Should we enforce that Unlock requires the correct ID?
 }
+
+// TestClientVersioner is a generic function to test the state history of any
+// client implementing ClientVersioner. The client must retain at least two
+// versions of the state.
+func TestClientVersioner(t *testing.T, c ClientVersioner) {
+	var payloads [][]byte
+	for serial := uint64(1); serial <= 2; serial++ {
+		var buf bytes.Buffer
+		sf := statefile.New(statemgr.TestFullInitialState(), "stub-lineage", serial)
+		if err := statefile.Write(sf, &buf, encryption.StateEncryptionDisabled()); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := c.PutVersion(buf.Bytes(), serial, "stub-lineage"); err != nil {
+			t.Fatalf("put: %s", err)
+		}
+		payloads = append(payloads, buf.Bytes())
+	}
+
+	versions, err := c.Versions()
+	if err != nil {
+		t.Fatalf("versions: %s", err)
+	}
+	if len(versions) < 2 {
+		t.Fatalf("expected at least 2 versions, got %d", len(versions))
+	}
+
+	for i, want := range []uint64{2, 1} {
+		v := versions[i]
+		if v.Serial != want {
+			t.Fatalf("expected version %d to have serial %d, got %d", i, want, v.Serial)
+		}
+		if v.Lineage != "stub-lineage" {
+			t.Fatalf("expected version %d to have lineage %q, got %q", i, "stub-lineage", v.Lineage)
+		}
+
+		p, err := c.GetVersion(v.ID)
+		if err != nil {
+			t.Fatalf("get version %s: %s", v.ID, err)
+		}
+		if p == nil {
+			t.Fatalf("version %s not found", v.ID)
+		}
+		if !bytes.Equal(p.Data, payloads[want-1]) {
+			t.Fatalf("wrong data for version %s\nexpected: %q\ngot: %q", v.ID, string(payloads[want-1]), string(p.Data))
+		}
+	}
+}
+
+// TestClientVersionerRollback is a generic function to test that a version
+// retained by any client implementing ClientVersioner can be restored in the
+// same way as by "tofu state rollback", which writes it as a new version of
+// the current state. The state is written with the given encryption.
+func TestClientVersionerRollback(t *testing.T, c ClientVersioner, enc encryption.StateEncryption) {
+	mgr := NewState(c, enc)
+	if err := mgr.RefreshState(); err != nil {
+		t.Fatalf("refresh: %s", err)
+	}
+	for _, name := range []string{"foo", "bar"} {
+		state := states.NewState()
+		state.RootModule().SetOutputValue(name, cty.StringVal(name), false)
+		if err := statemgr.WriteAndPersist(mgr, state, nil); err != nil {
+			t.Fatalf("write %s: %s", name, err)
+		}
+	}
+
+	versions, err := c.Versions()
+	if err != nil {
+		t.Fatalf("versions: %s", err)
+	}
+	if len(versions) < 2 {
+		t.Fatalf("expected at least 2 versions, got %d", len(versions))
+	}
+	if sf := statemgr.Export(mgr); versions[0].Serial != sf.Serial || versions[0].Lineage != sf.Lineage {
+		t.Fatalf("expected the latest version to have serial %d and lineage %q, got %d and %q", sf.Serial, sf.Lineage, versions[0].Serial, versions[0].Lineage)
+	}
+	first := versions[1]
+
+	p, err := c.GetVersion(first.ID)
+	if err != nil {
+		t.Fatalf("get version %s: %s", first.ID, err)
+	}
+	if p == nil {
+		t.Fatalf("version %s not found", first.ID)
+	}
+	sf, err := statefile.Read(bytes.NewReader(p.Data), enc)
+	if err != nil {
+		t.Fatalf("read version %s: %s", first.ID, err)
+	}
+	if err := statemgr.WriteAndPersist(mgr, sf.State, nil); err != nil {
+		t.Fatalf("rollback: %s", err)
+	}
+
+	// The restored state is a new version with the next serial.
+	mgr = NewState(c, enc)
+	if err := mgr.RefreshState(); err != nil {
+		t.Fatalf("refresh: %s", err)
+	}
+	outputs := mgr.State().RootModule().OutputValues
+	if _, ok := outputs["foo"]; !ok || len(outputs) != 1 {
+		t.Fatalf("wrong outputs after rollback: %#v", outputs)
+	}
+	versions, err = c.Versions()
+	if err != nil {
+		t.Fatalf("versions: %s", err)
+	}
+	if len(versions) < 3 {
+		t.Fatalf("expected at least 3 versions after rollback, got %d", len(versions))
+	}
+	sf = statemgr.Export(mgr)
+	if sf.Serial != first.Serial+2 || sf.Lineage != first.Lineage {
+		t.Fatalf("expected the rollback to have serial %d and lineage %q, got %d and %q", first.Serial+2, first.Lineage, sf.Serial, sf.Lineage)
+	}
+	if versions[0].Serial != sf.Serial || versions[0].Lineage != sf.Lineage {
+		t.Fatalf("expected the latest version to have serial %d and lineage %q, got %d and %q", sf.Serial, sf.Lineage, versions[0].Serial, versions[0].Lineage)
+	}
+}
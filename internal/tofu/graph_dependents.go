@@ -0,0 +1,113 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+)
+
+// GraphDependent is an object of the configuration that depends, directly or
+// transitively, on a resource in a graph.
+type GraphDependent struct {
+	// Addr is the address of the dependent object, which is either an
+	// addrs.ConfigResource, an addrs.ConfigOutputValue, or an addrs.Module
+	// for a module call whose count, for_each or depends_on arguments
+	// depend on the resource.
+	Addr fmt.Stringer
+
+	// Distance is the number of objects on the path from the dependent
+	// object to the resource, including the dependent object itself. It is
+	// 1 for objects that refer to the resource directly, even when the
+	// reference goes through local values or module variables.
+	Distance int
+}
+
+// GraphDependents returns all of the objects of the configuration that depend
+// on the given resource in the given graph, ordered by distance and then by
+// address. It returns an error if the graph doesn't contain the resource.
+//
+// This is intended for graphs built for the UI, where each resource of the
+// configuration is represented by a single node.
+func GraphDependents(g *Graph, addr addrs.ConfigResource) ([]GraphDependent, error) {
+	// The dependencies are found by walking up the edges from the resource,
+	// where each object on the path counts as one step while the other
+	// nodes, such as local values and module variables, count as none. We
+	// use a double-ended queue to visit the nodes by increasing distance.
+	distances := make(map[dag.Vertex]int)
+	var queue []dag.Vertex
+	for _, v := range g.Vertices() {
+		if rn, ok := v.(GraphNodeConfigResource); ok && rn.ResourceAddr().Equal(addr) {
+			distances[v] = 0
+			queue = append(queue, v)
+		}
+	}
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("the configuration has no resource %s", addr)
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, up := range g.UpEdges(v) {
+			d := distances[v]
+			if graphDependentAddr(up, addr) != nil {
+				d++
+			}
+			if existing, ok := distances[up]; ok && existing <= d {
+				continue
+			}
+			distances[up] = d
+			if d == distances[v] {
+				queue = append([]dag.Vertex{up}, queue...)
+			} else {
+				queue = append(queue, up)
+			}
+		}
+	}
+
+	// Several nodes can represent the same object, so each object is
+	// reported once, at the shortest distance of any of its nodes.
+	byAddr := make(map[string]int)
+	var result []GraphDependent
+	for v, d := range distances {
+		depAddr := graphDependentAddr(v, addr)
+		if depAddr == nil {
+			continue
+		}
+		if i, ok := byAddr[depAddr.String()]; ok {
+			result[i].Distance = min(result[i].Distance, d)
+			continue
+		}
+		byAddr[depAddr.String()] = len(result)
+		result = append(result, GraphDependent{Addr: depAddr, Distance: d})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Distance != result[j].Distance {
+			return result[i].Distance < result[j].Distance
+		}
+		return result[i].Addr.String() < result[j].Addr.String()
+	})
+
+	return result, nil
+}
+
+// graphDependentAddr returns the address of the object represented by the
+// given node if it is reported by GraphDependents, or nil otherwise.
+func graphDependentAddr(v dag.Vertex, target addrs.ConfigResource) fmt.Stringer {
+	switch v := v.(type) {
+	case GraphNodeConfigResource:
+		if addr := v.ResourceAddr(); !addr.Equal(target) {
+			return addr
+		}
+	case *nodeExpandOutput:
+		return addrs.ConfigOutputValue{Module: v.Module, OutputValue: v.Addr}
+	case *nodeExpandModule:
+		return v.Addr
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestGraphDependents(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "a"
+}
+
+locals {
+  a = test_object.a.test_string
+}
+
+resource "test_object" "b" {
+  test_string = local.a
+}
+
+resource "test_object" "unrelated" {
+}
+
+module "child" {
+  source = "./child"
+  in     = test_object.b.test_string
+}
+
+module "counted" {
+  source = "./child"
+  count  = length(test_object.a.test_string)
+  in     = "static"
+}
+
+output "out" {
+  value = module.child.out
+}
+`,
+		"child/main.tf": `
+variable "in" {
+  type = string
+}
+
+resource "test_object" "c" {
+  test_string = var.in
+}
+
+output "out" {
+  value = test_object.c.test_string
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	g, diags := ctx.PlanGraphForUI(m, states.NewState(), plans.NormalMode)
+	assertNoErrors(t, diags)
+
+	target := addrs.ConfigResource{
+		Module: addrs.RootModule,
+		Resource: addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_object",
+			Name: "a",
+		},
+	}
+	deps, err := GraphDependents(g, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		got = append(got, fmt.Sprintf("%d %s", dep.Distance, dep.Addr))
+	}
+	want := []string{
+		"1 module.counted",
+		"1 test_object.b",
+		"2 module.child.test_object.c",
+		"2 module.counted.test_object.c",
+		"3 module.child.output.out",
+		"3 module.counted.output.out",
+		"4 output.out",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong dependents\n%s", diff)
+	}
+
+	_, err = GraphDependents(g, addrs.ConfigResource{
+		Module:   addrs.RootModule,
+		Resource: addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_object", Name: "missing"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a missing resource")
+	}
+}
+
+func TestGraphDependents_sharedAddress(t *testing.T) {
+	resource := func(name string) addrs.ConfigResource {
+		return addrs.ConfigResource{
+			Module:   addrs.RootModule,
+			Resource: addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_object", Name: name},
+		}
+	}
+
+	// Two nodes represent test_object.b, one depending on test_object.a
+	// directly and one through test_object.x.
+	var g Graph
+	a := g.Add(NewNodeAbstractResource(resource("a")))
+	x := g.Add(NewNodeAbstractResource(resource("x")))
+	b1 := g.Add(NewNodeAbstractResource(resource("b")))
+	b2 := g.Add(NewNodeAbstractResource(resource("b")))
+	g.Connect(dag.BasicEdge(x, a))
+	g.Connect(dag.BasicEdge(b1, a))
+	g.Connect(dag.BasicEdge(b2, x))
+
+	// The nodes are visited in map order, so we check several times that
+	// the shortest distance wins.
+	for i := 0; i < 20; i++ {
+		deps, err := GraphDependents(&g, resource("a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			got = append(got, fmt.Sprintf("%d %s", dep.Distance, dep.Addr))
+		}
+		want := []string{"1 test_object.b", "1 test_object.x"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("wrong dependents\n%s", diff)
+		}
+	}
+}
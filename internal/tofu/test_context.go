@@ -98,7 +98,7 @@ func (ctx *TestContext) evaluate(state *states.SyncState, changes *plans.Changes
 			PlanTimestamp:      ctx.Plan.Timestamp,
 		},
 		ModulePath:      nil, // nil for the root module
-		InstanceKeyData: EvalDataForNoInstanceKey,
+		InstanceKeyData: run.Repetition,
 		Operation:       operation,
 	}
 